@@ -1,15 +1,55 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"theater-ticket-system/internal/api"
 	"theater-ticket-system/internal/config"
+	"theater-ticket-system/internal/database/postgres"
+	model "theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	service "theater-ticket-system/internal/services"
 )
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	cfg := config.Init()
 
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	server := api.NewServer(cfg)
 
 	log.Fatal(server.Run())
 }
+
+// runCommand выполняет служебную подкоманду вместо запуска сервера
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "promote-admin":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s promote-admin <email>", os.Args[0])
+		}
+		if err := postgres.Init(cfg); err != nil {
+			return err
+		}
+
+		users := service.NewUsers(repository.NewUsers(postgres.DB))
+		user, err := users.SetRole(args[0], model.RoleAdmin)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("User %s (%s) is now %s", user.Email, user.ID, user.Role)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
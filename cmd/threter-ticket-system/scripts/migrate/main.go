@@ -1,18 +1,80 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
 	"theater-ticket-system/internal/config"
 	"theater-ticket-system/internal/database/postgres"
 )
 
+const usage = "usage: migrate [up|down|status|redo|baseline]"
+
 func main() {
+	command := "up"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
 	cfg := config.Init()
 	if err := postgres.Init(cfg); err != nil {
 		log.Fatal(err)
 	}
 
-	if err := postgres.Migrate(); err != nil {
+	migrator, err := postgres.NewMigrator()
+	if err != nil {
 		log.Fatal(err)
 	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Println("Database is up to date")
+		}
+		for _, m := range applied {
+			log.Printf("Applied %04d_%s", m.Version, m.Name)
+		}
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if m == nil {
+			log.Println("Nothing to roll back")
+			return
+		}
+		log.Printf("Rolled back %04d_%s", m.Version, m.Name)
+	case "redo":
+		m, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Redone %04d_%s", m.Version, m.Name)
+	case "baseline":
+		m, err := migrator.Baseline(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Marked %04d_%s as applied", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		log.Fatal(usage)
+	}
 }
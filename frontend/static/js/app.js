@@ -26,11 +26,12 @@ function showSection(sectionIdToShow) {
 // Загрузка спектаклей с API и отрисовка списка
 async function loadPlays() {
     try {
-        const response = await fetch('/api/plays');
+        const response = await fetch('/api/plays?limit=100');
         if (!response.ok) throw new Error('HTTP ' + response.status);
-        const plays = await response.json();
+        const page = await response.json();
+        const plays = page && page.items;
         if (!Array.isArray(plays)) {
-            throw new Error('Unexpected response format: expected array');
+            throw new Error('Unexpected response format: expected page of plays');
         }
 
         const playsContainer = document.getElementById('plays-list');
@@ -133,7 +134,9 @@ async function loadUserBookings(container, email) {
     container.innerHTML = 'Загрузка бронирований...';
 
     try {
-        const bookingsResp = await fetch(`/api/bookings?email=${encodeURIComponent(email)}`);
+        const bookingsResp = await fetch('/api/bookings', {
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
         if (!bookingsResp.ok) throw new Error('Ошибка загрузки бронирований');
 
         const bookings = await bookingsResp.json();
@@ -287,8 +290,9 @@ function renderBookingsHtml(bookings) {
 	`;
 }
 
-// Глобальная переменная для хранения верифицированного email
+// Глобальные переменные для хранения верифицированного email и access-токена
 let verifiedEmail = null;
+let accessToken = null;
 
 let selectedSeats = [];
 let currentPerformanceId = null;
@@ -380,7 +384,8 @@ async function cancelBooking(bookingId) {
         async () => {
             try {
                 const response = await fetch(`/api/bookings/${bookingId}/cancel`, {
-                    method: 'PATCH'
+                    method: 'PATCH',
+                    headers: { 'Authorization': `Bearer ${accessToken}` }
                 });
 
                 if (!response.ok) {
@@ -600,6 +605,7 @@ function showEmailCodeModal(onVerified) {
 
                 const result = await response.json();
                 if (!result.verified) throw new Error('Код не подтвержден');
+                accessToken = result.tokens.access_token;
 
                 closeModal(modal);
                 await onVerified(email);
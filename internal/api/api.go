@@ -4,9 +4,13 @@ import (
 	"strings"
 	_ "theater-ticket-system/docs"
 	"theater-ticket-system/internal/api/controllers"
+	"theater-ticket-system/internal/api/middleware"
 	"theater-ticket-system/internal/database/postgres"
+	model "theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/ratelimit"
 	"theater-ticket-system/internal/repository"
 	service "theater-ticket-system/internal/services"
+	"theater-ticket-system/internal/tickets"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -15,6 +19,25 @@ import (
 
 func (s *Server) setupRoutes() {
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	usersRepo := repository.NewUsers(postgres.DB)
+	sessionsRepo := repository.NewSessions(postgres.DB)
+	tokensService := service.NewTokens(sessionsRepo, usersRepo, s.cfg.Auth.JWTSecret,
+		s.cfg.Auth.AccessTTL, s.cfg.Auth.RefreshTTL, service.SystemClock)
+	requireAuth := middleware.RequireAuth(tokensService)
+	optionalAuth := middleware.OptionalAuth(tokensService)
+	canManageCatalog := middleware.RequirePermission(model.PermissionManageCatalog)
+	canManageSchedule := middleware.RequirePermission(model.PermissionManageSchedule)
+	canManageHalls := middleware.RequirePermission(model.PermissionManageHalls)
+	canManagePromos := middleware.RequirePermission(model.PermissionManagePromos)
+	canScanTickets := middleware.RequirePermission(model.PermissionScanTickets)
+	canManageRefunds := middleware.RequirePermission(model.PermissionManageRefunds)
+	canViewAudit := middleware.RequirePermission(model.PermissionViewAudit)
+	canManageUsers := middleware.RequirePermission(model.PermissionManageUsers)
+	canSellTickets := middleware.RequirePermission(model.PermissionSellTickets)
+	ticketSigner := tickets.NewSigner(s.cfg.Tickets.SigningSecret)
+	emailService := s.emails
+	auditService := s.audit
+
 	api := s.router.Group("/api")
 	{
 		api.GET("/health-check", func(c *gin.Context) {
@@ -24,39 +47,98 @@ func (s *Server) setupRoutes() {
 		// Auth
 		auth := api.Group("/auth")
 		{
-			authRepo := repository.NewAuth(postgres.DB)
-			emailService := service.NewEmailService(s.cfg)
-			authService := service.NewAuth(authRepo, emailService)
+			authRepo := newAuthStore()
+			authService := service.NewAuth(authRepo, usersRepo, tokensService, emailService, service.SystemClock,
+				service.AuthLimits{
+					CodesPerEmail: s.cfg.RateLimit.CodesPerEmail,
+					CodesWindow:   s.cfg.RateLimit.CodesWindow,
+					CooldownBase:  s.cfg.RateLimit.CodeCooldown,
+					CooldownMax:   s.cfg.RateLimit.CodeCooldownMax,
+					MaxAttempts:   s.cfg.RateLimit.CodeMaxAttempts,
+				})
 			authController := controllers.NewAuthController(authService)
 
-			auth.POST("/send-code", authController.SendCode)
-			auth.POST("/verify-code", authController.VerifyCode)
+			sendCodeLimit := middleware.RateLimit(s.rateLimits, ratelimit.Rule{
+				Name: "send-code", Limit: s.cfg.RateLimit.SendCodePerIP, Window: s.cfg.RateLimit.Window,
+			}, middleware.ByClientIP)
+			verifyCodeLimit := middleware.RateLimit(s.rateLimits, ratelimit.Rule{
+				Name: "verify-code", Limit: s.cfg.RateLimit.VerifyCodePerIP, Window: s.cfg.RateLimit.Window,
+			}, middleware.ByClientIP)
+
+			auth.POST("/send-code", sendCodeLimit, authController.SendCode)
+			auth.POST("/verify-code", verifyCodeLimit, authController.VerifyCode)
+			auth.POST("/refresh", authController.Refresh)
+			auth.POST("/logout", authController.Logout)
 		}
 
 		// Plays
 		plays := api.Group("/plays")
 		{
-			playsRepo := repository.NewPlays(postgres.DB)
-			playsService := service.NewPlays(playsRepo)
+			playsRepo := newPlaysStore()
+			playsService := service.NewPlays(playsRepo, auditService)
 			playsController := controllers.NewPlays(playsService)
 
 			plays.GET("", playsController.GetAllPlays)
 			plays.GET("/:id", playsController.GetPlayByID)
-			plays.POST("", playsController.CreatePlay)
-			plays.PUT("/:id", playsController.UpdatePlay)
-			plays.DELETE("/:id", playsController.DeletePlay)
+			plays.POST("", requireAuth, canManageCatalog, playsController.CreatePlay)
+			plays.PUT("/:id", requireAuth, canManageCatalog, playsController.UpdatePlay)
+			plays.DELETE("/:id", requireAuth, canManageCatalog, playsController.DeletePlay)
+
+			// Search
+			api.GET("/search", playsController.Search)
+		}
+
+		bookingsRepo := newBookingsStore()
+		refundsService := service.NewRefunds(repository.NewRefunds(postgres.DB), bookingsRepo, s.paymentProvider,
+			s.refundPolicy, service.SystemClock, emailService, s.waitlist, auditService)
+
+		// Price plans
+		pricePlansRepo := repository.NewPricePlans(postgres.DB)
+		pricePlans := api.Group("/price-plans", requireAuth, canManageSchedule)
+		{
+			pricePlansService := service.NewPricePlans(pricePlansRepo)
+			pricePlansController := controllers.NewPricePlansController(pricePlansService)
+
+			pricePlans.GET("", pricePlansController.GetAllPricePlans)
+			pricePlans.GET("/:id", pricePlansController.GetPricePlanByID)
+			pricePlans.POST("", pricePlansController.CreatePricePlan)
 		}
 
 		// Performances
 		performances := api.Group("/performances")
 		{
-			performancesRepo := repository.NewPerformances(postgres.DB)
+			performancesRepo := newPerformancesStore()
 			performancesService := service.NewPerformances(performancesRepo)
-			performancesController := controllers.NewPerformancesController(performancesService)
+			scheduleService := service.NewSchedule(performancesRepo,
+				newPlaysStore(), repository.NewSeats(postgres.DB), pricePlansRepo,
+				service.DefaultPricing, s.cfg.Schedule.TurnoverBuffer, service.SystemClock, auditService)
+			performancesController := controllers.NewPerformancesController(performancesService, scheduleService)
+			disruptionsService := service.NewDisruptions(performancesRepo, bookingsRepo, scheduleService, refundsService,
+				service.SystemClock, emailService, auditService)
+			disruptionsController := controllers.NewDisruptionsController(disruptionsService)
 
 			performances.GET("", performancesController.GetAllPerformances)
 			performances.GET("/:id", performancesController.GetPerformanceByID)
 			performances.GET("/:id/seats", performancesController.GetPerformanceSeats)
+			performances.GET("/:id/seats/stream", controllers.NewSeatStreamController(s.seatStream).StreamSeats)
+			performances.POST("", requireAuth, canManageSchedule, performancesController.CreatePerformance)
+			performances.PUT("/:id", requireAuth, canManageSchedule, performancesController.UpdatePerformance)
+			performances.DELETE("/:id", requireAuth, canManageSchedule, disruptionsController.CancelPerformance)
+			performances.POST("/:id/status", requireAuth, canManageSchedule, performancesController.SetPerformanceStatus)
+			performances.POST("/:id/postpone", requireAuth, canManageSchedule, disruptionsController.PostponePerformance)
+
+			holdsController := controllers.NewHoldsController(s.holds)
+			holdLimit := middleware.RateLimit(s.rateLimits, ratelimit.Rule{
+				Name: "seat-holds", Limit: s.cfg.RateLimit.HoldsPerIP, Window: s.cfg.RateLimit.Window,
+			}, middleware.ByClientIP)
+			performances.POST("/:id/holds", holdLimit, holdsController.CreateHold)
+			api.POST("/holds/:id/extend", holdsController.ExtendHold)
+			api.DELETE("/holds/:id", holdsController.ReleaseHold)
+
+			waitlistController := controllers.NewWaitlistController(s.waitlist)
+			performances.POST("/:id/waitlist", requireAuth, waitlistController.JoinWaitlist)
+			performances.GET("/:id/waitlist", requireAuth, waitlistController.GetWaitlistEntry)
+			performances.DELETE("/:id/waitlist", requireAuth, waitlistController.LeaveWaitlist)
 		}
 
 		// Halls/Seats
@@ -66,21 +148,101 @@ func (s *Server) setupRoutes() {
 			seatsService := service.NewSeats(seatsRepo)
 			seatsController := controllers.NewSeatsController(seatsService)
 
+			hallsRepo := newHallsStore()
+			hallsService := service.NewHalls(hallsRepo, service.DefaultPricing, service.SystemClock, auditService)
+			hallsController := controllers.NewHallsController(hallsService)
+
+			halls.GET("", hallsController.GetAllHalls)
+			halls.GET("/:id", hallsController.GetHallByID)
 			halls.GET("/:id/seats", seatsController.GetHallSeats)
+			halls.POST("", requireAuth, canManageHalls, hallsController.CreateHall)
+			halls.PUT("/:id", requireAuth, canManageHalls, hallsController.UpdateHall)
+			halls.PUT("/:id/layout", requireAuth, canManageHalls, hallsController.UpdateLayout)
+			halls.DELETE("/:id", requireAuth, canManageHalls, hallsController.DeleteHall)
+		}
+
+		// Promo codes
+		promoCodes := api.Group("/promo-codes", requireAuth, canManagePromos)
+		{
+			promoCodesService := service.NewPromoCodes(repository.NewPromoCodes(postgres.DB))
+			promoCodesController := controllers.NewPromoCodesController(promoCodesService)
+
+			promoCodes.GET("", promoCodesController.GetAllPromoCodes)
+			promoCodes.POST("", promoCodesController.CreatePromoCode)
+			promoCodes.PATCH("/:id/deactivate", promoCodesController.DeactivatePromoCode)
 		}
 
+		bookingsService := service.NewBookings(bookingsRepo, usersRepo,
+			service.WithBookingHoldTTL(s.cfg.Booking.HoldTTL),
+			service.WithSalesCloseBefore(s.cfg.Schedule.SalesCloseBefore),
+			service.WithBookingsPricing(service.NewPricingEngine(s.cfg.Pricing.Location)),
+			service.WithBookingsEmails(emailService),
+			service.WithBookingsWaitlist(s.waitlist),
+			service.WithBookingsRefunds(refundsService),
+			service.WithBookingsAudit(auditService),
+			service.WithBookingsCurrency(s.cfg.Payment.Currency),
+		)
+
 		// Bookings
 		bookings := api.Group("/bookings")
 		{
-			bookingsRepo := repository.NewBookings(postgres.DB)
-			usersRepo := repository.NewUsers(postgres.DB)
-			bookingsService := service.NewBookings(bookingsRepo, usersRepo)
+			refundsController := controllers.NewRefundsController(refundsService)
 			bookingsController := controllers.NewBookingsController(bookingsService)
 
-			bookings.POST("", bookingsController.CreateBooking)
+			paymentsRepo := repository.NewPayments(postgres.DB)
+			paymentsService := service.NewPayments(paymentsRepo, bookingsRepo, s.paymentProvider,
+				service.SystemClock, s.cfg.Payment.Currency, emailService, auditService)
+			paymentsController := controllers.NewPaymentsController(paymentsService)
+
+			ticketsService := service.NewTickets(bookingsRepo, ticketSigner, s.cfg.Pricing.Location)
+			ticketsController := controllers.NewTicketsController(ticketsService)
+
+			bookings.POST("", optionalAuth, bookingsController.CreateBooking)
 			bookings.GET("/:id", bookingsController.GetBookingByID)
-			bookings.GET("", bookingsController.GetUserBookings)
-			bookings.PATCH("/:id/cancel", bookingsController.CancelBooking)
+			bookings.GET("", requireAuth, bookingsController.GetUserBookings)
+			bookings.PATCH("/:id/cancel", requireAuth, bookingsController.CancelBooking)
+			bookings.PATCH("/:id/reminders", requireAuth, bookingsController.SetReminders)
+			bookings.POST("/:id/cancel-seats", requireAuth, refundsController.CancelSeats)
+			bookings.GET("/:id/refunds", requireAuth, refundsController.GetRefunds)
+			bookings.POST("/:id/refunds", requireAuth, canManageRefunds, refundsController.OverrideRefund)
+			bookings.POST("/:id/confirm", paymentsController.ConfirmBooking)
+			bookings.GET("/:id/tickets.pdf", requireAuth, ticketsController.BookingTicketsPDF)
+
+			api.POST("/payments/webhook", paymentsController.Webhook)
+		}
+
+		// Box office
+		boxOffice := api.Group("/box-office", requireAuth, canSellTickets)
+		{
+			boxOfficeService := service.NewBoxOffice(bookingsService, repository.NewBoxOffice(postgres.DB),
+				service.SystemClock, s.cfg.Pricing.Location)
+			boxOfficeController := controllers.NewBoxOfficeController(boxOfficeService)
+
+			boxOffice.POST("/sales", boxOfficeController.Sell)
+			boxOffice.GET("/sales/:id/receipt.pdf", boxOfficeController.SaleReceiptPDF)
+			boxOffice.GET("/shifts/report", boxOfficeController.GetShiftReport)
+		}
+
+		// Check-in
+		checkIn := api.Group("/checkin", requireAuth, canScanTickets)
+		{
+			checkInService := service.NewCheckIn(newCheckInStore(), ticketSigner,
+				service.SystemClock, s.cfg.CheckIn.OpensBefore, s.cfg.CheckIn.ClosesAfter)
+			checkInController := controllers.NewCheckInController(checkInService)
+
+			checkIn.POST("/scan", checkInController.Scan)
+			checkIn.POST("/batch", checkInController.ScanBatch)
+			checkIn.GET("/performances/:id/attendance", checkInController.GetAttendance)
+		}
+
+		// Admin
+		admin := api.Group("/admin", requireAuth)
+		{
+			auditController := controllers.NewAuditController(auditService)
+			usersController := controllers.NewUsersController(service.NewUsers(usersRepo))
+
+			admin.GET("/audit", canViewAudit, auditController.GetAuditLog)
+			admin.PUT("/users/role", canManageUsers, usersController.SetUserRole)
 		}
 	}
 
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditService interface {
+	List(filter service.AuditFilter, cursor string, limit int) ([]model.AuditEntry, string, error)
+}
+
+type AuditController struct {
+	service AuditService
+}
+
+func NewAuditController(service AuditService) *AuditController {
+	return &AuditController{service: service}
+}
+
+// GetAuditLog godoc
+// @Summary Get audit log
+// @Description Get changes of plays, performances, halls and bookings from newest to oldest. Pass next_cursor from the previous page as cursor to get the next page
+// @Tags admin
+// @Produce json
+// @Param actor_id query string false "User who made the change"
+// @Param action query string false "Action, e.g. play.update or booking.cancel"
+// @Param entity_type query string false "Entity type" Enums(play, performance, hall, booking, waitlist_entry)
+// @Param entity_id query string false "Entity ID"
+// @Param from query string false "Changes at or after this time (RFC3339)"
+// @Param to query string false "Changes before this time (RFC3339)"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size, 50 by default" minimum(1) maximum(200)
+// @Success 200 {object} response.AuditPage
+// @Failure 400 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/admin/audit [get]
+func (c *AuditController) GetAuditLog(ctx *gin.Context) {
+	var req request.AuditQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := service.AuditFilter{
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		From:       req.From,
+		To:         req.To,
+	}
+	var err error
+	if filter.ActorID, err = optionalUUID(req.ActorID, "actor ID"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.EntityID, err = optionalUUID(req.EntityID, "entity ID"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, next, err := c.service.List(filter, req.Cursor, req.Limit)
+	if errors.Is(err, service.ErrInvalidAuditCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := response.AuditPage{
+		Entries:    make([]response.AuditEntry, len(entries)),
+		NextCursor: next,
+	}
+	for i := range entries {
+		resp.Entries[i] = entries[i].Response()
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
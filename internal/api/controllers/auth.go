@@ -1,14 +1,19 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	service "theater-ticket-system/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthService interface {
 	SendVerificationCode(email string) error
-	VerifyCode(email, code string) (bool, error)
+	VerifyCode(email, code string) (*service.TokenPair, error)
+	Refresh(refreshToken string) (*service.TokenPair, error)
+	Logout(refreshToken string) error
 }
 
 type AuthController struct {
@@ -21,12 +26,13 @@ func NewAuthController(service AuthService) *AuthController {
 
 // SendCode godoc
 // @Summary Send verification code
-// @Description Send verification code to email
+// @Description Send verification code to email. Repeated requests for the same email must wait an exponentially growing cooldown
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body object{email=string} true "Email"
 // @Success 200 {object} object{message=string}
+// @Failure 429 {object} object{error=string,retry_after=integer}
 // @Router /api/auth/send-code [post]
 func (c *AuthController) SendCode(ctx *gin.Context) {
 	var req struct {
@@ -39,6 +45,12 @@ func (c *AuthController) SendCode(ctx *gin.Context) {
 	}
 
 	if err := c.service.SendVerificationCode(req.Email); err != nil {
+		var retry *service.RetryLaterError
+		if errors.As(err, &retry) {
+			middleware.SetRetryAfter(ctx, retry.RetryAfter)
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": int(retry.RetryAfter.Seconds())})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -48,12 +60,13 @@ func (c *AuthController) SendCode(ctx *gin.Context) {
 
 // VerifyCode godoc
 // @Summary Verify code
-// @Description Verify email with code
+// @Description Verify email with code and open a session. The code is locked after too many failed attempts
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body object{email=string,code=string} true "Email and code"
-// @Success 200 {object} object{verified=boolean}
+// @Success 200 {object} object{verified=boolean,tokens=response.Tokens}
+// @Failure 429 {object} object{error=string}
 // @Router /api/auth/verify-code [post]
 func (c *AuthController) VerifyCode(ctx *gin.Context) {
 	var req struct {
@@ -66,11 +79,70 @@ func (c *AuthController) VerifyCode(ctx *gin.Context) {
 		return
 	}
 
-	verified, err := c.service.VerifyCode(req.Email, req.Code)
+	tokens, err := c.service.VerifyCode(req.Email, req.Code)
+	if errors.Is(err, service.ErrCodeLocked) {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"verified": verified})
+	ctx.JSON(http.StatusOK, gin.H{"verified": true, "tokens": tokens.Response()})
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair. The old refresh token is revoked
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{refresh_token=string} true "Refresh token"
+// @Success 200 {object} response.Tokens
+// @Router /api/auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	tokens, err := c.service.Refresh(req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens.Response())
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the session of a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body object{refresh_token=string} true "Refresh token"
+// @Success 204
+// @Router /api/auth/logout [post]
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.service.Logout(req.RefreshToken); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
 }
@@ -1,19 +1,23 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"theater-ticket-system/internal/api/middleware"
 	model "theater-ticket-system/internal/models/models"
 	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type BookingsService interface {
-	CreateBooking(email, name string, performanceID uuid.UUID, seatIDs []uuid.UUID) (*model.Booking, error)
+	CreateBooking(actor model.Actor, email, name string, performanceID uuid.UUID, seatIDs []uuid.UUID, promoCode, holdToken string) (*model.Booking, error)
 	GetBookingByID(id string) (*model.Booking, error)
 	GetUserBookings(email string) ([]model.Booking, error)
-	CancelBooking(id string) error
+	CancelBooking(actor model.Actor, id string) error
+	SetReminders(actor model.Actor, id string, enabled bool) (*model.Booking, error)
 }
 
 type BookingsController struct {
@@ -26,19 +30,24 @@ func NewBookingsController(service BookingsService) *BookingsController {
 
 // CreateBooking godoc
 // @Summary Create booking
-// @Description Create a new booking for selected seats. User will be created or found by email
+// @Description Create a new booking for selected seats. User will be created or found by email. With hold_token the held seats are booked atomically; without seat_ids all held seats are booked
 // @Tags bookings
 // @Accept json
 // @Produce json
-// @Param booking body object{email=string,name=string,performance_id=string,seat_ids=[]string} true "Booking object"
+// @Param booking body object{email=string,name=string,performance_id=string,seat_ids=[]string,promo_code=string,hold_token=string} true "Booking object"
 // @Success 201 {object} response.Booking
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Failure 422 {object} object{error=string}
 // @Router /api/bookings [post]
 func (c *BookingsController) CreateBooking(ctx *gin.Context) {
 	var req struct {
 		Email         string      `json:"email" binding:"required,email"`
 		Name          string      `json:"name" binding:"required"`
 		PerformanceID uuid.UUID   `json:"performance_id" binding:"required"`
-		SeatIDs       []uuid.UUID `json:"seat_ids" binding:"required,min=1"`
+		SeatIDs       []uuid.UUID `json:"seat_ids" binding:"required_without=HoldToken"`
+		PromoCode     string      `json:"promo_code"`
+		HoldToken     string      `json:"hold_token"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -46,8 +55,19 @@ func (c *BookingsController) CreateBooking(ctx *gin.Context) {
 		return
 	}
 
-	booking, err := c.service.CreateBooking(req.Email, req.Name, req.PerformanceID, req.SeatIDs)
-	if err != nil {
+	booking, err := c.service.CreateBooking(middleware.CurrentActor(ctx), req.Email, req.Name, req.PerformanceID, req.SeatIDs, req.PromoCode, req.HoldToken)
+	switch {
+	case errors.Is(err, service.ErrHoldNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrHoldExpired):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrNotOnSale), errors.Is(err, service.ErrPerformanceCancelled),
+		errors.Is(err, service.ErrPerformanceStarted):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -77,20 +97,20 @@ func (c *BookingsController) GetBookingByID(ctx *gin.Context) {
 
 // GetUserBookings godoc
 // @Summary Get user bookings
-// @Description Get booking history for a user by email
+// @Description Get booking history of the authenticated user
 // @Tags bookings
 // @Produce json
-// @Param email query string true "User email"
+// @Security BearerAuth
 // @Success 200 {array} response.Booking
 // @Router /api/bookings [get]
 func (c *BookingsController) GetUserBookings(ctx *gin.Context) {
-	email := ctx.Query("email")
-	if email == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
 		return
 	}
 
-	bookings, err := c.service.GetUserBookings(email)
+	bookings, err := c.service.GetUserBookings(user.Email)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -106,17 +126,19 @@ func (c *BookingsController) GetUserBookings(ctx *gin.Context) {
 
 // CancelBooking godoc
 // @Summary Cancel booking
-// @Description Cancel an existing booking (only pending bookings can be cancelled)
+// @Description Cancel an existing booking. A paid booking is refunded according to the refund policy. Available to the booking owner and staff
 // @Tags bookings
 // @Produce json
 // @Param id path string true "Booking ID"
 // @Success 200 {object} response.Booking
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
 // @Router /api/bookings/{id}/cancel [patch]
 func (c *BookingsController) CancelBooking(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.service.CancelBooking(id); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.service.CancelBooking(middleware.CurrentActor(ctx), id); err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -128,3 +150,34 @@ func (c *BookingsController) CancelBooking(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, booking.Response())
 }
+
+// SetReminders godoc
+// @Summary Configure performance reminders
+// @Description Opt in or out of reminder emails before the performance for a booking. Available to the booking owner and staff
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param reminders body object{enabled=bool} true "Reminder settings"
+// @Success 200 {object} response.Booking
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/bookings/{id}/reminders [patch]
+func (c *BookingsController) SetReminders(ctx *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := c.service.SetReminders(middleware.CurrentActor(ctx), ctx.Param("id"), *req.Enabled)
+	if err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking.Response())
+}
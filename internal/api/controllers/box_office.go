@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BoxOfficeService interface {
+	Sell(actor model.Actor, sale service.BoxOfficeSale) (*model.Booking, *model.Payment, error)
+	ReceiptPDF(id string) ([]byte, error)
+	ShiftReport(cashierID uuid.UUID, from, to *time.Time, counted *int) (*service.ShiftReport, error)
+}
+
+type BoxOfficeController struct {
+	service BoxOfficeService
+}
+
+func NewBoxOfficeController(service BoxOfficeService) *BoxOfficeController {
+	return &BoxOfficeController{service: service}
+}
+
+// Sell godoc
+// @Summary Sell seats at the box office
+// @Description Sell seats to a customer at the window. Seats are sold and tickets issued immediately, no emails are sent. The payment is recorded with its method (cash or card terminal) and the cashier. Without an email the sale is a walk-in sale; promo codes need an email. Cash sales require the tendered amount to cover the total. The box office keeps selling after online sales close, until the performance starts
+// @Tags box-office
+// @Accept json
+// @Produce json
+// @Param request body request.BoxOfficeSale true "Sale"
+// @Success 201 {object} response.BoxOfficeSale
+// @Failure 422 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/box-office/sales [post]
+func (c *BoxOfficeController) Sell(ctx *gin.Context) {
+	var req request.BoxOfficeSale
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, payment, err := c.service.Sell(middleware.CurrentActor(ctx), service.BoxOfficeSale{
+		PerformanceID: req.PerformanceID,
+		SeatIDs:       req.SeatIDs,
+		Email:         req.Email,
+		Name:          req.Name,
+		PromoCode:     req.PromoCode,
+		Method:        req.Method,
+		Tendered:      req.Tendered,
+	})
+	switch {
+	case errors.Is(err, service.ErrNotOnSale), errors.Is(err, service.ErrPerformanceCancelled),
+		errors.Is(err, service.ErrPerformanceStarted), errors.Is(err, service.ErrInsufficientCash):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.BoxOfficeSale{
+		Booking: booking.Response(),
+		Payment: boxOfficePaymentResponse(payment),
+	})
+}
+
+// SaleReceiptPDF godoc
+// @Summary Get box office receipt
+// @Description Render a receipt of a box office sale for an 80 mm receipt printer
+// @Tags box-office
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Failure 404 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/box-office/sales/{id}/receipt.pdf [get]
+func (c *BoxOfficeController) SaleReceiptPDF(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	pdf, err := c.service.ReceiptPDF(id)
+	if errors.Is(err, service.ErrNotBoxOfficeSale) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="receipt-`+id+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetShiftReport godoc
+// @Summary Get cashier shift report
+// @Description Reconcile a cashier's shift: sales and refunds of their sales by payment method, revenue and the cash expected in the drawer. The shift defaults to the current day in the theatre time zone. With counted_cash the report shows the discrepancy. Reports of other cashiers require the shifts:view permission
+// @Tags box-office
+// @Produce json
+// @Param cashier_id query string false "Cashier ID, defaults to the current user"
+// @Param from query string false "Shift start (RFC 3339)"
+// @Param to query string false "Shift end (RFC 3339)"
+// @Param counted_cash query int false "Cash counted in the drawer"
+// @Success 200 {object} response.ShiftReport
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/box-office/shifts/report [get]
+func (c *BoxOfficeController) GetShiftReport(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+		return
+	}
+
+	var req request.ShiftReportQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cashierID := user.ID
+	if req.CashierID != "" {
+		cashierID = uuid.MustParse(req.CashierID)
+	}
+	if cashierID != user.ID && !user.Can(model.PermissionViewShifts) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	report, err := c.service.ShiftReport(cashierID, req.From, req.To, req.CountedCash)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.ShiftReport{
+		CashierID:    report.CashierID,
+		From:         report.From,
+		To:           report.To,
+		Sales:        methodTotalsResponse(report.Sales),
+		Refunds:      methodTotalsResponse(report.Refunds),
+		Revenue:      report.Revenue,
+		ExpectedCash: report.ExpectedCash,
+		CountedCash:  report.CountedCash,
+		Discrepancy:  report.Discrepancy,
+	})
+}
+
+func boxOfficePaymentResponse(payment *model.Payment) response.BoxOfficePayment {
+	resp := response.BoxOfficePayment{
+		ID:        payment.ID,
+		Method:    payment.Method,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Tendered:  payment.Tendered,
+		CreatedAt: payment.CreatedAt,
+	}
+	if payment.CashierID != nil {
+		resp.CashierID = *payment.CashierID
+	}
+	if payment.Tendered != nil {
+		change := *payment.Tendered - payment.Amount
+		resp.Change = &change
+	}
+	return resp
+}
+
+func methodTotalsResponse(totals []service.MethodTotal) []response.MethodTotal {
+	resp := make([]response.MethodTotal, len(totals))
+	for i, total := range totals {
+		resp[i] = response.MethodTotal{
+			Method: total.Method,
+			Count:  total.Count,
+			Amount: total.Amount,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CheckInService interface {
+	Scan(input service.ScanInput) (*model.TicketScan, error)
+	ScanBatch(performanceID uuid.UUID, gate, deviceID string, scans []service.ScanInput) ([]service.BatchScanResult, error)
+	GetAttendance(performanceID string) (*service.Attendance, error)
+}
+
+type CheckInController struct {
+	service CheckInService
+}
+
+func NewCheckInController(service CheckInService) *CheckInController {
+	return &CheckInController{service: service}
+}
+
+// Scan godoc
+// @Summary Scan ticket
+// @Description Verify a scanned ticket code and admit the visitor. A ticket that was already scanned is rejected with the original admission time
+// @Tags checkin
+// @Accept json
+// @Produce json
+// @Param scan body request.Scan true "Scanned ticket"
+// @Success 200 {object} response.TicketScan
+// @Failure 409 {object} response.TicketScan
+// @Security BearerAuth
+// @Router /api/checkin/scan [post]
+func (c *CheckInController) Scan(ctx *gin.Context) {
+	var req request.Scan
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scan, err := c.service.Scan(service.ScanInput{
+		Code:          req.Code,
+		PerformanceID: req.PerformanceID,
+		Gate:          req.Gate,
+		DeviceID:      req.DeviceID,
+	})
+	if scan == nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := scan.Response()
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, resp)
+	case errors.Is(err, service.ErrTicketAlreadyAdmitted):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":         err.Error(),
+			"admitted_at":   resp.AdmittedAt,
+			"admitted_gate": resp.AdmittedGate,
+			"scan":          resp,
+		})
+	default:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "scan": resp})
+	}
+}
+
+// ScanBatch godoc
+// @Summary Upload offline scans
+// @Description Upload scans collected by a device while it was offline. Scans are applied in device time order; results are returned in request order
+// @Tags checkin
+// @Accept json
+// @Produce json
+// @Param batch body request.ScanBatch true "Offline scans"
+// @Success 200 {array} response.BatchScanResult
+// @Security BearerAuth
+// @Router /api/checkin/batch [post]
+func (c *CheckInController) ScanBatch(ctx *gin.Context) {
+	var req request.ScanBatch
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scans := make([]service.ScanInput, len(req.Scans))
+	for i, s := range req.Scans {
+		scans[i] = service.ScanInput{Code: s.Code, ScannedAt: s.ScannedAt}
+	}
+
+	results, err := c.service.ScanBatch(req.PerformanceID, req.Gate, req.DeviceID, scans)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]response.BatchScanResult, len(results))
+	for i, result := range results {
+		resp[i].Code = req.Scans[i].Code
+		if result.Err != nil {
+			resp[i].Error = result.Err.Error()
+		}
+		if result.Scan != nil {
+			scan := result.Scan.Response()
+			resp[i].Scan = &scan
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetAttendance godoc
+// @Summary Performance attendance
+// @Description Get number of issued tickets and admitted visitors for a performance
+// @Tags checkin
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Success 200 {object} response.Attendance
+// @Security BearerAuth
+// @Router /api/checkin/performances/{id}/attendance [get]
+func (c *CheckInController) GetAttendance(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	attendance, err := c.service.GetAttendance(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	performanceID, _ := uuid.Parse(id)
+	ctx.JSON(http.StatusOK, response.Attendance{
+		PerformanceID: performanceID,
+		Tickets:       attendance.Tickets,
+		Admitted:      attendance.Admitted,
+		ByGate:        attendance.ByGate,
+	})
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DisruptionsService interface {
+	Cancel(actor model.Actor, id string) (*service.DisruptionReport, error)
+	Postpone(actor model.Actor, id string, target service.PostponeTarget) (*service.DisruptionReport, error)
+}
+
+type DisruptionsController struct {
+	service DisruptionsService
+}
+
+func NewDisruptionsController(service DisruptionsService) *DisruptionsController {
+	return &DisruptionsController{service: service}
+}
+
+// CancelPerformance godoc
+// @Summary Cancel performance
+// @Description Cancel a performance together with all its bookings. Paid bookings are refunded in full and every customer is notified. Bookings that could not be refunded are listed in the report and stay active; cancel the performance again to retry them. Completed performances cannot be cancelled
+// @Tags performances
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Success 200 {object} response.DisruptionReport
+// @Failure 409 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/performances/{id} [delete]
+func (c *DisruptionsController) CancelPerformance(ctx *gin.Context) {
+	report, err := c.service.Cancel(middleware.CurrentActor(ctx), ctx.Param("id"))
+	c.respond(ctx, report, err)
+}
+
+// PostponePerformance godoc
+// @Summary Postpone performance
+// @Description Postpone a performance to another performance of the same play or to a new date in the same hall. Bookings move to the same seats and keep their prices; tickets of paid bookings are reissued. Every customer is notified. Bookings whose seats are taken or missing on the new performance stay on the postponed one and are listed in the report; postpone again to the same performance to retry them
+// @Tags performances
+// @Accept json
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Param target body request.PostponePerformance true "New performance or date"
+// @Success 200 {object} response.DisruptionReport
+// @Failure 409 {object} object{error=string}
+// @Failure 422 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/performances/{id}/postpone [post]
+func (c *DisruptionsController) PostponePerformance(ctx *gin.Context) {
+	var req request.PostponePerformance
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := c.service.Postpone(middleware.CurrentActor(ctx), ctx.Param("id"), service.PostponeTarget{
+		PerformanceID: req.PerformanceID,
+		Date:          req.Date,
+	})
+	c.respond(ctx, report, err)
+}
+
+func (c *DisruptionsController) respond(ctx *gin.Context, report *service.DisruptionReport, err error) {
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, disruptionReportResponse(report))
+	case errors.Is(err, service.ErrInvalidTransition):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPostponement):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func disruptionReportResponse(report *service.DisruptionReport) response.DisruptionReport {
+	resp := response.DisruptionReport{
+		Performance: report.Performance.Response(),
+		Moved:       report.Moved,
+		Cancelled:   report.Cancelled,
+		Refunded:    report.Refunded,
+		Unresolved:  make([]response.UnresolvedBooking, len(report.Unresolved)),
+	}
+	if report.Target != nil {
+		target := report.Target.Response()
+		resp.Target = &target
+	}
+	for i, unresolved := range report.Unresolved {
+		resp.Unresolved[i] = response.UnresolvedBooking{
+			BookingID: unresolved.BookingID,
+			Reason:    unresolved.Reason,
+		}
+		if booking := unresolved.Booking; booking != nil {
+			resp.Unresolved[i].Status = booking.Status
+			resp.Unresolved[i].Email = booking.User.Email
+			resp.Unresolved[i].Seats = len(booking.PerformanceSeats)
+		}
+	}
+	return resp
+}
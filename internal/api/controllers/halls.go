@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HallsService interface {
+	GetAllHalls() ([]model.Hall, error)
+	GetHallByID(id string) (*model.Hall, error)
+	CreateHall(actor model.Actor, name string, seats []model.Seat) (*model.Hall, error)
+	UpdateHall(actor model.Actor, id, name string) (*model.Hall, error)
+	UpdateLayout(actor model.Actor, id string, seats []model.Seat) (*model.Hall, error)
+	DeleteHall(actor model.Actor, id string) error
+}
+
+type HallsController struct {
+	service HallsService
+}
+
+func NewHallsController(service HallsService) *HallsController {
+	return &HallsController{service: service}
+}
+
+// GetAllHalls godoc
+// @Summary Get all halls
+// @Description Get list of all halls
+// @Tags halls
+// @Produce json
+// @Success 200 {array} response.Hall
+// @Router /api/halls [get]
+func (c *HallsController) GetAllHalls(ctx *gin.Context) {
+	halls, err := c.service.GetAllHalls()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]response.Hall, len(halls))
+	for i := range halls {
+		resp[i] = halls[i].Response()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetHallByID godoc
+// @Summary Get hall by ID
+// @Description Get detailed information about a hall
+// @Tags halls
+// @Produce json
+// @Param id path string true "Hall ID"
+// @Success 200 {object} response.Hall
+// @Router /api/halls/{id} [get]
+func (c *HallsController) GetHallByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	hall, err := c.service.GetHallByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hall.Response())
+}
+
+// CreateHall godoc
+// @Summary Create hall
+// @Description Create a hall together with its seat map
+// @Tags halls
+// @Accept json
+// @Produce json
+// @Param hall body request.Hall true "Hall object"
+// @Success 201 {object} response.Hall
+// @Security BearerAuth
+// @Router /api/halls [post]
+func (c *HallsController) CreateHall(ctx *gin.Context) {
+	var req request.Hall
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seats, err := req.Layout.Seats()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hall, err := c.service.CreateHall(middleware.CurrentActor(ctx), req.Name, seats)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, hall.Response())
+}
+
+// UpdateHall godoc
+// @Summary Update hall
+// @Description Rename a hall
+// @Tags halls
+// @Accept json
+// @Produce json
+// @Param id path string true "Hall ID"
+// @Param hall body request.UpdateHall true "Hall object"
+// @Success 200 {object} response.Hall
+// @Security BearerAuth
+// @Router /api/halls/{id} [put]
+func (c *HallsController) UpdateHall(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req request.UpdateHall
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hall, err := c.service.UpdateHall(middleware.CurrentActor(ctx), id, req.Name)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hall.Response())
+}
+
+// UpdateLayout godoc
+// @Summary Update hall layout
+// @Description Replace the seat map of a hall. Seats sold for upcoming performances cannot be removed
+// @Tags halls
+// @Accept json
+// @Produce json
+// @Param id path string true "Hall ID"
+// @Param layout body request.HallLayout true "Hall layout"
+// @Success 200 {object} response.Hall
+// @Security BearerAuth
+// @Router /api/halls/{id}/layout [put]
+func (c *HallsController) UpdateLayout(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req request.HallLayout
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seats, err := req.Seats()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hall, err := c.service.UpdateLayout(middleware.CurrentActor(ctx), id, seats)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hall.Response())
+}
+
+// DeleteHall godoc
+// @Summary Delete hall
+// @Description Delete a hall without upcoming performances
+// @Tags halls
+// @Produce json
+// @Param id path string true "Hall ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/halls/{id} [delete]
+func (c *HallsController) DeleteHall(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.service.DeleteHall(middleware.CurrentActor(ctx), id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
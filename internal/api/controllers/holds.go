@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HoldTokenHeader - заголовок, в котором владелец передает токен удержания
+const HoldTokenHeader = "X-Hold-Token"
+
+type HoldsService interface {
+	Hold(performanceID string, seatIDs []uuid.UUID, session string) (*model.SeatHold, string, error)
+	Extend(holdID, token string) (*model.SeatHold, error)
+	Release(holdID, token string) error
+}
+
+type HoldsController struct {
+	service HoldsService
+}
+
+func NewHoldsController(service HoldsService) *HoldsController {
+	return &HoldsController{service: service}
+}
+
+// CreateHold godoc
+// @Summary Hold seats
+// @Description Hold the selected seats for a few minutes while the buyer fills in checkout. The number of seats in one hold and in all active holds of one client is limited. The returned token is shown only once: pass it in the X-Hold-Token header to extend or release the hold and as hold_token when creating the booking. Holds expire on their own
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Param request body request.CreateHold true "Seats to hold"
+// @Success 201 {object} response.SeatHold
+// @Failure 400 {object} object{error=string}
+// @Failure 422 {object} object{error=string}
+// @Router /api/performances/{id}/holds [post]
+func (c *HoldsController) CreateHold(ctx *gin.Context) {
+	var req request.CreateHold
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Анонимная сессия определяется по IP, как и в ограничении частоты удержаний
+	hold, token, err := c.service.Hold(ctx.Param("id"), req.SeatIDs, ctx.ClientIP())
+	switch {
+	case err == nil:
+		resp := hold.Response()
+		resp.Token = token
+		ctx.JSON(http.StatusCreated, resp)
+	case errors.Is(err, service.ErrNotOnSale), errors.Is(err, service.ErrPerformanceCancelled),
+		errors.Is(err, service.ErrPerformanceStarted):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// ExtendHold godoc
+// @Summary Extend seat hold
+// @Description Extend an active hold by another hold period. A hold cannot be extended past its maximum duration
+// @Tags holds
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param X-Hold-Token header string true "Hold token"
+// @Success 200 {object} response.SeatHold
+// @Failure 404 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Router /api/holds/{id}/extend [post]
+func (c *HoldsController) ExtendHold(ctx *gin.Context) {
+	hold, err := c.service.Extend(ctx.Param("id"), ctx.GetHeader(HoldTokenHeader))
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, hold.Response())
+	case errors.Is(err, service.ErrHoldNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrHoldExpired), errors.Is(err, service.ErrHoldMaxed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// ReleaseHold godoc
+// @Summary Release seat hold
+// @Description Release the held seats back to sale
+// @Tags holds
+// @Param id path string true "Hold ID"
+// @Param X-Hold-Token header string true "Hold token"
+// @Success 204
+// @Failure 404 {object} object{error=string}
+// @Router /api/holds/{id} [delete]
+func (c *HoldsController) ReleaseHold(ctx *gin.Context) {
+	err := c.service.Release(ctx.Param("id"), ctx.GetHeader(HoldTokenHeader))
+	if errors.Is(err, service.ErrHoldNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
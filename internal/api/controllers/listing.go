@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"theater-ticket-system/internal/listing"
+
+	"github.com/google/uuid"
+)
+
+// listStatus возвращает HTTP-статус ошибки выборки списка: неверные параметры - 400
+func listStatus(err error) int {
+	if errors.Is(err, listing.ErrInvalidQuery) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// optionalUUID разбирает необязательный параметр запроса name
+func optionalUUID(value, name string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format", name)
+	}
+	return &id, nil
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentsService interface {
+	ConfirmBooking(id, paymentToken string) (*model.Booking, error)
+	HandleWebhook(payload []byte, signature string) error
+}
+
+type PaymentsController struct {
+	service PaymentsService
+}
+
+func NewPaymentsController(service PaymentsService) *PaymentsController {
+	return &PaymentsController{service: service}
+}
+
+// ConfirmBooking godoc
+// @Summary Confirm booking
+// @Description Pay for a pending booking and mark its seats as sold. A booking fully covered by a promo code is confirmed without payment, and the payment token may be omitted
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body object{payment_token=string} true "Payment token, not required for a free booking"
+// @Success 200 {object} response.Booking
+// @Router /api/bookings/{id}/confirm [post]
+func (c *PaymentsController) ConfirmBooking(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req struct {
+		PaymentToken string `json:"payment_token"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := c.service.ConfirmBooking(id, req.PaymentToken)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking.Response())
+}
+
+// Webhook godoc
+// @Summary Payment webhook
+// @Description Receive payment status notifications from the payment provider
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "HMAC signature of the body"
+// @Success 200 {object} object{status=string}
+// @Router /api/payments/webhook [post]
+func (c *PaymentsController) Webhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.service.HandleWebhook(payload, ctx.GetHeader("X-Payment-Signature")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -1,71 +1,91 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	"theater-ticket-system/internal/listing"
 	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
 	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type PerformancesService interface {
-	GetAllPerformances(playID *string, dateFrom, dateTo *time.Time) ([]model.Performance, error)
+	GetAllPerformances(filter service.PerformanceFilter, sort, cursor string, limit int) (listing.Page[model.Performance], error)
 	GetPerformanceByID(id string) (*model.Performance, error)
 	GetPerformanceSeats(id string) ([]model.PerformanceSeat, error)
 }
 
+type ScheduleService interface {
+	CreatePerformance(actor model.Actor, playID, hallID uuid.UUID, date time.Time, pricePlanID *uuid.UUID, premiere, onSale bool) (*model.Performance, error)
+	UpdatePerformance(actor model.Actor, id string, playID uuid.UUID, date time.Time) (*model.Performance, error)
+	SetStatus(actor model.Actor, id, status string) (*model.Performance, error)
+}
+
 type PerformancesController struct {
-	service PerformancesService
+	service  PerformancesService
+	schedule ScheduleService
 }
 
-func NewPerformancesController(service PerformancesService) *PerformancesController {
-	return &PerformancesController{service: service}
+func NewPerformancesController(service PerformancesService, schedule ScheduleService) *PerformancesController {
+	return &PerformancesController{service: service, schedule: schedule}
 }
 
 // GetAllPerformances godoc
-// @Summary Get all performances
-// @Description Get list of all performances with optional filters
+// @Summary Get performances
+// @Description Get a page of performances. Pass next_cursor from the previous page as cursor to get the next page
 // @Tags performances
 // @Produce json
-// @Param play_id query string false "Filter by play ID"
-// @Param date_from query string false "Filter by date from (RFC3339)"
-// @Param date_to query string false "Filter by date to (RFC3339)"
-// @Success 200 {array} response.Performance
+// @Param play_id query string false "Play ID"
+// @Param hall_id query string false "Hall ID"
+// @Param status query string false "Status" Enums(scheduled, on_sale, sales_closed, completed, cancelled, postponed)
+// @Param date_from query string false "Performances at or after this time (RFC3339)"
+// @Param date_to query string false "Performances at or before this time (RFC3339)"
+// @Param available query bool false "Tickets are on sale and free seats are left"
+// @Param sort query string false "Sort field, prefix with - for descending order" Enums(date, -date, created_at, -created_at) default(date)
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Success 200 {object} response.PerformancePage
+// @Failure 400 {object} object{error=string}
 // @Router /api/performances [get]
 func (c *PerformancesController) GetAllPerformances(ctx *gin.Context) {
-	playID := ctx.Query("play_id")
-	dateFromStr := ctx.Query("date_from")
-	dateToStr := ctx.Query("date_to")
-
-	var playIDPtr *string
-	if playID != "" {
-		playIDPtr = &playID
+	var req request.ListPerformances
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	var dateFrom, dateTo *time.Time
-	if dateFromStr != "" {
-		parsed, err := time.Parse(time.RFC3339, dateFromStr)
-		if err == nil {
-			dateFrom = &parsed
-		}
+	filter := service.PerformanceFilter{
+		Status:    req.Status,
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
+		Available: req.Available,
+	}
+	if req.PlayID != "" {
+		filter.PlayID = &req.PlayID
 	}
-	if dateToStr != "" {
-		parsed, err := time.Parse(time.RFC3339, dateToStr)
-		if err == nil {
-			dateTo = &parsed
-		}
+	if req.HallID != "" {
+		filter.HallID = &req.HallID
 	}
 
-	performances, err := c.service.GetAllPerformances(playIDPtr, dateFrom, dateTo)
+	page, err := c.service.GetAllPerformances(filter, req.Sort, req.Cursor, req.Limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(performancesListStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]response.Performance, len(performances))
-	for i := range performances {
-		resp[i] = performances[i].Response()
+	resp := response.PerformancePage{
+		Items:      make([]response.Performance, len(page.Items)),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+	for i := range page.Items {
+		resp.Items[i] = page.Items[i].Response()
 	}
 
 	ctx.JSON(http.StatusOK, resp)
@@ -115,3 +135,101 @@ func (c *PerformancesController) GetPerformanceSeats(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// CreatePerformance godoc
+// @Summary Create performance
+// @Description Schedule a performance and generate its seats from the hall layout. With on_sale the ticket sales open at once, otherwise the performance stays scheduled
+// @Tags performances
+// @Accept json
+// @Produce json
+// @Param performance body request.Performance true "Performance object"
+// @Success 201 {object} response.Performance
+// @Security BearerAuth
+// @Router /api/performances [post]
+func (c *PerformancesController) CreatePerformance(ctx *gin.Context) {
+	var req request.Performance
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	performance, err := c.schedule.CreatePerformance(middleware.CurrentActor(ctx), req.PlayID, req.HallID, req.Date, req.PricePlanID, req.Premiere, req.OnSale)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, performance.Response())
+}
+
+// UpdatePerformance godoc
+// @Summary Update performance
+// @Description Reschedule a performance or change its play. The date of a performance with bookings cannot be changed; postpone it with POST /api/performances/{id}/postpone instead
+// @Tags performances
+// @Accept json
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Param performance body request.UpdatePerformance true "Performance object"
+// @Success 200 {object} response.Performance
+// @Failure 409 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/performances/{id} [put]
+func (c *PerformancesController) UpdatePerformance(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req request.UpdatePerformance
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	performance, err := c.schedule.UpdatePerformance(middleware.CurrentActor(ctx), id, req.PlayID, req.Date)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, performance.Response())
+	case errors.Is(err, service.ErrPerformanceHasBookings):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// SetPerformanceStatus godoc
+// @Summary Change performance status
+// @Description Open or close ticket sales or return a performance to scheduled. Sales close automatically before the curtain and past performances are completed automatically; cancel a performance with DELETE and postpone it with POST /api/performances/{id}/postpone
+// @Tags performances
+// @Accept json
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Param status body request.PerformanceStatus true "New status"
+// @Success 200 {object} response.Performance
+// @Failure 409 {object} object{error=string}
+// @Failure 422 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/performances/{id}/status [post]
+func (c *PerformancesController) SetPerformanceStatus(ctx *gin.Context) {
+	var req request.PerformanceStatus
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	performance, err := c.schedule.SetStatus(middleware.CurrentActor(ctx), ctx.Param("id"), req.Status)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, performance.Response())
+	case errors.Is(err, service.ErrInvalidTransition):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPerformanceStarted):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func performancesListStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidPlayID) || errors.Is(err, service.ErrInvalidHallID) {
+		return http.StatusBadRequest
+	}
+	return listStatus(err)
+}
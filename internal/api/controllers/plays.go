@@ -1,20 +1,25 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	"theater-ticket-system/internal/listing"
 	model "theater-ticket-system/internal/models/models"
 	"theater-ticket-system/internal/models/requests"
 	"theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PlaysService interface {
-	GetAllPlays() ([]model.Play, error)
+	GetAllPlays(filter service.PlayFilter, sort, cursor string, limit int) (listing.Page[model.Play], error)
+	Search(query string, limit int) ([]model.PlayMatch, error)
 	GetPlayByID(id string) (*model.Play, error)
-	CreatePlay(play *model.Play) error
-	UpdatePlay(id string, play *model.Play) error
-	DeletePlay(id string) error
+	CreatePlay(actor model.Actor, play *model.Play) error
+	UpdatePlay(actor model.Actor, id string, play *model.Play) error
+	DeletePlay(actor model.Actor, id string) error
 }
 
 type Plays struct {
@@ -26,22 +31,84 @@ func NewPlays(service PlaysService) *Plays {
 }
 
 // GetAllPlays godoc
-// @Summary Get all plays
-// @Description Get list of all plays
+// @Summary Get plays
+// @Description Get a page of plays with their upcoming performances. Pass next_cursor from the previous page as cursor to get the next page
 // @Tags plays
 // @Produce json
-// @Success 200 {array} response.Play
+// @Param genre query string false "Genre"
+// @Param author query string false "Part of the author name"
+// @Param q query string false "Part of the title, author or description"
+// @Param available query bool false "Has an upcoming performance with free seats"
+// @Param sort query string false "Sort field, prefix with - for descending order" Enums(title, -title, author, -author, duration, -duration, created_at, -created_at) default(-created_at)
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Success 200 {object} response.PlayPage
+// @Failure 400 {object} object{error=string}
 // @Router /api/plays [get]
 func (c *Plays) GetAllPlays(ctx *gin.Context) {
-	plays, err := c.service.GetAllPlays()
+	var req request.ListPlays
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := service.PlayFilter{
+		Genre:     req.Genre,
+		Author:    req.Author,
+		Search:    req.Query,
+		Available: req.Available,
+	}
+	page, err := c.service.GetAllPlays(filter, req.Sort, req.Cursor, req.Limit)
+	if err != nil {
+		ctx.JSON(listStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := response.PlayPage{
+		Items:      make([]response.Play, len(page.Items)),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+	for i := range page.Items {
+		resp.Items[i] = page.Items[i].Response()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Search godoc
+// @Summary Search repertoire
+// @Description Full-text search over play titles, authors, genres and descriptions with Russian morphology. Plays are ordered by relevance and include their upcoming performances. Quoted words are searched as a phrase, words prefixed with - are excluded. Matches in highlights are wrapped in <mark>, the rest of the text is HTML-escaped
+// @Tags plays
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of plays" minimum(1) maximum(50) default(20)
+// @Success 200 {object} response.SearchResults
+// @Failure 400 {object} object{error=string}
+// @Router /api/search [get]
+func (c *Plays) Search(ctx *gin.Context) {
+	var req request.Search
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches, err := c.service.Search(req.Query, req.Limit)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidSearch) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]response.Play, len(plays))
-	for i := range plays {
-		resp[i] = plays[i].Response()
+	resp := response.SearchResults{
+		Query:   req.Query,
+		Results: make([]response.PlayMatch, len(matches)),
+	}
+	for i := range matches {
+		resp.Results[i] = matches[i].Response()
 	}
 
 	ctx.JSON(http.StatusOK, resp)
@@ -75,6 +142,7 @@ func (c *Plays) GetPlayByID(ctx *gin.Context) {
 // @Produce json
 // @Param play body request.Play true "Play object"
 // @Success 201 {object} response.Play
+// @Security BearerAuth
 // @Router /api/plays [post]
 func (c *Plays) CreatePlay(ctx *gin.Context) {
 	var req request.Play
@@ -85,7 +153,7 @@ func (c *Plays) CreatePlay(ctx *gin.Context) {
 
 	// ✅ ИСПРАВЛЕНО: создаём модель один раз и используем её
 	play := req.Model()
-	if err := c.service.CreatePlay(play); err != nil {
+	if err := c.service.CreatePlay(middleware.CurrentActor(ctx), play); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -103,6 +171,7 @@ func (c *Plays) CreatePlay(ctx *gin.Context) {
 // @Param id path string true "Play ID"
 // @Param play body request.Play true "Play object"
 // @Success 200 {object} response.Play
+// @Security BearerAuth
 // @Router /api/plays/{id} [put]
 func (c *Plays) UpdatePlay(ctx *gin.Context) {
 	id := ctx.Param("id")
@@ -115,7 +184,7 @@ func (c *Plays) UpdatePlay(ctx *gin.Context) {
 
 	// ✅ ИСПРАВЛЕНО: создаём модель один раз и используем её
 	play := req.Model()
-	if err := c.service.UpdatePlay(id, play); err != nil {
+	if err := c.service.UpdatePlay(middleware.CurrentActor(ctx), id, play); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -131,11 +200,12 @@ func (c *Plays) UpdatePlay(ctx *gin.Context) {
 // @Produce json
 // @Param id path string true "Play ID"
 // @Success 204
+// @Security BearerAuth
 // @Router /api/plays/{id} [delete]
 func (c *Plays) DeletePlay(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.service.DeletePlay(id); err != nil {
+	if err := c.service.DeletePlay(middleware.CurrentActor(ctx), id); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
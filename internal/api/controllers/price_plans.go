@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricePlansService interface {
+	GetAllPricePlans() ([]model.PricePlan, error)
+	GetPricePlanByID(id string) (*model.PricePlan, error)
+	CreatePricePlan(plan *model.PricePlan) error
+}
+
+type PricePlansController struct {
+	service PricePlansService
+}
+
+func NewPricePlansController(service PricePlansService) *PricePlansController {
+	return &PricePlansController{service: service}
+}
+
+// GetAllPricePlans godoc
+// @Summary Get all price plans
+// @Description Get list of price plans with their tiers and surcharge rules
+// @Tags price-plans
+// @Produce json
+// @Success 200 {array} response.PricePlan
+// @Security BearerAuth
+// @Router /api/price-plans [get]
+func (c *PricePlansController) GetAllPricePlans(ctx *gin.Context) {
+	plans, err := c.service.GetAllPricePlans()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]response.PricePlan, len(plans))
+	for i := range plans {
+		resp[i] = plans[i].Response()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetPricePlanByID godoc
+// @Summary Get price plan by ID
+// @Description Get a price plan with its tiers and surcharge rules
+// @Tags price-plans
+// @Produce json
+// @Param id path string true "Price plan ID"
+// @Success 200 {object} response.PricePlan
+// @Security BearerAuth
+// @Router /api/price-plans/{id} [get]
+func (c *PricePlansController) GetPricePlanByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	plan, err := c.service.GetPricePlanByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plan.Response())
+}
+
+// CreatePricePlan godoc
+// @Summary Create price plan
+// @Description Create a price plan. Tiers are matched in order, the first matching tier sets the base price
+// @Tags price-plans
+// @Accept json
+// @Produce json
+// @Param plan body request.PricePlan true "Price plan object"
+// @Success 201 {object} response.PricePlan
+// @Security BearerAuth
+// @Router /api/price-plans [post]
+func (c *PricePlansController) CreatePricePlan(ctx *gin.Context) {
+	var req request.PricePlan
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan := req.Model()
+	if err := c.service.CreatePricePlan(plan); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, plan.Response())
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodesService interface {
+	GetAllPromoCodes() ([]model.PromoCode, error)
+	CreatePromoCode(promo *model.PromoCode) error
+	DeactivatePromoCode(id string) error
+}
+
+type PromoCodesController struct {
+	service PromoCodesService
+}
+
+func NewPromoCodesController(service PromoCodesService) *PromoCodesController {
+	return &PromoCodesController{service: service}
+}
+
+// GetAllPromoCodes godoc
+// @Summary Get all promo codes
+// @Description Get list of promo codes with their usage
+// @Tags promo-codes
+// @Produce json
+// @Success 200 {array} response.PromoCode
+// @Security BearerAuth
+// @Router /api/promo-codes [get]
+func (c *PromoCodesController) GetAllPromoCodes(ctx *gin.Context) {
+	promos, err := c.service.GetAllPromoCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]response.PromoCode, len(promos))
+	for i := range promos {
+		resp[i] = promos[i].Response()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// CreatePromoCode godoc
+// @Summary Create promo code
+// @Description Create a percentage or fixed-amount promo code
+// @Tags promo-codes
+// @Accept json
+// @Produce json
+// @Param promo body request.PromoCode true "Promo code object"
+// @Success 201 {object} response.PromoCode
+// @Security BearerAuth
+// @Router /api/promo-codes [post]
+func (c *PromoCodesController) CreatePromoCode(ctx *gin.Context) {
+	var req request.PromoCode
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promo := req.Model()
+	if err := c.service.CreatePromoCode(promo); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, promo.Response())
+}
+
+// DeactivatePromoCode godoc
+// @Summary Deactivate promo code
+// @Description Stop accepting a promo code for new bookings
+// @Tags promo-codes
+// @Produce json
+// @Param id path string true "Promo code ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/promo-codes/{id}/deactivate [patch]
+func (c *PromoCodesController) DeactivatePromoCode(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.service.DeactivatePromoCode(id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
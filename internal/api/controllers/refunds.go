@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RefundsService interface {
+	CancelSeats(actor model.Actor, bookingID string, seatIDs []uuid.UUID, override *service.RefundOverride) (*model.Booking, *model.Refund, error)
+	GetRefunds(actor model.Actor, bookingID string) ([]model.Refund, error)
+}
+
+type RefundsController struct {
+	service RefundsService
+}
+
+func NewRefundsController(service RefundsService) *RefundsController {
+	return &RefundsController{service: service}
+}
+
+// CancelSeats godoc
+// @Summary Cancel seats of a booking
+// @Description Cancel some seats of a booking and free them. The booking price is recalculated; for a paid booking the refund percentage depends on the time left before the performance
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body request.CancelSeats true "Seats to cancel"
+// @Success 200 {object} response.SeatsCancellation
+// @Failure 403 {object} object{error=string}
+// @Failure 409 {object} object{error=string}
+// @Failure 422 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/bookings/{id}/cancel-seats [post]
+func (c *RefundsController) CancelSeats(ctx *gin.Context) {
+	var req request.CancelSeats
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, refund, err := c.service.CancelSeats(middleware.CurrentActor(ctx), ctx.Param("id"), req.SeatIDs, nil)
+	if err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cancellationResponse(booking, refund))
+}
+
+// OverrideRefund godoc
+// @Summary Cancel seats with a custom refund
+// @Description Staff cancel seats of a booking (all seats if seat_ids is empty) with a refund percentage that overrides the policy, also after the performance has started. The staff member and the reason are recorded with the refund
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body request.OverrideRefund true "Seats, refund percentage and reason"
+// @Success 200 {object} response.SeatsCancellation
+// @Failure 409 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/bookings/{id}/refunds [post]
+func (c *RefundsController) OverrideRefund(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+		return
+	}
+
+	var req request.OverrideRefund
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, refund, err := c.service.CancelSeats(middleware.CurrentActor(ctx), ctx.Param("id"), req.SeatIDs, &service.RefundOverride{
+		Percent: *req.Percent,
+		Reason:  req.Reason,
+		StaffID: user.ID,
+	})
+	if err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cancellationResponse(booking, refund))
+}
+
+// GetRefunds godoc
+// @Summary Get booking refunds
+// @Description Get refunds issued for a booking. Available to the booking owner and staff
+// @Tags refunds
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {array} response.Refund
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/bookings/{id}/refunds [get]
+func (c *RefundsController) GetRefunds(ctx *gin.Context) {
+	refunds, err := c.service.GetRefunds(middleware.CurrentActor(ctx), ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]response.Refund, len(refunds))
+	for i := range refunds {
+		resp[i] = refunds[i].Response()
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func cancellationResponse(booking *model.Booking, refund *model.Refund) response.SeatsCancellation {
+	resp := response.SeatsCancellation{Booking: booking.Response()}
+	if refund != nil {
+		r := refund.Response()
+		resp.Refund = &r
+	}
+	return resp
+}
+
+// cancellationStatus возвращает HTTP-статус ошибки отмены мест
+func cancellationStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotBookingOwner):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrBookingNotActive):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPerformanceStarted):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrRefundFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	response "theater-ticket-system/internal/models/responses"
+	service "theater-ticket-system/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	seatStreamHeartbeat = 15 * time.Second
+	seatStreamRetry     = 3 * time.Second // пауза перед переподключением, которую подскажем браузеру
+)
+
+type SeatStreamService interface {
+	Subscribe(performanceID uuid.UUID, lastEventID int64) (*service.SeatSubscription, error)
+}
+
+type SeatStreamController struct {
+	service SeatStreamService
+}
+
+func NewSeatStreamController(service SeatStreamService) *SeatStreamController {
+	return &SeatStreamController{service: service}
+}
+
+// StreamSeats godoc
+// @Summary Stream seat availability
+// @Description Server-Sent Events stream of seat status changes. Events: snapshot (all seats, sent on first connect or when missed events can no longer be replayed), held, reserved, released, sold. Reconnect with the Last-Event-ID header or last_event_id query parameter to receive missed events
+// @Tags performances
+// @Produce text/event-stream
+// @Param id path string true "Performance ID"
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Param last_event_id query string false "ID of the last received event"
+// @Success 200 {object} response.SeatEvent
+// @Router /api/performances/{id}/seats/stream [get]
+func (c *SeatStreamController) StreamSeats(ctx *gin.Context) {
+	performanceID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid performance ID format"})
+		return
+	}
+
+	lastEventID := parseLastEventID(ctx)
+	sub, err := c.service.Subscribe(performanceID, lastEventID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+	ctx.Status(http.StatusOK)
+
+	w := ctx.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", seatStreamRetry.Milliseconds())
+
+	last := lastEventID
+	if sub.Snapshot != nil {
+		seats := make([]response.SeatState, len(sub.Snapshot.Seats))
+		for i, seat := range sub.Snapshot.Seats {
+			seats[i] = response.SeatState{PerformanceSeatID: seat.ID, SeatID: seat.SeatID, Status: seat.Status}
+		}
+		last = sub.Snapshot.LastEventID
+		writeSeatEvent(w, last, "snapshot", seats)
+	}
+	for i := range sub.Replay {
+		last = sub.Replay[i].Seq
+		writeSeatEvent(w, last, sub.Replay[i].Kind(), sub.Replay[i].Response())
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(seatStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			// Комментарий не виден клиенту, но не дает прокси закрыть соединение
+			io.WriteString(w, ": ping\n\n")
+			w.Flush()
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Отключены как медленный клиент; браузер переподключится с Last-Event-ID
+				return
+			}
+			if event.Seq <= last {
+				continue
+			}
+			last = event.Seq
+			writeSeatEvent(w, event.Seq, event.Kind(), event.Response())
+			w.Flush()
+		}
+	}
+}
+
+func parseLastEventID(ctx *gin.Context) int64 {
+	value := ctx.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = ctx.Query("last_event_id")
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+func writeSeatEvent(w io.Writer, id int64, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+}
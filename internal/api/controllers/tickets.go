@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketsService interface {
+	BookingTicketsPDF(actor model.Actor, id string) ([]byte, error)
+}
+
+type TicketsController struct {
+	service TicketsService
+}
+
+func NewTicketsController(service TicketsService) *TicketsController {
+	return &TicketsController{service: service}
+}
+
+// BookingTicketsPDF godoc
+// @Summary Download e-tickets
+// @Description Render PDF e-tickets with signed QR codes for a confirmed booking. Available to the booking owner and staff
+// @Tags bookings
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/bookings/{id}/tickets.pdf [get]
+func (c *TicketsController) BookingTicketsPDF(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	pdf, err := c.service.BookingTicketsPDF(middleware.CurrentActor(ctx), id)
+	if err != nil {
+		ctx.JSON(cancellationStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="tickets-`+id+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
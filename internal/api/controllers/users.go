@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsersService interface {
+	SetRole(email, role string) (*model.User, error)
+}
+
+type UsersController struct {
+	service UsersService
+}
+
+func NewUsersController(service UsersService) *UsersController {
+	return &UsersController{service: service}
+}
+
+// SetUserRole godoc
+// @Summary Set user role
+// @Description Assign a role to the user with the given email. A missing user is created, so staff can be granted a role before their first login
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body request.SetUserRole true "User email and role"
+// @Success 200 {object} response.User
+// @Failure 400 {object} object{error=string}
+// @Failure 401 {object} object{error=string}
+// @Failure 403 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/admin/users/role [put]
+func (c *UsersController) SetUserRole(ctx *gin.Context) {
+	var req request.SetUserRole
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := c.service.SetRole(req.Email, req.Role)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, user.Response())
+	case errors.Is(err, service.ErrUnknownRole):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"theater-ticket-system/internal/api/middleware"
+	model "theater-ticket-system/internal/models/models"
+	request "theater-ticket-system/internal/models/requests"
+	service "theater-ticket-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WaitlistService interface {
+	Join(performanceID string, user *model.User, seats int, category string) (*model.WaitlistEntry, error)
+	GetEntry(performanceID string, userID uuid.UUID) (*model.WaitlistEntry, error)
+	Leave(performanceID string, userID uuid.UUID) error
+}
+
+type WaitlistController struct {
+	service WaitlistService
+}
+
+func NewWaitlistController(service WaitlistService) *WaitlistController {
+	return &WaitlistController{service: service}
+}
+
+// JoinWaitlist godoc
+// @Summary Join performance waitlist
+// @Description Join the waitlist of a sold-out performance. When seats are freed, the first matching entry gets a pending booking that must be paid before it expires
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Param request body request.JoinWaitlist true "Desired seats"
+// @Success 201 {object} response.WaitlistEntry
+// @Failure 409 {object} object{error=string}
+// @Security BearerAuth
+// @Router /api/performances/{id}/waitlist [post]
+func (c *WaitlistController) JoinWaitlist(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+		return
+	}
+
+	var req request.JoinWaitlist
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := c.service.Join(ctx.Param("id"), user, req.Seats, req.Category)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, entry.Response())
+	case errors.Is(err, service.ErrSeatsAvailable), errors.Is(err, service.ErrAlreadyWaitlisted):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotOnSale), errors.Is(err, service.ErrPerformanceCancelled),
+		errors.Is(err, service.ErrPerformanceStarted):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// GetWaitlistEntry godoc
+// @Summary Get my waitlist entry
+// @Description Get the current user's waitlist entry for a performance with the position in the queue or the offered booking
+// @Tags waitlist
+// @Produce json
+// @Param id path string true "Performance ID"
+// @Success 200 {object} response.WaitlistEntry
+// @Security BearerAuth
+// @Router /api/performances/{id}/waitlist [get]
+func (c *WaitlistController) GetWaitlistEntry(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+		return
+	}
+
+	entry, err := c.service.GetEntry(ctx.Param("id"), user.ID)
+	if errors.Is(err, service.ErrNotOnWaitlist) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry.Response())
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave performance waitlist
+// @Description Remove the current user's waiting entry. An offer that was already made is declined by cancelling its booking
+// @Tags waitlist
+// @Param id path string true "Performance ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/performances/{id}/waitlist [delete]
+func (c *WaitlistController) LeaveWaitlist(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+		return
+	}
+
+	err := c.service.Leave(ctx.Param("id"), user.ID)
+	if errors.Is(err, service.ErrNotOnWaitlist) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	model "theater-ticket-system/internal/models/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const userKey = "user"
+
+type Authenticator interface {
+	Authenticate(accessToken string) (*model.User, error)
+}
+
+// RequireAuth пропускает только запросы с действующим access-токеном
+// и кладет владельца токена в контекст запроса
+func RequireAuth(auth Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := bearerToken(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+			return
+		}
+
+		user, err := auth.Authenticate(token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Set(userKey, user)
+		ctx.Next()
+	}
+}
+
+// OptionalAuth кладет в контекст владельца действующего access-токена, если он передан.
+// Запросы без токена или с недействительным токеном пропускаются анонимно.
+func OptionalAuth(auth Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if token, ok := bearerToken(ctx); ok {
+			if user, err := auth.Authenticate(token); err == nil {
+				ctx.Set(userKey, user)
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// CurrentUser возвращает пользователя, установленного RequireAuth или OptionalAuth
+func CurrentUser(ctx *gin.Context) (*model.User, bool) {
+	value, ok := ctx.Get(userKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*model.User)
+	return user, ok
+}
+
+// CurrentActor возвращает автора изменения для журнала аудита: пользователя запроса,
+// если он известен, и IP клиента
+func CurrentActor(ctx *gin.Context) model.Actor {
+	actor := model.Actor{IP: ctx.ClientIP()}
+	if user, ok := CurrentUser(ctx); ok {
+		actor.UserID = &user.ID
+		actor.Email = user.Email
+		actor.Role = user.Role
+	}
+	return actor
+}
+
+func bearerToken(ctx *gin.Context) (string, bool) {
+	header := ctx.GetHeader("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || token == "" {
+		return "", false
+	}
+	return token, true
+}
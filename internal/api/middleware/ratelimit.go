@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"theater-ticket-system/internal/ratelimit"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey выделяет из запроса ключ, по которому считаются запросы
+type RateLimitKey func(ctx *gin.Context) string
+
+// ByClientIP считает запросы по IP клиента
+func ByClientIP(ctx *gin.Context) string {
+	return ctx.ClientIP()
+}
+
+// RateLimit отклоняет запросы сверх правила rule с ответом 429. Если хранилище
+// счетчиков недоступно, запрос пропускается.
+func RateLimit(store ratelimit.Store, rule ratelimit.Rule, key RateLimitKey) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		now := time.Now()
+		result, err := store.Allow(rule, key(ctx), now)
+		if err != nil {
+			log.Printf("Rate limit %s unavailable: %v", rule.Name, err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			SetRetryAfter(ctx, result.RetryAfter(now))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// SetRetryAfter выставляет заголовок Retry-After в целых секундах с округлением вверх
+func SetRetryAfter(ctx *gin.Context, wait time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
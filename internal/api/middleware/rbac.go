@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	model "theater-ticket-system/internal/models/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission пропускает только пользователей, чья роль дает право permission.
+// Должен стоять после RequireAuth.
+func RequirePermission(permission model.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := CurrentUser(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+			return
+		}
+
+		if !user.Can(permission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
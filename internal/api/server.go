@@ -1,17 +1,33 @@
 package api
 
 import (
+	"context"
 	"log"
 	"strconv"
 	"theater-ticket-system/internal/config"
 	"theater-ticket-system/internal/database/postgres"
+	"theater-ticket-system/internal/notifications"
+	"theater-ticket-system/internal/payments"
+	"theater-ticket-system/internal/ratelimit"
+	"theater-ticket-system/internal/repository"
+	service "theater-ticket-system/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Server struct {
-	router *gin.Engine
-	cfg    *config.Config
+	router          *gin.Engine
+	cfg             *config.Config
+	paymentProvider service.PaymentProvider
+	emailSender     service.EmailSender
+	emails          *service.EmailService
+	rateLimits      ratelimit.Store
+	waitlist        *service.Waitlist
+	seatStream      *service.SeatStream
+	holds           *service.Holds
+	refundPolicy    *service.RefundPolicy
+	audit           *service.Audit
 }
 
 func NewServer(cfg *config.Config) *Server {
@@ -20,15 +36,179 @@ func NewServer(cfg *config.Config) *Server {
 	}
 
 	server := &Server{
-		router: gin.Default(),
-		cfg:    cfg,
+		router:          gin.Default(),
+		cfg:             cfg,
+		paymentProvider: newPaymentProvider(cfg.Payment),
+		emailSender:     newEmailSender(cfg.Email),
+		emails:          newEmailService(cfg),
+		rateLimits:      newRateLimitStore(cfg.RateLimit),
+		refundPolicy:    newRefundPolicy(cfg.Refunds),
+		audit:           service.NewAudit(repository.NewAudit(postgres.DB), service.SystemClock),
 	}
 
+	server.waitlist = service.NewWaitlist(
+		repository.NewWaitlist(postgres.DB),
+		newBookingsStore(),
+		service.NewPricingEngine(cfg.Pricing.Location),
+		service.SystemClock,
+		cfg.Waitlist.OfferTTL,
+		cfg.Schedule.SalesCloseBefore,
+		server.emails,
+		server.audit,
+	)
+
+	server.seatStream = service.NewSeatStream(
+		repository.NewSeatEvents(postgres.DB),
+		postgres.NewListener(),
+		service.SystemClock,
+		cfg.SeatMap.EventsRetention,
+	)
+
+	server.holds = service.NewHolds(
+		newHoldsStore(),
+		service.SystemClock,
+		cfg.Holds.TTL,
+		cfg.Holds.MaxDuration,
+		cfg.Schedule.SalesCloseBefore,
+		cfg.Booking.ExpiryInterval,
+		service.HoldLimits{Seats: cfg.Holds.MaxSeats, SessionSeats: cfg.Holds.MaxSessionSeats},
+		server.waitlist,
+	)
+
 	server.setupRoutes()
 
 	return server
 }
 
 func (s *Server) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.startWorkers(ctx)
+
 	return s.router.Run("0.0.0.0:" + strconv.Itoa(s.cfg.Port))
 }
+
+// startWorkers запускает фоновые процессы, работающие до остановки сервера
+func (s *Server) startWorkers(ctx context.Context) {
+	bookingExpiry := service.NewBookingExpiry(
+		newBookingsStore(),
+		service.SystemClock,
+		s.cfg.Booking.ExpiryInterval,
+		s.waitlist,
+		s.audit,
+	)
+	go bookingExpiry.Run(ctx)
+	go s.holds.Run(ctx)
+
+	emailOutbox := service.NewEmailOutbox(
+		repository.NewEmailOutbox(postgres.DB),
+		s.emailSender,
+		service.SystemClock,
+		s.cfg.Email.OutboxInterval,
+	)
+	go emailOutbox.Run(ctx)
+
+	reminders := service.NewReminders(
+		newBookingsStore(),
+		newPerformancesStore(),
+		s.emails,
+		service.SystemClock,
+		s.cfg.Reminders.Offsets,
+		s.cfg.Reminders.Interval,
+	)
+	go reminders.Run(ctx)
+
+	lifecycle := service.NewPerformanceLifecycle(
+		newLifecycleStore(),
+		service.SystemClock,
+		s.cfg.Schedule.SalesCloseBefore,
+		s.cfg.Schedule.LifecycleInterval,
+		s.audit,
+	)
+	go lifecycle.Run(ctx)
+
+	go s.seatStream.Run(ctx)
+
+	payments := service.NewPayments(
+		repository.NewPayments(postgres.DB),
+		newBookingsStore(),
+		s.paymentProvider,
+		service.SystemClock,
+		s.cfg.Payment.Currency,
+		s.emails,
+		s.audit,
+	)
+	go payments.Run(ctx, s.cfg.Payment.RefundRetryInterval)
+
+	if store, ok := s.rateLimits.(*repository.RateLimits); ok {
+		go pruneRateLimits(ctx, store, s.cfg.RateLimit.Window)
+	}
+}
+
+// pruneRateLimits периодически удаляет из Postgres счетчики закончившихся окон
+func pruneRateLimits(ctx context.Context, store *repository.RateLimits, window time.Duration) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.DeleteExpired(time.Now().Add(-window)); err != nil {
+				log.Println("Pruning rate limits failed:", err)
+			}
+		}
+	}
+}
+
+func newEmailService(cfg *config.Config) *service.EmailService {
+	templates, err := notifications.NewTemplates(cfg.Pricing.Location, cfg.Payment.Currency)
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+	return service.NewEmailService(templates, service.SystemClock)
+}
+
+func newEmailSender(cfg config.EmailConfig) service.EmailSender {
+	switch cfg.Sender {
+	case "smtp":
+		return notifications.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.From, cfg.Password)
+	case "mailbox":
+		return notifications.NewMailbox()
+	default:
+		log.Fatal("Unknown email sender:", cfg.Sender)
+		return nil
+	}
+}
+
+func newRateLimitStore(cfg config.RateLimitConfig) ratelimit.Store {
+	switch cfg.Backend {
+	case "memory":
+		return ratelimit.NewMemory()
+	case "postgres":
+		return repository.NewRateLimits(postgres.DB)
+	default:
+		log.Fatal("Unknown rate limit backend:", cfg.Backend)
+		return nil
+	}
+}
+
+func newPaymentProvider(cfg config.PaymentConfig) service.PaymentProvider {
+	switch cfg.Provider {
+	case "fake":
+		return payments.NewFake(cfg.WebhookSecret)
+	default:
+		log.Fatal("Unknown payment provider:", cfg.Provider)
+		return nil
+	}
+}
+
+func newRefundPolicy(cfg config.RefundsConfig) *service.RefundPolicy {
+	tiers := make([]service.RefundTier, len(cfg.Policy))
+	for i, tier := range cfg.Policy {
+		tiers[i] = service.RefundTier{Before: tier.Before, Percent: tier.Percent}
+	}
+	return service.NewRefundPolicy(tiers)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"theater-ticket-system/internal/database/postgres"
+	model "theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	service "theater-ticket-system/internal/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// bookingsStore отдает сервисам транзакции репозитория бронирований через интерфейсы,
+// которые объявляют сами сервисы
+type bookingsStore struct {
+	*repository.Bookings
+}
+
+func newBookingsStore() bookingsStore {
+	return bookingsStore{repository.NewBookings(postgres.DB)}
+}
+
+func (s bookingsStore) Transaction(fn func(tx service.BookingsTx) error) error {
+	return s.Bookings.Transaction(func(tx *repository.Bookings) error {
+		return fn(tx)
+	})
+}
+
+func (s bookingsStore) ExpirePending(now time.Time, limit int, onRelease service.ReleaseHook) (int, error) {
+	var hook func(tx *repository.Bookings, released []model.Booking) error
+	if onRelease != nil {
+		hook = func(tx *repository.Bookings, released []model.Booking) error {
+			return onRelease(tx, released)
+		}
+	}
+	return s.Bookings.ExpirePending(now, limit, hook)
+}
+
+// holdsStore передает транзакцию снятия удержания листу ожидания
+type holdsStore struct {
+	*repository.Holds
+}
+
+func newHoldsStore() holdsStore {
+	return holdsStore{repository.NewHolds(postgres.DB)}
+}
+
+func (s holdsStore) Release(hold *model.SeatHold, onRelease service.FreedHook) error {
+	return s.Holds.Release(hold, freedHook(onRelease))
+}
+
+func (s holdsStore) ReleaseExpired(now time.Time, limit int, onRelease service.FreedHook) (int, error) {
+	return s.Holds.ReleaseExpired(now, limit, freedHook(onRelease))
+}
+
+func freedHook(onRelease service.FreedHook) func(tx *repository.Bookings, performanceIDs []uuid.UUID) error {
+	if onRelease == nil {
+		return nil
+	}
+	return func(tx *repository.Bookings, performanceIDs []uuid.UUID) error {
+		return onRelease(tx, performanceIDs)
+	}
+}
+
+// performancesStore открывает транзакции расписания для сервисов показов
+type performancesStore struct {
+	*repository.Performances
+}
+
+func newPerformancesStore() performancesStore {
+	return performancesStore{repository.NewPerformances(postgres.DB)}
+}
+
+func (s performancesStore) Transaction(fn func(tx service.PerformancesTx) error) error {
+	return s.Performances.Transaction(func(tx *repository.Performances) error {
+		return fn(tx)
+	})
+}
+
+// lifecycleStore открывает транзакции смены статусов показов
+type lifecycleStore struct {
+	*repository.Performances
+}
+
+func newLifecycleStore() lifecycleStore {
+	return lifecycleStore{repository.NewPerformances(postgres.DB)}
+}
+
+func (s lifecycleStore) Transaction(fn func(tx service.LifecycleTx) error) error {
+	return s.Performances.Transaction(func(tx *repository.Performances) error {
+		return fn(tx)
+	})
+}
+
+type playsStore struct {
+	*repository.Plays
+}
+
+func newPlaysStore() playsStore {
+	return playsStore{repository.NewPlays(postgres.DB)}
+}
+
+func (s playsStore) Transaction(fn func(tx service.PlaysTx) error) error {
+	return s.Plays.Transaction(func(tx *repository.Plays) error {
+		return fn(tx)
+	})
+}
+
+type hallsStore struct {
+	*repository.Halls
+}
+
+func newHallsStore() hallsStore {
+	return hallsStore{repository.NewHalls(postgres.DB)}
+}
+
+func (s hallsStore) Transaction(fn func(tx service.HallsTx) error) error {
+	return s.Halls.Transaction(func(tx *repository.Halls) error {
+		return fn(tx)
+	})
+}
+
+type authStore struct {
+	*repository.Auth
+}
+
+func newAuthStore() authStore {
+	return authStore{repository.NewAuth(postgres.DB)}
+}
+
+func (s authStore) Transaction(fn func(tx service.AuthTx) error) error {
+	return s.Auth.Transaction(func(tx *repository.Auth) error {
+		return fn(tx)
+	})
+}
+
+type checkInStore struct {
+	*repository.CheckIn
+}
+
+func newCheckInStore() checkInStore {
+	return checkInStore{repository.NewCheckIn(postgres.DB)}
+}
+
+func (s checkInStore) Transaction(fn func(tx service.CheckInTx) error) error {
+	return s.CheckIn.Transaction(func(tx *repository.CheckIn) error {
+		return fn(tx)
+	})
+}
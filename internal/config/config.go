@@ -4,14 +4,33 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // часовые пояса доступны и в минимальных образах без системной базы
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port  int
-	DB    DBConfig
-	Email EmailConfig
+	// Dev - режим разработки (APP_ENV=development): разрешены тестовая платежная
+	// система и секреты по умолчанию
+	Dev       bool
+	Port      int
+	DB        DBConfig
+	Email     EmailConfig
+	Booking   BookingConfig
+	Payment   PaymentConfig
+	Auth      AuthConfig
+	Schedule  ScheduleConfig
+	Pricing   PricingConfig
+	Tickets   TicketsConfig
+	CheckIn   CheckInConfig
+	Reminders RemindersConfig
+	RateLimit RateLimitConfig
+	Waitlist  WaitlistConfig
+	SeatMap   SeatMapConfig
+	Holds     HoldsConfig
+	Refunds   RefundsConfig
 }
 
 type DBConfig struct {
@@ -23,10 +42,111 @@ type DBConfig struct {
 }
 
 type EmailConfig struct {
+	Sender   string // smtp или mailbox (письма остаются в памяти процесса)
 	From     string
 	Password string
 	SMTPHost string
 	SMTPPort string
+
+	OutboxInterval time.Duration
+}
+
+type BookingConfig struct {
+	HoldTTL        time.Duration
+	ExpiryInterval time.Duration
+}
+
+type ScheduleConfig struct {
+	TurnoverBuffer time.Duration
+	// SalesCloseBefore - за сколько до начала показа закрывается продажа билетов
+	SalesCloseBefore time.Duration
+	// LifecycleInterval - как часто закрывается продажа и завершаются прошедшие показы
+	LifecycleInterval time.Duration
+}
+
+type PricingConfig struct {
+	// Location - часовой пояс театра, по нему определяются выходные дни
+	Location *time.Location
+}
+
+type TicketsConfig struct {
+	// SigningSecret - ключ HMAC-подписи QR-кодов билетов
+	SigningSecret string
+}
+
+type CheckInConfig struct {
+	// OpensBefore и ClosesAfter задают окно входа относительно начала показа
+	OpensBefore time.Duration
+	ClosesAfter time.Duration
+}
+
+type RemindersConfig struct {
+	// Offsets - за сколько до начала показа напоминать покупателям
+	Offsets  []time.Duration
+	Interval time.Duration
+}
+
+type WaitlistConfig struct {
+	// OfferTTL - сколько места удерживаются за покупателем из листа ожидания
+	OfferTTL time.Duration
+}
+
+type HoldsConfig struct {
+	// TTL - на сколько удерживаются места при создании и каждом продлении удержания
+	TTL time.Duration
+	// MaxDuration - дольше этого от создания удержание продлить нельзя
+	MaxDuration time.Duration
+	// MaxSeats - сколько мест можно удержать одним запросом
+	MaxSeats int
+	// MaxSessionSeats - сколько мест можно удерживать одновременно с одного IP
+	MaxSessionSeats int
+}
+
+type RefundsConfig struct {
+	// Policy - ступени политики возврата: процент, если до начала показа осталось не меньше Before
+	Policy []RefundTier
+}
+
+type RefundTier struct {
+	Before  time.Duration
+	Percent int
+}
+
+type SeatMapConfig struct {
+	// EventsRetention - сколько хранятся события мест для досылки переподключившимся клиентам
+	EventsRetention time.Duration
+}
+
+type RateLimitConfig struct {
+	Backend string // memory или postgres (общие счетчики для нескольких экземпляров)
+
+	// Лимиты запросов с одного IP
+	SendCodePerIP   int
+	VerifyCodePerIP int
+	HoldsPerIP      int
+	Window          time.Duration
+
+	// Лимиты на один адрес
+	CodesPerEmail   int
+	CodesWindow     time.Duration
+	CodeCooldown    time.Duration
+	CodeCooldownMax time.Duration
+	CodeMaxAttempts int
+}
+
+type AuthConfig struct {
+	JWTSecret  string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+type PaymentConfig struct {
+	Provider      string
+	Currency      string
+	WebhookSecret string
+
+	// RefundRetryInterval - как часто повторяются не прошедшие возвраты платежей
+	RefundRetryInterval time.Duration
 }
 
 func Init() *Config {
@@ -44,7 +164,10 @@ func Init() *Config {
 		log.Fatal("Invalid DB_PORT:", err)
 	}
 
+	dev := getEnv("APP_ENV", "production") == "development"
+
 	return &Config{
+		Dev:  dev,
 		Port: port,
 		DB: DBConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -54,10 +177,80 @@ func Init() *Config {
 			Name:     getEnv("DB_NAME", "theater_tickets"),
 		},
 		Email: EmailConfig{
+			Sender:   getEnv("EMAIL_SENDER", "smtp"),
 			From:     getEnv("EMAIL_FROM", ""),
 			Password: getEnv("EMAIL_PASSWORD", ""),
 			SMTPHost: getEnv("SMTP_HOST", "smtp.gmail.com"),
 			SMTPPort: getEnv("SMTP_PORT", "587"),
+
+			OutboxInterval: getDuration("EMAIL_OUTBOX_INTERVAL", 5*time.Second),
+		},
+		Booking: BookingConfig{
+			HoldTTL:        getDuration("BOOKING_HOLD_TTL", 15*time.Minute),
+			ExpiryInterval: getDuration("BOOKING_EXPIRY_INTERVAL", time.Minute),
+		},
+		Payment: PaymentConfig{
+			Provider:      getPaymentProvider("PAYMENT_PROVIDER", dev),
+			Currency:      getEnv("PAYMENT_CURRENCY", "BYN"),
+			WebhookSecret: getSecret("PAYMENT_WEBHOOK_SECRET", "dev-webhook-secret", dev),
+
+			RefundRetryInterval: getDuration("PAYMENT_REFUND_RETRY_INTERVAL", 5*time.Minute),
+		},
+		Auth: AuthConfig{
+			JWTSecret:  getSecret("JWT_SECRET", "dev-jwt-secret", dev),
+			AccessTTL:  getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL: getDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		},
+		Schedule: ScheduleConfig{
+			TurnoverBuffer:    getDuration("PERFORMANCE_TURNOVER_BUFFER", 30*time.Minute),
+			SalesCloseBefore:  getDuration("PERFORMANCE_SALES_CLOSE_BEFORE", 30*time.Minute),
+			LifecycleInterval: getDuration("PERFORMANCE_LIFECYCLE_INTERVAL", time.Minute),
+		},
+		Pricing: PricingConfig{
+			Location: getLocation("THEATER_TIMEZONE", "Europe/Minsk"),
+		},
+		Tickets: TicketsConfig{
+			SigningSecret: getSecret("TICKET_SIGNING_SECRET", "dev-ticket-secret", dev),
+		},
+		CheckIn: CheckInConfig{
+			OpensBefore: getDuration("CHECKIN_OPENS_BEFORE", 2*time.Hour),
+			ClosesAfter: getDuration("CHECKIN_CLOSES_AFTER", time.Hour),
+		},
+		Reminders: RemindersConfig{
+			Offsets:  getDurations("REMINDER_OFFSETS", []time.Duration{24 * time.Hour, 2 * time.Hour}),
+			Interval: getDuration("REMINDER_INTERVAL", time.Minute),
+		},
+		RateLimit: RateLimitConfig{
+			Backend:         getEnv("RATE_LIMIT_BACKEND", "memory"),
+			SendCodePerIP:   getInt("RATE_LIMIT_SEND_CODE_PER_IP", 20),
+			VerifyCodePerIP: getInt("RATE_LIMIT_VERIFY_CODE_PER_IP", 30),
+			HoldsPerIP:      getInt("RATE_LIMIT_HOLDS_PER_IP", 30),
+			Window:          getDuration("RATE_LIMIT_WINDOW", 15*time.Minute),
+			CodesPerEmail:   getInt("AUTH_CODES_PER_EMAIL", 5),
+			CodesWindow:     getDuration("AUTH_CODES_WINDOW", time.Hour),
+			CodeCooldown:    getDuration("AUTH_CODE_COOLDOWN", 30*time.Second),
+			CodeCooldownMax: getDuration("AUTH_CODE_COOLDOWN_MAX", 10*time.Minute),
+			CodeMaxAttempts: getInt("AUTH_CODE_MAX_ATTEMPTS", 5),
+		},
+		SeatMap: SeatMapConfig{
+			EventsRetention: getDuration("SEAT_EVENTS_RETENTION", 24*time.Hour),
+		},
+		Waitlist: WaitlistConfig{
+			OfferTTL: getDuration("WAITLIST_OFFER_TTL", 30*time.Minute),
+		},
+		Holds: HoldsConfig{
+			TTL:         getDuration("SEAT_HOLD_TTL", 5*time.Minute),
+			MaxDuration: getDuration("SEAT_HOLD_MAX_DURATION", 20*time.Minute),
+
+			MaxSeats:        getInt("SEAT_HOLD_MAX_SEATS", 10),
+			MaxSessionSeats: getInt("SEAT_HOLD_MAX_SESSION_SEATS", 20),
+		},
+		Refunds: RefundsConfig{
+			Policy: getRefundTiers("REFUND_POLICY", []RefundTier{
+				{Before: 7 * 24 * time.Hour, Percent: 100},
+				{Before: 72 * time.Hour, Percent: 50},
+				{Before: 24 * time.Hour, Percent: 25},
+			}),
 		},
 	}
 }
@@ -69,3 +262,117 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getSecret читает обязательный секрет. Значение devValue допускается только
+// в режиме разработки, где оно же используется по умолчанию.
+func getSecret(key, devValue string, dev bool) string {
+	value := os.Getenv(key)
+	if dev {
+		if value == "" {
+			return devValue
+		}
+		return value
+	}
+	if value == "" {
+		log.Fatalf("%s is required", key)
+	}
+	if value == devValue {
+		log.Fatalf("%s must not use the development value outside APP_ENV=development", key)
+	}
+	return value
+}
+
+// getPaymentProvider читает платежную систему. Тестовая система fake
+// разрешена только в режиме разработки.
+func getPaymentProvider(key string, dev bool) string {
+	value := os.Getenv(key)
+	if dev {
+		if value == "" {
+			return "fake"
+		}
+		return value
+	}
+	if value == "" {
+		log.Fatalf("%s is required", key)
+	}
+	if value == "fake" {
+		log.Fatalf("%s=fake is allowed only with APP_ENV=development", key)
+	}
+	return value
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return d
+}
+
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s: %q", key, value)
+	}
+	return n
+}
+
+// getDurations читает список длительностей через запятую, например "24h,2h"
+func getDurations(key string, defaultValue []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid %s: %q", key, part)
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
+
+// getRefundTiers читает ступени политики возврата через запятую, например "168h:100,24h:50"
+func getRefundTiers(key string, defaultValue []RefundTier) []RefundTier {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var tiers []RefundTier
+	for _, part := range strings.Split(value, ",") {
+		before, percent, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			log.Fatalf("Invalid %s: %q", key, part)
+		}
+		d, err := time.ParseDuration(before)
+		if err != nil || d < 0 {
+			log.Fatalf("Invalid %s: %q", key, part)
+		}
+		p, err := strconv.Atoi(percent)
+		if err != nil || p < 0 || p > 100 {
+			log.Fatalf("Invalid %s: %q", key, part)
+		}
+		tiers = append(tiers, RefundTier{Before: d, Percent: p})
+	}
+	return tiers
+}
+
+func getLocation(key, defaultValue string) *time.Location {
+	loc, err := time.LoadLocation(getEnv(key, defaultValue))
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return loc
+}
@@ -0,0 +1,69 @@
+-- Приводит схему, созданную AutoMigrate, к 0001_initial. Каждая команда идемпотентна:
+-- то, что AutoMigrate уже создал, не меняется.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'customer';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+ALTER TABLE plays ADD COLUMN IF NOT EXISTS description text;
+ALTER TABLE plays ADD COLUMN IF NOT EXISTS poster_url text;
+ALTER TABLE plays ADD COLUMN IF NOT EXISTS genre text;
+CREATE INDEX IF NOT EXISTS idx_plays_deleted_at ON plays (deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_halls_deleted_at ON halls (deleted_at);
+
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS section text NOT NULL DEFAULT '';
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS position bigint;
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS category text;
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS accessible boolean DEFAULT false;
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS restricted_view boolean DEFAULT false;
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_seats_hall_id ON seats (hall_id);
+CREATE INDEX IF NOT EXISTS idx_seats_deleted_at ON seats (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seats_place ON seats (hall_id, section, row, number) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_performances_play_id ON performances (play_id);
+CREATE INDEX IF NOT EXISTS idx_performances_hall_id ON performances (hall_id);
+CREATE INDEX IF NOT EXISTS idx_performances_date ON performances (date);
+CREATE INDEX IF NOT EXISTS idx_performances_deleted_at ON performances (deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings (user_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_performance_id ON bookings (performance_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_deleted_at ON bookings (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_pending_expiry ON bookings (expires_at) WHERE status = 'pending';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_performance_seats_seat') THEN
+        ALTER TABLE performance_seats
+            ADD CONSTRAINT uq_performance_seats_seat UNIQUE (performance_id, seat_id);
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_performance_seats_performance_id ON performance_seats (performance_id);
+CREATE INDEX IF NOT EXISTS idx_performance_seats_seat_id ON performance_seats (seat_id);
+CREATE INDEX IF NOT EXISTS idx_performance_seats_booking_id ON performance_seats (booking_id);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_email ON email_verifications (email);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id         uuid PRIMARY KEY,
+    booking_id uuid NOT NULL REFERENCES bookings (id),
+    provider   text NOT NULL,
+    intent_id  text NOT NULL,
+    amount     bigint NOT NULL,
+    currency   text NOT NULL,
+    status     text DEFAULT 'pending',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_intent_id ON payments (intent_id);
+CREATE INDEX IF NOT EXISTS idx_payments_booking_id ON payments (booking_id);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id         uuid PRIMARY KEY,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
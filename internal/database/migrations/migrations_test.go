@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("pairs and sorts scripts by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX ...")},
+			"0002_add_index.down.sql": {Data: []byte("DROP INDEX ...")},
+			"0001_initial.up.sql":     {Data: []byte("CREATE TABLE ...")},
+			"0001_initial.down.sql":   {Data: []byte("DROP TABLE ...")},
+		}
+
+		migrations, err := Load(fsys)
+
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 2)
+		assert.Equal(t, int64(1), migrations[0].Version)
+		assert.Equal(t, "initial", migrations[0].Name)
+		assert.Equal(t, "DROP TABLE ...", migrations[0].Down)
+		assert.Equal(t, "add_index", migrations[1].Name)
+	})
+
+	t.Run("missing down script", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0001_initial.up.sql": {Data: []byte("CREATE TABLE ...")},
+		}
+
+		_, err := Load(fsys)
+
+		assert.EqualError(t, err, "migration 1_initial: missing down script")
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"first_initial.up.sql": {Data: []byte("CREATE TABLE ...")},
+		}
+
+		_, err := Load(fsys)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid version")
+	})
+
+	t.Run("embedded migrations are well formed", func(t *testing.T) {
+		m, err := New(nil)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m.migrations)
+		assert.Equal(t, int64(1), m.migrations[0].Version)
+	})
+}
+
+func TestBaselineScript(t *testing.T) {
+	t.Run("creates every index and constraint of the initial migration", func(t *testing.T) {
+		m, err := New(nil)
+		assert.NoError(t, err)
+
+		objects := regexp.MustCompile(`CREATE (?:UNIQUE )?INDEX (\w+)|CONSTRAINT (\w+)`)
+		for _, match := range objects.FindAllStringSubmatch(m.migrations[0].Up, -1) {
+			name := match[1] + match[2]
+			assert.Regexp(t, `(IF NOT EXISTS |conname = ')`+name+`\b`, baselineScript, "baseline.sql does not create %s", name)
+		}
+	})
+}
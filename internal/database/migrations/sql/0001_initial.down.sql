@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS email_verifications;
+DROP TABLE IF EXISTS performance_seats;
+DROP TABLE IF EXISTS bookings;
+DROP TABLE IF EXISTS performances;
+DROP TABLE IF EXISTS seats;
+DROP TABLE IF EXISTS halls;
+DROP TABLE IF EXISTS plays;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,130 @@
+CREATE TABLE users (
+    id            uuid PRIMARY KEY,
+    email         text NOT NULL,
+    name          text NOT NULL,
+    password_hash text NOT NULL,
+    role          text NOT NULL DEFAULT 'customer',
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE plays (
+    id          uuid PRIMARY KEY,
+    title       text NOT NULL,
+    author      text NOT NULL,
+    description text,
+    duration    bigint NOT NULL,
+    poster_url  text,
+    genre       text,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX idx_plays_deleted_at ON plays (deleted_at);
+
+CREATE TABLE halls (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    capacity   bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX idx_halls_deleted_at ON halls (deleted_at);
+
+CREATE TABLE seats (
+    id              uuid PRIMARY KEY,
+    hall_id         uuid NOT NULL REFERENCES halls (id),
+    section         text NOT NULL DEFAULT '',
+    row             bigint NOT NULL,
+    number          bigint NOT NULL,
+    position        bigint,
+    category        text,
+    accessible      boolean DEFAULT false,
+    restricted_view boolean DEFAULT false,
+    deleted_at      timestamptz
+);
+CREATE INDEX idx_seats_hall_id ON seats (hall_id);
+CREATE INDEX idx_seats_deleted_at ON seats (deleted_at);
+CREATE UNIQUE INDEX idx_seats_place ON seats (hall_id, section, row, number) WHERE deleted_at IS NULL;
+
+CREATE TABLE performances (
+    id         uuid PRIMARY KEY,
+    play_id    uuid NOT NULL REFERENCES plays (id),
+    hall_id    uuid NOT NULL REFERENCES halls (id),
+    date       timestamptz NOT NULL,
+    status     text DEFAULT 'scheduled',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX idx_performances_play_id ON performances (play_id);
+CREATE INDEX idx_performances_hall_id ON performances (hall_id);
+CREATE INDEX idx_performances_date ON performances (date);
+CREATE INDEX idx_performances_deleted_at ON performances (deleted_at);
+
+CREATE TABLE bookings (
+    id             uuid PRIMARY KEY,
+    user_id        uuid NOT NULL REFERENCES users (id),
+    performance_id uuid NOT NULL REFERENCES performances (id),
+    total_price    bigint NOT NULL,
+    status         text DEFAULT 'pending',
+    expires_at     timestamptz,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz
+);
+CREATE INDEX idx_bookings_user_id ON bookings (user_id);
+CREATE INDEX idx_bookings_performance_id ON bookings (performance_id);
+CREATE INDEX idx_bookings_deleted_at ON bookings (deleted_at);
+CREATE INDEX idx_bookings_pending_expiry ON bookings (expires_at) WHERE status = 'pending';
+
+CREATE TABLE performance_seats (
+    id             uuid PRIMARY KEY,
+    performance_id uuid NOT NULL REFERENCES performances (id) ON DELETE CASCADE,
+    seat_id        uuid NOT NULL REFERENCES seats (id),
+    booking_id     uuid REFERENCES bookings (id) ON DELETE SET NULL,
+    price          bigint NOT NULL,
+    status         text DEFAULT 'available',
+    reserved_until timestamptz,
+    CONSTRAINT uq_performance_seats_seat UNIQUE (performance_id, seat_id)
+);
+CREATE INDEX idx_performance_seats_performance_id ON performance_seats (performance_id);
+CREATE INDEX idx_performance_seats_seat_id ON performance_seats (seat_id);
+CREATE INDEX idx_performance_seats_booking_id ON performance_seats (booking_id);
+
+CREATE TABLE email_verifications (
+    id         uuid PRIMARY KEY,
+    email      text NOT NULL,
+    code       text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used       boolean DEFAULT false,
+    created_at timestamptz
+);
+CREATE INDEX idx_email_verifications_email ON email_verifications (email);
+
+CREATE TABLE payments (
+    id         uuid PRIMARY KEY,
+    booking_id uuid NOT NULL REFERENCES bookings (id),
+    provider   text NOT NULL,
+    intent_id  text NOT NULL,
+    amount     bigint NOT NULL,
+    currency   text NOT NULL,
+    status     text DEFAULT 'pending',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_payments_intent_id ON payments (intent_id);
+CREATE INDEX idx_payments_booking_id ON payments (booking_id);
+
+CREATE TABLE sessions (
+    id         uuid PRIMARY KEY,
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
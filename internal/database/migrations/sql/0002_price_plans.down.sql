@@ -0,0 +1,10 @@
+ALTER TABLE performance_seats DROP COLUMN charged_price;
+
+DROP INDEX IF EXISTS idx_performances_price_plan_id;
+ALTER TABLE performances
+    DROP COLUMN premiere,
+    DROP COLUMN price_plan_id;
+
+DROP TABLE IF EXISTS price_demand_steps;
+DROP TABLE IF EXISTS price_tiers;
+DROP TABLE IF EXISTS price_plans;
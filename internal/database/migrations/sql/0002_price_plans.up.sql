@@ -0,0 +1,40 @@
+CREATE TABLE price_plans (
+    id                 uuid PRIMARY KEY,
+    name               text NOT NULL,
+    default_price      bigint NOT NULL,
+    weekend_surcharge  bigint NOT NULL DEFAULT 0,
+    premiere_surcharge bigint NOT NULL DEFAULT 0,
+    created_at         timestamptz,
+    updated_at         timestamptz
+);
+CREATE UNIQUE INDEX idx_price_plans_name ON price_plans (name);
+
+CREATE TABLE price_tiers (
+    id            uuid PRIMARY KEY,
+    price_plan_id uuid NOT NULL REFERENCES price_plans (id) ON DELETE CASCADE,
+    category      text,
+    row_from      bigint NOT NULL DEFAULT 1,
+    row_to        bigint NOT NULL DEFAULT 0,
+    price         bigint NOT NULL,
+    position      bigint NOT NULL
+);
+CREATE INDEX idx_price_tiers_price_plan_id ON price_tiers (price_plan_id);
+
+CREATE TABLE price_demand_steps (
+    id            uuid PRIMARY KEY,
+    price_plan_id uuid NOT NULL REFERENCES price_plans (id) ON DELETE CASCADE,
+    occupancy     bigint NOT NULL,
+    surcharge     bigint NOT NULL,
+    CONSTRAINT uq_price_demand_steps_occupancy UNIQUE (price_plan_id, occupancy)
+);
+CREATE INDEX idx_price_demand_steps_price_plan_id ON price_demand_steps (price_plan_id);
+
+ALTER TABLE performances
+    ADD COLUMN price_plan_id uuid REFERENCES price_plans (id),
+    ADD COLUMN premiere boolean NOT NULL DEFAULT false;
+CREATE INDEX idx_performances_price_plan_id ON performances (price_plan_id);
+
+ALTER TABLE performance_seats ADD COLUMN charged_price bigint;
+
+-- Уже забронированные места считаем проданными по базовой цене
+UPDATE performance_seats SET charged_price = price WHERE booking_id IS NOT NULL;
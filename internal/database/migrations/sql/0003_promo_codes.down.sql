@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_bookings_promo_code_id;
+ALTER TABLE bookings
+    DROP COLUMN promo_code_id,
+    DROP COLUMN discount,
+    DROP COLUMN subtotal;
+
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,38 @@
+CREATE TABLE promo_codes (
+    id                uuid PRIMARY KEY,
+    code              text NOT NULL,
+    kind              text NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value             bigint NOT NULL CHECK (value > 0),
+    valid_from        timestamptz,
+    valid_until       timestamptz,
+    max_uses          bigint NOT NULL DEFAULT 0,
+    max_uses_per_user bigint NOT NULL DEFAULT 0,
+    used_count        bigint NOT NULL DEFAULT 0,
+    play_id           uuid REFERENCES plays (id),
+    performance_id    uuid REFERENCES performances (id),
+    category          text,
+    active            boolean NOT NULL DEFAULT true,
+    created_at        timestamptz,
+    updated_at        timestamptz
+);
+CREATE UNIQUE INDEX idx_promo_codes_code ON promo_codes (code);
+
+CREATE TABLE promo_redemptions (
+    id            uuid PRIMARY KEY,
+    promo_code_id uuid NOT NULL REFERENCES promo_codes (id),
+    booking_id    uuid NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    user_id       uuid NOT NULL REFERENCES users (id),
+    discount      bigint NOT NULL,
+    created_at    timestamptz
+);
+CREATE INDEX idx_promo_redemptions_promo_code_id ON promo_redemptions (promo_code_id);
+CREATE UNIQUE INDEX idx_promo_redemptions_booking_id ON promo_redemptions (booking_id);
+CREATE INDEX idx_promo_redemptions_user_id ON promo_redemptions (user_id);
+
+ALTER TABLE bookings
+    ADD COLUMN subtotal bigint NOT NULL DEFAULT 0,
+    ADD COLUMN discount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN promo_code_id uuid REFERENCES promo_codes (id);
+CREATE INDEX idx_bookings_promo_code_id ON bookings (promo_code_id);
+
+UPDATE bookings SET subtotal = total_price;
@@ -0,0 +1,14 @@
+CREATE TABLE tickets (
+    id                  uuid PRIMARY KEY,
+    booking_id          uuid NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    performance_id      uuid NOT NULL REFERENCES performances (id),
+    performance_seat_id uuid NOT NULL REFERENCES performance_seats (id),
+    nonce               text NOT NULL,
+    status              text NOT NULL DEFAULT 'valid',
+    created_at          timestamptz
+);
+CREATE INDEX idx_tickets_booking_id ON tickets (booking_id);
+CREATE INDEX idx_tickets_performance_id ON tickets (performance_id);
+CREATE INDEX idx_tickets_performance_seat_id ON tickets (performance_seat_id);
+-- На место может быть выпущен только один действующий билет
+CREATE UNIQUE INDEX uq_tickets_valid_seat ON tickets (performance_seat_id) WHERE status = 'valid';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ticket_scans;
+
+ALTER TABLE tickets
+    DROP COLUMN admitted_gate,
+    DROP COLUMN admitted_at;
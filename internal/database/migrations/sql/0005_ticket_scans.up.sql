@@ -0,0 +1,18 @@
+ALTER TABLE tickets
+    ADD COLUMN admitted_at timestamptz,
+    ADD COLUMN admitted_gate text;
+
+CREATE TABLE ticket_scans (
+    id             uuid PRIMARY KEY,
+    ticket_id      uuid REFERENCES tickets (id) ON DELETE CASCADE,
+    performance_id uuid NOT NULL REFERENCES performances (id),
+    gate           text NOT NULL,
+    device_id      text NOT NULL,
+    result         text NOT NULL,
+    reason         text,
+    offline        boolean NOT NULL DEFAULT false,
+    scanned_at     timestamptz NOT NULL,
+    created_at     timestamptz
+);
+CREATE INDEX idx_ticket_scans_ticket_id ON ticket_scans (ticket_id);
+CREATE INDEX idx_ticket_scans_performance_id ON ticket_scans (performance_id);
@@ -0,0 +1,16 @@
+CREATE TABLE email_outbox (
+    id              uuid PRIMARY KEY,
+    template        text NOT NULL,
+    recipient       text NOT NULL,
+    subject         text NOT NULL,
+    text_body       text,
+    html_body       text,
+    status          text NOT NULL DEFAULT 'pending',
+    attempts        bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_error      text,
+    sent_at         timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+CREATE INDEX idx_email_outbox_pending ON email_outbox (next_attempt_at) WHERE status = 'pending';
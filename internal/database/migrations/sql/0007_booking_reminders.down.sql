@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS booking_reminders;
+
+ALTER TABLE bookings DROP COLUMN reminders_enabled;
@@ -0,0 +1,8 @@
+ALTER TABLE bookings ADD COLUMN reminders_enabled boolean NOT NULL DEFAULT true;
+
+CREATE TABLE booking_reminders (
+    booking_id     uuid NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    offset_minutes bigint NOT NULL,
+    sent_at        timestamptz NOT NULL,
+    PRIMARY KEY (booking_id, offset_minutes)
+);
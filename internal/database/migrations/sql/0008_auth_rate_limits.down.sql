@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS rate_limits;
+
+DROP INDEX IF EXISTS idx_email_verifications_email_created;
+ALTER TABLE email_verifications DROP COLUMN attempts;
@@ -0,0 +1,9 @@
+ALTER TABLE email_verifications ADD COLUMN attempts integer NOT NULL DEFAULT 0;
+CREATE INDEX idx_email_verifications_email_created ON email_verifications (email, created_at);
+
+CREATE TABLE rate_limits (
+    key          text PRIMARY KEY,
+    window_start timestamptz NOT NULL,
+    hits         integer NOT NULL
+);
+CREATE INDEX idx_rate_limits_window_start ON rate_limits (window_start);
@@ -0,0 +1,18 @@
+CREATE TABLE waitlist_entries (
+    id             uuid PRIMARY KEY,
+    performance_id uuid NOT NULL REFERENCES performances (id),
+    user_id        uuid NOT NULL REFERENCES users (id),
+    seats          integer NOT NULL CHECK (seats > 0),
+    category       text NOT NULL DEFAULT '',
+    status         text NOT NULL DEFAULT 'waiting',
+    booking_id     uuid REFERENCES bookings (id),
+    offered_at     timestamptz,
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+CREATE INDEX idx_waitlist_entries_performance_id ON waitlist_entries (performance_id, created_at);
+CREATE INDEX idx_waitlist_entries_user_id ON waitlist_entries (user_id);
+CREATE INDEX idx_waitlist_entries_booking_id ON waitlist_entries (booking_id);
+-- Одна действующая заявка покупателя на показ
+CREATE UNIQUE INDEX idx_waitlist_entries_active ON waitlist_entries (performance_id, user_id)
+    WHERE status IN ('waiting', 'offered');
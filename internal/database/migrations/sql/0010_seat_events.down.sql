@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS performance_seats_status_changed ON performance_seats;
+DROP FUNCTION IF EXISTS record_seat_event();
+
+DROP TABLE IF EXISTS seat_events;
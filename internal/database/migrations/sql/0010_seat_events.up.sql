@@ -0,0 +1,31 @@
+CREATE TABLE seat_events (
+    id                  bigserial PRIMARY KEY,
+    performance_id      uuid NOT NULL,
+    performance_seat_id uuid NOT NULL,
+    seat_id             uuid NOT NULL,
+    status              text NOT NULL,
+    created_at          timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_seat_events_performance_id ON seat_events (performance_id, id);
+CREATE INDEX idx_seat_events_created_at ON seat_events (created_at);
+
+-- Каждое изменение статуса места записывается в журнал и рассылается через NOTIFY.
+-- Уведомление доставляется только после фиксации транзакции.
+CREATE FUNCTION record_seat_event() RETURNS trigger AS $$
+DECLARE
+    event seat_events;
+BEGIN
+    INSERT INTO seat_events (performance_id, performance_seat_id, seat_id, status)
+    VALUES (NEW.performance_id, NEW.id, NEW.seat_id, NEW.status)
+    RETURNING * INTO event;
+
+    PERFORM pg_notify('seat_events', row_to_json(event)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER performance_seats_status_changed
+    AFTER UPDATE OF status ON performance_seats
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION record_seat_event();
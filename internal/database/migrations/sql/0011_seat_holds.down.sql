@@ -0,0 +1,4 @@
+UPDATE performance_seats SET status = 'available', reserved_until = NULL WHERE status = 'held';
+DROP INDEX IF EXISTS idx_performance_seats_hold_id;
+ALTER TABLE performance_seats DROP COLUMN IF EXISTS hold_id;
+DROP TABLE IF EXISTS seat_holds;
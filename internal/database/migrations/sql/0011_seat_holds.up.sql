@@ -0,0 +1,13 @@
+CREATE TABLE seat_holds (
+    id             uuid PRIMARY KEY,
+    performance_id uuid NOT NULL REFERENCES performances (id),
+    token_hash     text NOT NULL UNIQUE,
+    booking_id     uuid,
+    expires_at     timestamptz NOT NULL,
+    created_at     timestamptz
+);
+CREATE INDEX idx_seat_holds_performance_id ON seat_holds (performance_id);
+CREATE INDEX idx_seat_holds_expires_at ON seat_holds (expires_at);
+
+ALTER TABLE performance_seats ADD COLUMN hold_id uuid REFERENCES seat_holds (id) ON DELETE SET NULL;
+CREATE INDEX idx_performance_seats_hold_id ON performance_seats (hold_id);
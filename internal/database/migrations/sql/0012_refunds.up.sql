@@ -0,0 +1,24 @@
+ALTER TABLE payments ADD COLUMN refunded integer NOT NULL DEFAULT 0;
+
+CREATE TABLE refunds (
+    id                 uuid PRIMARY KEY,
+    booking_id         uuid NOT NULL REFERENCES bookings (id),
+    payment_id         uuid REFERENCES payments (id),
+    seats_value        integer NOT NULL,
+    amount             integer NOT NULL CHECK (amount >= 0),
+    percent            integer NOT NULL CHECK (percent BETWEEN 0 AND 100),
+    policy_percent     integer NOT NULL CHECK (policy_percent BETWEEN 0 AND 100),
+    overridden_by      uuid REFERENCES users (id),
+    reason             text NOT NULL DEFAULT '',
+    provider_refund_id text NOT NULL DEFAULT '',
+    created_at         timestamptz
+);
+CREATE INDEX idx_refunds_booking_id ON refunds (booking_id);
+CREATE INDEX idx_refunds_payment_id ON refunds (payment_id);
+
+CREATE TABLE refund_items (
+    refund_id           uuid NOT NULL REFERENCES refunds (id) ON DELETE CASCADE,
+    performance_seat_id uuid NOT NULL REFERENCES performance_seats (id),
+    paid                integer NOT NULL,
+    PRIMARY KEY (refund_id, performance_seat_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE audit_log (
+    id          bigserial PRIMARY KEY,
+    actor_id    uuid REFERENCES users (id) ON DELETE SET NULL,
+    actor_email text NOT NULL DEFAULT '',
+    actor_ip    text NOT NULL DEFAULT '',
+    action      text NOT NULL,
+    entity_type text NOT NULL,
+    entity_id   uuid NOT NULL,
+    changes     jsonb NOT NULL DEFAULT '{}',
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_audit_log_actor_id ON audit_log (actor_id, id);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id, id);
+CREATE INDEX idx_audit_log_action ON audit_log (action, id);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
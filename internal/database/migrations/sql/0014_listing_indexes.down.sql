@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_performances_created_at_id;
+DROP INDEX IF EXISTS idx_performances_date_id;
+
+DROP INDEX IF EXISTS idx_plays_genre_lower;
+DROP INDEX IF EXISTS idx_plays_created_at_id;
+DROP INDEX IF EXISTS idx_plays_duration_id;
+DROP INDEX IF EXISTS idx_plays_author_id;
+DROP INDEX IF EXISTS idx_plays_title_id;
@@ -0,0 +1,9 @@
+-- Индексы для постраничной выдачи каталога: ключ сортировки вместе с id
+CREATE INDEX idx_plays_title_id ON plays (title, id);
+CREATE INDEX idx_plays_author_id ON plays (author, id);
+CREATE INDEX idx_plays_duration_id ON plays (duration, id);
+CREATE INDEX idx_plays_created_at_id ON plays (created_at, id);
+CREATE INDEX idx_plays_genre_lower ON plays (LOWER(genre));
+
+CREATE INDEX idx_performances_date_id ON performances (date, id);
+CREATE INDEX idx_performances_created_at_id ON performances (created_at, id);
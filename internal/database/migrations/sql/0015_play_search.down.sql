@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_plays_search_vector;
+ALTER TABLE plays DROP COLUMN IF EXISTS search_vector;
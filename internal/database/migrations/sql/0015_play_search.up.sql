@@ -0,0 +1,10 @@
+-- Поисковый вектор спектакля пересчитывается самой базой при любом изменении строки.
+-- Буква ё приводится к е, чтобы "вишнёвый" и "вишневый" находили одно и то же.
+ALTER TABLE plays ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', translate(coalesce(title, ''), 'ёЁ', 'еЕ')), 'A') ||
+    setweight(to_tsvector('russian', translate(coalesce(author, ''), 'ёЁ', 'еЕ')), 'B') ||
+    setweight(to_tsvector('russian', translate(coalesce(genre, ''), 'ёЁ', 'еЕ')), 'C') ||
+    setweight(to_tsvector('russian', translate(coalesce(description, ''), 'ёЁ', 'еЕ')), 'D')
+) STORED;
+
+CREATE INDEX idx_plays_search_vector ON plays USING gin (search_vector);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_performances_status_date;
+
+ALTER TABLE performances DROP CONSTRAINT IF EXISTS performances_status_check;
+ALTER TABLE performances ALTER COLUMN status DROP NOT NULL;
+
+UPDATE performances SET status = 'scheduled' WHERE status IN ('on_sale', 'sales_closed', 'postponed');
@@ -0,0 +1,8 @@
+-- До появления жизненного цикла на запланированные показы сразу шла продажа
+UPDATE performances SET status = 'on_sale' WHERE status = 'scheduled' OR status IS NULL;
+
+ALTER TABLE performances ALTER COLUMN status SET NOT NULL;
+ALTER TABLE performances ADD CONSTRAINT performances_status_check
+    CHECK (status IN ('scheduled', 'on_sale', 'sales_closed', 'completed', 'cancelled', 'postponed'));
+
+CREATE INDEX idx_performances_status_date ON performances (status, date);
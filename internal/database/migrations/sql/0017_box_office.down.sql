@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_payments_cashier_id_created_at;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS tendered;
+ALTER TABLE payments DROP COLUMN IF EXISTS cashier_id;
+ALTER TABLE payments DROP COLUMN IF EXISTS method;
+
+-- Откат невозможен, пока остаются продажи без покупателя
+ALTER TABLE bookings ALTER COLUMN user_id SET NOT NULL;
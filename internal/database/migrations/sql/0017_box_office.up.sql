@@ -0,0 +1,9 @@
+-- Продажа в кассе может быть оформлена без покупателя
+ALTER TABLE bookings ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE payments ADD COLUMN method text NOT NULL DEFAULT 'online'
+    CHECK (method IN ('online', 'cash', 'card'));
+ALTER TABLE payments ADD COLUMN cashier_id uuid REFERENCES users (id);
+ALTER TABLE payments ADD COLUMN tendered integer CHECK (tendered >= 0);
+
+CREATE INDEX idx_payments_cashier_id_created_at ON payments (cashier_id, created_at) WHERE cashier_id IS NOT NULL;
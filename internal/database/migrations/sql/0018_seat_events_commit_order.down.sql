@@ -0,0 +1,20 @@
+DROP TRIGGER IF EXISTS seat_event_committed ON seat_events;
+DROP FUNCTION IF EXISTS number_seat_event();
+
+CREATE OR REPLACE FUNCTION record_seat_event() RETURNS trigger AS $$
+DECLARE
+    event seat_events;
+BEGIN
+    INSERT INTO seat_events (performance_id, performance_seat_id, seat_id, status)
+    VALUES (NEW.performance_id, NEW.id, NEW.seat_id, NEW.status)
+    RETURNING * INTO event;
+
+    PERFORM pg_notify('seat_events', row_to_json(event)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_seat_events_performance_id_seq;
+DROP INDEX IF EXISTS idx_seat_events_seq;
+ALTER TABLE seat_events DROP COLUMN IF EXISTS seq;
+DROP SEQUENCE IF EXISTS seat_events_seq;
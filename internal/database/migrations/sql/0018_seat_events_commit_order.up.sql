@@ -0,0 +1,45 @@
+-- Номер id выдается при вставке, поэтому транзакция, начавшая менять места раньше,
+-- может зафиксироваться позже и получить меньший номер, чем уже доставленное событие.
+-- seq выдается при фиксации транзакции под advisory-блокировкой и растет в порядке
+-- фиксации, поэтому клиенты и догоняющие экземпляры могут читать журнал по seq.
+CREATE SEQUENCE seat_events_seq;
+
+ALTER TABLE seat_events ADD COLUMN seq bigint;
+UPDATE seat_events SET seq = id;
+SELECT setval('seat_events_seq', COALESCE((SELECT MAX(id) FROM seat_events), 0) + 1, false);
+
+CREATE UNIQUE INDEX idx_seat_events_seq ON seat_events (seq);
+CREATE INDEX idx_seat_events_performance_id_seq ON seat_events (performance_id, seq);
+
+-- Событие записывается в журнал сразу, а номер и уведомление получает при фиксации
+CREATE OR REPLACE FUNCTION record_seat_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO seat_events (performance_id, performance_seat_id, seat_id, status)
+    VALUES (NEW.performance_id, NEW.id, NEW.seat_id, NEW.status);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Блокировка берется в самом конце транзакции, когда все блокировки строк уже
+-- получены, и держится до фиксации: следующая транзакция получит номер только
+-- после того, как эта станет видна
+CREATE FUNCTION number_seat_event() RETURNS trigger AS $$
+DECLARE
+    event seat_events;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('seat_events'));
+
+    UPDATE seat_events SET seq = nextval('seat_events_seq')
+    WHERE id = NEW.id
+    RETURNING * INTO event;
+
+    PERFORM pg_notify('seat_events', row_to_json(event)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER seat_event_committed
+    AFTER INSERT ON seat_events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    EXECUTE FUNCTION number_seat_event();
@@ -0,0 +1 @@
+ALTER TABLE performances DROP COLUMN IF EXISTS postponed_to_id;
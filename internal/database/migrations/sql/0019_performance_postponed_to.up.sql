@@ -0,0 +1,3 @@
+-- Показ, на который перенесен отложенный показ: повторный перенос на ту же дату
+-- продолжает работу с ним, а не создает еще один показ
+ALTER TABLE performances ADD COLUMN postponed_to_id uuid REFERENCES performances (id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_seat_holds_session_expires_at;
+ALTER TABLE seat_holds DROP COLUMN IF EXISTS session;
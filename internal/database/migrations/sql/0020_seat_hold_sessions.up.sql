@@ -0,0 +1,3 @@
+-- Анонимная сессия, создавшая удержание: число удерживаемых ею мест ограничено
+ALTER TABLE seat_holds ADD COLUMN session text NOT NULL DEFAULT '';
+CREATE INDEX idx_seat_holds_session_expires_at ON seat_holds (session, expires_at);
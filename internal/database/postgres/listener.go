@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Listener получает уведомления NOTIFY на отдельном соединении, вне пула gorm:
+// LISTEN действует только в рамках одного соединения
+type Listener struct {
+	dsn string
+}
+
+// NewListener создает Listener с параметрами подключения из Init
+func NewListener() *Listener {
+	return &Listener{dsn: dsn}
+}
+
+// Listen подписывается на канал и возвращает полезную нагрузку уведомлений.
+// Канал закрывается при отмене ctx или потере соединения; после этого нужно
+// подписаться заново.
+func (l *Listener) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	payloads := make(chan string)
+	go func() {
+		defer close(payloads)
+		defer conn.Close(context.Background())
+
+		for {
+			notification, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case payloads <- notification.Payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return payloads, nil
+}
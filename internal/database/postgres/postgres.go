@@ -1,9 +1,11 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"theater-ticket-system/internal/config"
+	"theater-ticket-system/internal/database/migrations"
 	"theater-ticket-system/internal/models/models"
 	"time"
 
@@ -16,8 +18,11 @@ import (
 
 var DB *gorm.DB
 
+// dsn - параметры подключения, нужны для отдельных соединений вне пула gorm
+var dsn string
+
 func Init(cfg *config.Config) error {
-	dsn := fmt.Sprintf(
+	dsn = fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Europe/Minsk",
 		cfg.DB.Host,
 		cfg.DB.User,
@@ -38,27 +43,36 @@ func Init(cfg *config.Config) error {
 	return nil
 }
 
+// Migrate применяет все непримененные миграции схемы
 func Migrate() error {
 	log.Println("Running migrations...")
 
-	err := DB.AutoMigrate(
-		&model.User{},
-		&model.Play{},
-		&model.Hall{},
-		&model.Seat{},
-		&model.Performance{},
-		&model.PerformanceSeat{},
-		&model.Booking{},
-		&model.EmailVerification{},
-	)
+	migrator, err := NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to migrate: %w", err)
 	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
 
 	log.Println("Migrations completed")
 	return nil
 }
 
+// NewMigrator создает Migrator поверх текущего подключения
+func NewMigrator() (*migrations.Migrator, error) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(sqlDB)
+}
+
 func Seed() error {
 	log.Println("Seeding database...")
 
@@ -88,8 +102,10 @@ func Seed() error {
 			seat := model.Seat{
 				ID:       uuid.New(),
 				HallID:   hall.ID,
+				Section:  category,
 				Row:      row,
 				Number:   number,
+				Position: number,
 				Category: category,
 			}
 			if err := DB.Create(&seat).Error; err != nil {
@@ -98,6 +114,27 @@ func Seed() error {
 		}
 	}
 
+	planID := uuid.New()
+	plan := model.PricePlan{
+		ID:                planID,
+		Name:              "Стандартный",
+		DefaultPrice:      1500,
+		WeekendSurcharge:  10,
+		PremiereSurcharge: 20,
+		Tiers: []model.PriceTier{
+			{ID: uuid.New(), PricePlanID: planID, Category: "parterre", RowFrom: 1, RowTo: 5, Price: 3500, Position: 0},
+			{ID: uuid.New(), PricePlanID: planID, Category: "parterre", RowFrom: 6, Price: 1500, Position: 1},
+			{ID: uuid.New(), PricePlanID: planID, Category: "balcony", RowFrom: 1, Price: 1000, Position: 2},
+		},
+		DemandSteps: []model.DemandStep{
+			{ID: uuid.New(), PricePlanID: planID, Occupancy: 70, Surcharge: 10},
+			{ID: uuid.New(), PricePlanID: planID, Occupancy: 90, Surcharge: 25},
+		},
+	}
+	if err := DB.Create(&plan).Error; err != nil {
+		return err
+	}
+
 	plays := []model.Play{
 		{
 			ID:          uuid.New(),
@@ -139,7 +176,10 @@ func Seed() error {
 				PlayID: play.ID,
 				HallID: hall.ID,
 				Date:   time.Now().AddDate(0, 0, i*7),
-				Status: "scheduled",
+				Status: "on_sale",
+
+				PricePlanID: &planID,
+				Premiere:    i == 1,
 			}
 			if err := DB.Create(&performance).Error; err != nil {
 				return err
@@ -149,18 +189,11 @@ func Seed() error {
 			DB.Where("hall_id = ?", hall.ID).Find(&seats)
 
 			for _, seat := range seats {
-				price := 1500
-				if seat.Category == "parterre" && seat.Row <= 5 {
-					price = 3500
-				} else if seat.Category == "balcony" {
-					price = 1000
-				}
-
 				perfSeat := model.PerformanceSeat{
 					ID:            uuid.New(),
 					PerformanceID: performance.ID,
 					SeatID:        seat.ID,
-					Price:         price,
+					Price:         plan.Price(seat),
 					Status:        "available",
 				}
 				if err := DB.Create(&perfSeat).Error; err != nil {
@@ -0,0 +1,119 @@
+// Package listing описывает постраничную выборку списков: сортировку, курсор
+// следующей страницы и ответ со страницей и общим числом записей.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidQuery - параметры списка не удалось разобрать
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// Sort - поле сортировки и направление
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort разбирает параметр вида "title" или "-date" (по убыванию). Пустое
+// значение дает сортировку def; поле должно входить в allowed.
+func ParseSort(value string, allowed []string, def Sort) (Sort, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	sort := Sort{Field: value}
+	if field, ok := strings.CutPrefix(value, "-"); ok {
+		sort = Sort{Field: field, Desc: true}
+	}
+	for _, field := range allowed {
+		if field == sort.Field {
+			return sort, nil
+		}
+	}
+	return Sort{}, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, sort.Field)
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor - позиция последней записи выданной страницы: значение поля сортировки и ID.
+// Курсор действителен только для той сортировки, с которой он выдан.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode упаковывает курсор в непрозрачную для клиента строку
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор, выданный Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return &cursor, nil
+}
+
+// Query - сортировка, курсор и размер страницы
+type Query struct {
+	Sort   Sort
+	Cursor *Cursor
+	Limit  int
+}
+
+// NewQuery проверяет параметры страницы. Пустой cursor - первая страница,
+// limit 0 - размер страницы по умолчанию.
+func NewQuery(sort Sort, cursor string, limit int) (Query, error) {
+	if limit < 0 || limit > MaxLimit {
+		return Query{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxLimit)
+	}
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	query := Query{Sort: sort, Limit: limit}
+	if cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return Query{}, err
+		}
+		if decoded.Sort != sort.String() {
+			return Query{}, fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidQuery)
+		}
+		query.Cursor = decoded
+	}
+	return query, nil
+}
+
+// Page - страница списка. NextCursor пуст на последней странице; Total - число
+// записей, подходящих под фильтры, без учета курсора.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      int64
+}
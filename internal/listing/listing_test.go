@@ -0,0 +1,65 @@
+package listing
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	allowed := []string{"title", "created_at"}
+	def := Sort{Field: "created_at", Desc: true}
+
+	sort, err := ParseSort("", allowed, def)
+	require.NoError(t, err)
+	assert.Equal(t, def, sort)
+
+	sort, err = ParseSort("title", allowed, def)
+	require.NoError(t, err)
+	assert.Equal(t, Sort{Field: "title"}, sort)
+
+	sort, err = ParseSort("-title", allowed, def)
+	require.NoError(t, err)
+	assert.Equal(t, Sort{Field: "title", Desc: true}, sort)
+	assert.Equal(t, "-title", sort.String())
+
+	_, err = ParseSort("password", allowed, def)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestNewQuery(t *testing.T) {
+	sort := Sort{Field: "date"}
+	cursor := Cursor{Sort: "date", Value: "2025-05-01T19:00:00Z", ID: uuid.New()}
+
+	t.Run("defaults", func(t *testing.T) {
+		query, err := NewQuery(sort, "", 0)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultLimit, query.Limit)
+		assert.Nil(t, query.Cursor)
+	})
+
+	t.Run("cursor round trip", func(t *testing.T) {
+		query, err := NewQuery(sort, cursor.Encode(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, &cursor, query.Cursor)
+	})
+
+	t.Run("cursor of another sort", func(t *testing.T) {
+		_, err := NewQuery(Sort{Field: "date", Desc: true}, cursor.Encode(), 10)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		_, err := NewQuery(sort, "not a cursor!", 10)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+
+	t.Run("limit out of range", func(t *testing.T) {
+		_, err := NewQuery(sort, "", MaxLimit+1)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+		_, err = NewQuery(sort, "", -1)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actor - кто выполняет изменение. У анонимного покупателя есть только IP,
+// у фоновых процессов нет ни пользователя, ни IP.
+type Actor struct {
+	UserID *uuid.UUID
+	Email  string
+	Role   string
+	IP     string
+}
+
+// AuditEntry - запись журнала аудита об изменении сущности. Changes хранит
+// изменившиеся поля в виде {"поле": {"before": ..., "after": ...}}.
+type AuditEntry struct {
+	ID int64 `gorm:"primaryKey;autoIncrement"`
+
+	ActorID    *uuid.UUID `gorm:"index"`
+	ActorEmail string
+	ActorIP    string
+	Action     string    `gorm:"not null"` // например play.create, booking.cancel
+	EntityType string    `gorm:"not null"` // play, performance, hall, booking, waitlist_entry
+	EntityID   uuid.UUID `gorm:"not null"`
+	Changes    string    `gorm:"type:jsonb;not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (*AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+func (e *AuditEntry) Response() response.AuditEntry {
+	return response.AuditEntry{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		ActorEmail: e.ActorEmail,
+		ActorIP:    e.ActorIP,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Changes:    json.RawMessage(e.Changes),
+		CreatedAt:  e.CreatedAt,
+	}
+}
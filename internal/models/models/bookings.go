@@ -9,26 +9,44 @@ import (
 )
 
 type Booking struct {
-	ID            uuid.UUID `gorm:"primaryKey"`
-	UserID        uuid.UUID `gorm:"not null;index"`
-	PerformanceID uuid.UUID `gorm:"not null;index"`
+	ID            uuid.UUID  `gorm:"primaryKey"`
+	UserID        *uuid.UUID `gorm:"index"` // nil - продажа в кассе без покупателя
+	PerformanceID uuid.UUID  `gorm:"not null;index"`
 
-	TotalPrice int    `gorm:"not null"`
-	Status     string `gorm:"default:'pending'"` // pending, confirmed, cancelled
-	ExpiresAt  time.Time
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	Subtotal    int        `gorm:"not null;default:0"` // стоимость мест до скидки
+	Discount    int        `gorm:"not null;default:0"`
+	PromoCodeID *uuid.UUID `gorm:"index"`
+	TotalPrice  int        `gorm:"not null"`
+	Status      string     `gorm:"default:'pending'"` // pending, confirmed, cancelled, expired
+	ExpiresAt   time.Time
+
+	RemindersEnabled bool `gorm:"not null;default:true"` // покупатель может отказаться от напоминаний
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
 
 	User             User              `gorm:"foreignKey:UserID"`
 	Performance      Performance       `gorm:"foreignKey:PerformanceID"`
 	PerformanceSeats []PerformanceSeat `gorm:"foreignKey:BookingID"`
+	Tickets          []Ticket          `gorm:"foreignKey:BookingID"`
 }
 
 func (*Booking) TableName() string {
 	return "bookings"
 }
 
+// BookingReminder - отметка об отправленном напоминании. Первичный ключ не дает
+// отправить одно и то же напоминание дважды.
+type BookingReminder struct {
+	BookingID     uuid.UUID `gorm:"primaryKey"`
+	OffsetMinutes int       `gorm:"primaryKey"` // за сколько минут до начала показа
+	SentAt        time.Time `gorm:"not null"`
+}
+
+func (*BookingReminder) TableName() string {
+	return "booking_reminders"
+}
+
 // ОБНОВЛЕННЫЙ МЕТОД Response()
 func (b *Booking) Response() response.Booking {
 	seats := make([]response.PerformanceSeat, len(b.PerformanceSeats))
@@ -40,8 +58,11 @@ func (b *Booking) Response() response.Booking {
 		ID:            b.ID,
 		UserID:        b.UserID,
 		PerformanceID: b.PerformanceID,
+		Subtotal:      b.Subtotal,
+		Discount:      b.Discount,
 		TotalPrice:    b.TotalPrice,
 		Status:        b.Status,
+		Reminders:     b.RemindersEnabled,
 		SeatsCount:    len(b.PerformanceSeats),
 		ExpiresAt:     b.ExpiresAt,
 		CreatedAt:     b.CreatedAt,
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage - письмо в очереди на отправку. Записывается в одной транзакции
+// с изменением, о котором сообщает, и отправляется фоновым процессом.
+type OutboxMessage struct {
+	ID uuid.UUID `gorm:"primaryKey"`
+
+	Template      string `gorm:"not null"`
+	Recipient     string `gorm:"not null"`
+	Subject       string `gorm:"not null"`
+	TextBody      string
+	HTMLBody      string
+	Status        string    `gorm:"default:'pending'"` // pending, sent, failed
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (*OutboxMessage) TableName() string {
+	return "email_outbox"
+}
@@ -13,6 +13,7 @@ type EmailVerification struct {
 	Code      string    `gorm:"not null"`
 	ExpiresAt time.Time `gorm:"not null"`
 	Used      bool      `gorm:"default:false"`
+	Attempts  int       `gorm:"not null;default:0"` // неверных попыток ввода кода
 	CreatedAt time.Time
 }
 
@@ -1,6 +1,7 @@
 package model
 
 import (
+	response "theater-ticket-system/internal/models/responses"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,3 +24,13 @@ type Hall struct {
 func (Hall) TableName() string {
 	return "halls"
 }
+
+func (h *Hall) Response() response.Hall {
+	return response.Hall{
+		ID:        h.ID,
+		Name:      h.Name,
+		Capacity:  h.Capacity,
+		CreatedAt: h.CreatedAt,
+		UpdatedAt: h.UpdatedAt,
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Способы оплаты
+const (
+	PaymentOnline = "online" // через платежную систему
+	PaymentCash   = "cash"   // наличными в кассе
+	PaymentCard   = "card"   // картой через терминал в кассе
+)
+
+// Payment - платеж по бронированию во внешней платежной системе или в кассе театра
+type Payment struct {
+	ID        uuid.UUID `gorm:"primaryKey"`
+	BookingID uuid.UUID `gorm:"not null;index"`
+
+	Provider string `gorm:"not null"`
+	IntentID string `gorm:"not null;uniqueIndex"`
+	Amount   int    `gorm:"not null"`
+	Currency string `gorm:"not null"`
+	Refunded int    `gorm:"not null;default:0"` // сумма всех возвратов по платежу
+	Status   string `gorm:"default:'pending'"`  // pending, captured, failed, refunded, refund_pending
+
+	Method    string     `gorm:"not null;default:'online'"` // online, cash, card
+	CashierID *uuid.UUID `gorm:"index"`                     // кассир, принявший оплату в кассе
+	Tendered  *int       // сколько наличных передал покупатель
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Booking Booking `gorm:"foreignKey:BookingID"`
+	Cashier *User   `gorm:"foreignKey:CashierID"`
+}
+
+func (*Payment) TableName() string {
+	return "payments"
+}
@@ -13,10 +13,12 @@ type PerformanceSeat struct {
 	PerformanceID uuid.UUID  `gorm:"not null;index"`
 	SeatID        uuid.UUID  `gorm:"not null;index"`
 	BookingID     *uuid.UUID `gorm:"index"`
+	HoldID        *uuid.UUID `gorm:"index"` // удержание, пока место в статусе held
 
-	Price         int    `gorm:"not null"`
-	Status        string `gorm:"default:'available'"` // available, reserved, sold
-	ReservedUntil time.Time
+	Price         int        `gorm:"not null"` // базовая цена по тарифному плану
+	ChargedPrice  *int       // цена с надбавками, по которой место забронировано
+	Status        string     `gorm:"default:'available'"` // available, held, reserved, sold
+	ReservedUntil *time.Time // до какого момента действует удержание или бронь
 
 	Performance Performance `gorm:"foreignKey:PerformanceID"`
 	Seat        Seat        `gorm:"foreignKey:SeatID"`
@@ -33,6 +35,7 @@ func (ps *PerformanceSeat) Response() response.PerformanceSeat {
 		PerformanceID: ps.PerformanceID,
 		SeatID:        ps.SeatID,
 		Price:         ps.Price,
+		ChargedPrice:  ps.ChargedPrice,
 		Status:        ps.Status,
 		Seat: func() *response.Seat {
 			if ps.Seat.ID != uuid.Nil {
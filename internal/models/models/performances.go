@@ -14,14 +14,20 @@ type Performance struct {
 	PlayID uuid.UUID `gorm:"not null;index"`
 	HallID uuid.UUID `gorm:"not null;index"`
 
+	PricePlanID *uuid.UUID `gorm:"index"`
+	Premiere    bool       `gorm:"not null;default:false"`
+
+	PostponedToID *uuid.UUID // показ, на который перенесен этот показ
+
 	Date      time.Time `gorm:"not null;index"`
-	Status    string    `gorm:"default:'scheduled'"` // scheduled, completed, cancelled
+	Status    string    `gorm:"default:'scheduled'"` // scheduled, on_sale, sales_closed, completed, cancelled, postponed
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
 	Play             *Play             `gorm:"foreignKey:PlayID"`
 	Hall             Hall              `gorm:"foreignKey:HallID"`
+	PricePlan        *PricePlan        `gorm:"foreignKey:PricePlanID"`
 	PerformanceSeats []PerformanceSeat `gorm:"foreignKey:PerformanceID"`
 	Bookings         []Booking         `gorm:"foreignKey:PerformanceID"`
 }
@@ -32,11 +38,15 @@ func (*Performance) TableName() string {
 
 func (p *Performance) Response() response.Performance {
 	return response.Performance{
-		ID:        p.ID,
-		Date:      p.Date,
-		Status:    p.Status,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:          p.ID,
+		Date:        p.Date,
+		Status:      p.Status,
+		Premiere:    p.Premiere,
+		PricePlanID: p.PricePlanID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+
+		PostponedToID: p.PostponedToID,
 
 		Play: func() *response.Play {
 			if p.Play != nil {
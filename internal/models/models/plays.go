@@ -50,3 +50,26 @@ func (p *Play) Response() response.Play {
 		Performances: performances,
 	}
 }
+
+// PlayMatch - спектакль, найденный полнотекстовым поиском
+type PlayMatch struct {
+	Play Play
+	Rank float64
+
+	// Фрагменты полей, в которых совпадения выделены тегом <mark>; остальной текст экранирован
+	TitleHeadline       string
+	AuthorHeadline      string
+	DescriptionHeadline string
+}
+
+func (m *PlayMatch) Response() response.PlayMatch {
+	return response.PlayMatch{
+		Play: m.Play.Response(),
+		Rank: m.Rank,
+		Highlights: response.PlayHighlights{
+			Title:       m.TitleHeadline,
+			Author:      m.AuthorHeadline,
+			Description: m.DescriptionHeadline,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PricePlan - тарифный план показа: базовые цены по категориям и рядам и правила надбавок
+type PricePlan struct {
+	ID uuid.UUID `gorm:"primaryKey"`
+
+	Name              string `gorm:"not null;uniqueIndex"`
+	DefaultPrice      int    `gorm:"not null"`           // цена места, не попавшего ни в один тариф
+	WeekendSurcharge  int    `gorm:"not null;default:0"` // надбавка в процентах на субботу и воскресенье
+	PremiereSurcharge int    `gorm:"not null;default:0"` // надбавка в процентах на премьерные показы
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+
+	Tiers       []PriceTier  `gorm:"foreignKey:PricePlanID"`
+	DemandSteps []DemandStep `gorm:"foreignKey:PricePlanID"`
+}
+
+func (*PricePlan) TableName() string {
+	return "price_plans"
+}
+
+// PriceTier - цена для категории мест в диапазоне рядов. RowTo = 0 означает "до последнего ряда",
+// пустая категория подходит для любой.
+type PriceTier struct {
+	ID          uuid.UUID `gorm:"primaryKey"`
+	PricePlanID uuid.UUID `gorm:"not null;index"`
+
+	Category string
+	RowFrom  int `gorm:"not null;default:1"`
+	RowTo    int `gorm:"not null;default:0"`
+	Price    int `gorm:"not null"`
+	Position int `gorm:"not null"` // порядок проверки тарифов, первый подходящий побеждает
+}
+
+func (*PriceTier) TableName() string {
+	return "price_tiers"
+}
+
+// DemandStep - надбавка, которая действует, когда заполненность показа достигла Occupancy процентов
+type DemandStep struct {
+	ID          uuid.UUID `gorm:"primaryKey"`
+	PricePlanID uuid.UUID `gorm:"not null;index"`
+
+	Occupancy int `gorm:"not null"`
+	Surcharge int `gorm:"not null"`
+}
+
+func (*DemandStep) TableName() string {
+	return "price_demand_steps"
+}
+
+// Matches сообщает, относится ли место к тарифу
+func (t *PriceTier) Matches(seat Seat) bool {
+	if t.Category != "" && t.Category != seat.Category {
+		return false
+	}
+	return seat.Row >= t.RowFrom && (t.RowTo == 0 || seat.Row <= t.RowTo)
+}
+
+// Price возвращает базовую цену места по первому подходящему тарифу плана
+func (p *PricePlan) Price(seat Seat) int {
+	for i := range p.Tiers {
+		if p.Tiers[i].Matches(seat) {
+			return p.Tiers[i].Price
+		}
+	}
+	return p.DefaultPrice
+}
+
+func (p *PricePlan) Response() response.PricePlan {
+	tiers := make([]response.PriceTier, len(p.Tiers))
+	for i, t := range p.Tiers {
+		tiers[i] = response.PriceTier{
+			Category: t.Category,
+			RowFrom:  t.RowFrom,
+			RowTo:    t.RowTo,
+			Price:    t.Price,
+		}
+	}
+	steps := make([]response.DemandStep, len(p.DemandSteps))
+	for i, s := range p.DemandSteps {
+		steps[i] = response.DemandStep{
+			Occupancy: s.Occupancy,
+			Surcharge: s.Surcharge,
+		}
+	}
+
+	return response.PricePlan{
+		ID:                p.ID,
+		Name:              p.Name,
+		DefaultPrice:      p.DefaultPrice,
+		WeekendSurcharge:  p.WeekendSurcharge,
+		PremiereSurcharge: p.PremiereSurcharge,
+		Tiers:             tiers,
+		DemandSteps:       steps,
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PromoKindPercent = "percent"
+	PromoKindFixed   = "fixed"
+)
+
+// PromoCode - промокод на скидку. Ограничения по спектаклю, показу и категории мест
+// необязательны; нулевые лимиты означают отсутствие ограничения.
+type PromoCode struct {
+	ID uuid.UUID `gorm:"primaryKey"`
+
+	Code           string `gorm:"not null;uniqueIndex"`
+	Kind           string `gorm:"not null"` // percent, fixed
+	Value          int    `gorm:"not null"` // процент или сумма скидки
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	MaxUses        int `gorm:"not null;default:0"`
+	MaxUsesPerUser int `gorm:"not null;default:0"`
+	UsedCount      int `gorm:"not null;default:0"`
+	PlayID         *uuid.UUID
+	PerformanceID  *uuid.UUID
+	Category       string // скидка действует только на места этой категории
+	Active         bool   `gorm:"not null;default:true"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (*PromoCode) TableName() string {
+	return "promo_codes"
+}
+
+// PromoRedemption - использование промокода бронированием
+type PromoRedemption struct {
+	ID          uuid.UUID `gorm:"primaryKey"`
+	PromoCodeID uuid.UUID `gorm:"not null;index"`
+	BookingID   uuid.UUID `gorm:"not null;uniqueIndex"`
+	UserID      uuid.UUID `gorm:"not null;index"`
+
+	Discount  int `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (*PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
+
+func (p *PromoCode) Response() response.PromoCode {
+	return response.PromoCode{
+		ID:             p.ID,
+		Code:           p.Code,
+		Kind:           p.Kind,
+		Value:          p.Value,
+		ValidFrom:      p.ValidFrom,
+		ValidUntil:     p.ValidUntil,
+		MaxUses:        p.MaxUses,
+		MaxUsesPerUser: p.MaxUsesPerUser,
+		UsedCount:      p.UsedCount,
+		PlayID:         p.PlayID,
+		PerformanceID:  p.PerformanceID,
+		Category:       p.Category,
+		Active:         p.Active,
+	}
+}
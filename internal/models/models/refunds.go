@@ -0,0 +1,62 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Refund - возврат денег за отмененные места подтвержденного бронирования.
+// Процент возврата берется из политики возврата; сотрудник может его изменить,
+// тогда в записи остаются исходный процент политики, автор и причина.
+type Refund struct {
+	ID        uuid.UUID  `gorm:"primaryKey"`
+	BookingID uuid.UUID  `gorm:"not null;index"`
+	PaymentID *uuid.UUID `gorm:"index"`
+
+	SeatsValue       int        `gorm:"not null"` // сколько покупатель заплатил за отмененные места
+	Amount           int        `gorm:"not null"` // сумма возврата
+	Percent          int        `gorm:"not null"`
+	PolicyPercent    int        `gorm:"not null"` // процент по политике возврата
+	OverriddenBy     *uuid.UUID // сотрудник, изменивший процент политики
+	Reason           string
+	ProviderRefundID string
+	CreatedAt        time.Time
+
+	Items []RefundItem `gorm:"foreignKey:RefundID"`
+}
+
+func (*Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem - отмененное место и оплаченная за него сумма
+type RefundItem struct {
+	RefundID          uuid.UUID `gorm:"primaryKey"`
+	PerformanceSeatID uuid.UUID `gorm:"primaryKey"`
+	Paid              int       `gorm:"not null"`
+}
+
+func (*RefundItem) TableName() string {
+	return "refund_items"
+}
+
+func (r *Refund) Response() response.Refund {
+	seatIDs := make([]uuid.UUID, len(r.Items))
+	for i, item := range r.Items {
+		seatIDs[i] = item.PerformanceSeatID
+	}
+	return response.Refund{
+		ID:            r.ID,
+		BookingID:     r.BookingID,
+		SeatIDs:       seatIDs,
+		SeatsValue:    r.SeatsValue,
+		Amount:        r.Amount,
+		Percent:       r.Percent,
+		PolicyPercent: r.PolicyPercent,
+		Overridden:    r.OverriddenBy != nil,
+		Reason:        r.Reason,
+		CreatedAt:     r.CreatedAt,
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+const (
+	RoleCustomer = "customer"
+	RoleCashier  = "cashier"
+	RoleUsher    = "usher"
+	RoleManager  = "manager"
+	RoleAdmin    = "admin"
+)
+
+// Permission - право на группу операций API
+type Permission string
+
+const (
+	PermissionManageCatalog  Permission = "catalog:manage"
+	PermissionManageSchedule Permission = "schedule:manage"
+	PermissionManageHalls    Permission = "halls:manage"
+	PermissionManagePromos   Permission = "promos:manage"
+	PermissionScanTickets    Permission = "tickets:scan"
+	PermissionManageUsers    Permission = "users:manage"
+	PermissionManageRefunds  Permission = "refunds:manage"
+	PermissionViewAudit      Permission = "audit:view"
+	PermissionSellTickets    Permission = "tickets:sell"
+	PermissionViewShifts     Permission = "shifts:view" // отчеты о сменах всех кассиров
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleCustomer: {},
+	RoleCashier:  {PermissionManageRefunds, PermissionSellTickets},
+	RoleUsher:    {PermissionScanTickets},
+	RoleManager:  {PermissionManageCatalog, PermissionManageSchedule, PermissionManageHalls, PermissionManagePromos, PermissionScanTickets, PermissionManageRefunds, PermissionSellTickets, PermissionViewShifts},
+	RoleAdmin:    {PermissionManageCatalog, PermissionManageSchedule, PermissionManageHalls, PermissionManagePromos, PermissionScanTickets, PermissionManageUsers, PermissionManageRefunds, PermissionViewAudit, PermissionSellTickets, PermissionViewShifts},
+}
+
+// IsValidRole сообщает, существует ли роль
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can сообщает, есть ли у пользователя право
+func (u *User) Can(permission Permission) bool {
+	return roleCan(u.Role, permission)
+}
+
+// Can сообщает, есть ли право у того, кто выполняет изменение
+func (a Actor) Can(permission Permission) bool {
+	return a.UserID != nil && roleCan(a.Role, permission)
+}
+
+func roleCan(role string, permission Permission) bool {
+	if role == "" {
+		role = RoleCustomer
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatEvent - изменение статуса места показа. События записывает триггер на
+// performance_seats; при фиксации транзакции событие получает номер Seq и
+// рассылается через NOTIFY всем экземплярам сервера.
+type SeatEvent struct {
+	ID                int64     `gorm:"primaryKey" json:"id"`
+	Seq               int64     `json:"seq"` // номер в порядке фиксации, по нему клиенты читают журнал
+	PerformanceID     uuid.UUID `gorm:"not null" json:"performance_id"`
+	PerformanceSeatID uuid.UUID `gorm:"not null" json:"performance_seat_id"`
+	SeatID            uuid.UUID `gorm:"not null" json:"seat_id"`
+	Status            string    `gorm:"not null" json:"status"` // новый статус места
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (*SeatEvent) TableName() string {
+	return "seat_events"
+}
+
+// Kind возвращает тип события: held, reserved, released или sold
+func (e *SeatEvent) Kind() string {
+	if e.Status == "available" {
+		return "released"
+	}
+	return e.Status
+}
+
+func (e *SeatEvent) Response() response.SeatEvent {
+	return response.SeatEvent{
+		ID:                e.Seq,
+		PerformanceSeatID: e.PerformanceSeatID,
+		SeatID:            e.SeatID,
+		Status:            e.Status,
+		At:                e.CreatedAt,
+	}
+}
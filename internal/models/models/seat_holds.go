@@ -0,0 +1,41 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatHold - временное удержание мест, пока покупатель заполняет оформление.
+// Удержание принадлежит анонимной сессии: знает его только владелец токена,
+// в базе хранится хеш токена. Истекшее удержание не мешает занять места,
+// даже если фоновая очистка еще не успела его снять.
+type SeatHold struct {
+	ID            uuid.UUID  `gorm:"primaryKey"`
+	PerformanceID uuid.UUID  `gorm:"not null;index"`
+	TokenHash     string     `gorm:"not null;uniqueIndex"`
+	Session       string     `gorm:"not null;default:''"` // анонимная сессия (IP клиента), создавшая удержание
+	BookingID     *uuid.UUID // бронирование, в которое удержание превращается в текущей транзакции
+	ExpiresAt     time.Time  `gorm:"not null;index"`
+	CreatedAt     time.Time
+
+	Seats []PerformanceSeat `gorm:"foreignKey:HoldID"`
+}
+
+func (*SeatHold) TableName() string {
+	return "seat_holds"
+}
+
+func (h *SeatHold) Response() response.SeatHold {
+	seatIDs := make([]uuid.UUID, len(h.Seats))
+	for i, seat := range h.Seats {
+		seatIDs[i] = seat.ID
+	}
+	return response.SeatHold{
+		ID:            h.ID,
+		PerformanceID: h.PerformanceID,
+		SeatIDs:       seatIDs,
+		ExpiresAt:     h.ExpiresAt,
+	}
+}
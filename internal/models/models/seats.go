@@ -4,15 +4,21 @@ import (
 	response "theater-ticket-system/internal/models/responses"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Seat struct {
 	ID     uuid.UUID `gorm:"primaryKey"`
-	HallID uuid.UUID `gorm:"not null;index"`
+	HallID uuid.UUID `gorm:"not null;index;uniqueIndex:idx_seats_place,where:deleted_at IS NULL"`
 
-	Row      int    `gorm:"not null"`
-	Number   int    `gorm:"not null"`
-	Category string // parterre, balcony, box
+	Section        string         `gorm:"not null;default:'';uniqueIndex:idx_seats_place"`
+	Row            int            `gorm:"not null;uniqueIndex:idx_seats_place"`
+	Number         int            `gorm:"not null;uniqueIndex:idx_seats_place"`
+	Position       int            // место в ряду с учетом проходов, для отрисовки схемы
+	Category       string         // parterre, balcony, box
+	Accessible     bool           `gorm:"default:false"`
+	RestrictedView bool           `gorm:"default:false"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
 
 	Hall             Hall              `gorm:"foreignKey:HallID" json:"hall,omitempty"`
 	PerformanceSeats []PerformanceSeat `gorm:"foreignKey:SeatID" json:"performance_seats,omitempty"`
@@ -24,10 +30,14 @@ func (*Seat) TableName() string {
 
 func (s *Seat) Response() response.Seat {
 	return response.Seat{
-		ID:       s.ID,
-		HallID:   s.HallID,
-		Row:      s.Row,
-		Number:   s.Number,
-		Category: s.Category,
+		ID:             s.ID,
+		HallID:         s.HallID,
+		Section:        s.Section,
+		Row:            s.Row,
+		Number:         s.Number,
+		Position:       s.Position,
+		Category:       s.Category,
+		Accessible:     s.Accessible,
+		RestrictedView: s.RestrictedView,
 	}
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session - сессия пользователя, к которой привязан refresh-токен
+type Session struct {
+	ID     uuid.UUID `gorm:"primaryKey"`
+	UserID uuid.UUID `gorm:"not null;index"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+func (*Session) TableName() string {
+	return "sessions"
+}
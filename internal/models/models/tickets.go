@@ -0,0 +1,75 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ticket - билет на одно место подтвержденного бронирования
+type Ticket struct {
+	ID                uuid.UUID `gorm:"primaryKey"`
+	BookingID         uuid.UUID `gorm:"not null;index"`
+	PerformanceID     uuid.UUID `gorm:"not null;index"`
+	PerformanceSeatID uuid.UUID `gorm:"not null;index"`
+
+	Nonce        string     `gorm:"not null"`        // случайная часть подписанного кода
+	Status       string     `gorm:"default:'valid'"` // valid, voided
+	AdmittedAt   *time.Time // время прохода зрителя в зал
+	AdmittedGate string
+	CreatedAt    time.Time
+
+	Performance Performance `gorm:"foreignKey:PerformanceID"`
+}
+
+func (*Ticket) TableName() string {
+	return "tickets"
+}
+
+const (
+	ScanAdmitted  = "admitted"
+	ScanDuplicate = "duplicate"
+	ScanInvalid   = "invalid"
+	ScanRejected  = "rejected"
+)
+
+// TicketScan - попытка пройти по билету, записывается независимо от результата
+type TicketScan struct {
+	ID            uuid.UUID  `gorm:"primaryKey"`
+	TicketID      *uuid.UUID `gorm:"index"` // пусто, если код не удалось распознать
+	PerformanceID uuid.UUID  `gorm:"not null;index"`
+
+	Gate      string `gorm:"not null"`
+	DeviceID  string `gorm:"not null"`
+	Result    string `gorm:"not null"` // admitted, duplicate, invalid, rejected
+	Reason    string
+	Offline   bool      `gorm:"not null;default:false"` // загружено пакетом после потери связи
+	ScannedAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+
+	Ticket *Ticket `gorm:"foreignKey:TicketID"`
+}
+
+func (*TicketScan) TableName() string {
+	return "ticket_scans"
+}
+
+func (s *TicketScan) Response() response.TicketScan {
+	resp := response.TicketScan{
+		ID:            s.ID,
+		TicketID:      s.TicketID,
+		PerformanceID: s.PerformanceID,
+		Gate:          s.Gate,
+		DeviceID:      s.DeviceID,
+		Result:        s.Result,
+		Reason:        s.Reason,
+		Offline:       s.Offline,
+		ScannedAt:     s.ScannedAt,
+	}
+	if s.Ticket != nil {
+		resp.AdmittedAt = s.Ticket.AdmittedAt
+		resp.AdmittedGate = s.Ticket.AdmittedGate
+	}
+	return resp
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	response "theater-ticket-system/internal/models/responses"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ type User struct {
 	Email        string `gorm:"uniqueIndex;not null"`
 	Name         string `gorm:"not null"`
 	PasswordHash string `gorm:"not null"`
+	Role         string `gorm:"not null;default:'customer'"` // customer, cashier, manager, admin
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
@@ -23,3 +25,12 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+func (u *User) Response() response.User {
+	return response.User{
+		ID:    u.ID,
+		Email: u.Email,
+		Name:  u.Name,
+		Role:  u.Role,
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaitlistEntry - заявка покупателя на места распроданного показа. Когда места
+// освобождаются, заявке предлагается бронирование с ограниченным сроком оплаты.
+type WaitlistEntry struct {
+	ID            uuid.UUID `gorm:"primaryKey"`
+	PerformanceID uuid.UUID `gorm:"not null;index"`
+	UserID        uuid.UUID `gorm:"not null;index"`
+
+	Seats     int        `gorm:"not null"`
+	Category  string     `gorm:"not null;default:''"`        // пусто - любая категория мест
+	Status    string     `gorm:"not null;default:'waiting'"` // waiting, offered, expired, cancelled
+	BookingID *uuid.UUID `gorm:"index"`                      // бронирование, созданное по предложению
+	OfferedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Position int `gorm:"-"` // место в очереди, пока заявка ожидает
+
+	User    User     `gorm:"foreignKey:UserID"`
+	Booking *Booking `gorm:"foreignKey:BookingID"`
+}
+
+func (*WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
+func (e *WaitlistEntry) Response() response.WaitlistEntry {
+	resp := response.WaitlistEntry{
+		ID:            e.ID,
+		PerformanceID: e.PerformanceID,
+		Seats:         e.Seats,
+		Category:      e.Category,
+		Status:        e.Status,
+		Position:      e.Position,
+		BookingID:     e.BookingID,
+		OfferedAt:     e.OfferedAt,
+		CreatedAt:     e.CreatedAt,
+	}
+	if e.Booking != nil {
+		resp.OfferExpiresAt = &e.Booking.ExpiresAt
+	}
+	return resp
+}
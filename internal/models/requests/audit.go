@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+// AuditQuery - фильтры и курсор выборки журнала аудита
+type AuditQuery struct {
+	ActorID    string     `form:"actor_id" binding:"omitempty,uuid"`
+	Action     string     `form:"action"`
+	EntityType string     `form:"entity_type" binding:"omitempty,oneof=play performance hall booking waitlist_entry"`
+	EntityID   string     `form:"entity_id" binding:"omitempty,uuid"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor     string     `form:"cursor"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=200"`
+}
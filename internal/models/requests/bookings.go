@@ -16,7 +16,7 @@ type CreateBooking struct {
 
 func (b *CreateBooking) Model(userID uuid.UUID) *model.Booking {
 	return &model.Booking{
-		UserID:        userID,
+		UserID:        &userID,
 		PerformanceID: b.PerformanceID,
 		Status:        "pending",
 		ExpiresAt:     time.Now().Add(15 * time.Minute),
@@ -0,0 +1,26 @@
+package request
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BoxOfficeSale - продажа мест кассиром. Без email продажа оформляется без покупателя.
+type BoxOfficeSale struct {
+	PerformanceID uuid.UUID   `json:"performance_id" binding:"required"`
+	SeatIDs       []uuid.UUID `json:"seat_ids" binding:"required,min=1"`
+	Email         string      `json:"email" binding:"omitempty,email"`
+	Name          string      `json:"name"`
+	PromoCode     string      `json:"promo_code"`
+	Method        string      `json:"method" binding:"required,oneof=cash card"`
+	Tendered      int         `json:"tendered" binding:"min=0"` // полученные наличные, только для cash
+}
+
+// ShiftReportQuery - параметры сверки смены кассира
+type ShiftReportQuery struct {
+	CashierID   string     `form:"cashier_id" binding:"omitempty,uuid"` // по умолчанию - текущий пользователь
+	From        *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To          *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	CountedCash *int       `form:"counted_cash" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Scan struct {
+	Code          string    `json:"code" binding:"required"`
+	PerformanceID uuid.UUID `json:"performance_id" binding:"required"`
+	Gate          string    `json:"gate" binding:"required"`
+	DeviceID      string    `json:"device_id" binding:"required"`
+}
+
+// ScanBatch - сканирования, накопленные устройством без связи
+type ScanBatch struct {
+	PerformanceID uuid.UUID     `json:"performance_id" binding:"required"`
+	Gate          string        `json:"gate" binding:"required"`
+	DeviceID      string        `json:"device_id" binding:"required"`
+	Scans         []OfflineScan `json:"scans" binding:"required,min=1,max=1000,dive"`
+}
+
+type OfflineScan struct {
+	Code      string    `json:"code" binding:"required"`
+	ScannedAt time.Time `json:"scanned_at" binding:"required"`
+}
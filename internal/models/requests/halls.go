@@ -0,0 +1,95 @@
+package request
+
+import (
+	"fmt"
+	model "theater-ticket-system/internal/models/models"
+)
+
+type Hall struct {
+	Name   string      `json:"name" binding:"required"`
+	Layout *HallLayout `json:"layout" binding:"required"`
+}
+
+type UpdateHall struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// HallLayout - схема зала: секции, в каждой ряды с количеством мест
+type HallLayout struct {
+	Sections []HallSection `json:"sections" binding:"required,min=1,dive"`
+}
+
+type HallSection struct {
+	Name     string    `json:"name" binding:"required"`
+	Category string    `json:"category" binding:"required,oneof=parterre balcony box"`
+	Rows     []HallRow `json:"rows" binding:"required,min=1,dive"`
+}
+
+// HallRow - ряд секции. Gaps - позиции в ряду, оставленные пустыми (проходы),
+// Accessible и RestrictedView - номера мест с соответствующими отметками.
+type HallRow struct {
+	Number         int   `json:"number" binding:"required,min=1"`
+	Seats          int   `json:"seats" binding:"required,min=1"`
+	Gaps           []int `json:"gaps"`
+	Accessible     []int `json:"accessible"`
+	RestrictedView []int `json:"restricted_view"`
+}
+
+// Seats раскладывает схему в список мест зала. Места в ряду нумеруются подряд
+// слева направо, пропуская позиции-проходы.
+func (l *HallLayout) Seats() ([]model.Seat, error) {
+	var seats []model.Seat
+	for _, section := range l.Sections {
+		for _, row := range section.Rows {
+			width := row.Seats + len(row.Gaps)
+			gaps := make(map[int]bool, len(row.Gaps))
+			for _, gap := range row.Gaps {
+				if gap < 1 || gap > width {
+					return nil, fmt.Errorf("section %q row %d: gap %d is outside the row", section.Name, row.Number, gap)
+				}
+				gaps[gap] = true
+			}
+			if len(gaps) != len(row.Gaps) {
+				return nil, fmt.Errorf("section %q row %d: duplicate gaps", section.Name, row.Number)
+			}
+
+			accessible, err := seatNumberSet(row.Accessible, row.Seats)
+			if err != nil {
+				return nil, fmt.Errorf("section %q row %d: accessible %w", section.Name, row.Number, err)
+			}
+			restricted, err := seatNumberSet(row.RestrictedView, row.Seats)
+			if err != nil {
+				return nil, fmt.Errorf("section %q row %d: restricted_view %w", section.Name, row.Number, err)
+			}
+
+			number := 0
+			for position := 1; position <= width; position++ {
+				if gaps[position] {
+					continue
+				}
+				number++
+				seats = append(seats, model.Seat{
+					Section:        section.Name,
+					Row:            row.Number,
+					Number:         number,
+					Position:       position,
+					Category:       section.Category,
+					Accessible:     accessible[number],
+					RestrictedView: restricted[number],
+				})
+			}
+		}
+	}
+	return seats, nil
+}
+
+func seatNumberSet(numbers []int, seats int) (map[int]bool, error) {
+	set := make(map[int]bool, len(numbers))
+	for _, n := range numbers {
+		if n < 1 || n > seats {
+			return nil, fmt.Errorf("seat %d does not exist", n)
+		}
+		set[n] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,7 @@
+package request
+
+import "github.com/google/uuid"
+
+type CreateHold struct {
+	SeatIDs []uuid.UUID `json:"seat_ids" binding:"required,min=1"`
+}
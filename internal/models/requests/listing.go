@@ -0,0 +1,8 @@
+package request
+
+// Page - сортировка и курсор страницы списка
+type Page struct {
+	Sort   string `form:"sort"`   // поле сортировки, с "-" - по убыванию
+	Cursor string `form:"cursor"` // next_cursor предыдущей страницы
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
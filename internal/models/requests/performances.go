@@ -0,0 +1,45 @@
+package request
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Performance struct {
+	PlayID uuid.UUID `json:"play_id" binding:"required"`
+	HallID uuid.UUID `json:"hall_id" binding:"required"`
+	Date   time.Time `json:"date" binding:"required"`
+
+	PricePlanID *uuid.UUID `json:"price_plan_id"`
+	Premiere    bool       `json:"premiere"`
+	OnSale      bool       `json:"on_sale"` // открыть продажу сразу
+}
+
+type UpdatePerformance struct {
+	PlayID uuid.UUID `json:"play_id" binding:"required"`
+	Date   time.Time `json:"date" binding:"required"`
+}
+
+// PerformanceStatus - новый статус показа
+type PerformanceStatus struct {
+	Status string `json:"status" binding:"required,oneof=scheduled on_sale sales_closed"`
+}
+
+// PostponePerformance - куда перенести показ: на существующий показ того же спектакля
+// или на новую дату. Нужно указать ровно одно из полей.
+type PostponePerformance struct {
+	PerformanceID *uuid.UUID `json:"performance_id" binding:"required_without=Date,excluded_with=Date"`
+	Date          *time.Time `json:"date" binding:"required_without=PerformanceID"`
+}
+
+// ListPerformances - фильтры афиши
+type ListPerformances struct {
+	Page
+	PlayID    string     `form:"play_id" binding:"omitempty,uuid"`
+	HallID    string     `form:"hall_id" binding:"omitempty,uuid"`
+	Status    string     `form:"status" binding:"omitempty,oneof=scheduled on_sale sales_closed completed cancelled postponed"`
+	DateFrom  *time.Time `form:"date_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	DateTo    *time.Time `form:"date_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Available *bool      `form:"available"`
+}
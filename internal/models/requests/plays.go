@@ -21,3 +21,18 @@ func (p *Play) Model() *model.Play {
 		Genre:       p.Genre,
 	}
 }
+
+// ListPlays - фильтры каталога спектаклей
+type ListPlays struct {
+	Page
+	Genre     string `form:"genre"`
+	Author    string `form:"author"`
+	Query     string `form:"q"`
+	Available *bool  `form:"available"`
+}
+
+// Search - поиск по репертуару
+type Search struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
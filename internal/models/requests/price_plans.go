@@ -0,0 +1,55 @@
+package request
+
+import model "theater-ticket-system/internal/models/models"
+
+type PricePlan struct {
+	Name              string       `json:"name" binding:"required"`
+	DefaultPrice      int          `json:"default_price" binding:"required,min=1"`
+	WeekendSurcharge  int          `json:"weekend_surcharge" binding:"min=0"`
+	PremiereSurcharge int          `json:"premiere_surcharge" binding:"min=0"`
+	Tiers             []PriceTier  `json:"tiers" binding:"dive"`
+	DemandSteps       []DemandStep `json:"demand_steps" binding:"dive"`
+}
+
+// PriceTier - тариф плана. Тарифы проверяются по порядку, первый подходящий побеждает.
+type PriceTier struct {
+	Category string `json:"category" binding:"omitempty,oneof=parterre balcony box"`
+	RowFrom  int    `json:"row_from" binding:"min=0"`
+	RowTo    int    `json:"row_to" binding:"min=0"`
+	Price    int    `json:"price" binding:"required,min=1"`
+}
+
+// DemandStep - надбавка в процентах, начиная с заданной заполненности зала в процентах
+type DemandStep struct {
+	Occupancy int `json:"occupancy" binding:"required,min=1,max=100"`
+	Surcharge int `json:"surcharge" binding:"required,min=1"`
+}
+
+func (p *PricePlan) Model() *model.PricePlan {
+	plan := &model.PricePlan{
+		Name:              p.Name,
+		DefaultPrice:      p.DefaultPrice,
+		WeekendSurcharge:  p.WeekendSurcharge,
+		PremiereSurcharge: p.PremiereSurcharge,
+	}
+	for i, t := range p.Tiers {
+		rowFrom := t.RowFrom
+		if rowFrom == 0 {
+			rowFrom = 1
+		}
+		plan.Tiers = append(plan.Tiers, model.PriceTier{
+			Category: t.Category,
+			RowFrom:  rowFrom,
+			RowTo:    t.RowTo,
+			Price:    t.Price,
+			Position: i,
+		})
+	}
+	for _, s := range p.DemandSteps {
+		plan.DemandSteps = append(plan.DemandSteps, model.DemandStep{
+			Occupancy: s.Occupancy,
+			Surcharge: s.Surcharge,
+		})
+	}
+	return plan
+}
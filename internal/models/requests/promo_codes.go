@@ -0,0 +1,37 @@
+package request
+
+import (
+	model "theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoCode struct {
+	Code           string     `json:"code" binding:"required"`
+	Kind           string     `json:"kind" binding:"required,oneof=percent fixed"`
+	Value          int        `json:"value" binding:"required,min=1"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	MaxUses        int        `json:"max_uses" binding:"min=0"`
+	MaxUsesPerUser int        `json:"max_uses_per_user" binding:"min=0"`
+	PlayID         *uuid.UUID `json:"play_id"`
+	PerformanceID  *uuid.UUID `json:"performance_id"`
+	Category       string     `json:"category" binding:"omitempty,oneof=parterre balcony box"`
+}
+
+func (p *PromoCode) Model() *model.PromoCode {
+	return &model.PromoCode{
+		Code:           p.Code,
+		Kind:           p.Kind,
+		Value:          p.Value,
+		ValidFrom:      p.ValidFrom,
+		ValidUntil:     p.ValidUntil,
+		MaxUses:        p.MaxUses,
+		MaxUsesPerUser: p.MaxUsesPerUser,
+		PlayID:         p.PlayID,
+		PerformanceID:  p.PerformanceID,
+		Category:       p.Category,
+		Active:         true,
+	}
+}
@@ -0,0 +1,14 @@
+package request
+
+import "github.com/google/uuid"
+
+type CancelSeats struct {
+	SeatIDs []uuid.UUID `json:"seat_ids" binding:"required,min=1"`
+}
+
+// OverrideRefund - отмена мест сотрудником с процентом возврата вне политики
+type OverrideRefund struct {
+	SeatIDs []uuid.UUID `json:"seat_ids"` // пусто - все места бронирования
+	Percent *int        `json:"percent" binding:"required,min=0,max=100"`
+	Reason  string      `json:"reason" binding:"required"`
+}
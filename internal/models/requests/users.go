@@ -0,0 +1,7 @@
+package request
+
+// SetUserRole - назначение роли пользователю администратором
+type SetUserRole struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=customer cashier usher manager admin"`
+}
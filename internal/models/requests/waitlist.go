@@ -0,0 +1,6 @@
+package request
+
+type JoinWaitlist struct {
+	Seats    int    `json:"seats" binding:"required,min=1,max=10"`
+	Category string `json:"category" binding:"omitempty,oneof=parterre balcony box"` // пусто - любая категория
+}
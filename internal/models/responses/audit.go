@@ -0,0 +1,28 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry - запись журнала аудита
+type AuditEntry struct {
+	ID         int64           `json:"id" binding:"required"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	ActorEmail string          `json:"actor_email,omitempty"`
+	ActorIP    string          `json:"actor_ip,omitempty"`
+	Action     string          `json:"action" binding:"required"`
+	EntityType string          `json:"entity_type" binding:"required"` // play, performance, hall, booking
+	EntityID   uuid.UUID       `json:"entity_id" binding:"required"`
+	Changes    json.RawMessage `json:"changes" swaggertype:"object"` // {"поле": {"before": ..., "after": ...}}
+	CreatedAt  time.Time       `json:"created_at" binding:"required"`
+}
+
+// AuditPage - страница журнала аудита. Следующая страница запрашивается
+// с cursor=next_cursor; на последней странице next_cursor пуст.
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries" binding:"required"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
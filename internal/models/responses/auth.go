@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+type Tokens struct {
+	AccessToken      string    `json:"access_token" binding:"required"`
+	RefreshToken     string    `json:"refresh_token" binding:"required"`
+	TokenType        string    `json:"token_type" binding:"required"`
+	AccessExpiresAt  time.Time `json:"access_expires_at" binding:"required"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at" binding:"required"`
+}
@@ -8,11 +8,14 @@ import (
 
 type Booking struct {
 	ID            uuid.UUID         `json:"id" binding:"required"`
-	UserID        uuid.UUID         `json:"user_id" binding:"required"`
+	UserID        *uuid.UUID        `json:"user_id,omitempty"` // пусто для продажи в кассе без покупателя
 	PerformanceID uuid.UUID         `json:"performance_id" binding:"required"`
+	Subtotal      int               `json:"subtotal"`
+	Discount      int               `json:"discount"`
 	TotalPrice    int               `json:"total_price" binding:"required"`
-	Status        string            `json:"status" binding:"required"` // pending, confirmed, cancelled
+	Status        string            `json:"status" binding:"required"` // pending, confirmed, cancelled, expired
 	SeatsCount    int               `json:"seats_count" binding:"required"`
+	Reminders     bool              `json:"reminders"`
 	ExpiresAt     time.Time         `json:"expires_at" binding:"required"`
 	CreatedAt     time.Time         `json:"created_at" binding:"required"`
 	UpdatedAt     time.Time         `json:"updated_at" binding:"required"`
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BoxOfficePayment - оплата в кассе
+type BoxOfficePayment struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	Method    string    `json:"method" binding:"required"` // cash, card
+	Amount    int       `json:"amount" binding:"required"`
+	Currency  string    `json:"currency" binding:"required"`
+	Tendered  *int      `json:"tendered,omitempty"`
+	Change    *int      `json:"change,omitempty"`
+	CashierID uuid.UUID `json:"cashier_id" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+}
+
+// BoxOfficeSale - проданное в кассе бронирование и его оплата
+type BoxOfficeSale struct {
+	Booking Booking          `json:"booking" binding:"required"`
+	Payment BoxOfficePayment `json:"payment" binding:"required"`
+}
+
+// MethodTotal - число и сумма операций одним способом оплаты
+type MethodTotal struct {
+	Method string `json:"method" binding:"required"`
+	Count  int    `json:"count" binding:"required"`
+	Amount int    `json:"amount" binding:"required"`
+}
+
+// ShiftReport - сверка кассы кассира за смену
+type ShiftReport struct {
+	CashierID    uuid.UUID     `json:"cashier_id" binding:"required"`
+	From         time.Time     `json:"from" binding:"required"`
+	To           time.Time     `json:"to" binding:"required"`
+	Sales        []MethodTotal `json:"sales" binding:"required"`
+	Refunds      []MethodTotal `json:"refunds" binding:"required"`
+	Revenue      int           `json:"revenue" binding:"required"`       // продажи за вычетом возвратов
+	ExpectedCash int           `json:"expected_cash" binding:"required"` // наличные, которые должны быть в кассе
+	CountedCash  *int          `json:"counted_cash,omitempty"`
+	Discrepancy  *int          `json:"discrepancy,omitempty"` // counted_cash - expected_cash
+}
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TicketScan struct {
+	ID            uuid.UUID  `json:"id" binding:"required"`
+	TicketID      *uuid.UUID `json:"ticket_id,omitempty"`
+	PerformanceID uuid.UUID  `json:"performance_id" binding:"required"`
+	Gate          string     `json:"gate" binding:"required"`
+	DeviceID      string     `json:"device_id" binding:"required"`
+	Result        string     `json:"result" binding:"required" enums:"admitted,duplicate,invalid,rejected"`
+	Reason        string     `json:"reason,omitempty"`
+	Offline       bool       `json:"offline"`
+	ScannedAt     time.Time  `json:"scanned_at" binding:"required"`
+	AdmittedAt    *time.Time `json:"admitted_at,omitempty"` // время первого прохода по билету
+	AdmittedGate  string     `json:"admitted_gate,omitempty"`
+}
+
+type Attendance struct {
+	PerformanceID uuid.UUID        `json:"performance_id" binding:"required"`
+	Tickets       int64            `json:"tickets"`
+	Admitted      int64            `json:"admitted"`
+	ByGate        map[string]int64 `json:"by_gate"`
+}
+
+type BatchScanResult struct {
+	Code  string      `json:"code" binding:"required"`
+	Error string      `json:"error,omitempty"`
+	Scan  *TicketScan `json:"scan,omitempty"` // пусто, если сканирование не удалось записать
+}
@@ -0,0 +1,22 @@
+package response
+
+import "github.com/google/uuid"
+
+// DisruptionReport - итог отмены или переноса показа
+type DisruptionReport struct {
+	Performance Performance         `json:"performance" binding:"required"`
+	Target      *Performance        `json:"target,omitempty"` // показ, на который перенесены бронирования
+	Moved       []uuid.UUID         `json:"moved,omitempty"`
+	Cancelled   []uuid.UUID         `json:"cancelled,omitempty"`
+	Refunded    int                 `json:"refunded"` // сумма возвратов
+	Unresolved  []UnresolvedBooking `json:"unresolved" binding:"required"`
+}
+
+// UnresolvedBooking - бронирование, которое сотрудникам нужно обработать вручную
+type UnresolvedBooking struct {
+	BookingID uuid.UUID `json:"booking_id" binding:"required"`
+	Status    string    `json:"status,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Seats     int       `json:"seats,omitempty"`
+	Reason    string    `json:"reason" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Hall struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	Name      string    `json:"name" binding:"required"`
+	Capacity  int       `json:"capacity" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+	UpdatedAt time.Time `json:"updated_at" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeatHold struct {
+	ID            uuid.UUID   `json:"id" binding:"required"`
+	PerformanceID uuid.UUID   `json:"performance_id" binding:"required"`
+	SeatIDs       []uuid.UUID `json:"seat_ids" binding:"required"`
+	Token         string      `json:"token,omitempty"` // выдается один раз при создании удержания
+	ExpiresAt     time.Time   `json:"expires_at" binding:"required"`
+}
@@ -9,11 +9,22 @@ import (
 type Performance struct {
 	ID uuid.UUID `json:"id" binding:"required"`
 
-	Date      time.Time `json:"date" binding:"required"`
-	Status    string    `json:"status" enums:"scheduled,completed,cancelled"`
-	CreatedAt time.Time `json:"created_at" binding:"required"`
-	UpdatedAt time.Time `json:"updated_at" binding:"required"`
+	Date        time.Time  `json:"date" binding:"required"`
+	Status      string     `json:"status" enums:"scheduled,on_sale,sales_closed,completed,cancelled,postponed"`
+	Premiere    bool       `json:"premiere"`
+	PricePlanID *uuid.UUID `json:"price_plan_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" binding:"required"`
+	UpdatedAt   time.Time  `json:"updated_at" binding:"required"`
+
+	PostponedToID *uuid.UUID `json:"postponed_to_id,omitempty"` // показ, на который перенесен отложенный показ
 
 	Play *Play `json:"play" binding:"omitempty"`
 	// Hall Hall `json:"hall" binding:"required"`
 }
+
+// PerformancePage - страница афиши
+type PerformancePage struct {
+	Items      []Performance `json:"items" binding:"required"`
+	NextCursor string        `json:"next_cursor,omitempty"` // пуст на последней странице
+	Total      int64         `json:"total" binding:"required"`
+}
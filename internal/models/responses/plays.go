@@ -20,3 +20,30 @@ type Play struct {
 
 	Performances []Performance `json:"performances" binding:"omitempty"`
 }
+
+// PlayPage - страница каталога спектаклей
+type PlayPage struct {
+	Items      []Play `json:"items" binding:"required"`
+	NextCursor string `json:"next_cursor,omitempty"` // пуст на последней странице
+	Total      int64  `json:"total" binding:"required"`
+}
+
+// PlayMatch - спектакль в результатах поиска вместе с предстоящими показами
+type PlayMatch struct {
+	Play       Play           `json:"play" binding:"required"`
+	Rank       float64        `json:"rank" binding:"required"`
+	Highlights PlayHighlights `json:"highlights" binding:"required"`
+}
+
+// PlayHighlights - фрагменты текста спектакля, совпадения выделены тегом <mark>
+type PlayHighlights struct {
+	Title       string `json:"title" binding:"required"`
+	Author      string `json:"author" binding:"required"`
+	Description string `json:"description" binding:"required"`
+}
+
+// SearchResults - результаты поиска по репертуару, от наиболее подходящих
+type SearchResults struct {
+	Query   string      `json:"query" binding:"required"`
+	Results []PlayMatch `json:"results" binding:"required"`
+}
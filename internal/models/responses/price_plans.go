@@ -0,0 +1,25 @@
+package response
+
+import "github.com/google/uuid"
+
+type PricePlan struct {
+	ID                uuid.UUID    `json:"id" binding:"required"`
+	Name              string       `json:"name" binding:"required"`
+	DefaultPrice      int          `json:"default_price" binding:"required"`
+	WeekendSurcharge  int          `json:"weekend_surcharge"`
+	PremiereSurcharge int          `json:"premiere_surcharge"`
+	Tiers             []PriceTier  `json:"tiers"`
+	DemandSteps       []DemandStep `json:"demand_steps"`
+}
+
+type PriceTier struct {
+	Category string `json:"category,omitempty"`
+	RowFrom  int    `json:"row_from"`
+	RowTo    int    `json:"row_to,omitempty"`
+	Price    int    `json:"price"`
+}
+
+type DemandStep struct {
+	Occupancy int `json:"occupancy"`
+	Surcharge int `json:"surcharge"`
+}
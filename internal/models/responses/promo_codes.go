@@ -0,0 +1,23 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoCode struct {
+	ID             uuid.UUID  `json:"id" binding:"required"`
+	Code           string     `json:"code" binding:"required"`
+	Kind           string     `json:"kind" binding:"required" enums:"percent,fixed"`
+	Value          int        `json:"value" binding:"required"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	UsedCount      int        `json:"used_count"`
+	PlayID         *uuid.UUID `json:"play_id,omitempty"`
+	PerformanceID  *uuid.UUID `json:"performance_id,omitempty"`
+	Category       string     `json:"category,omitempty"`
+	Active         bool       `json:"active"`
+}
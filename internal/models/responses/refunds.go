@@ -0,0 +1,26 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Refund struct {
+	ID            uuid.UUID   `json:"id" binding:"required"`
+	BookingID     uuid.UUID   `json:"booking_id" binding:"required"`
+	SeatIDs       []uuid.UUID `json:"seat_ids" binding:"required"`
+	SeatsValue    int         `json:"seats_value" binding:"required"` // оплачено за отмененные места
+	Amount        int         `json:"amount" binding:"required"`
+	Percent       int         `json:"percent" binding:"required"`
+	PolicyPercent int         `json:"policy_percent" binding:"required"`
+	Overridden    bool        `json:"overridden"` // процент изменен сотрудником
+	Reason        string      `json:"reason,omitempty"`
+	CreatedAt     time.Time   `json:"created_at" binding:"required"`
+}
+
+// SeatsCancellation - бронирование после отмены мест и возврат, если бронь была оплачена
+type SeatsCancellation struct {
+	Booking Booking `json:"booking" binding:"required"`
+	Refund  *Refund `json:"refund,omitempty"`
+}
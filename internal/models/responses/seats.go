@@ -1,15 +1,21 @@
 package response
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Seat struct {
-	ID       uuid.UUID `json:"id" binding:"required"`
-	HallID   uuid.UUID `json:"hall_id" binding:"required"`
-	Row      int       `json:"row" binding:"required"`
-	Number   int       `json:"number" binding:"required"`
-	Category string    `json:"category" binding:"required"` // parterre, balcony, box
+	ID             uuid.UUID `json:"id" binding:"required"`
+	HallID         uuid.UUID `json:"hall_id" binding:"required"`
+	Section        string    `json:"section"`
+	Row            int       `json:"row" binding:"required"`
+	Number         int       `json:"number" binding:"required"`
+	Position       int       `json:"position"`
+	Category       string    `json:"category" binding:"required"` // parterre, balcony, box
+	Accessible     bool      `json:"accessible"`
+	RestrictedView bool      `json:"restricted_view"`
 }
 
 type PerformanceSeat struct {
@@ -17,6 +23,23 @@ type PerformanceSeat struct {
 	PerformanceID uuid.UUID `json:"performance_id" binding:"required"`
 	SeatID        uuid.UUID `json:"seat_id" binding:"required"`
 	Price         int       `json:"price" binding:"required"`
+	ChargedPrice  *int      `json:"charged_price,omitempty"`
 	Status        string    `json:"status" binding:"required"` // available, reserved, sold
 	Seat          *Seat     `json:"seat,omitempty"`
 }
+
+// SeatEvent - изменение статуса места в потоке схемы зала
+type SeatEvent struct {
+	ID                int64     `json:"id" binding:"required"`
+	PerformanceSeatID uuid.UUID `json:"performance_seat_id" binding:"required"`
+	SeatID            uuid.UUID `json:"seat_id" binding:"required"`
+	Status            string    `json:"status" binding:"required"` // available, reserved, sold
+	At                time.Time `json:"at" binding:"required"`
+}
+
+// SeatState - статус места в снимке схемы зала
+type SeatState struct {
+	PerformanceSeatID uuid.UUID `json:"performance_seat_id" binding:"required"`
+	SeatID            uuid.UUID `json:"seat_id" binding:"required"`
+	Status            string    `json:"status" binding:"required"`
+}
@@ -0,0 +1,10 @@
+package response
+
+import "github.com/google/uuid"
+
+type User struct {
+	ID    uuid.UUID `json:"id" binding:"required"`
+	Email string    `json:"email" binding:"required"`
+	Name  string    `json:"name"`
+	Role  string    `json:"role" binding:"required"`
+}
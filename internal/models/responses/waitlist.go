@@ -0,0 +1,20 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistEntry struct {
+	ID             uuid.UUID  `json:"id" binding:"required"`
+	PerformanceID  uuid.UUID  `json:"performance_id" binding:"required"`
+	Seats          int        `json:"seats" binding:"required"`
+	Category       string     `json:"category,omitempty"`
+	Status         string     `json:"status" binding:"required" enums:"waiting,offered,expired,cancelled"`
+	Position       int        `json:"position,omitempty"`   // место в очереди, пока заявка ожидает
+	BookingID      *uuid.UUID `json:"booking_id,omitempty"` // бронирование, которое нужно оплатить
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" binding:"required"`
+}
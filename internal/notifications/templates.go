@@ -0,0 +1,155 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Шаблоны писем. Для каждого есть text-версия (templates/<name>.txt, содержит блок
+// "subject") и HTML-версия (templates/<name>.html, содержит блок "content").
+const (
+	TemplateVerificationCode     = "verification_code"
+	TemplateBookingCreated       = "booking_created"
+	TemplateBookingConfirmed     = "booking_confirmed"
+	TemplateBookingCancelled     = "booking_cancelled"
+	TemplatePerformanceCancelled = "performance_cancelled"
+	TemplatePerformancePostponed = "performance_postponed"
+	TemplateReminder             = "reminder"
+	TemplateWaitlistOffer        = "waitlist_offer"
+	TemplateRefundIssued         = "refund_issued"
+)
+
+var templateNames = []string{
+	TemplateVerificationCode,
+	TemplateBookingCreated,
+	TemplateBookingConfirmed,
+	TemplateBookingCancelled,
+	TemplatePerformanceCancelled,
+	TemplatePerformancePostponed,
+	TemplateReminder,
+	TemplateWaitlistOffer,
+	TemplateRefundIssued,
+}
+
+// Message - готовое к отправке письмо
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// VerificationCodeData - данные письма с кодом подтверждения
+type VerificationCodeData struct {
+	Code string
+	TTL  time.Duration
+}
+
+// BookingData - данные писем о бронировании и показе
+type BookingData struct {
+	Name      string
+	BookingID uuid.UUID
+	PlayTitle string
+	HallName  string
+	Date      time.Time
+	Seats     []SeatData
+	Total     int
+	ExpiresAt time.Time
+	Refund    int // сумма возврата за отмененные места
+
+	// Для писем о переносе показа: прежняя дата и удалось ли перенести бронирование
+	PreviousDate time.Time
+	Moved        bool
+}
+
+type SeatData struct {
+	Section string
+	Row     int
+	Number  int
+	Price   int
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates - реестр шаблонов писем
+type Templates struct {
+	templates map[string]emailTemplate
+}
+
+// NewTemplates разбирает все шаблоны. Даты выводятся в часовом поясе театра loc,
+// суммы - в валюте currency.
+func NewTemplates(loc *time.Location, currency string) (*Templates, error) {
+	funcs := map[string]interface{}{
+		"date": func(t time.Time) string {
+			return t.In(loc).Format("02.01.2006 15:04")
+		},
+		"price": func(amount int) string {
+			return fmt.Sprintf("%d %s", amount, currency)
+		},
+		"minutes": func(d time.Duration) int {
+			return int(d.Minutes())
+		},
+	}
+
+	registry := &Templates{templates: make(map[string]emailTemplate, len(templateNames))}
+	for _, name := range templateNames {
+		text, err := texttemplate.New(name+".txt").
+			Funcs(funcs).
+			ParseFS(templatesFS, "templates/"+name+".txt", "templates/partials.txt")
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("template %s: missing subject block", name)
+		}
+
+		html, err := htmltemplate.New("layout.html").
+			Funcs(funcs).
+			ParseFS(templatesFS, "templates/layout.html", "templates/"+name+".html", "templates/partials.html")
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+
+		registry.templates[name] = emailTemplate{text: text, html: html}
+	}
+	return registry, nil
+}
+
+// Render формирует письмо по шаблону name для получателя to
+func (t *Templates) Render(name, to string, data interface{}) (*Message, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	templates, err := NewTemplates(time.UTC, "BYN")
+	require.NoError(t, err)
+
+	booking := BookingData{
+		Name:      "Анна <script>",
+		BookingID: uuid.New(),
+		PlayTitle: "Вишневый сад",
+		HallName:  "Большой зал",
+		Date:      time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC),
+		Seats:     []SeatData{{Section: "parterre", Row: 3, Number: 12, Price: 3500}},
+		Total:     3500,
+		ExpiresAt: time.Date(2025, 5, 1, 12, 15, 0, 0, time.UTC),
+	}
+
+	t.Run("renders every template", func(t *testing.T) {
+		for _, name := range templateNames {
+			var data interface{} = booking
+			if name == TemplateVerificationCode {
+				data = VerificationCodeData{Code: "123456", TTL: 10 * time.Minute}
+			}
+
+			msg, err := templates.Render(name, "anna@example.com", data)
+
+			require.NoError(t, err, name)
+			assert.Equal(t, "anna@example.com", msg.To)
+			assert.NotEmpty(t, msg.Subject, name)
+			assert.NotEmpty(t, msg.Text, name)
+			assert.Contains(t, msg.HTML, "<html", name)
+		}
+	})
+
+	t.Run("booking created", func(t *testing.T) {
+		msg, err := templates.Render(TemplateBookingCreated, "anna@example.com", booking)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Бронирование на спектакль «Вишневый сад»", msg.Subject)
+		assert.Contains(t, msg.Text, "ряд 3, место 12 - 3500 BYN")
+		assert.Contains(t, msg.Text, "01.05.2025 12:15")
+		assert.Contains(t, msg.HTML, "Анна &lt;script&gt;")
+	})
+
+	t.Run("refund issued", func(t *testing.T) {
+		refund := booking
+		refund.Refund = 1750
+		refund.Total = 0
+
+		msg, err := templates.Render(TemplateRefundIssued, "anna@example.com", refund)
+
+		require.NoError(t, err)
+		assert.Contains(t, msg.Text, "ряд 3, место 12")
+		assert.Contains(t, msg.Text, "Сумма возврата: 1750 BYN")
+		assert.NotContains(t, msg.Text, "оставшихся мест")
+	})
+
+	t.Run("performance postponed", func(t *testing.T) {
+		postponed := booking
+		postponed.PreviousDate = time.Date(2025, 5, 3, 19, 0, 0, 0, time.UTC)
+		postponed.Moved = true
+
+		msg, err := templates.Render(TemplatePerformancePostponed, "anna@example.com", postponed)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Показ спектакля «Вишневый сад» перенесен", msg.Subject)
+		assert.Contains(t, msg.Text, "назначенный на 03.05.2025 19:00, перенесен на 10.05.2025 19:00")
+		assert.Contains(t, msg.Text, "ряд 3, место 12")
+
+		postponed.Moved = false
+		msg, err = templates.Render(TemplatePerformancePostponed, "anna@example.com", postponed)
+
+		require.NoError(t, err)
+		assert.Contains(t, msg.Text, "перенести ваше бронирование автоматически не удалось")
+		assert.NotContains(t, msg.Text, "ряд 3, место 12")
+	})
+
+	t.Run("verification code", func(t *testing.T) {
+		msg, err := templates.Render(TemplateVerificationCode, "anna@example.com",
+			VerificationCodeData{Code: "042017", TTL: 10 * time.Minute})
+
+		require.NoError(t, err)
+		assert.Contains(t, msg.Text, "Ваш код подтверждения: 042017")
+		assert.Contains(t, msg.Text, "10 минут")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := templates.Render("newsletter", "anna@example.com", nil)
+
+		assert.EqualError(t, err, `unknown email template "newsletter"`)
+	})
+}
@@ -0,0 +1,130 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DeclinedToken - платежный токен, который фейковый провайдер всегда отклоняет
+const DeclinedToken = "tok_declined"
+
+// Fake - платежный провайдер в памяти процесса для тестов и локальной разработки
+type Fake struct {
+	mu      sync.Mutex
+	secret  []byte
+	intents map[string]*Intent
+	refunds map[string][]Refund
+}
+
+func NewFake(webhookSecret string) *Fake {
+	return &Fake{
+		secret:  []byte(webhookSecret),
+		intents: map[string]*Intent{},
+		refunds: map[string][]Refund{},
+	}
+}
+
+func (p *Fake) Name() string {
+	return "fake"
+}
+
+func (p *Fake) CreateIntent(bookingID uuid.UUID, amount int, currency string) (*Intent, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent := &Intent{
+		ID:       "pi_" + uuid.NewString(),
+		Amount:   amount,
+		Currency: currency,
+		Status:   IntentRequiresPayment,
+	}
+	p.intents[intent.ID] = intent
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *Fake) Capture(intentID, paymentToken string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if paymentToken == "" || paymentToken == DeclinedToken {
+		return nil, ErrPaymentDeclined
+	}
+
+	intent.Status = IntentSucceeded
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *Fake) Refund(intentID string, amount int) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != IntentSucceeded {
+		return nil, errors.New("only captured payments can be refunded")
+	}
+
+	refunded := 0
+	for _, r := range p.refunds[intentID] {
+		refunded += r.Amount
+	}
+	if amount <= 0 || refunded+amount > intent.Amount {
+		return nil, errors.New("invalid refund amount")
+	}
+
+	refund := Refund{
+		ID:       "re_" + uuid.NewString(),
+		IntentID: intentID,
+		Amount:   amount,
+	}
+	p.refunds[intentID] = append(p.refunds[intentID], refund)
+	if refunded+amount == intent.Amount {
+		intent.Status = IntentRefunded
+	}
+
+	return &refund, nil
+}
+
+func (p *Fake) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// SignWebhook подписывает тело уведомления так же, как это сделал бы провайдер
+func (p *Fake) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(p.sign(payload))
+}
+
+func (p *Fake) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
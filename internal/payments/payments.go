@@ -0,0 +1,41 @@
+package payments
+
+import "errors"
+
+var (
+	ErrPaymentDeclined  = errors.New("payment declined")
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+const (
+	IntentRequiresPayment = "requires_payment"
+	IntentSucceeded       = "succeeded"
+	IntentRefunded        = "refunded"
+)
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+)
+
+// Intent - намерение оплатить бронирование
+type Intent struct {
+	ID       string
+	Amount   int
+	Currency string
+	Status   string
+}
+
+// Refund - возврат средств по намерению
+type Refund struct {
+	ID       string
+	IntentID string
+	Amount   int
+}
+
+// Event - уведомление платежной системы, пришедшее через webhook
+type Event struct {
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+}
@@ -0,0 +1,104 @@
+// Package ratelimit ограничивает частоту запросов счетчиком в фиксированном окне.
+// Счетчики хранятся в памяти процесса или в Postgres, если экземпляров сервера несколько.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Rule - не более Limit запросов с одного ключа за Window
+type Rule struct {
+	Name   string // отделяет счетчики разных правил с одинаковыми ключами
+	Limit  int
+	Window time.Duration
+}
+
+// Result - решение по одному запросу
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time // когда начнется следующее окно
+}
+
+// RetryAfter возвращает, сколько осталось ждать до следующего окна
+func (r Result) RetryAfter(now time.Time) time.Duration {
+	if r.Allowed || !r.ResetAt.After(now) {
+		return 0
+	}
+	return r.ResetAt.Sub(now)
+}
+
+// Store учитывает запрос по ключу и решает, укладывается ли он в правило
+type Store interface {
+	Allow(rule Rule, key string, now time.Time) (Result, error)
+}
+
+// Decide строит решение по числу запросов в текущем окне с учетом этого
+func Decide(rule Rule, windowStart time.Time, hits int) Result {
+	remaining := rule.Limit - hits
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   hits <= rule.Limit,
+		Limit:     rule.Limit,
+		Remaining: remaining,
+		ResetAt:   windowStart.Add(rule.Window),
+	}
+}
+
+// sweepInterval - как часто Memory удаляет счетчики закончившихся окон
+const sweepInterval = time.Minute
+
+type counter struct {
+	windowStart time.Time
+	window      time.Duration
+	hits        int
+}
+
+// Memory хранит счетчики в памяти процесса
+type Memory struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{counters: make(map[string]*counter)}
+}
+
+func (m *Memory) Allow(rule Rule, key string, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	id := rule.Name + ":" + key
+	c, ok := m.counters[id]
+	if !ok || !now.Before(c.windowStart.Add(rule.Window)) {
+		c = &counter{windowStart: now, window: rule.Window}
+		m.counters[id] = c
+	}
+	// Сверх лимита не считаем: превышение не продлевает блокировку
+	if c.hits <= rule.Limit {
+		c.hits++
+	}
+
+	return Decide(rule, c.windowStart, c.hits), nil
+}
+
+// sweep удаляет счетчики, окна которых закончились
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for id, c := range m.counters {
+		if !now.Before(c.windowStart.Add(c.window)) {
+			delete(m.counters, id)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	rule := Rule{Name: "send-code", Limit: 2, Window: time.Minute}
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("limits hits within window", func(t *testing.T) {
+		store := NewMemory()
+
+		first, _ := store.Allow(rule, "10.0.0.1", now)
+		second, _ := store.Allow(rule, "10.0.0.1", now.Add(10*time.Second))
+		third, _ := store.Allow(rule, "10.0.0.1", now.Add(20*time.Second))
+
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+		assert.True(t, second.Allowed)
+		assert.Equal(t, 0, second.Remaining)
+		assert.False(t, third.Allowed)
+		assert.Equal(t, 40*time.Second, third.RetryAfter(now.Add(20*time.Second)))
+	})
+
+	t.Run("keys and rules are independent", func(t *testing.T) {
+		store := NewMemory()
+		other := Rule{Name: "verify-code", Limit: 1, Window: time.Minute}
+
+		store.Allow(rule, "10.0.0.1", now)
+		store.Allow(rule, "10.0.0.1", now)
+
+		byKey, _ := store.Allow(rule, "10.0.0.2", now)
+		byRule, _ := store.Allow(other, "10.0.0.1", now)
+
+		assert.True(t, byKey.Allowed)
+		assert.True(t, byRule.Allowed)
+	})
+
+	t.Run("new window resets counter", func(t *testing.T) {
+		store := NewMemory()
+
+		store.Allow(rule, "10.0.0.1", now)
+		store.Allow(rule, "10.0.0.1", now)
+		blocked, _ := store.Allow(rule, "10.0.0.1", now)
+		result, _ := store.Allow(rule, "10.0.0.1", now.Add(time.Minute))
+
+		assert.False(t, blocked.Allowed)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("sweeps finished windows", func(t *testing.T) {
+		store := NewMemory()
+
+		store.Allow(rule, "10.0.0.1", now)
+		store.Allow(rule, "10.0.0.2", now.Add(2*time.Minute))
+
+		assert.Len(t, store.counters, 1)
+	})
+}
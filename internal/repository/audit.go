@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditFilter - условия выборки журнала аудита; пустые поля не ограничивают выборку
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+type Audit struct {
+	db *gorm.DB
+}
+
+func NewAudit(db *gorm.DB) *Audit {
+	return &Audit{db: db}
+}
+
+// List возвращает записи от новых к старым. Если beforeID больше нуля, выдаются
+// только записи старше него: так журнал листается курсором без пропусков и повторов.
+func (r *Audit) List(filter AuditFilter, beforeID int64, limit int) ([]model.AuditEntry, error) {
+	query := r.db.Model(&model.AuditEntry{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var entries []model.AuditEntry
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// recordAudit пишет запись журнала; внутри транзакции запись сохранится,
+// только если зафиксировано само изменение
+func recordAudit(db *gorm.DB, entry *model.AuditEntry) error {
+	return db.Create(entry).Error
+}
@@ -15,14 +15,33 @@ func NewAuth(db *gorm.DB) *Auth {
 	return &Auth{db: db}
 }
 
+// Transaction выполняет fn в одной транзакции
+func (r *Auth) Transaction(fn func(tx *Auth) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Auth{db: tx})
+	})
+}
+
+// LockEmail берет транзакционную advisory-блокировку на адрес, чтобы параллельные
+// запросы кода проверяли лимиты отправки по очереди
+func (r *Auth) LockEmail(email string) error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "email:"+email).Error
+}
+
 func (r *Auth) CreateVerification(verification *model.EmailVerification) error {
 	return r.db.Create(verification).Error
 }
 
-func (r *Auth) GetVerification(email, code string) (*model.EmailVerification, error) {
+func (r *Auth) EnqueueEmail(message *model.OutboxMessage) error {
+	return enqueueEmail(r.db, message)
+}
+
+// GetActiveVerification возвращает последний неиспользованный и не истекший код адреса.
+// Более ранние коды после отправки нового не действуют.
+func (r *Auth) GetActiveVerification(email string, now time.Time) (*model.EmailVerification, error) {
 	var verification model.EmailVerification
-	err := r.db.Where("email = ? AND code = ? AND used = ? AND expires_at > ?",
-		email, code, false, time.Now()).
+	err := r.db.Where("email = ? AND used = ? AND expires_at > ?", email, false, now).
+		Order("created_at DESC").
 		First(&verification).Error
 	if err != nil {
 		return nil, err
@@ -30,13 +49,39 @@ func (r *Auth) GetVerification(email, code string) (*model.EmailVerification, er
 	return &verification, nil
 }
 
-func (r *Auth) MarkVerificationUsed(id string) error {
-	return r.db.Model(&model.EmailVerification{}).
-		Where("id = ?", id).
-		Update("used", true).Error
+// GetRecentVerifications возвращает коды, отправленные на адрес после since, от старых к новым
+func (r *Auth) GetRecentVerifications(email string, since time.Time) ([]model.EmailVerification, error) {
+	var verifications []model.EmailVerification
+	err := r.db.Where("email = ? AND created_at > ?", email, since).
+		Order("created_at ASC").
+		Find(&verifications).Error
+	return verifications, err
+}
+
+// ClaimAttempt засчитывает попытку ввода кода и возвращает новое число попыток.
+// Возвращает false, если попытки уже исчерпаны или код использован.
+func (r *Auth) ClaimAttempt(id string, maxAttempts int) (int, bool, error) {
+	var claimed []struct{ Attempts int }
+	err := r.db.Raw("UPDATE email_verifications SET attempts = attempts + 1 WHERE id = ? AND used = ? AND attempts < ? RETURNING attempts",
+		id, false, maxAttempts).Scan(&claimed).Error
+	if err != nil || len(claimed) == 0 {
+		return 0, false, err
+	}
+	return claimed[0].Attempts, true, nil
+}
+
+// MarkVerificationUsed помечает код использованным. Возвращает false, если код
+// уже успели использовать.
+func (r *Auth) MarkVerificationUsed(id string) (bool, error) {
+	result := r.db.Model(&model.EmailVerification{}).
+		Where("id = ? AND used = ?", id, false).
+		Update("used", true)
+	return result.RowsAffected > 0, result.Error
 }
 
-func (r *Auth) DeleteExpiredVerifications() error {
-	return r.db.Where("expires_at < ? OR used = ?", time.Now(), true).
+// DeleteVerificationsBefore удаляет коды, созданные раньше before. Более свежие коды
+// нужны для расчета лимитов отправки, даже если они истекли или использованы.
+func (r *Auth) DeleteVerificationsBefore(before time.Time) error {
+	return r.db.Where("created_at < ?", before).
 		Delete(&model.EmailVerification{}).Error
 }
@@ -1,12 +1,20 @@
 package repository
 
 import (
+	"errors"
 	"theater-ticket-system/internal/models/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrSeatNotAvailable = errors.New("seat is not available")
+
+// ErrSessionHoldLimit - сессия уже удерживает столько мест, сколько ей разрешено
+var ErrSessionHoldLimit = errors.New("session holds too many seats")
+
 type Bookings struct {
 	db *gorm.DB
 }
@@ -15,14 +23,24 @@ func NewBookings(db *gorm.DB) *Bookings {
 	return &Bookings{db: db}
 }
 
+// Transaction выполняет fn в одной транзакции: либо применяются все изменения, либо ни одного
+func (r *Bookings) Transaction(fn func(tx *Bookings) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Bookings{db: tx})
+	})
+}
+
 func (r *Bookings) Create(booking *model.Booking) error {
 	return r.db.Create(booking).Error
 }
 
 func (r *Bookings) GetByID(id uuid.UUID) (*model.Booking, error) {
 	var booking model.Booking
-	err := r.db.Preload("Performance.Play").
+	err := r.db.Preload("User").
+		Preload("Performance.Play").
+		Preload("Performance.Hall").
 		Preload("PerformanceSeats.Seat").
+		Preload("Tickets", "status = ?", "valid").
 		First(&booking, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -30,6 +48,31 @@ func (r *Bookings) GetByID(id uuid.UUID) (*model.Booking, error) {
 	return &booking, nil
 }
 
+// LockByID загружает бронирование с местами и блокирует его строку до конца транзакции
+func (r *Bookings) LockByID(id uuid.UUID) (*model.Booking, error) {
+	var booking model.Booking
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("User").
+		Preload("Performance.Play").
+		Preload("Performance.Hall").
+		Preload("PerformanceSeats.Seat").
+		First(&booking, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// ActiveBookingIDs возвращает неотмененные и неистекшие бронирования показа в порядке создания
+func (r *Bookings) ActiveBookingIDs(performanceID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&model.Booking{}).
+		Where("performance_id = ? AND status IN ?", performanceID, []string{"pending", "confirmed"}).
+		Order("created_at ASC, id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
 func (r *Bookings) GetByUserID(userID uuid.UUID) ([]model.Booking, error) {
 	var bookings []model.Booking
 	err := r.db.Preload("Performance.Play").
@@ -40,7 +83,18 @@ func (r *Bookings) GetByUserID(userID uuid.UUID) ([]model.Booking, error) {
 }
 
 func (r *Bookings) Update(booking *model.Booking) error {
-	return r.db.Save(booking).Error
+	// Места и показ меняются отдельными запросами, сохраняем только саму бронь
+	return r.db.Omit(clause.Associations).Save(booking).Error
+}
+
+// UpdateStatus меняет только статус бронирования
+func (r *Bookings) UpdateStatus(id uuid.UUID, status string) error {
+	return r.db.Model(&model.Booking{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// UpdateReminders включает или отключает напоминания, не трогая остальные поля брони
+func (r *Bookings) UpdateReminders(id uuid.UUID, enabled bool) error {
+	return r.db.Model(&model.Booking{}).Where("id = ?", id).Update("reminders_enabled", enabled).Error
 }
 
 func (r *Bookings) UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error {
@@ -49,15 +103,374 @@ func (r *Bookings) UpdatePerformanceSeatStatus(seatID uuid.UUID, status string,
 	}
 	if bookingID != nil {
 		updates["booking_id"] = *bookingID
+		// Место держится ровно столько же, сколько живет бронирование
+		updates["reserved_until"] = gorm.Expr("(SELECT expires_at FROM bookings WHERE id = ?)", *bookingID)
+	}
+	if status == "reserved" || status == "available" {
+		updates["hold_id"] = nil
+	}
+	if status == "available" {
+		updates["booking_id"] = nil
+		updates["reserved_until"] = nil
+		updates["charged_price"] = nil
+	}
+
+	query := r.db.Model(&model.PerformanceSeat{}).Where("id = ?", seatID)
+	switch status {
+	case "reserved":
+		// Условное обновление: занять можно только свободное место
+		// или место из удержания, которое превращается в эту бронь
+		query = query.Where("("+seatFree+" OR (status = ? AND hold_id IN (SELECT id FROM seat_holds WHERE booking_id = ?)))",
+			"held", bookingID)
+	case "sold":
+		// Продать можно только место, зарезервированное этой же бронью
+		delete(updates, "booking_id")
+		updates["reserved_until"] = nil
+		query = query.Where("status = ? AND booking_id = ?", "reserved", bookingID)
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if (status == "reserved" || status == "sold") && result.RowsAffected == 0 {
+		return ErrSeatNotAvailable
+	}
+	return nil
+}
+
+// GetPerformanceSeatsByIDs возвращает свободные места (в том числе с истекшим удержанием) и блокирует их (SELECT ... FOR UPDATE)
+// до конца транзакции. Места блокируются в порядке id, чтобы параллельные брони
+// пересекающихся наборов не приводили к взаимоблокировке.
+func (r *Bookings) GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+	var seats []model.PerformanceSeat
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Seat").
+		Where("id IN ? AND performance_id = ?", seatIDs, performanceID).
+		Where(seatFree).
+		Order("id ASC").
+		Find(&seats).Error
+	return seats, err
+}
+
+// GetPerformance загружает показ вместе с тарифным планом. Внутри транзакции строка показа остается
+// заблокированной FOR SHARE, чтобы показ не перенесли, пока создается бронирование.
+func (r *Bookings) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	var performance model.Performance
+	err := r.db.Clauses(clause.Locking{Strength: "SHARE"}).
+		Preload("PricePlan.DemandSteps").
+		Preload("Play").
+		Preload("Hall").
+		First(&performance, "id = ?", id).Error
+	if err != nil {
+		return nil, err
 	}
+	return &performance, nil
+}
+
+// CountOccupiedSeats возвращает число занятых мест показа и общее число мест
+func (r *Bookings) CountOccupiedSeats(performanceID uuid.UUID) (occupied, total int64, err error) {
+	var counts struct {
+		Occupied int64
+		Total    int64
+	}
+	err = r.db.Model(&model.PerformanceSeat{}).
+		Select("COUNT(*) FILTER (WHERE NOT "+seatFree+") AS occupied, COUNT(*) AS total").
+		Where("performance_id = ?", performanceID).
+		Scan(&counts).Error
+	return counts.Occupied, counts.Total, err
+}
+
+// SetChargedPrice фиксирует цену, по которой место забронировано
+func (r *Bookings) SetChargedPrice(seatID uuid.UUID, price int) error {
 	return r.db.Model(&model.PerformanceSeat{}).
 		Where("id = ?", seatID).
-		Updates(updates).Error
+		Update("charged_price", price).Error
 }
 
-func (r *Bookings) GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+// LockPromoCode загружает промокод и блокирует его строку, чтобы лимит использований
+// не был превышен параллельными бронированиями
+func (r *Bookings) LockPromoCode(code string) (*model.PromoCode, error) {
+	var promo model.PromoCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&promo, "code = ?", code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *Bookings) CountPromoRedemptions(promoCodeID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PromoRedemption{}).
+		Where("promo_code_id = ? AND user_id = ?", promoCodeID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// RedeemPromoCode записывает использование промокода и увеличивает счетчик
+func (r *Bookings) RedeemPromoCode(redemption *model.PromoRedemption) error {
+	if err := r.db.Create(redemption).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&model.PromoCode{}).
+		Where("id = ?", redemption.PromoCodeID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// ReleasePromoCode возвращает использование промокода, если бронирование его применяло
+func (r *Bookings) ReleasePromoCode(bookingID uuid.UUID) error {
+	return releasePromoCodes(r.db, []uuid.UUID{bookingID})
+}
+
+// releasePromoCodes удаляет использования промокодов бронированиями bookingIDs
+// и уменьшает счетчики использований
+func releasePromoCodes(db *gorm.DB, bookingIDs []uuid.UUID) error {
+	if len(bookingIDs) == 0 {
+		return nil
+	}
+
+	err := db.Exec(`UPDATE promo_codes SET used_count = used_count - r.uses
+		FROM (
+			SELECT promo_code_id, COUNT(*) AS uses FROM promo_redemptions
+			WHERE booking_id IN ? GROUP BY promo_code_id
+		) r
+		WHERE promo_codes.id = r.promo_code_id`, bookingIDs).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Where("booking_id IN ?", bookingIDs).Delete(&model.PromoRedemption{}).Error
+}
+
+// CreateTickets сохраняет билеты, выпущенные при подтверждении бронирования
+func (r *Bookings) CreateTickets(tickets []model.Ticket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	return r.db.Create(&tickets).Error
+}
+
+func (r *Bookings) EnqueueEmail(message *model.OutboxMessage) error {
+	return enqueueEmail(r.db, message)
+}
+
+func (r *Bookings) RecordAudit(entry *model.AuditEntry) error {
+	return recordAudit(r.db, entry)
+}
+
+// GetForReminder возвращает подтвержденные бронирования показа, покупатели которых
+// не отказались от напоминаний и еще не получили напоминание за offset до начала
+func (r *Bookings) GetForReminder(performanceID uuid.UUID, offset time.Duration) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Preload("User").
+		Preload("PerformanceSeats.Seat").
+		Where("performance_id = ? AND status = ? AND reminders_enabled = ?", performanceID, "confirmed", true).
+		Where("NOT EXISTS (SELECT 1 FROM booking_reminders br WHERE br.booking_id = bookings.id AND br.offset_minutes = ?)",
+			int(offset.Minutes())).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// RecordReminder отмечает напоминание отправленным. Возвращает false, если его уже
+// отправил другой экземпляр сервера или предыдущий запуск.
+func (r *Bookings) RecordReminder(bookingID uuid.UUID, offset time.Duration, at time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.BookingReminder{
+		BookingID:     bookingID,
+		OffsetMinutes: int(offset.Minutes()),
+		SentAt:        at,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpirePending переводит просроченные pending-бронирования в expired и освобождает места.
+// Строки блокируются через FOR UPDATE SKIP LOCKED, поэтому несколько экземпляров
+// сервера могут выполнять очистку одновременно, не мешая друг другу. Если задан
+// onRelease, он выполняется в той же транзакции после освобождения мест.
+func (r *Bookings) ExpirePending(now time.Time, limit int, onRelease func(tx *Bookings, released []model.Booking) error) (int, error) {
+	var expired []model.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Booking{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id", "performance_id").
+			Where("status = ? AND expires_at < ?", "pending", now).
+			Order("expires_at ASC").
+			Limit(limit).
+			Find(&expired).Error
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(expired))
+		for i := range expired {
+			ids[i] = expired[i].ID
+			expired[i].Status = "expired"
+		}
+
+		err = tx.Model(&model.Booking{}).
+			Where("id IN ?", ids).
+			Update("status", "expired").Error
+		if err != nil {
+			return err
+		}
+
+		if err := releasePromoCodes(tx, ids); err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.PerformanceSeat{}).
+			Where("booking_id IN ? AND status = ?", ids, "reserved").
+			Updates(map[string]interface{}{
+				"status":         "available",
+				"booking_id":     nil,
+				"reserved_until": nil,
+				"charged_price":  nil,
+			}).Error
+		if err != nil || onRelease == nil {
+			return err
+		}
+
+		return onRelease(&Bookings{db: tx}, expired)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// LockWaitlist возвращает ожидающие заявки показа в порядке подачи и блокирует их,
+// чтобы одно освобождение мест не предлагалось дважды
+func (r *Bookings) LockWaitlist(performanceID uuid.UUID) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("User").
+		Where("performance_id = ? AND status = ?", performanceID, "waiting").
+		Order("created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// LockAvailableSeats выбирает до limit свободных мест показа нужной категории
+// (любой, если category пуста) подряд по рядам и блокирует их. Места, которые
+// сейчас бронирует кто-то другой, пропускаются.
+func (r *Bookings) LockAvailableSeats(performanceID uuid.UUID, category string, limit int) ([]model.PerformanceSeat, error) {
+	query := r.db.Clauses(clause.Locking{
+		Strength: "UPDATE",
+		Table:    clause.Table{Name: clause.CurrentTable},
+		Options:  "SKIP LOCKED",
+	}).
+		Joins("Seat").
+		Where("performance_seats.performance_id = ?", performanceID).
+		Where(seatFree)
+	if category != "" {
+		query = query.Where(`"Seat".category = ?`, category)
+	}
+
 	var seats []model.PerformanceSeat
-	err := r.db.Where("id IN ? AND performance_id = ? AND status = ?", seatIDs, performanceID, "available").
+	err := query.Order(`"Seat".section, "Seat".row, "Seat".position`).
+		Limit(limit).
 		Find(&seats).Error
 	return seats, err
 }
+
+func (r *Bookings) UpdateWaitlistEntry(entry *model.WaitlistEntry) error {
+	return r.db.Omit(clause.Associations).Save(entry).Error
+}
+
+// CloseWaitlistOffers закрывает предложения из листа ожидания, бронирования
+// которых отменены или просрочены. Возвращает закрытые заявки.
+func (r *Bookings) CloseWaitlistOffers(bookingIDs []uuid.UUID, status string) ([]model.WaitlistEntry, error) {
+	if len(bookingIDs) == 0 {
+		return nil, nil
+	}
+	var closed []model.WaitlistEntry
+	err := r.db.Model(&closed).
+		Clauses(clause.Returning{}).
+		Where("booking_id IN ? AND status = ?", bookingIDs, "offered").
+		Update("status", status).Error
+	return closed, err
+}
+
+// ClaimHold блокирует удержание владельца токена вместе с его местами и отмечает,
+// что удержание превращается в бронирование bookingID: такие места можно
+// зарезервировать за этой бронью, хотя они в статусе held
+func (r *Bookings) ClaimHold(tokenHash string, performanceID, bookingID uuid.UUID) (*model.SeatHold, error) {
+	var hold model.SeatHold
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&hold, "token_hash = ? AND performance_id = ?", tokenHash, performanceID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&hold).Update("booking_id", bookingID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Seat").
+		Where("hold_id = ? AND status = ?", hold.ID, "held").
+		Order("id ASC").
+		Find(&hold.Seats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// DeleteHold освобождает места удержания, которые не вошли в бронирование,
+// и удаляет само удержание. Возвращает число освобожденных мест.
+func (r *Bookings) DeleteHold(id uuid.UUID) (int64, error) {
+	return deleteHold(r.db, id)
+}
+
+// LockCapturedPayment возвращает списанный платеж бронирования и блокирует его,
+// чтобы параллельные возвраты не превысили оплаченную сумму
+func (r *Bookings) LockCapturedPayment(bookingID uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("booking_id = ? AND status = ?", bookingID, "captured").
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// LockPayment возвращает платеж и блокирует его, чтобы подтверждение брони по
+// платежу и возврат платежа не выполнялись одновременно
+func (r *Bookings) LockPayment(id uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *Bookings) UpdatePayment(payment *model.Payment) error {
+	return r.db.Omit(clause.Associations).Save(payment).Error
+}
+
+// CreateRefund сохраняет возврат вместе с отмененными местами
+func (r *Bookings) CreateRefund(refund *model.Refund) error {
+	return r.db.Create(refund).Error
+}
+
+// CreatePayment сохраняет платеж, принятый в кассе
+func (r *Bookings) CreatePayment(payment *model.Payment) error {
+	return r.db.Omit(clause.Associations).Create(payment).Error
+}
+
+// VoidTickets аннулирует билеты бронирования на отмененные места
+func (r *Bookings) VoidTickets(bookingID uuid.UUID, seatIDs []uuid.UUID) error {
+	if len(seatIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.Ticket{}).
+		Where("booking_id = ? AND performance_seat_id IN ? AND status = ?", bookingID, seatIDs, "valid").
+		Update("status", "voided").Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MethodTotal - число и сумма операций одним способом оплаты
+type MethodTotal struct {
+	Method string
+	Count  int
+	Amount int
+}
+
+type BoxOffice struct {
+	db *gorm.DB
+}
+
+func NewBoxOffice(db *gorm.DB) *BoxOffice {
+	return &BoxOffice{db: db}
+}
+
+// GetPayment возвращает платеж, принятый в кассе за бронирование, вместе с кассиром
+func (r *BoxOffice) GetPayment(bookingID uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.Preload("Cashier").
+		Where("booking_id = ? AND method IN ?", bookingID, []string{model.PaymentCash, model.PaymentCard}).
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// SalesByMethod суммирует продажи кассира за [from, to) по способам оплаты
+func (r *BoxOffice) SalesByMethod(cashierID uuid.UUID, from, to time.Time) ([]MethodTotal, error) {
+	var totals []MethodTotal
+	err := r.db.Model(&model.Payment{}).
+		Select("method, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Where("cashier_id = ? AND created_at >= ? AND created_at < ?", cashierID, from, to).
+		Group("method").
+		Order("method").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// RefundsByMethod суммирует возвраты за [from, to) по продажам кассира по способам оплаты
+func (r *BoxOffice) RefundsByMethod(cashierID uuid.UUID, from, to time.Time) ([]MethodTotal, error) {
+	var totals []MethodTotal
+	err := r.db.Model(&model.Refund{}).
+		Select("payments.method, COUNT(*) AS count, COALESCE(SUM(refunds.amount), 0) AS amount").
+		Joins("JOIN payments ON payments.id = refunds.payment_id").
+		Where("payments.cashier_id = ? AND refunds.created_at >= ? AND refunds.created_at < ?", cashierID, from, to).
+		Group("payments.method").
+		Order("payments.method").
+		Scan(&totals).Error
+	return totals, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckIn struct {
+	db *gorm.DB
+}
+
+func NewCheckIn(db *gorm.DB) *CheckIn {
+	return &CheckIn{db: db}
+}
+
+// Transaction выполняет fn в одной транзакции
+func (r *CheckIn) Transaction(fn func(tx *CheckIn) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&CheckIn{db: tx})
+	})
+}
+
+// LockTicket загружает билет вместе с показом и блокирует его строку, чтобы
+// два одновременных сканирования одного билета не пропустили двух зрителей
+func (r *CheckIn) LockTicket(id uuid.UUID) (*model.Ticket, error) {
+	var ticket model.Ticket
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: clause.CurrentTable}}).
+		Joins("Performance").
+		First(&ticket, "tickets.id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *CheckIn) AdmitTicket(id uuid.UUID, gate string, at time.Time) error {
+	return r.db.Model(&model.Ticket{}).
+		Where("id = ? AND admitted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"admitted_at":   at,
+			"admitted_gate": gate,
+		}).Error
+}
+
+func (r *CheckIn) CreateScan(scan *model.TicketScan) error {
+	return r.db.Omit(clause.Associations).Create(scan).Error
+}
+
+// CountAttendance возвращает число действующих билетов показа, число прошедших зрителей
+// и распределение прошедших по входам
+func (r *CheckIn) CountAttendance(performanceID uuid.UUID) (tickets, admitted int64, byGate map[string]int64, err error) {
+	var counts struct {
+		Tickets  int64
+		Admitted int64
+	}
+	err = r.db.Model(&model.Ticket{}).
+		Select("COUNT(*) AS tickets, COUNT(admitted_at) AS admitted").
+		Where("performance_id = ? AND status = ?", performanceID, "valid").
+		Scan(&counts).Error
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	var gates []struct {
+		AdmittedGate string
+		Count        int64
+	}
+	err = r.db.Model(&model.Ticket{}).
+		Select("admitted_gate, COUNT(*) AS count").
+		Where("performance_id = ? AND status = ? AND admitted_at IS NOT NULL", performanceID, "valid").
+		Group("admitted_gate").
+		Scan(&gates).Error
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	byGate = make(map[string]int64, len(gates))
+	for _, g := range gates {
+		byGate[g.AdmittedGate] = g.Count
+	}
+	return counts.Tickets, counts.Admitted, byGate, nil
+}
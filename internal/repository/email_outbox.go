@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailOutbox struct {
+	db *gorm.DB
+}
+
+func NewEmailOutbox(db *gorm.DB) *EmailOutbox {
+	return &EmailOutbox{db: db}
+}
+
+// enqueueEmail добавляет письмо в outbox; внутри транзакции письмо будет отправлено,
+// только если транзакция зафиксирована
+func enqueueEmail(db *gorm.DB, message *model.OutboxMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	return db.Create(message).Error
+}
+
+// ClaimDue выбирает письма, которые пора отправить, и откладывает их следующую попытку
+// на lease. Пока идет отправка, другие экземпляры сервера эти письма не возьмут.
+func (r *EmailOutbox) ClaimDue(now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", "pending", now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+		}
+		return tx.Model(&model.OutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return messages, err
+}
+
+func (r *EmailOutbox) MarkSent(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     "sent",
+			"attempts":   gorm.Expr("attempts + 1"),
+			"sent_at":    at,
+			"last_error": "",
+		}).Error
+}
+
+// MarkAttemptFailed записывает неудачную попытку. Если next равен nil, письмо
+// больше не отправляется.
+func (r *EmailOutbox) MarkAttemptFailed(id uuid.UUID, lastErr string, next *time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastErr,
+	}
+	if next != nil {
+		updates["next_attempt_at"] = *next
+	} else {
+		updates["status"] = "failed"
+	}
+	return r.db.Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Halls struct {
+	db *gorm.DB
+}
+
+func NewHalls(db *gorm.DB) *Halls {
+	return &Halls{db: db}
+}
+
+// Transaction выполняет fn в одной транзакции
+func (r *Halls) Transaction(fn func(tx *Halls) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Halls{db: tx})
+	})
+}
+
+func (r *Halls) GetAll() ([]model.Hall, error) {
+	var halls []model.Hall
+	err := r.db.Order("name ASC").Find(&halls).Error
+	return halls, err
+}
+
+func (r *Halls) GetByID(id uuid.UUID) (*model.Hall, error) {
+	var hall model.Hall
+	err := r.db.First(&hall, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hall, nil
+}
+
+func (r *Halls) Create(hall *model.Hall) error {
+	if hall.ID == uuid.Nil {
+		hall.ID = uuid.New()
+	}
+	return r.db.Omit(clause.Associations).Create(hall).Error
+}
+
+func (r *Halls) Update(hall *model.Hall) error {
+	return r.db.Omit(clause.Associations).Save(hall).Error
+}
+
+func (r *Halls) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.Hall{}, "id = ?", id).Error
+}
+
+// HasUpcomingPerformances сообщает, есть ли в зале неотмененные показы после after
+func (r *Halls) HasUpcomingPerformances(hallID uuid.UUID, after time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Performance{}).
+		Where("hall_id = ? AND date > ? AND status <> ?", hallID, after, "cancelled").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// LockHall использует тот же ключ блокировки, что и расписание показов,
+// поэтому схема зала не меняется, пока на него назначается показ
+func (r *Halls) LockHall(hallID uuid.UUID) error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "hall:"+hallID.String()).Error
+}
+
+func (r *Halls) GetSeats(hallID uuid.UUID) ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.db.Where("hall_id = ?", hallID).Find(&seats).Error
+	return seats, err
+}
+
+// CountCommittedSeats считает проданные или забронированные места на будущие показы
+func (r *Halls) CountCommittedSeats(seatIDs []uuid.UUID, after time.Time) (int64, error) {
+	if len(seatIDs) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.Model(&model.PerformanceSeat{}).
+		Joins("JOIN performances ON performances.id = performance_seats.performance_id").
+		Where("performance_seats.seat_id IN ? AND performance_seats.status <> ?", seatIDs, "available").
+		Where("performances.date > ? AND performances.status <> ?", after, "cancelled").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *Halls) CreateSeats(seats []model.Seat) error {
+	if len(seats) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(seats, 500).Error
+}
+
+func (r *Halls) UpdateSeat(seat *model.Seat) error {
+	return r.db.Omit(clause.Associations).Save(seat).Error
+}
+
+// DeleteSeats убирает места из схемы зала вместе с их свободными местами на будущие показы.
+// Места прошедших показов остаются в истории, поэтому сами места удаляются мягко.
+func (r *Halls) DeleteSeats(seatIDs []uuid.UUID, after time.Time) error {
+	if len(seatIDs) == 0 {
+		return nil
+	}
+
+	upcoming := r.db.Model(&model.Performance{}).Select("id").Where("date > ?", after)
+	err := r.db.Where("seat_id IN ? AND status = ? AND performance_id IN (?)", seatIDs, "available", upcoming).
+		Delete(&model.PerformanceSeat{}).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.Delete(&model.Seat{}, "id IN ?", seatIDs).Error
+}
+
+// UpcomingPerformances возвращает будущие показы зала с тарифами для расчета цен новых мест
+func (r *Halls) UpcomingPerformances(hallID uuid.UUID, after time.Time) ([]model.Performance, error) {
+	var performances []model.Performance
+	err := r.db.Preload("PricePlan.Tiers", orderByPosition).
+		Where("hall_id = ? AND date > ? AND status <> ?", hallID, after, "cancelled").
+		Find(&performances).Error
+	return performances, err
+}
+
+func (r *Halls) CreatePerformanceSeats(seats []model.PerformanceSeat) error {
+	if len(seats) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(seats, 500).Error
+}
+
+func (r *Halls) UpdateCapacity(hallID uuid.UUID, capacity int) error {
+	return r.db.Model(&model.Hall{}).
+		Where("id = ?", hallID).
+		Update("capacity", capacity).Error
+}
+
+func (r *Halls) RecordAudit(entry *model.AuditEntry) error {
+	return recordAudit(r.db, entry)
+}
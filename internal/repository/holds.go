@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seatFree - условие, при котором место показа можно занять: оно свободно
+// или удерживается, но срок удержания уже истек
+const seatFree = "(performance_seats.status = 'available' OR " +
+	"(performance_seats.status = 'held' AND performance_seats.reserved_until <= now()))"
+
+type Holds struct {
+	db *gorm.DB
+}
+
+func NewHolds(db *gorm.DB) *Holds {
+	return &Holds{db: db}
+}
+
+// Create сохраняет удержание и переводит в него места seatIDs показа.
+// Если хотя бы одно место уже занято, ничего не меняется и возвращается ErrSeatNotAvailable.
+// Если вместе с ними сессия удержания держала бы больше maxSessionSeats мест,
+// возвращается ErrSessionHoldLimit; 0 - без ограничения.
+func (r *Holds) Create(hold *model.SeatHold, seatIDs []uuid.UUID, maxSessionSeats int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if maxSessionSeats > 0 {
+			// Параллельные удержания одной сессии считают ее места по очереди
+			err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "hold-session:"+hold.Session).Error
+			if err != nil {
+				return err
+			}
+			var held int64
+			err = tx.Model(&model.PerformanceSeat{}).
+				Joins("JOIN seat_holds ON seat_holds.id = performance_seats.hold_id").
+				Where("seat_holds.session = ? AND seat_holds.expires_at > ?", hold.Session, hold.CreatedAt).
+				Where("performance_seats.status = ?", "held").
+				Count(&held).Error
+			if err != nil {
+				return err
+			}
+			if int(held)+len(seatIDs) > maxSessionSeats {
+				return ErrSessionHoldLimit
+			}
+		}
+
+		// Места блокируются в порядке id, как и при бронировании
+		var seats []model.PerformanceSeat
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Seat").
+			Where("id IN ? AND performance_id = ?", seatIDs, hold.PerformanceID).
+			Where(seatFree).
+			Order("id ASC").
+			Find(&seats).Error
+		if err != nil {
+			return err
+		}
+		if len(seats) != len(seatIDs) {
+			return ErrSeatNotAvailable
+		}
+
+		if err := tx.Omit(clause.Associations).Create(hold).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.PerformanceSeat{}).
+			Where("id IN ?", seatIDs).
+			Updates(map[string]interface{}{
+				"status":         "held",
+				"hold_id":        hold.ID,
+				"reserved_until": hold.ExpiresAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range seats {
+			seats[i].Status = "held"
+			seats[i].HoldID = &hold.ID
+			seats[i].ReservedUntil = &hold.ExpiresAt
+		}
+		hold.Seats = seats
+		return nil
+	})
+}
+
+// GetByID возвращает удержание вместе с местами, которые оно еще держит
+func (r *Holds) GetByID(id uuid.UUID) (*model.SeatHold, error) {
+	var hold model.SeatHold
+	err := r.db.Preload("Seats", "status = ?", "held").
+		Preload("Seats.Seat").
+		First(&hold, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Extend продлевает удержание и его места до expiresAt, если оно еще не истекло к моменту now
+func (r *Holds) Extend(id uuid.UUID, now, expiresAt time.Time) (bool, error) {
+	extended := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.SeatHold{}).
+			Where("id = ? AND expires_at > ?", id, now).
+			Update("expires_at", expiresAt)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		extended = true
+
+		return tx.Model(&model.PerformanceSeat{}).
+			Where("hold_id = ? AND status = ?", id, "held").
+			Update("reserved_until", expiresAt).Error
+	})
+	return extended, err
+}
+
+// Release снимает удержание и возвращает его места в продажу. Если задан onRelease,
+// он выполняется в той же транзакции.
+func (r *Holds) Release(hold *model.SeatHold, onRelease func(tx *Bookings, performanceIDs []uuid.UUID) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		released, err := deleteHold(tx, hold.ID)
+		if err != nil || released == 0 || onRelease == nil {
+			return err
+		}
+		return onRelease(&Bookings{db: tx}, []uuid.UUID{hold.PerformanceID})
+	})
+}
+
+// ReleaseExpired снимает не более limit удержаний, истекших к моменту now.
+// Строки блокируются через FOR UPDATE SKIP LOCKED, поэтому очистку могут
+// одновременно выполнять несколько экземпляров сервера. Возвращает число снятых удержаний.
+func (r *Holds) ReleaseExpired(now time.Time, limit int, onRelease func(tx *Bookings, performanceIDs []uuid.UUID) error) (int, error) {
+	var expired []model.SeatHold
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id", "performance_id").
+			Where("expires_at <= ?", now).
+			Order("expires_at ASC").
+			Limit(limit).
+			Find(&expired).Error
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+
+		var performances []uuid.UUID
+		seen := make(map[uuid.UUID]bool)
+		for _, hold := range expired {
+			released, err := deleteHold(tx, hold.ID)
+			if err != nil {
+				return err
+			}
+			if released > 0 && !seen[hold.PerformanceID] {
+				seen[hold.PerformanceID] = true
+				performances = append(performances, hold.PerformanceID)
+			}
+		}
+		if len(performances) == 0 || onRelease == nil {
+			return nil
+		}
+
+		return onRelease(&Bookings{db: tx}, performances)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// GetPerformance возвращает показ, на который создается удержание
+func (r *Holds) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	var performance model.Performance
+	if err := r.db.First(&performance, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &performance, nil
+}
+
+// deleteHold возвращает в продажу места, которые удержание id еще держит, и удаляет его
+func deleteHold(db *gorm.DB, id uuid.UUID) (int64, error) {
+	result := db.Model(&model.PerformanceSeat{}).
+		Where("hold_id = ? AND status = ?", id, "held").
+		Updates(map[string]interface{}{
+			"status":         "available",
+			"hold_id":        nil,
+			"reserved_until": nil,
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if err := db.Delete(&model.SeatHold{}, "id = ?", id).Error; err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
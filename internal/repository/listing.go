@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"theater-ticket-system/internal/listing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sortKey - колонка, по которой можно сортировать список записей T. value достает
+// из записи значение колонки для курсора, parse превращает его обратно в параметр запроса.
+// Колонка не должна допускать NULL, иначе сравнение с курсором потеряет записи.
+type sortKey[T any] struct {
+	column string
+	value  func(item *T) string
+	parse  func(value string) (interface{}, error)
+}
+
+func timeSortKey[T any](column string, get func(item *T) time.Time) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		value:  func(item *T) string { return get(item).UTC().Format(time.RFC3339Nano) },
+		parse: func(value string) (interface{}, error) {
+			return time.Parse(time.RFC3339Nano, value)
+		},
+	}
+}
+
+func stringSortKey[T any](column string, get func(item *T) string) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		value:  get,
+		parse:  func(value string) (interface{}, error) { return value, nil },
+	}
+}
+
+func intSortKey[T any](column string, get func(item *T) int) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		value:  func(item *T) string { return strconv.Itoa(get(item)) },
+		parse: func(value string) (interface{}, error) {
+			return strconv.Atoi(value)
+		},
+	}
+}
+
+// paginate выбирает страницу filtered по ключу сортировки и курсору query. Записи
+// с одинаковым значением ключа упорядочиваются по idColumn, поэтому страницы не теряют
+// и не повторяют записи. load подгружает связи только для записей страницы.
+func paginate[T any](filtered *gorm.DB, load func(db *gorm.DB) *gorm.DB, keys map[string]sortKey[T],
+	idColumn string, id func(item *T) uuid.UUID, query listing.Query) (listing.Page[T], error) {
+	var page listing.Page[T]
+
+	key, ok := keys[query.Sort.Field]
+	if !ok {
+		return page, fmt.Errorf("%w: unknown sort field %q", listing.ErrInvalidQuery, query.Sort.Field)
+	}
+
+	if err := filtered.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	direction, compare := "ASC", ">"
+	if query.Sort.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	rows := filtered.Session(&gorm.Session{})
+	if query.Cursor != nil {
+		value, err := key.parse(query.Cursor.Value)
+		if err != nil {
+			return page, fmt.Errorf("%w: malformed cursor", listing.ErrInvalidQuery)
+		}
+		rows = rows.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", key.column, idColumn, compare), value, query.Cursor.ID)
+	}
+	if load != nil {
+		rows = load(rows)
+	}
+
+	// Лишняя запись показывает, есть ли следующая страница
+	err := rows.Order(fmt.Sprintf("%s %s, %s %s", key.column, direction, idColumn, direction)).
+		Limit(query.Limit + 1).
+		Find(&page.Items).Error
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > query.Limit {
+		page.Items = page.Items[:query.Limit]
+		last := &page.Items[query.Limit-1]
+		page.NextCursor = listing.Cursor{
+			Sort:  query.Sort.String(),
+			Value: key.value(last),
+			ID:    id(last),
+		}.Encode()
+	}
+	return page, nil
+}
+
+// containsPattern строит шаблон ILIKE для поиска подстроки value; спецсимволы
+// шаблона в value ищутся как обычные символы
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Payments struct {
+	db *gorm.DB
+}
+
+func NewPayments(db *gorm.DB) *Payments {
+	return &Payments{db: db}
+}
+
+func (r *Payments) Create(payment *model.Payment) error {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+	return r.db.Create(payment).Error
+}
+
+func (r *Payments) Update(payment *model.Payment) error {
+	return r.db.Omit("Booking").Save(payment).Error
+}
+
+func (r *Payments) GetByIntentID(intentID string) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.Where("intent_id = ?", intentID).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListByStatus возвращает не более limit платежей в статусе status, старые первыми
+func (r *Payments) ListByStatus(status string, limit int) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.Where("status = ?", status).Order("created_at").Limit(limit).Find(&payments).Error
+	return payments, err
+}
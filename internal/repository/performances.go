@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Performances struct {
@@ -16,33 +18,148 @@ func NewPerformances(db *gorm.DB) *Performances {
 	return &Performances{db: db}
 }
 
-func (r *Performances) GetAll(playID *uuid.UUID, dateFrom, dateTo *time.Time) ([]model.Performance, error) {
-	var performances []model.Performance
-	query := r.db.Preload("Play").Order("date ASC")
+// Transaction выполняет fn в одной транзакции
+func (r *Performances) Transaction(fn func(tx *Performances) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Performances{db: tx})
+	})
+}
+
+// PerformanceFilter - условия выборки показов; пустые поля не ограничивают выборку
+type PerformanceFilter struct {
+	PlayID    *uuid.UUID
+	HallID    *uuid.UUID
+	Status    string
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	Available *bool // идет ли продажа и есть ли свободные места
+}
+
+// performanceOnSale - условие, при котором на показ продаются билеты
+const performanceOnSale = "(performances.status = 'on_sale' AND performances.date > now())"
+
+// performanceHasFreeSeats - условие, при котором у показа есть место, которое можно занять
+const performanceHasFreeSeats = "EXISTS (SELECT 1 FROM performance_seats " +
+	"WHERE performance_seats.performance_id = performances.id AND " + seatFree + ")"
+
+var performanceSortKeys = map[string]sortKey[model.Performance]{
+	"date":       timeSortKey("performances.date", func(p *model.Performance) time.Time { return p.Date }),
+	"created_at": timeSortKey("performances.created_at", func(p *model.Performance) time.Time { return p.CreatedAt }),
+}
 
-	if playID != nil {
-		query = query.Where("play_id = ?", *playID)
+// GetAll возвращает страницу показов вместе со спектаклями
+func (r *Performances) GetAll(filter PerformanceFilter, query listing.Query) (listing.Page[model.Performance], error) {
+	filtered := r.db.Model(&model.Performance{})
+	if filter.PlayID != nil {
+		filtered = filtered.Where("performances.play_id = ?", *filter.PlayID)
 	}
-	if dateFrom != nil {
-		query = query.Where("date >= ?", *dateFrom)
+	if filter.HallID != nil {
+		filtered = filtered.Where("performances.hall_id = ?", *filter.HallID)
 	}
-	if dateTo != nil {
-		query = query.Where("date <= ?", *dateTo)
+	if filter.Status != "" {
+		filtered = filtered.Where("performances.status = ?", filter.Status)
+	}
+	if filter.DateFrom != nil {
+		filtered = filtered.Where("performances.date >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		filtered = filtered.Where("performances.date <= ?", *filter.DateTo)
+	}
+	if filter.Available != nil {
+		available := "(" + performanceOnSale + " AND " + performanceHasFreeSeats + ")"
+		if !*filter.Available {
+			available = "NOT " + available
+		}
+		filtered = filtered.Where(available)
 	}
 
-	err := query.Find(&performances).Error
-	return performances, err
+	load := func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Play")
+	}
+	return paginate(filtered, load, performanceSortKeys, "performances.id",
+		func(p *model.Performance) uuid.UUID { return p.ID }, query)
 }
 
 func (r *Performances) GetByID(id uuid.UUID) (*model.Performance, error) {
 	var performance model.Performance
-	err := r.db.Preload("Play").First(&performance, "id = ?", id).Error
+	err := r.db.Preload("Play").Preload("Hall").First(&performance, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &performance, nil
 }
 
+// LockByID загружает показ и блокирует его строку до конца транзакции. Бронирования
+// читают показ с FOR SHARE, поэтому новые бронирования ждут, пока блокировка не снята.
+func (r *Performances) LockByID(id uuid.UUID) (*model.Performance, error) {
+	var performance model.Performance
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Play").
+		Preload("Hall").
+		First(&performance, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &performance, nil
+}
+
+// HasActiveBookings сообщает, есть ли у показа неотмененные и неистекшие бронирования
+func (r *Performances) HasActiveBookings(performanceID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Booking{}).
+		Where("performance_id = ? AND status IN ?", performanceID, []string{"pending", "confirmed"}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetStartingBetween возвращает показы с проданными билетами, начинающиеся в (from, to]
+func (r *Performances) GetStartingBetween(from, to time.Time) ([]model.Performance, error) {
+	var performances []model.Performance
+	err := r.db.Preload("Play").
+		Preload("Hall").
+		Where("status IN ? AND date > ? AND date <= ?", []string{"on_sale", "sales_closed"}, from, to).
+		Order("date ASC").
+		Find(&performances).Error
+	return performances, err
+}
+
+// CloseSales закрывает продажу на показы, начинающиеся не позже before.
+// Возвращает показы со статусом, который был до закрытия.
+func (r *Performances) CloseSales(before time.Time) ([]model.Performance, error) {
+	return r.advanceStatus(r.db.Where("status = ? AND date <= ?", "on_sale", before), "sales_closed")
+}
+
+// CompleteFinished завершает показы, которые по длительности спектакля закончились не позже now.
+// Перенесенные показы не завершаются: их прежняя дата больше не действует.
+// Возвращает показы со статусом, который был до завершения.
+func (r *Performances) CompleteFinished(now time.Time) ([]model.Performance, error) {
+	query := r.db.Where("status IN ?", []string{"scheduled", "on_sale", "sales_closed"}).
+		Where("EXISTS (SELECT 1 FROM plays WHERE plays.id = performances.play_id AND "+
+			"performances.date + make_interval(mins => plays.duration) <= ?)", now)
+	return r.advanceStatus(query, "completed")
+}
+
+// advanceStatus блокирует показы, подходящие под query, и переводит их в статус status
+func (r *Performances) advanceStatus(query *gorm.DB, status string) ([]model.Performance, error) {
+	var performances []model.Performance
+	err := query.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&performances).Error
+	if err != nil || len(performances) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(performances))
+	for i := range performances {
+		ids[i] = performances[i].ID
+	}
+	err = r.db.Model(&model.Performance{}).
+		Where("id IN ?", ids).
+		Update("status", status).Error
+	if err != nil {
+		return nil, err
+	}
+	return performances, nil
+}
+
 func (r *Performances) GetSeats(performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
 	var seats []model.PerformanceSeat
 	err := r.db.Preload("Seat").
@@ -52,3 +169,66 @@ func (r *Performances) GetSeats(performanceID uuid.UUID) ([]model.PerformanceSea
 		Find(&seats).Error
 	return seats, err
 }
+
+// LockHall берет транзакционную advisory-блокировку на зал, чтобы параллельные
+// изменения расписания одного зала не прошли проверку пересечений одновременно
+func (r *Performances) LockHall(hallID uuid.UUID) error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "hall:"+hallID.String()).Error
+}
+
+// FindOverlapping ищет неотмененные и неперенесенные показы в зале, которые вместе с длительностью
+// спектакля и перерывом на подготовку зала пересекаются с интервалом [start, end)
+func (r *Performances) FindOverlapping(hallID uuid.UUID, start, end time.Time, turnover time.Duration, excludeID uuid.UUID) ([]model.Performance, error) {
+	var performances []model.Performance
+	err := r.db.Preload("Play").
+		Joins("JOIN plays ON plays.id = performances.play_id").
+		Where("performances.hall_id = ? AND performances.status NOT IN ? AND performances.id <> ?",
+			hallID, []string{"cancelled", "postponed"}, excludeID).
+		Where("performances.date < ?", end).
+		Where("performances.date + make_interval(mins => plays.duration, secs => ?) > ?", turnover.Seconds(), start).
+		Order("performances.date ASC").
+		Find(&performances).Error
+	return performances, err
+}
+
+func (r *Performances) Create(performance *model.Performance) error {
+	if performance.ID == uuid.Nil {
+		performance.ID = uuid.New()
+	}
+	return r.db.Omit(clause.Associations).Create(performance).Error
+}
+
+func (r *Performances) CreateSeats(seats []model.PerformanceSeat) error {
+	if len(seats) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(seats, 500).Error
+}
+
+func (r *Performances) Update(performance *model.Performance) error {
+	return r.db.Omit(clause.Associations).Save(performance).Error
+}
+
+// ReleaseHolds снимает все удержания мест показа: после отмены или переноса
+// показа удерживать места на него больше нельзя
+func (r *Performances) ReleaseHolds(performanceID uuid.UUID) error {
+	err := r.db.Model(&model.PerformanceSeat{}).
+		Where("performance_id = ? AND status = ?", performanceID, "held").
+		Updates(map[string]interface{}{
+			"status":         "available",
+			"hold_id":        nil,
+			"reserved_until": nil,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Where("performance_id = ?", performanceID).Delete(&model.SeatHold{}).Error
+}
+
+func (r *Performances) EnqueueEmail(message *model.OutboxMessage) error {
+	return enqueueEmail(r.db, message)
+}
+
+func (r *Performances) RecordAudit(entry *model.AuditEntry) error {
+	return recordAudit(r.db, entry)
+}
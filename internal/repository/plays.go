@@ -1,7 +1,12 @@
 package repository
 
 import (
+	"fmt"
+	"html"
+	"strings"
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,11 +20,150 @@ func NewPlays(db *gorm.DB) *Plays {
 	return &Plays{db: db}
 }
 
-func (r *Plays) GetAll() ([]model.Play, error) {
+// Transaction выполняет fn в одной транзакции
+func (r *Plays) Transaction(fn func(tx *Plays) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Plays{db: tx})
+	})
+}
+
+// PlayFilter - условия выборки спектаклей; пустые поля не ограничивают выборку
+type PlayFilter struct {
+	Genre     string
+	Author    string // часть имени автора
+	Search    string // часть названия, автора или описания
+	Available *bool  // есть ли предстоящий показ со свободными местами
+}
+
+var playSortKeys = map[string]sortKey[model.Play]{
+	"title":      stringSortKey("plays.title", func(p *model.Play) string { return p.Title }),
+	"author":     stringSortKey("plays.author", func(p *model.Play) string { return p.Author }),
+	"duration":   intSortKey("plays.duration", func(p *model.Play) int { return p.Duration }),
+	"created_at": timeSortKey("plays.created_at", func(p *model.Play) time.Time { return p.CreatedAt }),
+}
+
+// GetAll возвращает страницу спектаклей. У спектаклей подгружаются только
+// предстоящие неотмененные показы, прошедшие сезоны в список не попадают.
+func (r *Plays) GetAll(filter PlayFilter, query listing.Query) (listing.Page[model.Play], error) {
+	filtered := r.db.Model(&model.Play{})
+	if filter.Genre != "" {
+		filtered = filtered.Where("LOWER(plays.genre) = LOWER(?)", filter.Genre)
+	}
+	if filter.Author != "" {
+		filtered = filtered.Where("plays.author ILIKE ?", containsPattern(filter.Author))
+	}
+	if filter.Search != "" {
+		pattern := containsPattern(filter.Search)
+		filtered = filtered.Where("(plays.title ILIKE ? OR plays.author ILIKE ? OR plays.description ILIKE ?)",
+			pattern, pattern, pattern)
+	}
+	if filter.Available != nil {
+		available := "EXISTS (SELECT 1 FROM performances WHERE performances.play_id = plays.id AND " +
+			performanceOnSale + " AND " + performanceHasFreeSeats + ")"
+		if !*filter.Available {
+			available = "NOT " + available
+		}
+		filtered = filtered.Where(available)
+	}
+
+	load := func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Performances", upcomingPerformances)
+	}
+	return paginate(filtered, load, playSortKeys, "plays.id", func(p *model.Play) uuid.UUID { return p.ID }, query)
+}
+
+// upcomingPerformances ограничивает подгружаемые показы спектакля предстоящими неотмененными
+func upcomingPerformances(db *gorm.DB) *gorm.DB {
+	return db.Where("date > now() AND status <> ?", "cancelled").Order("date ASC")
+}
+
+// Маркеры совпадений в ts_headline. Управляющие символы не встречаются в тексте
+// спектаклей, поэтому после экранирования текста их можно заменить тегами.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var headlineTags = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// Search ищет спектакли по названию, автору, жанру и описанию с учетом русской морфологии.
+// text разбирается как поисковая строка: слова в кавычках ищутся фразой, "-слово" исключает
+// слово. Спектакли упорядочены по релевантности и возвращаются с предстоящими показами.
+func (r *Plays) Search(text string, limit int) ([]model.PlayMatch, error) {
+	var hits []struct {
+		ID                  uuid.UUID
+		Rank                float64
+		TitleHeadline       string
+		AuthorHeadline      string
+		DescriptionHeadline string
+	}
+	// Поиск идет без различия е и ё, как и поисковый вектор. Для выделения совпадений
+	// дополнительно берется запрос как есть, так как текст в фрагментах не нормализован.
+	err := r.db.Raw(`
+		SELECT plays.id,
+			ts_rank_cd(plays.search_vector, q.match) AS rank,
+			ts_headline('russian', plays.title, q.highlight, @whole) AS title_headline,
+			ts_headline('russian', plays.author, q.highlight, @whole) AS author_headline,
+			ts_headline('russian', coalesce(plays.description, ''), q.highlight, @fragments) AS description_headline
+		FROM plays, (
+			SELECT websearch_to_tsquery('russian', translate(@text, 'ёЁ', 'еЕ')) AS match,
+				websearch_to_tsquery('russian', translate(@text, 'ёЁ', 'еЕ')) || websearch_to_tsquery('russian', @text) AS highlight
+		) AS q
+		WHERE plays.deleted_at IS NULL AND plays.search_vector @@ q.match
+		ORDER BY rank DESC, plays.title, plays.id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"text":      text,
+			"whole":     headlineOptions("HighlightAll=true"),
+			"fragments": headlineOptions("MaxFragments=2, MinWords=10, MaxWords=30, FragmentDelimiter=\" … \""),
+			"limit":     limit,
+		}).Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return []model.PlayMatch{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
 	var plays []model.Play
-	err := r.db.Preload("Performances").
-		Order("created_at DESC").Find(&plays).Error
-	return plays, err
+	err = r.db.Preload("Performances", upcomingPerformances).Find(&plays, "id IN ?", ids).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]model.Play, len(plays))
+	for _, play := range plays {
+		byID[play.ID] = play
+	}
+
+	matches := make([]model.PlayMatch, 0, len(hits))
+	for _, hit := range hits {
+		play, ok := byID[hit.ID]
+		if !ok {
+			// Спектакль удален между запросами
+			continue
+		}
+		matches = append(matches, model.PlayMatch{
+			Play:                play,
+			Rank:                hit.Rank,
+			TitleHeadline:       highlight(hit.TitleHeadline),
+			AuthorHeadline:      highlight(hit.AuthorHeadline),
+			DescriptionHeadline: highlight(hit.DescriptionHeadline),
+		})
+	}
+	return matches, nil
+}
+
+func headlineOptions(options string) string {
+	return fmt.Sprintf(`StartSel="%s", StopSel="%s", %s`, headlineStart, headlineStop, options)
+}
+
+// highlight экранирует фрагмент ts_headline для HTML и выделяет совпадения тегом <mark>
+func highlight(headline string) string {
+	return headlineTags.Replace(html.EscapeString(headline))
 }
 
 func (r *Plays) GetByID(id uuid.UUID) (*model.Play, error) {
@@ -45,3 +189,7 @@ func (r *Plays) Update(play *model.Play) error {
 func (r *Plays) Delete(id uuid.UUID) error {
 	return r.db.Delete(&model.Play{}, "id = ?", id).Error
 }
+
+func (r *Plays) RecordAudit(entry *model.AuditEntry) error {
+	return recordAudit(r.db, entry)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PricePlans struct {
+	db *gorm.DB
+}
+
+func NewPricePlans(db *gorm.DB) *PricePlans {
+	return &PricePlans{db: db}
+}
+
+func (r *PricePlans) GetAll() ([]model.PricePlan, error) {
+	var plans []model.PricePlan
+	err := r.db.Preload("Tiers", orderByPosition).
+		Preload("DemandSteps", orderByOccupancy).
+		Order("name ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+func (r *PricePlans) GetByID(id uuid.UUID) (*model.PricePlan, error) {
+	var plan model.PricePlan
+	err := r.db.Preload("Tiers", orderByPosition).
+		Preload("DemandSteps", orderByOccupancy).
+		First(&plan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Create сохраняет план вместе с тарифами и ступенями спроса
+func (r *PricePlans) Create(plan *model.PricePlan) error {
+	return r.db.Create(plan).Error
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+func orderByOccupancy(db *gorm.DB) *gorm.DB {
+	return db.Order("occupancy ASC")
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PromoCodes struct {
+	db *gorm.DB
+}
+
+func NewPromoCodes(db *gorm.DB) *PromoCodes {
+	return &PromoCodes{db: db}
+}
+
+func (r *PromoCodes) GetAll() ([]model.PromoCode, error) {
+	var promos []model.PromoCode
+	err := r.db.Order("created_at DESC").Find(&promos).Error
+	return promos, err
+}
+
+func (r *PromoCodes) GetByID(id uuid.UUID) (*model.PromoCode, error) {
+	var promo model.PromoCode
+	err := r.db.First(&promo, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *PromoCodes) Create(promo *model.PromoCode) error {
+	return r.db.Create(promo).Error
+}
+
+// SetActive включает или отключает промокод, не трогая счетчик использований
+func (r *PromoCodes) SetActive(id uuid.UUID, active bool) error {
+	return r.db.Model(&model.PromoCode{}).
+		Where("id = ?", id).
+		Update("active", active).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/ratelimit"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RateLimits хранит счетчики ограничения частоты запросов в Postgres,
+// чтобы лимит был общим для всех экземпляров сервера
+type RateLimits struct {
+	db *gorm.DB
+}
+
+func NewRateLimits(db *gorm.DB) *RateLimits {
+	return &RateLimits{db: db}
+}
+
+// Allow атомарно увеличивает счетчик ключа. Если окно счетчика закончилось,
+// начинается новое.
+func (r *RateLimits) Allow(rule ratelimit.Rule, key string, now time.Time) (ratelimit.Result, error) {
+	var counter struct {
+		WindowStart time.Time
+		Hits        int
+	}
+	err := r.db.Raw(`
+		INSERT INTO rate_limits (key, window_start, hits) VALUES (@key, @now, 1)
+		ON CONFLICT (key) DO UPDATE SET
+			window_start = CASE WHEN rate_limits.window_start <= @expired THEN EXCLUDED.window_start ELSE rate_limits.window_start END,
+			hits = CASE WHEN rate_limits.window_start <= @expired THEN 1 ELSE LEAST(rate_limits.hits + 1, @cap) END
+		RETURNING window_start, hits`,
+		map[string]interface{}{
+			"key":     rule.Name + ":" + key,
+			"now":     now,
+			"expired": now.Add(-rule.Window),
+			"cap":     rule.Limit + 1,
+		}).Scan(&counter).Error
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	return ratelimit.Decide(rule, counter.WindowStart, counter.Hits), nil
+}
+
+// DeleteExpired удаляет счетчики, окна которых начались раньше before
+func (r *RateLimits) DeleteExpired(before time.Time) error {
+	return r.db.Exec("DELETE FROM rate_limits WHERE window_start < ?", before).Error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Refunds struct {
+	db *gorm.DB
+}
+
+func NewRefunds(db *gorm.DB) *Refunds {
+	return &Refunds{db: db}
+}
+
+// GetByBookingID возвращает возвраты бронирования в порядке оформления
+func (r *Refunds) GetByBookingID(bookingID uuid.UUID) ([]model.Refund, error) {
+	var refunds []model.Refund
+	err := r.db.Preload("Items").
+		Where("booking_id = ?", bookingID).
+		Order("created_at ASC").
+		Find(&refunds).Error
+	return refunds, err
+}
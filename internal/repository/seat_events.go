@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SeatEvents struct {
+	db *gorm.DB
+}
+
+func NewSeatEvents(db *gorm.DB) *SeatEvents {
+	return &SeatEvents{db: db}
+}
+
+// GetSince возвращает до limit событий показа с номером seq больше afterSeq по порядку
+func (r *SeatEvents) GetSince(performanceID uuid.UUID, afterSeq int64, limit int) ([]model.SeatEvent, error) {
+	var events []model.SeatEvent
+	err := r.db.Where("performance_id = ? AND seq > ?", performanceID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// GetAfter возвращает до limit событий всех показов с номером seq больше afterSeq
+func (r *SeatEvents) GetAfter(afterSeq int64, limit int) ([]model.SeatEvent, error) {
+	var events []model.SeatEvent
+	err := r.db.Where("seq > ?", afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// Snapshot возвращает текущие статусы мест показа и номер последнего события, которое
+// в них уже учтено. События с большим номером могли произойти после снимка.
+func (r *SeatEvents) Snapshot(performanceID uuid.UUID) (int64, []model.PerformanceSeat, error) {
+	var lastID int64
+	err := r.db.Model(&model.SeatEvent{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Where("performance_id = ?", performanceID).
+		Scan(&lastID).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var seats []model.PerformanceSeat
+	err = r.db.Select("id", "seat_id", "status").
+		Where("performance_id = ?", performanceID).
+		Find(&seats).Error
+	return lastID, seats, err
+}
+
+func (r *SeatEvents) PerformanceExists(id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Performance{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteBefore удаляет события старше before; клиент, отставший сильнее,
+// получит снимок схемы вместо пропущенных событий
+func (r *SeatEvents) DeleteBefore(before time.Time) error {
+	return r.db.Where("created_at < ?", before).Delete(&model.SeatEvent{}).Error
+}
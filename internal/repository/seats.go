@@ -18,7 +18,7 @@ func NewSeats(db *gorm.DB) *Seats {
 func (r *Seats) GetByHallID(hallID uuid.UUID) ([]model.Seat, error) {
 	var seats []model.Seat
 	err := r.db.Where("hall_id = ?", hallID).
-		Order("row ASC, number ASC").
+		Order("section ASC, row ASC, number ASC").
 		Find(&seats).Error
 	return seats, err
 }
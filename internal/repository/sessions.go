@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Sessions struct {
+	db *gorm.DB
+}
+
+func NewSessions(db *gorm.DB) *Sessions {
+	return &Sessions{db: db}
+}
+
+func (r *Sessions) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *Sessions) GetByID(id uuid.UUID) (*model.Session, error) {
+	var session model.Session
+	err := r.db.First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Revoke отзывает сессию. Возвращает false, если сессия уже была отозвана.
+func (r *Sessions) Revoke(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
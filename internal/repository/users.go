@@ -37,3 +37,9 @@ func (r *Users) GetByID(id uuid.UUID) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *Users) UpdateRole(id uuid.UUID, role string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", id).
+		Update("role", role).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrActiveWaitlistEntry - у покупателя уже есть действующая заявка на показ
+var ErrActiveWaitlistEntry = errors.New("active waitlist entry already exists")
+
+type Waitlist struct {
+	db *gorm.DB
+}
+
+func NewWaitlist(db *gorm.DB) *Waitlist {
+	return &Waitlist{db: db}
+}
+
+// Create сохраняет заявку. Если параллельный запрос уже создал действующую заявку
+// того же покупателя на тот же показ, возвращается ErrActiveWaitlistEntry.
+func (r *Waitlist) Create(entry *model.WaitlistEntry) error {
+	err := r.db.Create(entry).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_waitlist_entries_active" {
+		return ErrActiveWaitlistEntry
+	}
+	return err
+}
+
+// GetActive возвращает ожидающую заявку покупателя или заявку с действующим предложением
+func (r *Waitlist) GetActive(performanceID, userID uuid.UUID) (*model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	err := r.db.Preload("Booking").
+		Where("performance_id = ? AND user_id = ? AND status IN ?", performanceID, userID, []string{"waiting", "offered"}).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Position возвращает номер заявки в очереди ожидания показа, начиная с 1
+func (r *Waitlist) Position(entry *model.WaitlistEntry) (int, error) {
+	var ahead int64
+	err := r.db.Model(&model.WaitlistEntry{}).
+		Where("performance_id = ? AND status = ? AND created_at < ?", entry.PerformanceID, "waiting", entry.CreatedAt).
+		Count(&ahead).Error
+	return int(ahead) + 1, err
+}
+
+// CancelWaiting снимает ожидающую заявку покупателя. Возвращает false, если такой заявки нет.
+func (r *Waitlist) CancelWaiting(performanceID, userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&model.WaitlistEntry{}).
+		Where("performance_id = ? AND user_id = ? AND status = ?", performanceID, userID, "waiting").
+		Update("status", "cancelled")
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountAvailableSeats возвращает число свободных мест показа нужной категории
+// (любой, если category пуста)
+func (r *Waitlist) CountAvailableSeats(performanceID uuid.UUID, category string) (int64, error) {
+	query := r.db.Model(&model.PerformanceSeat{}).
+		Joins("JOIN seats ON seats.id = performance_seats.seat_id").
+		Where("performance_seats.performance_id = ?", performanceID).
+		Where(seatFree)
+	if category != "" {
+		query = query.Where("seats.category = ?", category)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *Waitlist) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	var performance model.Performance
+	if err := r.db.First(&performance, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &performance, nil
+}
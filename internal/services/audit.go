@@ -0,0 +1,234 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// ErrInvalidAuditCursor - курсор не выдан журналом аудита
+var ErrInvalidAuditCursor = errors.New("invalid cursor")
+
+// Типы сущностей в журнале аудита
+const (
+	AuditPlay        = "play"
+	AuditPerformance = "performance"
+	AuditHall        = "hall"
+	AuditBooking     = "booking"
+	AuditWaitlist    = "waitlist_entry"
+)
+
+// systemActor - фоновые процессы и платежная система: у них нет ни пользователя, ни IP
+var systemActor = model.Actor{}
+
+// AuditFilter - условия выборки журнала аудита
+type AuditFilter = repository.AuditFilter
+
+type AuditRepository interface {
+	List(filter repository.AuditFilter, beforeID int64, limit int) ([]model.AuditEntry, error)
+}
+
+// AuditRecorder - транзакция, в которой запись аудита сохраняется вместе с изменением
+type AuditRecorder interface {
+	RecordAudit(entry *model.AuditEntry) error
+}
+
+// Audit - журнал изменений каталога, расписания, залов и бронирований
+type Audit struct {
+	repo  AuditRepository
+	clock Clock
+}
+
+func NewAudit(repo AuditRepository, clock Clock) *Audit {
+	return &Audit{
+		repo:  repo,
+		clock: clock,
+	}
+}
+
+// List возвращает страницу журнала от новых записей к старым и курсор следующей страницы.
+// Пустой cursor - первая страница; пустой курсор в ответе означает, что записей больше нет.
+func (s *Audit) List(filter AuditFilter, cursor string, limit int) ([]model.AuditEntry, string, error) {
+	var beforeID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", ErrInvalidAuditCursor
+		}
+		beforeID = id
+	}
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit > maxAuditPageSize {
+		limit = maxAuditPageSize
+	}
+
+	// Лишняя запись показывает, есть ли следующая страница
+	entries, err := s.repo.List(filter, beforeID, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		next = strconv.FormatInt(entries[limit-1].ID, 10)
+	}
+	return entries, next, nil
+}
+
+// recordAudit пишет в транзакции tx, кто и как изменил сущность. before - состояние
+// до изменения (nil при создании), after - после (nil при удалении). Если журнал
+// не настроен, запись пропускается.
+func recordAudit(audit *Audit, tx AuditRecorder, actor model.Actor, action, entityType string, entityID uuid.UUID, before, after auditFields) error {
+	if audit == nil {
+		return nil
+	}
+
+	changes, err := json.Marshal(diffAuditFields(before, after))
+	if err != nil {
+		return err
+	}
+
+	return tx.RecordAudit(&model.AuditEntry{
+		ActorID:    actor.UserID,
+		ActorEmail: actor.Email,
+		ActorIP:    actor.IP,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    string(changes),
+		CreatedAt:  audit.clock.Now(),
+	})
+}
+
+// auditFields - отслеживаемые поля сущности в том виде, в каком они попадут в журнал
+type auditFields map[string]interface{}
+
+type auditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diffAuditFields оставляет только поля, значение которых изменилось
+func diffAuditFields(before, after auditFields) map[string]auditChange {
+	changes := make(map[string]auditChange)
+	diff := func(field string) {
+		if !sameAuditValue(before[field], after[field]) {
+			changes[field] = auditChange{Before: before[field], After: after[field]}
+		}
+	}
+	for field := range before {
+		diff(field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			diff(field)
+		}
+	}
+	return changes
+}
+
+// sameAuditValue сравнивает значения по их JSON-представлению, как они хранятся в журнале
+func sameAuditValue(a, b interface{}) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+func playAudit(play *model.Play) auditFields {
+	return auditFields{
+		"title":       play.Title,
+		"author":      play.Author,
+		"description": play.Description,
+		"duration":    play.Duration,
+		"poster_url":  play.PosterURL,
+		"genre":       play.Genre,
+	}
+}
+
+func performanceAudit(performance *model.Performance) auditFields {
+	return auditFields{
+		"play_id":         performance.PlayID,
+		"hall_id":         performance.HallID,
+		"date":            performance.Date.UTC(),
+		"status":          performance.Status,
+		"premiere":        performance.Premiere,
+		"price_plan_id":   performance.PricePlanID,
+		"postponed_to_id": performance.PostponedToID,
+	}
+}
+
+// hallAudit описывает зал; если передана схема мест, в журнал попадает
+// число мест каждой категории
+func hallAudit(hall *model.Hall, seats []model.Seat) auditFields {
+	fields := auditFields{
+		"name":     hall.Name,
+		"capacity": hall.Capacity,
+	}
+	if seats != nil {
+		categories := make(map[string]int)
+		for _, seat := range seats {
+			categories[seat.Category]++
+		}
+		fields["seats"] = categories
+	}
+	return fields
+}
+
+// bookingAudit описывает бронирование с местами seats
+func bookingAudit(booking *model.Booking, seats []model.PerformanceSeat) auditFields {
+	seatIDs := make([]string, len(seats))
+	for i, seat := range seats {
+		seatIDs[i] = seat.ID.String()
+	}
+	sort.Strings(seatIDs)
+
+	return auditFields{
+		"user_id":        booking.UserID,
+		"performance_id": booking.PerformanceID,
+		"status":         booking.Status,
+		"subtotal":       booking.Subtotal,
+		"discount":       booking.Discount,
+		"total_price":    booking.TotalPrice,
+		"promo_code_id":  booking.PromoCodeID,
+		"reminders":      booking.RemindersEnabled,
+		"seat_ids":       seatIDs,
+	}
+}
+
+// paymentAudit описывает платеж бронирования
+func paymentAudit(payment *model.Payment) auditFields {
+	return auditFields{
+		"payment_id":     payment.ID,
+		"payment_status": payment.Status,
+	}
+}
+
+func waitlistAudit(entry *model.WaitlistEntry) auditFields {
+	return auditFields{
+		"performance_id": entry.PerformanceID,
+		"user_id":        entry.UserID,
+		"seats":          entry.Seats,
+		"category":       entry.Category,
+		"status":         entry.Status,
+		"booking_id":     entry.BookingID,
+	}
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testActorID = uuid.New()
+
+// testActor - сотрудник, от имени которого тесты выполняют изменения
+var testActor = model.Actor{UserID: &testActorID, Email: "manager@example.com", Role: model.RoleManager, IP: "192.0.2.1"}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) List(filter repository.AuditFilter, beforeID int64, limit int) ([]model.AuditEntry, error) {
+	args := m.Called(filter, beforeID, limit)
+	return args.Get(0).([]model.AuditEntry), args.Error(1)
+}
+
+func auditChanges(t *testing.T, entry *model.AuditEntry) map[string]auditChange {
+	var changes map[string]auditChange
+	require.NoError(t, json.Unmarshal([]byte(entry.Changes), &changes))
+	return changes
+}
+
+func TestDiffAuditFields(t *testing.T) {
+	planID := uuid.New()
+
+	t.Run("only changed fields", func(t *testing.T) {
+		changes := diffAuditFields(
+			auditFields{"title": "Гамлет", "duration": 180, "price_plan_id": (*uuid.UUID)(nil)},
+			auditFields{"title": "Гамлет", "duration": 200, "price_plan_id": &planID},
+		)
+
+		assert.Equal(t, map[string]auditChange{
+			"duration":      {Before: 180, After: 200},
+			"price_plan_id": {Before: (*uuid.UUID)(nil), After: &planID},
+		}, changes)
+	})
+
+	t.Run("created and deleted", func(t *testing.T) {
+		created := diffAuditFields(nil, auditFields{"title": "Гамлет", "price_plan_id": (*uuid.UUID)(nil)})
+		assert.Equal(t, map[string]auditChange{"title": {Before: nil, After: "Гамлет"}}, created)
+
+		deleted := diffAuditFields(auditFields{"title": "Гамлет"}, nil)
+		assert.Equal(t, map[string]auditChange{"title": {Before: "Гамлет", After: nil}}, deleted)
+	})
+
+	t.Run("same instant in another zone", func(t *testing.T) {
+		date := time.Date(2025, 5, 1, 19, 0, 0, 0, time.UTC)
+		moscow := time.FixedZone("MSK", 3*60*60)
+		changes := diffAuditFields(
+			performanceAudit(&model.Performance{Date: date}),
+			performanceAudit(&model.Performance{Date: date.In(moscow)}),
+		)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestAuditList(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	entityID := uuid.New()
+	filter := AuditFilter{EntityType: AuditPlay, EntityID: &entityID}
+
+	t.Run("next cursor when more entries exist", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		service := NewAudit(repo, clock)
+		repo.On("List", filter, int64(0), 3).
+			Return([]model.AuditEntry{{ID: 30}, {ID: 20}, {ID: 10}}, nil)
+
+		entries, next, err := service.List(filter, "", 2)
+
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "20", next)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		service := NewAudit(repo, clock)
+		repo.On("List", filter, int64(20), defaultAuditPageSize+1).
+			Return([]model.AuditEntry{{ID: 10}}, nil)
+
+		entries, next, err := service.List(filter, "20", 0)
+
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Empty(t, next)
+	})
+
+	t.Run("page size is capped", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		service := NewAudit(repo, clock)
+		repo.On("List", filter, int64(0), maxAuditPageSize+1).Return([]model.AuditEntry{}, nil)
+
+		_, _, err := service.List(filter, "", 10000)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		service := NewAudit(repo, clock)
+
+		_, _, err := service.List(filter, "abc", 10)
+
+		assert.EqualError(t, err, "invalid cursor")
+		repo.AssertNotCalled(t, "List")
+	})
+}
+
+func TestPlaysAudit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	audit := NewAudit(new(MockAuditRepository), clock)
+
+	t.Run("create records actor and all fields", func(t *testing.T) {
+		repo := new(MockPlaysRepository)
+		service := NewPlays(repo, audit)
+		play := &model.Play{Title: "Гамлет", Author: "Шекспир", Duration: 180}
+
+		var entry *model.AuditEntry
+		repo.On("Create", play).Return(nil)
+		repo.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(0).(*model.AuditEntry)
+		}).Return(nil)
+
+		require.NoError(t, service.CreatePlay(testActor, play))
+
+		require.NotNil(t, entry)
+		assert.Equal(t, &testActorID, entry.ActorID)
+		assert.Equal(t, "manager@example.com", entry.ActorEmail)
+		assert.Equal(t, "192.0.2.1", entry.ActorIP)
+		assert.Equal(t, "play.create", entry.Action)
+		assert.Equal(t, AuditPlay, entry.EntityType)
+		assert.Equal(t, play.ID, entry.EntityID)
+		assert.Equal(t, clock.now, entry.CreatedAt)
+
+		changes := auditChanges(t, entry)
+		assert.Equal(t, auditChange{Before: nil, After: "Гамлет"}, changes["title"])
+		assert.Equal(t, auditChange{Before: nil, After: float64(180)}, changes["duration"])
+		assert.Equal(t, auditChange{Before: nil, After: ""}, changes["genre"])
+	})
+
+	t.Run("update records only changed fields", func(t *testing.T) {
+		repo := new(MockPlaysRepository)
+		service := NewPlays(repo, audit)
+		playID := uuid.New()
+		existing := &model.Play{ID: playID, Title: "Гамлет", Author: "Шекспир", Duration: 180}
+		updated := &model.Play{Title: "Гамлет", Author: "Шекспир", Duration: 200}
+
+		var entry *model.AuditEntry
+		repo.On("GetByID", playID).Return(existing, nil)
+		repo.On("Update", updated).Return(nil)
+		repo.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(0).(*model.AuditEntry)
+		}).Return(nil)
+
+		require.NoError(t, service.UpdatePlay(testActor, playID.String(), updated))
+
+		require.NotNil(t, entry)
+		assert.Equal(t, "play.update", entry.Action)
+		assert.Equal(t, map[string]auditChange{
+			"duration": {Before: float64(180), After: float64(200)},
+		}, auditChanges(t, entry))
+	})
+
+	t.Run("audit failure rolls back the change", func(t *testing.T) {
+		repo := new(MockPlaysRepository)
+		service := NewPlays(repo, audit)
+		playID := uuid.New()
+
+		repo.On("GetByID", playID).Return(&model.Play{ID: playID, Title: "Гамлет"}, nil)
+		repo.On("Delete", playID).Return(nil)
+		repo.On("RecordAudit", mock.Anything).Return(assert.AnError)
+
+		err := service.DeletePlay(testActor, playID.String())
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestCancelBookingAudit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	repo := new(MockBookingsRepository)
+	service := NewBookings(repo, new(MockUsersRepository), WithBookingsAudit(NewAudit(new(MockAuditRepository), clock)))
+
+	seatID := uuid.New()
+	booking := &model.Booking{
+		ID:               uuid.New(),
+		Status:           "pending",
+		PerformanceSeats: []model.PerformanceSeat{{ID: seatID}},
+	}
+
+	var entry *model.AuditEntry
+	repo.On("LockByID", booking.ID).Return(booking, nil)
+	repo.On("UpdateStatus", booking.ID, "cancelled").Return(nil)
+	repo.On("UpdatePerformanceSeatStatus", seatID, "available", (*uuid.UUID)(nil)).Return(nil)
+	repo.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*model.AuditEntry)
+	}).Return(nil)
+
+	require.NoError(t, service.CancelBooking(testActor, booking.ID.String()))
+
+	require.NotNil(t, entry)
+	assert.Equal(t, "booking.cancel", entry.Action)
+	assert.Equal(t, AuditBooking, entry.EntityType)
+	assert.Equal(t, booking.ID, entry.EntityID)
+	changes := auditChanges(t, entry)
+	assert.Equal(t, auditChange{Before: "pending", After: "cancelled"}, changes["status"])
+	assert.Equal(t, auditChange{Before: []interface{}{seatID.String()}, After: []interface{}{}}, changes["seat_ids"])
+}
+
+func TestBackgroundAudit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	audit := NewAudit(new(MockAuditRepository), clock)
+
+	t.Run("expired booking", func(t *testing.T) {
+		tx := new(MockBookingsRepository)
+		worker := NewBookingExpiry(new(MockBookingExpiryRepository), clock, time.Minute, nil, audit)
+		booking := model.Booking{ID: uuid.New(), PerformanceID: uuid.New(), Status: "expired"}
+
+		var entry *model.AuditEntry
+		tx.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(0).(*model.AuditEntry)
+		}).Return(nil)
+
+		require.NoError(t, worker.released(tx, []model.Booking{booking}))
+
+		require.NotNil(t, entry)
+		assert.Nil(t, entry.ActorID)
+		assert.Empty(t, entry.ActorIP)
+		assert.Equal(t, "booking.expire", entry.Action)
+		assert.Equal(t, AuditBooking, entry.EntityType)
+		assert.Equal(t, booking.ID, entry.EntityID)
+		assert.Equal(t, map[string]auditChange{
+			"status": {Before: "pending", After: "expired"},
+		}, auditChanges(t, entry))
+	})
+
+	t.Run("closed waitlist offer", func(t *testing.T) {
+		tx := new(MockBookingsRepository)
+		waitlist := NewWaitlist(new(MockWaitlistRepository), tx, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, audit)
+		booking := model.Booking{ID: uuid.New(), PerformanceID: uuid.New(), Status: "cancelled"}
+		closed := model.WaitlistEntry{ID: uuid.New(), PerformanceID: booking.PerformanceID, Status: "cancelled", BookingID: &booking.ID}
+
+		var entry *model.AuditEntry
+		tx.On("CloseWaitlistOffers", []uuid.UUID{booking.ID}, "cancelled").Return([]model.WaitlistEntry{closed}, nil)
+		tx.On("LockWaitlist", booking.PerformanceID).Return([]model.WaitlistEntry{}, nil)
+		tx.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(0).(*model.AuditEntry)
+		}).Return(nil)
+
+		require.NoError(t, waitlist.SeatsReleased(tx, []model.Booking{booking}))
+
+		require.NotNil(t, entry)
+		assert.Nil(t, entry.ActorID)
+		assert.Equal(t, "waitlist.close_offer", entry.Action)
+		assert.Equal(t, AuditWaitlist, entry.EntityType)
+		assert.Equal(t, closed.ID, entry.EntityID)
+		assert.Equal(t, map[string]auditChange{
+			"status": {Before: "offered", After: "cancelled"},
+		}, auditChanges(t, entry))
+	})
+
+	t.Run("refunded payment", func(t *testing.T) {
+		tx := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewPayments(new(MockPaymentsRepository), tx, provider, clock, "BYN", nil, audit)
+		bookingID := uuid.New()
+		intent, err := provider.CreateIntent(bookingID, 1000, "BYN")
+		require.NoError(t, err)
+		_, err = provider.Capture(intent.ID, "tok_visa")
+		require.NoError(t, err)
+		payment := &model.Payment{ID: uuid.New(), BookingID: bookingID, IntentID: intent.ID, Amount: 1000, Status: "pending"}
+
+		var entry *model.AuditEntry
+		tx.On("LockPayment", payment.ID).Return(&model.Payment{ID: payment.ID, BookingID: bookingID, IntentID: intent.ID, Amount: 1000, Status: "pending"}, nil)
+		tx.On("UpdatePayment", mock.Anything).Return(nil)
+		tx.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(0).(*model.AuditEntry)
+		}).Return(nil)
+
+		service.refundCaptured(payment)
+
+		assert.Equal(t, "refunded", payment.Status)
+		require.NotNil(t, entry)
+		assert.Nil(t, entry.ActorID)
+		assert.Equal(t, "payment.refund", entry.Action)
+		assert.Equal(t, AuditBooking, entry.EntityType)
+		assert.Equal(t, bookingID, entry.EntityID)
+		assert.Equal(t, map[string]auditChange{
+			"payment_status": {Before: "pending", After: "refunded"},
+		}, auditChanges(t, entry))
+	})
+
+	t.Run("performance lifecycle", func(t *testing.T) {
+		repo := new(MockLifecycleRepository)
+		lifecycle := NewPerformanceLifecycle(repo, clock, time.Hour, time.Minute, audit)
+		onSale := model.Performance{ID: uuid.New(), Status: PerformanceOnSale}
+		finished := model.Performance{ID: uuid.New(), Status: PerformanceSalesClosed}
+
+		var entries []*model.AuditEntry
+		repo.On("CloseSales", clock.now.Add(time.Hour)).Return([]model.Performance{onSale}, nil)
+		repo.On("CompleteFinished", clock.now).Return([]model.Performance{finished}, nil)
+		repo.On("RecordAudit", mock.Anything).Run(func(args mock.Arguments) {
+			entries = append(entries, args.Get(0).(*model.AuditEntry))
+		}).Return(nil)
+
+		_, _, err := lifecycle.AdvanceOnce()
+
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "performance.close_sales", entries[0].Action)
+		assert.Equal(t, onSale.ID, entries[0].EntityID)
+		assert.Equal(t, map[string]auditChange{
+			"status": {Before: PerformanceOnSale, After: PerformanceSalesClosed},
+		}, auditChanges(t, entries[0]))
+		assert.Equal(t, "performance.complete", entries[1].Action)
+		assert.Equal(t, finished.ID, entries[1].EntityID)
+		assert.Equal(t, map[string]auditChange{
+			"status": {Before: PerformanceSalesClosed, After: PerformanceCompleted},
+		}, auditChanges(t, entries[1]))
+	})
+
+	t.Run("audit failure rolls back closing sales", func(t *testing.T) {
+		repo := new(MockLifecycleRepository)
+		lifecycle := NewPerformanceLifecycle(repo, clock, time.Hour, time.Minute, audit)
+
+		repo.On("CloseSales", mock.Anything).Return([]model.Performance{{ID: uuid.New(), Status: PerformanceOnSale}}, nil)
+		repo.On("RecordAudit", mock.Anything).Return(assert.AnError)
+
+		_, _, err := lifecycle.AdvanceOnce()
+
+		assert.ErrorIs(t, err, assert.AnError)
+		repo.AssertNotCalled(t, "CompleteFinished", mock.Anything)
+	})
+}
@@ -1,39 +1,118 @@
 package service
 
 import (
+	"crypto/subtle"
 	"errors"
 	"theater-ticket-system/internal/models/models"
-	"theater-ticket-system/internal/repository"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AuthRepository interface {
+	Transaction(fn func(tx AuthTx) error) error
+	GetActiveVerification(email string, now time.Time) (*model.EmailVerification, error)
+	ClaimAttempt(id string, maxAttempts int) (int, bool, error)
+	MarkVerificationUsed(id string) (bool, error)
+	DeleteVerificationsBefore(before time.Time) error
+}
+
+// AuthTx - операции с кодами подтверждения, доступные внутри транзакции
+type AuthTx interface {
+	EmailEnqueuer
+	LockEmail(email string) error
+	GetRecentVerifications(email string, since time.Time) ([]model.EmailVerification, error)
 	CreateVerification(verification *model.EmailVerification) error
-	GetVerification(email, code string) (*model.EmailVerification, error)
-	MarkVerificationUsed(id string) error
-	DeleteExpiredVerifications() error
+}
+
+var (
+	ErrCodeInvalid = errors.New("invalid or expired code")
+	ErrCodeLocked  = errors.New("too many failed attempts, request a new code")
+)
+
+// RetryLaterError - запрос отклонен из-за ограничения частоты, повторить можно через RetryAfter
+type RetryLaterError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryLaterError) Error() string {
+	return "too many requests, try again later"
+}
+
+// AuthLimits - ограничения на отправку и проверку кодов подтверждения
+type AuthLimits struct {
+	CodesPerEmail int           // кодов на один адрес за CodesWindow
+	CodesWindow   time.Duration // за это время помнится история отправок
+	CooldownBase  time.Duration // пауза после первого кода, удваивается после каждого следующего
+	CooldownMax   time.Duration
+	MaxAttempts   int // неверных попыток, после которых код блокируется
 }
 
 type Auth struct {
 	repo         AuthRepository
+	usersRepo    UsersRepository
+	tokens       *Tokens
 	emailService *EmailService
+	clock        Clock
+	limits       AuthLimits
 }
 
-func NewAuth(repo *repository.Auth, emailService *EmailService) *Auth {
+func NewAuth(repo AuthRepository, usersRepo UsersRepository, tokens *Tokens, emailService *EmailService, clock Clock, limits AuthLimits) *Auth {
 	return &Auth{
 		repo:         repo,
+		usersRepo:    usersRepo,
+		tokens:       tokens,
 		emailService: emailService,
+		clock:        clock,
+		limits:       limits,
+	}
+}
+
+// codeCooldown возвращает паузу, которую нужно выдержать после sent отправленных кодов
+func (s *Auth) codeCooldown(sent int) time.Duration {
+	cooldown := s.limits.CooldownBase
+	for i := 1; i < sent; i++ {
+		cooldown *= 2
+		if cooldown >= s.limits.CooldownMax {
+			return s.limits.CooldownMax
+		}
+	}
+	return cooldown
+}
+
+// checkSendLimits проверяет, можно ли сейчас отправить на адрес еще один код
+func (s *Auth) checkSendLimits(tx AuthTx, email string, now time.Time) error {
+	recent, err := tx.GetRecentVerifications(email, now.Add(-s.limits.CodesWindow))
+	if err != nil {
+		return errors.New("failed to check verification history")
+	}
+	if len(recent) == 0 {
+		return nil
+	}
+
+	if len(recent) >= s.limits.CodesPerEmail {
+		// Лимит освободится, когда самый ранний код выйдет из окна
+		oldest := recent[len(recent)-s.limits.CodesPerEmail]
+		return &RetryLaterError{RetryAfter: oldest.CreatedAt.Add(s.limits.CodesWindow).Sub(now)}
+	}
+
+	last := recent[len(recent)-1]
+	if next := last.CreatedAt.Add(s.codeCooldown(len(recent))); now.Before(next) {
+		return &RetryLaterError{RetryAfter: next.Sub(now)}
 	}
+	return nil
 }
 
-// SendVerificationCode отправляет код подтверждения на email
+// SendVerificationCode отправляет код подтверждения на email. Каждый следующий код
+// на тот же адрес можно запросить после вдвое большей паузы.
 func (s *Auth) SendVerificationCode(email string) error {
 	if email == "" {
 		return errors.New("email is required")
 	}
 
+	now := s.clock.Now()
+
 	// Генерируем код
 	code, err := s.emailService.GenerateCode()
 	if err != nil {
@@ -45,40 +124,94 @@ func (s *Auth) SendVerificationCode(email string) error {
 		ID:        uuid.New(),
 		Email:     email,
 		Code:      code,
-		ExpiresAt: time.Now().Add(10 * time.Minute),
+		ExpiresAt: now.Add(verificationCodeTTL),
 		Used:      false,
+		CreatedAt: now,
 	}
 
-	if err := s.repo.CreateVerification(verification); err != nil {
-		return errors.New("failed to save verification code")
-	}
-
-	// Отправляем код на email
-	if err := s.emailService.SendVerificationCode(email, code); err != nil {
-		return errors.New("failed to send email")
-	}
-
-	return nil
+	// Лимиты проверяются под блокировкой адреса, иначе параллельные запросы
+	// увидят одну и ту же историю и отправят больше кодов, чем разрешено
+	return s.repo.Transaction(func(tx AuthTx) error {
+		if err := tx.LockEmail(email); err != nil {
+			return errors.New("failed to check verification history")
+		}
+		if err := s.checkSendLimits(tx, email, now); err != nil {
+			return err
+		}
+		if err := tx.CreateVerification(verification); err != nil {
+			return errors.New("failed to save verification code")
+		}
+		// Письмо уходит в outbox вместе с кодом: без сохраненного кода оно не отправится
+		if err := s.emailService.SendVerificationCode(tx, email, code); err != nil {
+			return errors.New("failed to send email")
+		}
+		return nil
+	})
 }
 
-// VerifyCode проверяет код подтверждения
-func (s *Auth) VerifyCode(email, code string) (bool, error) {
+// VerifyCode проверяет код подтверждения и открывает сессию пользователя.
+// После MaxAttempts неверных попыток код блокируется, и нужно запросить новый.
+// Попытка засчитывается до сравнения кода, поэтому параллельные запросы
+// не могут перебрать больше MaxAttempts кодов.
+func (s *Auth) VerifyCode(email, code string) (*TokenPair, error) {
 	if email == "" || code == "" {
-		return false, errors.New("email and code are required")
+		return nil, errors.New("email and code are required")
 	}
 
-	verification, err := s.repo.GetVerification(email, code)
+	now := s.clock.Now()
+	verification, err := s.repo.GetActiveVerification(email, now)
 	if err != nil {
-		return false, errors.New("invalid or expired code")
+		return nil, ErrCodeInvalid
+	}
+
+	attempts, ok, err := s.repo.ClaimAttempt(verification.ID.String(), s.limits.MaxAttempts)
+	if err != nil {
+		return nil, errors.New("failed to verify code")
+	}
+	if !ok {
+		return nil, ErrCodeLocked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(code), []byte(verification.Code)) != 1 {
+		if attempts >= s.limits.MaxAttempts {
+			return nil, ErrCodeLocked
+		}
+		return nil, ErrCodeInvalid
 	}
 
 	// Помечаем код как использованный
-	if err := s.repo.MarkVerificationUsed(verification.ID.String()); err != nil {
-		return false, errors.New("failed to mark code as used")
+	marked, err := s.repo.MarkVerificationUsed(verification.ID.String())
+	if err != nil {
+		return nil, errors.New("failed to mark code as used")
+	}
+	if !marked {
+		return nil, ErrCodeInvalid
 	}
 
 	// Очищаем старые коды
-	go s.repo.DeleteExpiredVerifications()
+	go s.repo.DeleteVerificationsBefore(now.Add(-s.limits.CodesWindow))
+
+	// Находим или создаем пользователя с подтвержденным email
+	user, err := s.usersRepo.FindByEmail(email)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.New("failed to find user")
+		}
+		user = &model.User{Email: email}
+		if err := s.usersRepo.Create(user); err != nil {
+			return nil, errors.New("failed to create user")
+		}
+	}
+
+	return s.tokens.Issue(user)
+}
+
+// Refresh выдает новую пару токенов по refresh-токену
+func (s *Auth) Refresh(refreshToken string) (*TokenPair, error) {
+	return s.tokens.Refresh(refreshToken)
+}
 
-	return true, nil
+// Logout отзывает сессию refresh-токена
+func (s *Auth) Logout(refreshToken string) error {
+	return s.tokens.Revoke(refreshToken)
 }
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memAuthRepo struct {
+	mu            sync.Mutex
+	txMu          sync.Mutex // блокировка адреса держится до конца транзакции
+	verifications []*model.EmailVerification
+	emails        []*model.OutboxMessage
+}
+
+// memAuthTx отпускает блокировку адреса при завершении транзакции
+type memAuthTx struct {
+	*memAuthRepo
+	locked bool
+}
+
+func (tx *memAuthTx) LockEmail(email string) error {
+	if !tx.locked {
+		tx.txMu.Lock()
+		tx.locked = true
+	}
+	return nil
+}
+
+func (r *memAuthRepo) Transaction(fn func(tx AuthTx) error) error {
+	tx := &memAuthTx{memAuthRepo: r}
+	defer func() {
+		if tx.locked {
+			r.txMu.Unlock()
+		}
+	}()
+	return fn(tx)
+}
+
+func (r *memAuthRepo) CreateVerification(verification *model.EmailVerification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *verification
+	r.verifications = append(r.verifications, &copied)
+	return nil
+}
+
+func (r *memAuthRepo) EnqueueEmail(message *model.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = append(r.emails, message)
+	return nil
+}
+
+func (r *memAuthRepo) GetActiveVerification(email string, now time.Time) (*model.EmailVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.verifications) - 1; i >= 0; i-- {
+		v := r.verifications[i]
+		if v.Email == email && !v.Used && v.ExpiresAt.After(now) {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *memAuthRepo) GetRecentVerifications(email string, since time.Time) ([]model.EmailVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var recent []model.EmailVerification
+	for _, v := range r.verifications {
+		if v.Email == email && v.CreatedAt.After(since) {
+			recent = append(recent, *v)
+		}
+	}
+	return recent, nil
+}
+
+func (r *memAuthRepo) ClaimAttempt(id string, maxAttempts int) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.verifications {
+		if v.ID.String() == id && !v.Used && v.Attempts < maxAttempts {
+			v.Attempts++
+			return v.Attempts, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (r *memAuthRepo) MarkVerificationUsed(id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.verifications {
+		if v.ID.String() == id && !v.Used {
+			v.Used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memAuthRepo) DeleteVerificationsBefore(before time.Time) error {
+	return nil
+}
+
+func (r *memAuthRepo) lastCode() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.verifications[len(r.verifications)-1].Code
+}
+
+// testAuthLimits - не больше 3 кодов в час с паузой от 30 до 90 секунд и 3 попытки ввода
+var testAuthLimits = AuthLimits{
+	CodesPerEmail: 3,
+	CodesWindow:   time.Hour,
+	CooldownBase:  30 * time.Second,
+	CooldownMax:   90 * time.Second,
+	MaxAttempts:   3,
+}
+
+func TestSendVerificationCodeLimits(t *testing.T) {
+	t.Run("cooldown doubles with each code", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+		start := clock.now
+
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+
+		clock.now = start.Add(10 * time.Second)
+		err := auth.SendVerificationCode("anna@example.com")
+		var retry *RetryLaterError
+		require.ErrorAs(t, err, &retry)
+		assert.Equal(t, 20*time.Second, retry.RetryAfter)
+
+		clock.now = start.Add(30 * time.Second)
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+
+		clock.now = start.Add(60 * time.Second)
+		err = auth.SendVerificationCode("anna@example.com")
+		require.ErrorAs(t, err, &retry)
+		assert.Equal(t, 30*time.Second, retry.RetryAfter)
+
+		// Другой адрес не ограничен
+		assert.NoError(t, auth.SendVerificationCode("boris@example.com"))
+	})
+
+	t.Run("limits codes per email within window", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+		start := clock.now
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+			clock.now = clock.now.Add(10 * time.Minute)
+		}
+
+		err := auth.SendVerificationCode("anna@example.com")
+		var retry *RetryLaterError
+		require.ErrorAs(t, err, &retry)
+		assert.Equal(t, start.Add(time.Hour).Sub(clock.now), retry.RetryAfter)
+
+		clock.now = start.Add(time.Hour)
+		assert.NoError(t, auth.SendVerificationCode("anna@example.com"))
+	})
+
+	t.Run("concurrent requests send one code", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				auth.SendVerificationCode("anna@example.com")
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, repo.verifications, 1)
+		assert.Len(t, repo.emails, 1)
+	})
+}
+
+func TestVerifyCode(t *testing.T) {
+	t.Run("opens session with correct code once", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+		user := &model.User{ID: uuid.New(), Email: "anna@example.com"}
+		users.On("FindByEmail", "anna@example.com").Return(user, nil)
+
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+		code := repo.lastCode()
+
+		pair, err := auth.VerifyCode("anna@example.com", code)
+		require.NoError(t, err)
+		assert.NotEmpty(t, pair.AccessToken)
+
+		_, err = auth.VerifyCode("anna@example.com", code)
+		assert.Equal(t, ErrCodeInvalid, err)
+	})
+
+	t.Run("locks code after too many failed attempts", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+		code := repo.lastCode()
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+
+		_, err := auth.VerifyCode("anna@example.com", wrong)
+		assert.Equal(t, ErrCodeInvalid, err)
+		_, err = auth.VerifyCode("anna@example.com", wrong)
+		assert.Equal(t, ErrCodeInvalid, err)
+		_, err = auth.VerifyCode("anna@example.com", wrong)
+		assert.Equal(t, ErrCodeLocked, err)
+
+		// Правильный код после блокировки тоже не принимается
+		_, err = auth.VerifyCode("anna@example.com", code)
+		assert.Equal(t, ErrCodeLocked, err)
+	})
+
+	t.Run("concurrent guesses are limited", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+		wrong := "000000"
+		if repo.lastCode() == wrong {
+			wrong = "111111"
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		invalid := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := auth.VerifyCode("anna@example.com", wrong)
+				if err == ErrCodeInvalid {
+					mu.Lock()
+					invalid++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 2, invalid)
+		assert.Equal(t, 3, repo.verifications[0].Attempts)
+	})
+
+	t.Run("new code replaces previous one", func(t *testing.T) {
+		repo := &memAuthRepo{}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		emails := newTestEmailService(t, clock)
+		auth := NewAuth(repo, users, tokens, emails, clock, testAuthLimits)
+
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+		first := repo.lastCode()
+		clock.now = clock.now.Add(time.Minute)
+		require.NoError(t, auth.SendVerificationCode("anna@example.com"))
+		if repo.lastCode() == first {
+			t.Skip("generated the same code twice")
+		}
+
+		_, err := auth.VerifyCode("anna@example.com", first)
+
+		assert.Equal(t, ErrCodeInvalid, err)
+	})
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"errors"
 	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,6 +17,7 @@ type BookingsRepository interface {
 	Update(booking *model.Booking) error
 	UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error
 	GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error)
+	Transaction(fn func(tx BookingsTx) error) error
 }
 
 type UsersRepository interface {
@@ -23,24 +26,158 @@ type UsersRepository interface {
 	GetByID(id uuid.UUID) (*model.User, error)
 }
 
+const defaultBookingHoldTTL = 15 * time.Minute
+
 type Bookings struct {
 	repo      BookingsRepository
 	usersRepo UsersRepository
+	clock     Clock
+	holdTTL   time.Duration
+	closing   time.Duration
+	currency  string
+	pricing   *PricingEngine
+	emails    *EmailService
+	waitlist  *Waitlist
+	refunds   *Refunds
+	audit     *Audit
+}
+
+type BookingsOption func(*Bookings)
+
+// WithBookingsClock подменяет источник времени (используется в тестах)
+func WithBookingsClock(clock Clock) BookingsOption {
+	return func(s *Bookings) {
+		s.clock = clock
+	}
+}
+
+// WithBookingsPricing задает движок расчета цены мест
+func WithBookingsPricing(pricing *PricingEngine) BookingsOption {
+	return func(s *Bookings) {
+		s.pricing = pricing
+	}
+}
+
+// WithBookingsEmails включает письма покупателю о создании и отмене бронирования
+func WithBookingsEmails(emails *EmailService) BookingsOption {
+	return func(s *Bookings) {
+		s.emails = emails
+	}
+}
+
+// WithBookingsWaitlist передает места отмененных бронирований листу ожидания
+func WithBookingsWaitlist(waitlist *Waitlist) BookingsOption {
+	return func(s *Bookings) {
+		s.waitlist = waitlist
+	}
+}
+
+// WithBookingsRefunds разрешает отмену оплаченных бронирований с возвратом денег по политике возврата
+func WithBookingsRefunds(refunds *Refunds) BookingsOption {
+	return func(s *Bookings) {
+		s.refunds = refunds
+	}
+}
+
+// WithBookingsAudit записывает создание, изменение и отмену бронирований в журнал аудита
+func WithBookingsAudit(audit *Audit) BookingsOption {
+	return func(s *Bookings) {
+		s.audit = audit
+	}
 }
 
-func NewBookings(repo BookingsRepository, usersRepo UsersRepository) *Bookings {
-	return &Bookings{
+// WithSalesCloseBefore задает, за сколько до начала показа закрывается продажа билетов
+func WithSalesCloseBefore(closeBefore time.Duration) BookingsOption {
+	return func(s *Bookings) {
+		s.closing = closeBefore
+	}
+}
+
+// WithBookingsCurrency задает валюту платежей, принятых в кассе
+func WithBookingsCurrency(currency string) BookingsOption {
+	return func(s *Bookings) {
+		s.currency = currency
+	}
+}
+
+// WithBookingHoldTTL задает, сколько неоплаченное бронирование удерживает места
+func WithBookingHoldTTL(ttl time.Duration) BookingsOption {
+	return func(s *Bookings) {
+		if ttl > 0 {
+			s.holdTTL = ttl
+		}
+	}
+}
+
+func NewBookings(repo BookingsRepository, usersRepo UsersRepository, opts ...BookingsOption) *Bookings {
+	s := &Bookings{
 		repo:      repo,
 		usersRepo: usersRepo,
+		clock:     SystemClock,
+		holdTTL:   defaultBookingHoldTTL,
+		pricing:   NewPricingEngine(time.UTC),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Bookings) CreateBooking(email, name string, performanceID uuid.UUID, seatIDs []uuid.UUID) (*model.Booking, error) {
-	if len(seatIDs) == 0 { // 1
+// CreateBooking резервирует места на показ. promoCode необязателен. Если передан holdToken,
+// места удержания превращаются в бронь в той же транзакции; без seatIDs бронируются
+// все удерживаемые места, остальные места удержания возвращаются в продажу.
+func (s *Bookings) CreateBooking(actor model.Actor, email, name string, performanceID uuid.UUID, seatIDs []uuid.UUID, promoCode, holdToken string) (*model.Booking, error) {
+	if len(seatIDs) == 0 && holdToken == "" { // 1
 		return nil, errors.New("at least one seat must be selected") // 2
 	}
 
 	// Найти или создать пользователя по email
+	user, err := s.findOrCreateUser(email, name)
+	if err != nil {
+		return nil, err
+	}
+
+	booking := &model.Booking{
+		ID:            uuid.New(),
+		UserID:        &user.ID,
+		PerformanceID: performanceID,
+		Status:        "pending",
+		ExpiresAt:     s.clock.Now().Add(s.holdTTL),
+
+		RemindersEnabled: true,
+	}
+
+	// Проверка мест, создание брони и резервирование выполняются атомарно:
+	// места блокируются до конца транзакции, поэтому одно место нельзя забронировать дважды
+	err = s.repo.Transaction(func(tx BookingsTx) error {
+		performance, seats, err := s.reserve(tx, booking, user, seatIDs, promoCode, holdToken, false)
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(s.audit, tx, actor, "booking.create", AuditBooking, booking.ID, nil, bookingAudit(booking, seats))
+		if err != nil {
+			return err
+		}
+
+		return enqueueEmail(s.emails, tx, notifications.TemplateBookingCreated, user.Email,
+			bookingEmailData(booking, user, performance, seats))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Получаем полное бронирование с данными
+	fullBooking, err := s.repo.GetByID(booking.ID) // 21
+	if err != nil {                                // 22
+		return nil, err // 23
+	}
+
+	return fullBooking, nil // 24
+}
+
+// findOrCreateUser находит покупателя по email или создает гостевого пользователя
+func (s *Bookings) findOrCreateUser(email, name string) (*model.User, error) {
 	user, err := s.usersRepo.FindByEmail(email) // 3
 	if err != nil {                             // 4
 		if err == gorm.ErrRecordNotFound { // 5
@@ -57,51 +194,191 @@ func (s *Bookings) CreateBooking(email, name string, performanceID uuid.UUID, se
 			return nil, errors.New("failed to find user") // 8
 		}
 	}
+	return user, nil
+}
 
-	// Проверяем доступность мест
-	seats, err := s.repo.GetPerformanceSeatsByIDs(seatIDs, performanceID) // 9
-	if err != nil {                                                       // 10
-		return nil, err // 11
+// reserve резервирует за бронированием booking места seatIDs и места удержания holdToken,
+// рассчитывает цену с учетом промокода и сохраняет бронирование. Покупатель user
+// необязателен, но без него нельзя применить промокод. Касса (boxOffice) продает места
+// и после закрытия онлайн-продажи. Возвращает показ и места брони.
+func (s *Bookings) reserve(tx reserveTx, booking *model.Booking, user *model.User, seatIDs []uuid.UUID, promoCode, holdToken string, boxOffice bool) (*model.Performance, []model.PerformanceSeat, error) {
+	performanceID := booking.PerformanceID
+
+	var hold *model.SeatHold
+	if holdToken != "" {
+		var err error
+		hold, err = s.claimHold(tx, holdToken, performanceID, booking.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(seatIDs) == 0 {
+			seatIDs = make([]uuid.UUID, len(hold.Seats))
+			for i, seat := range hold.Seats {
+				seatIDs[i] = seat.ID
+			}
+		}
 	}
 
-	if len(seats) != len(seatIDs) { // 12
-		return nil, errors.New("some seats are not available") // 13
+	seats, err := s.lockSeats(tx, hold, seatIDs, performanceID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Рассчитываем общую стоимость
-	totalPrice := 0
-	for _, seat := range seats { // 14
-		totalPrice += seat.Price
+	if len(seatIDs) == 0 || len(seats) != len(seatIDs) {
+		return nil, nil, errors.New("some seats are not available")
 	}
 
-	// Создаем бронирование
-	booking := &model.Booking{
-		ID:            uuid.New(),
-		UserID:        user.ID,
-		PerformanceID: performanceID,
-		TotalPrice:    totalPrice,
-		Status:        "pending",
+	performance, err := tx.GetPerformance(performanceID)
+	if err != nil {
+		return nil, nil, errors.New("performance not found")
+	}
+	if boxOffice {
+		err = checkBoxOfficeSale(performance, s.clock.Now())
+	} else {
+		err = checkOnSale(performance, s.clock.Now(), s.closing)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	prices, err := priceSeats(tx, s.pricing, performance, seats)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, price := range prices {
+		booking.Subtotal += price
 	}
 
-	if err := s.repo.Create(booking); err != nil { // 15
-		return nil, err // 16
+	var promo *model.PromoCode
+	if code := normalizePromoCode(promoCode); code != "" {
+		if user == nil {
+			return nil, nil, ErrPromoNeedsCustomer
+		}
+		promo, err = tx.LockPromoCode(code)
+		if err != nil {
+			return nil, nil, errors.New("invalid promo code")
+		}
+		uses, err := tx.CountPromoRedemptions(promo.ID, user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		booking.Discount, err = promoDiscount(promo, performance, seats, prices, uses, s.clock.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+		booking.PromoCodeID = &promo.ID
 	}
+	booking.TotalPrice = booking.Subtotal - booking.Discount
 
-	// Резервируем места
-	for _, seat := range seats { // 17
-		if err := s.repo.UpdatePerformanceSeatStatus(seat.ID, "reserved", &booking.ID); err != nil { // 18
-			return nil, err // 19
+	if err := tx.Create(booking); err != nil {
+		return nil, nil, err
+	}
+
+	if promo != nil {
+		err := tx.RedeemPromoCode(&model.PromoRedemption{
+			ID:          uuid.New(),
+			PromoCodeID: promo.ID,
+			BookingID:   booking.ID,
+			UserID:      user.ID,
+			Discount:    booking.Discount,
+		})
+		if err != nil {
+			return nil, nil, err
 		}
-		// i++ 20*
 	}
 
-	// Получаем полное бронирование с данными
-	fullBooking, err := s.repo.GetByID(booking.ID) // 21
-	if err != nil {                                // 22
-		return nil, err // 23
+	if err := reserveSeats(tx, booking.ID, seats, prices); err != nil {
+		return nil, nil, err
 	}
 
-	return fullBooking, nil // 24
+	if hold != nil {
+		// Невыбранные места удержания возвращаются в продажу
+		released, err := tx.DeleteHold(hold.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if released > 0 {
+			if err := s.waitlist.SeatsFreed(tx, []uuid.UUID{performanceID}); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return performance, seats, nil
+}
+
+// claimHold блокирует удержание владельца holdToken, которое превращается в бронирование bookingID
+func (s *Bookings) claimHold(tx reserveTx, holdToken string, performanceID, bookingID uuid.UUID) (*model.SeatHold, error) {
+	hold, err := tx.ClaimHold(hashHoldToken(holdToken), performanceID, bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if !hold.ExpiresAt.After(s.clock.Now()) {
+		return nil, ErrHoldExpired
+	}
+	return hold, nil
+}
+
+// lockSeats возвращает заблокированные места seatIDs: места удержания hold берутся
+// из него, остальные должны быть свободны
+func (s *Bookings) lockSeats(tx reserveTx, hold *model.SeatHold, seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+	if hold == nil {
+		return tx.GetPerformanceSeatsByIDs(seatIDs, performanceID)
+	}
+
+	held := make(map[uuid.UUID]model.PerformanceSeat, len(hold.Seats))
+	for _, seat := range hold.Seats {
+		held[seat.ID] = seat
+	}
+
+	var seats []model.PerformanceSeat
+	var rest []uuid.UUID
+	for _, id := range seatIDs {
+		if seat, ok := held[id]; ok {
+			seats = append(seats, seat)
+		} else {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) == 0 {
+		return seats, nil
+	}
+
+	free, err := tx.GetPerformanceSeatsByIDs(rest, performanceID)
+	if err != nil {
+		return nil, err
+	}
+	return append(seats, free...), nil
+}
+
+// priceSeats рассчитывает цену каждого места с учетом надбавки за спрос
+func priceSeats(tx seatPricingTx, pricing *PricingEngine, performance *model.Performance, seats []model.PerformanceSeat) ([]int, error) {
+	occupied, total, err := tx.CountOccupiedSeats(performance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	surcharge := pricing.Surcharge(performance, occupied, total)
+	prices := make([]int, len(seats))
+	for i, seat := range seats {
+		prices[i] = pricing.Charge(seat.Price, surcharge)
+	}
+	return prices, nil
+}
+
+// reserveSeats резервирует места за бронированием и фиксирует цену, по которой они проданы
+func reserveSeats(tx seatPricingTx, bookingID uuid.UUID, seats []model.PerformanceSeat, prices []int) error {
+	for i, seat := range seats {
+		if err := tx.UpdatePerformanceSeatStatus(seat.ID, "reserved", &bookingID); err != nil {
+			return err
+		}
+		if err := tx.SetChargedPrice(seat.ID, prices[i]); err != nil {
+			return err
+		}
+		seats[i].ChargedPrice = &prices[i]
+	}
+	return nil
 }
 
 func (s *Bookings) GetBookingByID(id string) (*model.Booking, error) {
@@ -134,36 +411,112 @@ func (s *Bookings) GetUserBookings(email string) ([]model.Booking, error) {
 	return s.repo.GetByUserID(user.ID)
 }
 
-func (s *Bookings) CancelBooking(id string) error {
+// CancelBooking отменяет бронирование. Статус проверяется на заблокированной строке,
+// поэтому отмена не перезапишет бронирование, подтвержденное оплатой в это же время;
+// оплаченное бронирование отменяется с возвратом денег по политике возврата.
+func (s *Bookings) CancelBooking(actor model.Actor, id string) error {
 	bookingID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid booking ID format")
 	}
 
-	booking, err := s.repo.GetByID(bookingID)
-	if err != nil {
-		return errors.New("booking not found")
-	}
+	paid := false
+	err = s.repo.Transaction(func(tx BookingsTx) error {
+		booking, err := tx.LockByID(bookingID)
+		if err != nil {
+			return errors.New("booking not found")
+		}
+		if !canManageBooking(actor, booking) {
+			return ErrNotBookingOwner
+		}
+
+		switch booking.Status {
+		case "cancelled":
+			return errors.New("booking already cancelled")
+		case "expired":
+			return errors.New("booking already expired")
+		case "confirmed":
+			paid = true
+			return nil
+		}
+
+		before := bookingAudit(booking, booking.PerformanceSeats)
+		booking.Status = "cancelled"
+		if err := tx.UpdateStatus(booking.ID, booking.Status); err != nil {
+			return err
+		}
+		err = recordAudit(s.audit, tx, actor, "booking.cancel", AuditBooking, booking.ID, before, bookingAudit(booking, nil))
+		if err != nil {
+			return err
+		}
 
-	if booking.Status == "cancelled" {
-		return errors.New("booking already cancelled")
+		// Освобождаем места
+		for _, seat := range booking.PerformanceSeats {
+			if err := tx.UpdatePerformanceSeatStatus(seat.ID, "available", nil); err != nil {
+				return err
+			}
+		}
+
+		// Возвращаем использование промокода
+		if booking.PromoCodeID != nil {
+			if err := tx.ReleasePromoCode(booking.ID); err != nil {
+				return err
+			}
+		}
+
+		err = enqueueEmail(s.emails, tx, notifications.TemplateBookingCancelled, booking.User.Email,
+			bookingEmailData(booking, &booking.User, &booking.Performance, booking.PerformanceSeats))
+		if err != nil {
+			return err
+		}
+
+		// Освободившиеся места в той же транзакции предлагаются листу ожидания
+		return s.waitlist.SeatsReleased(tx, []model.Booking{*booking})
+	})
+	if err != nil || !paid {
+		return err
 	}
 
-	if booking.Status == "confirmed" {
+	// Возврат блокирует бронирование заново и еще раз проверяет его статус
+	if s.refunds == nil {
 		return errors.New("cannot cancel confirmed booking")
 	}
+	_, _, err = s.refunds.CancelSeats(actor, id, nil, nil)
+	return err
+}
 
-	booking.Status = "cancelled"
-	if err := s.repo.Update(booking); err != nil {
-		return err
+// SetReminders включает или отключает напоминания о показе для бронирования.
+// Менять настройку может только владелец брони или сотрудник с правом на возвраты.
+func (s *Bookings) SetReminders(actor model.Actor, id string, enabled bool) (*model.Booking, error) {
+	bookingID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid booking ID format")
 	}
 
-	// Освобождаем места
-	for _, seat := range booking.PerformanceSeats {
-		if err := s.repo.UpdatePerformanceSeatStatus(seat.ID, "available", nil); err != nil {
-			return err
+	var booking *model.Booking
+	err = s.repo.Transaction(func(tx BookingsTx) error {
+		booking, err = tx.LockByID(bookingID)
+		if err != nil {
+			return errors.New("booking not found")
+		}
+		if !canManageBooking(actor, booking) {
+			return ErrNotBookingOwner
 		}
+		if booking.Status == "cancelled" || booking.Status == "expired" {
+			return errors.New("booking is no longer active")
+		}
+
+		before := bookingAudit(booking, booking.PerformanceSeats)
+		booking.RemindersEnabled = enabled
+		if err := tx.UpdateReminders(booking.ID, enabled); err != nil {
+			return errors.New("failed to update booking")
+		}
+		return recordAudit(s.audit, tx, actor, "booking.reminders", AuditBooking, booking.ID,
+			before, bookingAudit(booking, booking.PerformanceSeats))
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return booking, nil
 }
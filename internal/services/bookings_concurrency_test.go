@@ -0,0 +1,627 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// memBookingsRepo - репозиторий в памяти с транзакциями, которые, как в Postgres,
+// выполняются параллельно. Транзакция видит зафиксированные данные и свои изменения,
+// а строки блокирует только там, где их блокирует репозиторий: в FOR UPDATE и при
+// изменении. Изменения применяются при успешном завершении, блокировки держатся до него.
+type memBookingsRepo struct {
+	mu       sync.Mutex
+	unlocked *sync.Cond
+	owners   map[uuid.UUID]*memBookingsTx // чьи блокировки строк сейчас действуют
+	bookings map[uuid.UUID]model.Booking
+	seats    map[uuid.UUID]model.PerformanceSeat
+	holds    map[uuid.UUID]model.SeatHold
+	payments map[uuid.UUID]model.Payment
+	failSeat uuid.UUID
+}
+
+func newMemBookingsRepo(seats ...model.PerformanceSeat) *memBookingsRepo {
+	r := &memBookingsRepo{
+		owners:   map[uuid.UUID]*memBookingsTx{},
+		bookings: map[uuid.UUID]model.Booking{},
+		seats:    map[uuid.UUID]model.PerformanceSeat{},
+		holds:    map[uuid.UUID]model.SeatHold{},
+		payments: map[uuid.UUID]model.Payment{},
+	}
+	r.unlocked = sync.NewCond(&r.mu)
+	for _, seat := range seats {
+		r.seats[seat.ID] = seat
+	}
+	return r
+}
+
+func (r *memBookingsRepo) Transaction(fn func(tx BookingsTx) error) error {
+	tx := &memBookingsTx{
+		repo:         r,
+		bookings:     map[uuid.UUID]model.Booking{},
+		seats:        map[uuid.UUID]model.PerformanceSeat{},
+		holds:        map[uuid.UUID]model.SeatHold{},
+		deletedHolds: map[uuid.UUID]bool{},
+		payments:     map[uuid.UUID]model.Payment{},
+	}
+	err := fn(tx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		for id, b := range tx.bookings {
+			r.bookings[id] = b
+		}
+		for id, s := range tx.seats {
+			r.seats[id] = s
+		}
+		for id, h := range tx.holds {
+			r.holds[id] = h
+		}
+		for id := range tx.deletedHolds {
+			delete(r.holds, id)
+		}
+		for id, p := range tx.payments {
+			r.payments[id] = p
+		}
+	}
+	for _, id := range tx.locked {
+		delete(r.owners, id)
+	}
+	r.unlocked.Broadcast()
+	return err
+}
+
+func (r *memBookingsRepo) Create(booking *model.Booking) error {
+	return r.Transaction(func(tx BookingsTx) error { return tx.Create(booking) })
+}
+
+func (r *memBookingsRepo) Update(booking *model.Booking) error {
+	return r.Transaction(func(tx BookingsTx) error { return tx.Update(booking) })
+}
+
+func (r *memBookingsRepo) UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error {
+	return r.Transaction(func(tx BookingsTx) error {
+		return tx.UpdatePerformanceSeatStatus(seatID, status, bookingID)
+	})
+}
+
+func (r *memBookingsRepo) GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+	var seats []model.PerformanceSeat
+	err := r.Transaction(func(tx BookingsTx) error {
+		var err error
+		seats, err = tx.GetPerformanceSeatsByIDs(seatIDs, performanceID)
+		return err
+	})
+	return seats, err
+}
+
+func (r *memBookingsRepo) GetByID(id uuid.UUID) (*model.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	for _, seat := range r.seats {
+		if seat.BookingID != nil && *seat.BookingID == id {
+			booking.PerformanceSeats = append(booking.PerformanceSeats, seat)
+		}
+	}
+	return &booking, nil
+}
+
+func (r *memBookingsRepo) GetByUserID(userID uuid.UUID) ([]model.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var bookings []model.Booking
+	for _, b := range r.bookings {
+		if b.UserID != nil && *b.UserID == userID {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+// memBookingsTx хранит изменения транзакции до ее завершения
+type memBookingsTx struct {
+	repo         *memBookingsRepo
+	locked       []uuid.UUID
+	bookings     map[uuid.UUID]model.Booking
+	seats        map[uuid.UUID]model.PerformanceSeat
+	holds        map[uuid.UUID]model.SeatHold
+	deletedHolds map[uuid.UUID]bool
+	payments     map[uuid.UUID]model.Payment
+}
+
+// lock блокирует строку id до конца транзакции, дожидаясь, пока ее отпустит другая транзакция
+func (tx *memBookingsTx) lock(id uuid.UUID) {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.owners[id] != nil && r.owners[id] != tx {
+		r.unlocked.Wait()
+	}
+	if r.owners[id] == nil {
+		r.owners[id] = tx
+		tx.locked = append(tx.locked, id)
+	}
+}
+
+func (tx *memBookingsTx) booking(id uuid.UUID) (model.Booking, bool) {
+	if booking, ok := tx.bookings[id]; ok {
+		return booking, true
+	}
+	tx.repo.mu.Lock()
+	defer tx.repo.mu.Unlock()
+	booking, ok := tx.repo.bookings[id]
+	return booking, ok
+}
+
+func (tx *memBookingsTx) seat(id uuid.UUID) (model.PerformanceSeat, bool) {
+	if seat, ok := tx.seats[id]; ok {
+		return seat, true
+	}
+	tx.repo.mu.Lock()
+	defer tx.repo.mu.Unlock()
+	seat, ok := tx.repo.seats[id]
+	return seat, ok
+}
+
+func (tx *memBookingsTx) payment(id uuid.UUID) (model.Payment, bool) {
+	if payment, ok := tx.payments[id]; ok {
+		return payment, true
+	}
+	tx.repo.mu.Lock()
+	defer tx.repo.mu.Unlock()
+	payment, ok := tx.repo.payments[id]
+	return payment, ok
+}
+
+// allSeats возвращает места такими, какими их видит транзакция
+func (tx *memBookingsTx) allSeats() map[uuid.UUID]model.PerformanceSeat {
+	tx.repo.mu.Lock()
+	seats := make(map[uuid.UUID]model.PerformanceSeat, len(tx.repo.seats))
+	for id, seat := range tx.repo.seats {
+		seats[id] = seat
+	}
+	tx.repo.mu.Unlock()
+	for id, seat := range tx.seats {
+		seats[id] = seat
+	}
+	return seats
+}
+
+// allHolds возвращает удержания такими, какими их видит транзакция
+func (tx *memBookingsTx) allHolds() map[uuid.UUID]model.SeatHold {
+	tx.repo.mu.Lock()
+	holds := make(map[uuid.UUID]model.SeatHold, len(tx.repo.holds))
+	for id, hold := range tx.repo.holds {
+		holds[id] = hold
+	}
+	tx.repo.mu.Unlock()
+	for id, hold := range tx.holds {
+		holds[id] = hold
+	}
+	for id := range tx.deletedHolds {
+		delete(holds, id)
+	}
+	return holds
+}
+
+func (tx *memBookingsTx) LockByID(id uuid.UUID) (*model.Booking, error) {
+	tx.lock(id)
+	booking, ok := tx.booking(id)
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	for _, seat := range tx.allSeats() {
+		if seat.BookingID != nil && *seat.BookingID == id {
+			booking.PerformanceSeats = append(booking.PerformanceSeats, seat)
+		}
+	}
+	return &booking, nil
+}
+
+func (tx *memBookingsTx) Create(booking *model.Booking) error {
+	tx.lock(booking.ID)
+	tx.bookings[booking.ID] = *booking
+	return nil
+}
+
+func (tx *memBookingsTx) Update(booking *model.Booking) error {
+	tx.lock(booking.ID)
+	tx.bookings[booking.ID] = *booking
+	return nil
+}
+
+func (tx *memBookingsTx) UpdateStatus(id uuid.UUID, status string) error {
+	tx.lock(id)
+	booking, ok := tx.booking(id)
+	if !ok {
+		return errors.New("not found")
+	}
+	booking.Status = status
+	tx.bookings[id] = booking
+	return nil
+}
+
+// UpdatePerformanceSeatStatus, как и UPDATE в Postgres, ждет блокировку строки
+// и проверяет условие по последней зафиксированной версии места
+func (tx *memBookingsTx) UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error {
+	if seatID == tx.repo.failSeat {
+		return errors.New("database error")
+	}
+	tx.lock(seatID)
+	seat, ok := tx.seat(seatID)
+	if !ok {
+		return errors.New("seat not found")
+	}
+	if status == "reserved" && seat.Status != "available" && !tx.claimed(seat, bookingID) {
+		return repository.ErrSeatNotAvailable
+	}
+	seat.Status = status
+	seat.BookingID = bookingID
+	seat.HoldID = nil
+	tx.seats[seatID] = seat
+	return nil
+}
+
+// GetPerformanceSeatsByIDs блокирует места в порядке id, как SELECT ... ORDER BY id FOR UPDATE
+func (tx *memBookingsTx) GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+	ids := append([]uuid.UUID(nil), seatIDs...)
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	var seats []model.PerformanceSeat
+	for _, id := range ids {
+		tx.lock(id)
+		seat, ok := tx.seat(id)
+		if ok && seat.PerformanceID == performanceID && seat.Status == "available" {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+func (tx *memBookingsTx) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	// Уступаем планировщик, чтобы параллельные транзакции чаще пересекались
+	// между проверкой мест и их резервированием
+	runtime.Gosched()
+	return onSalePerformance(id), nil
+}
+
+func (tx *memBookingsTx) CountOccupiedSeats(performanceID uuid.UUID) (int64, int64, error) {
+	var occupied, total int64
+	for _, seat := range tx.allSeats() {
+		if seat.PerformanceID != performanceID {
+			continue
+		}
+		total++
+		if seat.Status != "available" {
+			occupied++
+		}
+	}
+	return occupied, total, nil
+}
+
+func (tx *memBookingsTx) SetChargedPrice(seatID uuid.UUID, price int) error {
+	tx.lock(seatID)
+	seat, _ := tx.seat(seatID)
+	seat.ChargedPrice = &price
+	tx.seats[seatID] = seat
+	return nil
+}
+
+func (tx *memBookingsTx) LockPromoCode(code string) (*model.PromoCode, error) {
+	return nil, errors.New("not found")
+}
+
+func (tx *memBookingsTx) CountPromoRedemptions(promoCodeID, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (tx *memBookingsTx) RedeemPromoCode(redemption *model.PromoRedemption) error {
+	return nil
+}
+
+func (tx *memBookingsTx) ReleasePromoCode(bookingID uuid.UUID) error {
+	return nil
+}
+
+func (tx *memBookingsTx) CreateTickets(tickets []model.Ticket) error {
+	return nil
+}
+
+func (tx *memBookingsTx) EnqueueEmail(message *model.OutboxMessage) error {
+	return nil
+}
+
+func (tx *memBookingsTx) RecordReminder(bookingID uuid.UUID, offset time.Duration, at time.Time) (bool, error) {
+	return true, nil
+}
+
+func (tx *memBookingsTx) LockWaitlist(performanceID uuid.UUID) ([]model.WaitlistEntry, error) {
+	return nil, nil
+}
+
+func (tx *memBookingsTx) LockAvailableSeats(performanceID uuid.UUID, category string, limit int) ([]model.PerformanceSeat, error) {
+	return nil, nil
+}
+
+func (tx *memBookingsTx) UpdateWaitlistEntry(entry *model.WaitlistEntry) error {
+	return nil
+}
+
+func (tx *memBookingsTx) CloseWaitlistOffers(bookingIDs []uuid.UUID, status string) ([]model.WaitlistEntry, error) {
+	return nil, nil
+}
+
+func (tx *memBookingsTx) ClaimHold(tokenHash string, performanceID, bookingID uuid.UUID) (*model.SeatHold, error) {
+	for id, hold := range tx.allHolds() {
+		if hold.TokenHash != tokenHash || hold.PerformanceID != performanceID {
+			continue
+		}
+		tx.lock(id)
+		hold.BookingID = &bookingID
+		tx.holds[id] = hold
+		for seatID, seat := range tx.allSeats() {
+			if seat.Status == "held" && seat.HoldID != nil && *seat.HoldID == id {
+				tx.lock(seatID)
+				hold.Seats = append(hold.Seats, seat)
+			}
+		}
+		return &hold, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (tx *memBookingsTx) DeleteHold(id uuid.UUID) (int64, error) {
+	var released int64
+	for seatID, seat := range tx.allSeats() {
+		if seat.Status == "held" && seat.HoldID != nil && *seat.HoldID == id {
+			tx.lock(seatID)
+			seat.Status = "available"
+			seat.HoldID = nil
+			tx.seats[seatID] = seat
+			released++
+		}
+	}
+	delete(tx.holds, id)
+	tx.deletedHolds[id] = true
+	return released, nil
+}
+
+func (tx *memBookingsTx) LockCapturedPayment(bookingID uuid.UUID) (*model.Payment, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (tx *memBookingsTx) UpdateReminders(id uuid.UUID, enabled bool) error {
+	tx.lock(id)
+	booking, _ := tx.booking(id)
+	booking.RemindersEnabled = enabled
+	tx.bookings[id] = booking
+	return nil
+}
+
+func (tx *memBookingsTx) LockPayment(id uuid.UUID) (*model.Payment, error) {
+	tx.lock(id)
+	payment, ok := tx.payment(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &payment, nil
+}
+
+func (tx *memBookingsTx) UpdatePayment(payment *model.Payment) error {
+	tx.lock(payment.ID)
+	tx.payments[payment.ID] = *payment
+	return nil
+}
+
+func (tx *memBookingsTx) CreateRefund(refund *model.Refund) error {
+	return nil
+}
+
+func (tx *memBookingsTx) CreatePayment(payment *model.Payment) error {
+	return nil
+}
+
+func (tx *memBookingsTx) VoidTickets(bookingID uuid.UUID, seatIDs []uuid.UUID) error {
+	return nil
+}
+
+func (tx *memBookingsTx) RecordAudit(entry *model.AuditEntry) error {
+	return nil
+}
+
+// claimed сообщает, что место удерживается удержанием, которое превращается в бронь bookingID
+func (tx *memBookingsTx) claimed(seat model.PerformanceSeat, bookingID *uuid.UUID) bool {
+	if seat.Status != "held" || seat.HoldID == nil || bookingID == nil {
+		return false
+	}
+	hold, ok := tx.allHolds()[*seat.HoldID]
+	return ok && hold.BookingID != nil && *hold.BookingID == *bookingID
+}
+
+type staticUsersRepo struct{}
+
+func (staticUsersRepo) FindByEmail(email string) (*model.User, error) {
+	return &model.User{ID: uuid.NewSHA1(uuid.NameSpaceOID, []byte(email)), Email: email}, nil
+}
+
+func (staticUsersRepo) Create(user *model.User) error {
+	return nil
+}
+
+func (staticUsersRepo) GetByID(id uuid.UUID) (*model.User, error) {
+	return &model.User{ID: id}, nil
+}
+
+func TestCreateBooking_Concurrency(t *testing.T) {
+	t.Run("same seats cannot be double-booked", func(t *testing.T) {
+		performanceID := uuid.New()
+		seatA := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 1500, Status: "available"}
+		seatB := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 2000, Status: "available"}
+
+		repo := newMemBookingsRepo(seatA, seatB)
+		service := NewBookings(repo, staticUsersRepo{})
+
+		const buyers = 50
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			successes []*model.Booking
+		)
+		for i := 0; i < buyers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Половина покупателей берет оба места, половина - только одно из них
+				seatIDs := []uuid.UUID{seatA.ID, seatB.ID}
+				if i%2 == 1 {
+					seatIDs = []uuid.UUID{seatB.ID}
+				}
+				booking, err := service.CreateBooking(testActor, "buyer@example.com", "Buyer", performanceID, seatIDs, "", "")
+				if err != nil {
+					assert.EqualError(t, err, "some seats are not available")
+					return
+				}
+				mu.Lock()
+				successes = append(successes, booking)
+				mu.Unlock()
+			}(i)
+		}
+		wg.Wait()
+
+		require.Len(t, successes, 1)
+		assert.Len(t, repo.bookings, 1)
+
+		winner := successes[0].ID
+		for _, seat := range repo.seats {
+			if seat.Status == "reserved" {
+				require.NotNil(t, seat.BookingID)
+				assert.Equal(t, winner, *seat.BookingID)
+			}
+		}
+		assert.Equal(t, "reserved", repo.seats[seatB.ID].Status)
+	})
+
+	t.Run("failure rolls back booking and seats", func(t *testing.T) {
+		performanceID := uuid.New()
+		seatA := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 1500, Status: "available"}
+		seatB := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 2000, Status: "available"}
+
+		repo := newMemBookingsRepo(seatA, seatB)
+		repo.failSeat = seatB.ID
+		service := NewBookings(repo, staticUsersRepo{})
+
+		booking, err := service.CreateBooking(testActor, "buyer@example.com", "Buyer", performanceID, []uuid.UUID{seatA.ID, seatB.ID}, "", "")
+
+		assert.Error(t, err)
+		assert.Nil(t, booking)
+		assert.Empty(t, repo.bookings)
+		assert.Equal(t, "available", repo.seats[seatA.ID].Status)
+		assert.Nil(t, repo.seats[seatA.ID].BookingID)
+	})
+	t.Run("held seats go only to the hold owner", func(t *testing.T) {
+		performanceID := uuid.New()
+		hold := model.SeatHold{
+			ID:            uuid.New(),
+			PerformanceID: performanceID,
+			TokenHash:     hashHoldToken("owner-token"),
+			ExpiresAt:     time.Now().Add(5 * time.Minute),
+		}
+		seatA := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 1500, Status: "held", HoldID: &hold.ID}
+		seatB := model.PerformanceSeat{ID: uuid.New(), PerformanceID: performanceID, Price: 2000, Status: "held", HoldID: &hold.ID}
+
+		repo := newMemBookingsRepo(seatA, seatB)
+		repo.holds[hold.ID] = hold
+		service := NewBookings(repo, staticUsersRepo{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := service.CreateBooking(testActor, "other@example.com", "Other", performanceID, []uuid.UUID{seatA.ID}, "", "")
+				assert.EqualError(t, err, "some seats are not available")
+			}()
+		}
+		wg.Wait()
+
+		booking, err := service.CreateBooking(testActor, "buyer@example.com", "Buyer", performanceID, []uuid.UUID{seatA.ID}, "", "owner-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, "reserved", repo.seats[seatA.ID].Status)
+		assert.Equal(t, booking.ID, *repo.seats[seatA.ID].BookingID)
+		assert.Nil(t, repo.seats[seatA.ID].HoldID)
+		// Невыбранное место удержания вернулось в продажу, само удержание снято
+		assert.Equal(t, "available", repo.seats[seatB.ID].Status)
+		assert.Empty(t, repo.holds)
+	})
+}
+
+func TestCancelBooking_ConcurrentSettle(t *testing.T) {
+	// Оплата и отмена одного бронирования одновременно: побеждает одна из них,
+	// и места остаются в состоянии, согласованном со статусом бронирования
+	for i := 0; i < 50; i++ {
+		performanceID := uuid.New()
+		bookingID := uuid.New()
+		seatID := uuid.New()
+		repo := newMemBookingsRepo(model.PerformanceSeat{
+			ID: seatID, PerformanceID: performanceID, Price: 1000, Status: "reserved", BookingID: &bookingID,
+		})
+		repo.bookings[bookingID] = model.Booking{ID: bookingID, PerformanceID: performanceID, Status: "pending", TotalPrice: 1000}
+
+		provider := payments.NewFake("secret")
+		intent, err := provider.CreateIntent(bookingID, 1000, "BYN")
+		require.NoError(t, err)
+		_, err = provider.Capture(intent.ID, "tok_visa")
+		require.NoError(t, err)
+		payment := model.Payment{ID: uuid.New(), BookingID: bookingID, IntentID: intent.ID, Amount: 1000, Status: "pending"}
+		repo.payments[payment.ID] = payment
+
+		paymentsService := NewPayments(new(MockPaymentsRepository), repo, provider, SystemClock, "BYN", nil, nil)
+		bookingsService := NewBookings(repo, staticUsersRepo{})
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = paymentsService.settle(&payment)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = bookingsService.CancelBooking(testActor, bookingID.String())
+		}()
+		wg.Wait()
+
+		booking := repo.bookings[bookingID]
+		seat := repo.seats[seatID]
+		switch booking.Status {
+		case "confirmed":
+			assert.Equal(t, "sold", seat.Status)
+			assert.Equal(t, "captured", repo.payments[payment.ID].Status)
+		case "cancelled":
+			assert.Equal(t, "available", seat.Status)
+			assert.Equal(t, "refunded", repo.payments[payment.ID].Status)
+		default:
+			t.Fatalf("unexpected booking status %q", booking.Status)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 	"theater-ticket-system/internal/models/models"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,19 @@ func (m *MockBookingsRepository) GetByID(id uuid.UUID) (*model.Booking, error) {
 	return args.Get(0).(*model.Booking), args.Error(1)
 }
 
+func (m *MockBookingsRepository) LockByID(id uuid.UUID) (*model.Booking, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Booking), args.Error(1)
+}
+
+func (m *MockBookingsRepository) ActiveBookingIDs(performanceID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 func (m *MockBookingsRepository) GetByUserID(userID uuid.UUID) ([]model.Booking, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
@@ -41,6 +55,16 @@ func (m *MockBookingsRepository) Update(booking *model.Booking) error {
 	return args.Error(0)
 }
 
+func (m *MockBookingsRepository) UpdateStatus(id uuid.UUID, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) UpdateReminders(id uuid.UUID, enabled bool) error {
+	args := m.Called(id, enabled)
+	return args.Error(0)
+}
+
 func (m *MockBookingsRepository) UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error {
 	args := m.Called(seatID, status, bookingID)
 	return args.Error(0)
@@ -54,6 +78,148 @@ func (m *MockBookingsRepository) GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, p
 	return args.Get(0).([]model.PerformanceSeat), args.Error(1)
 }
 
+func (m *MockBookingsRepository) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Performance), args.Error(1)
+}
+
+func (m *MockBookingsRepository) CountOccupiedSeats(performanceID uuid.UUID) (int64, int64, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBookingsRepository) SetChargedPrice(seatID uuid.UUID, price int) error {
+	args := m.Called(seatID, price)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) LockPromoCode(code string) (*model.PromoCode, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PromoCode), args.Error(1)
+}
+
+func (m *MockBookingsRepository) CountPromoRedemptions(promoCodeID, userID uuid.UUID) (int64, error) {
+	args := m.Called(promoCodeID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBookingsRepository) RedeemPromoCode(redemption *model.PromoRedemption) error {
+	args := m.Called(redemption)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) ReleasePromoCode(bookingID uuid.UUID) error {
+	args := m.Called(bookingID)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) CreateTickets(tickets []model.Ticket) error {
+	args := m.Called(tickets)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) EnqueueEmail(message *model.OutboxMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) RecordReminder(bookingID uuid.UUID, offset time.Duration, at time.Time) (bool, error) {
+	args := m.Called(bookingID, offset, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBookingsRepository) GetForReminder(performanceID uuid.UUID, offset time.Duration) ([]model.Booking, error) {
+	args := m.Called(performanceID, offset)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockBookingsRepository) LockWaitlist(performanceID uuid.UUID) ([]model.WaitlistEntry, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockBookingsRepository) LockAvailableSeats(performanceID uuid.UUID, category string, limit int) ([]model.PerformanceSeat, error) {
+	args := m.Called(performanceID, category, limit)
+	return args.Get(0).([]model.PerformanceSeat), args.Error(1)
+}
+
+func (m *MockBookingsRepository) UpdateWaitlistEntry(entry *model.WaitlistEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) CloseWaitlistOffers(bookingIDs []uuid.UUID, status string) ([]model.WaitlistEntry, error) {
+	args := m.Called(bookingIDs, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockBookingsRepository) ClaimHold(tokenHash string, performanceID, bookingID uuid.UUID) (*model.SeatHold, error) {
+	args := m.Called(tokenHash, performanceID, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SeatHold), args.Error(1)
+}
+
+func (m *MockBookingsRepository) DeleteHold(id uuid.UUID) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBookingsRepository) LockCapturedPayment(bookingID uuid.UUID) (*model.Payment, error) {
+	args := m.Called(bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockBookingsRepository) LockPayment(id uuid.UUID) (*model.Payment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockBookingsRepository) UpdatePayment(payment *model.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) CreateRefund(refund *model.Refund) error {
+	args := m.Called(refund)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) CreatePayment(payment *model.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) VoidTickets(bookingID uuid.UUID, seatIDs []uuid.UUID) error {
+	args := m.Called(bookingID, seatIDs)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) RecordAudit(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockBookingsRepository) Transaction(fn func(tx BookingsTx) error) error {
+	return fn(m)
+}
+
 type MockUsersRepository struct {
 	mock.Mock
 }
@@ -82,6 +248,11 @@ func (m *MockUsersRepository) GetByID(id uuid.UUID) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+// onSalePerformance - показ, на который идет продажа
+func onSalePerformance(id uuid.UUID) *model.Performance {
+	return &model.Performance{ID: id, Status: "on_sale", Date: time.Now().AddDate(1, 0, 0)}
+}
+
 func TestCreateBooking(t *testing.T) {
 	t.Run("success with existing user", func(t *testing.T) {
 		mockBookingsRepo := new(MockBookingsRepository)
@@ -105,7 +276,7 @@ func TestCreateBooking(t *testing.T) {
 
 		expectedBooking := &model.Booking{
 			ID:            uuid.New(),
-			UserID:        userID,
+			UserID:        &userID,
 			PerformanceID: performanceID,
 			TotalPrice:    3500,
 			Status:        "pending",
@@ -114,15 +285,22 @@ func TestCreateBooking(t *testing.T) {
 		mockUsersRepo.On("FindByEmail", "+1234567890").Return(existingUser, nil)
 		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
 			Return(availableSeats, nil)
-		mockBookingsRepo.On("Create", mock.AnythingOfType("*model.Booking")).Return(nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).
+			Return(onSalePerformance(performanceID), nil)
+		mockBookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(0), int64(100), nil)
+		mockBookingsRepo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.TotalPrice == 3500
+		})).Return(nil)
 		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatIDs[0], "reserved", mock.AnythingOfType("*uuid.UUID")).
 			Return(nil)
 		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatIDs[1], "reserved", mock.AnythingOfType("*uuid.UUID")).
 			Return(nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[0], 1500).Return(nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[1], 2000).Return(nil)
 		mockBookingsRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).
 			Return(expectedBooking, nil)
 
-		booking, err := service.CreateBooking("+1234567890", "John Doe", performanceID, seatIDs)
+		booking, err := service.CreateBooking(testActor, "+1234567890", "John Doe", performanceID, seatIDs, "", "")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, booking)
@@ -149,13 +327,17 @@ func TestCreateBooking(t *testing.T) {
 		})).Return(nil)
 		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
 			Return(availableSeats, nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).
+			Return(onSalePerformance(performanceID), nil)
+		mockBookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(0), int64(100), nil)
 		mockBookingsRepo.On("Create", mock.AnythingOfType("*model.Booking")).Return(nil)
 		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatIDs[0], "reserved", mock.AnythingOfType("*uuid.UUID")).
 			Return(nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[0], 1500).Return(nil)
 		mockBookingsRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).
 			Return(&model.Booking{ID: uuid.New(), TotalPrice: 1500}, nil)
 
-		booking, err := service.CreateBooking("+9876543210", "Jane Doe", performanceID, seatIDs)
+		booking, err := service.CreateBooking(testActor, "+9876543210", "Jane Doe", performanceID, seatIDs, "", "")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, booking)
@@ -163,12 +345,95 @@ func TestCreateBooking(t *testing.T) {
 		mockBookingsRepo.AssertExpectations(t)
 	})
 
+	t.Run("stamps hold expiry from clock", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewBookings(mockBookingsRepo, mockUsersRepo,
+			WithBookingsClock(clock),
+			WithBookingHoldTTL(10*time.Minute),
+		)
+
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New()}
+
+		mockUsersRepo.On("FindByEmail", "guest@example.com").Return(&model.User{ID: uuid.New()}, nil)
+		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{{ID: seatIDs[0], Price: 1500, Status: "available"}}, nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).
+			Return(onSalePerformance(performanceID), nil)
+		mockBookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(0), int64(100), nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[0], 1500).Return(nil)
+		mockBookingsRepo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.ExpiresAt.Equal(clock.now.Add(10 * time.Minute))
+		})).Return(nil)
+		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatIDs[0], "reserved", mock.AnythingOfType("*uuid.UUID")).
+			Return(nil)
+		mockBookingsRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).
+			Return(&model.Booking{ID: uuid.New()}, nil)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, "", "")
+
+		assert.NoError(t, err)
+		mockBookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("applies price plan surcharges and records charged prices", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		service := NewBookings(mockBookingsRepo, mockUsersRepo,
+			WithBookingsPricing(NewPricingEngine(time.UTC)),
+			WithBookingsClock(&fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}),
+		)
+
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New(), uuid.New()}
+		performance := &model.Performance{
+			ID:       performanceID,
+			Status:   "on_sale",
+			Date:     time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC), // суббота
+			Premiere: true,
+			PricePlan: &model.PricePlan{
+				WeekendSurcharge:  10,
+				PremiereSurcharge: 20,
+				DemandSteps: []model.DemandStep{
+					{Occupancy: 50, Surcharge: 5},
+					{Occupancy: 80, Surcharge: 15},
+				},
+			},
+		}
+
+		mockUsersRepo.On("FindByEmail", "guest@example.com").Return(&model.User{ID: uuid.New()}, nil)
+		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{
+				{ID: seatIDs[0], Price: 1000, Status: "available"},
+				{ID: seatIDs[1], Price: 3500, Status: "available"},
+			}, nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).Return(performance, nil)
+		// Заполненность 85% - действует ступень 80%: 10 + 20 + 15 = 45%
+		mockBookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(85), int64(100), nil)
+		mockBookingsRepo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.TotalPrice == 1450+5075
+		})).Return(nil)
+		mockBookingsRepo.On("UpdatePerformanceSeatStatus", mock.Anything, "reserved", mock.AnythingOfType("*uuid.UUID")).
+			Return(nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[0], 1450).Return(nil)
+		mockBookingsRepo.On("SetChargedPrice", seatIDs[1], 5075).Return(nil)
+		mockBookingsRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).
+			Return(&model.Booking{ID: uuid.New()}, nil)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, "", "")
+
+		assert.NoError(t, err)
+		mockBookingsRepo.AssertExpectations(t)
+	})
+
 	t.Run("no seats selected", func(t *testing.T) {
 		mockBookingsRepo := new(MockBookingsRepository)
 		mockUsersRepo := new(MockUsersRepository)
 		service := NewBookings(mockBookingsRepo, mockUsersRepo)
 
-		booking, err := service.CreateBooking("+1234567890", "John", uuid.New(), []uuid.UUID{})
+		booking, err := service.CreateBooking(testActor, "+1234567890", "John", uuid.New(), []uuid.UUID{}, "", "")
 
 		assert.Error(t, err)
 		assert.Nil(t, booking)
@@ -196,7 +461,7 @@ func TestCreateBooking(t *testing.T) {
 		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
 			Return(availableSeats, nil)
 
-		booking, err := service.CreateBooking("+1234567890", "John", performanceID, seatIDs)
+		booking, err := service.CreateBooking(testActor, "+1234567890", "John", performanceID, seatIDs, "", "")
 
 		assert.Error(t, err)
 		assert.Nil(t, booking)
@@ -204,6 +469,51 @@ func TestCreateBooking(t *testing.T) {
 		mockBookingsRepo.AssertNotCalled(t, "Create")
 	})
 
+	t.Run("sales closed before curtain", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 40, 0, 0, time.UTC)}
+		service := NewBookings(mockBookingsRepo, mockUsersRepo,
+			WithBookingsClock(clock),
+			WithSalesCloseBefore(30*time.Minute),
+		)
+
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New()}
+		performance := &model.Performance{ID: performanceID, Status: "on_sale", Date: time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC)}
+
+		mockUsersRepo.On("FindByEmail", "guest@example.com").Return(&model.User{ID: uuid.New()}, nil)
+		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{{ID: seatIDs[0], Price: 1500, Status: "available"}}, nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).Return(performance, nil)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, "", "")
+
+		assert.ErrorIs(t, err, ErrSalesClosed)
+		mockBookingsRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("performance not on sale", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		service := NewBookings(mockBookingsRepo, mockUsersRepo)
+
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New()}
+		performance := onSalePerformance(performanceID)
+		performance.Status = "scheduled"
+
+		mockUsersRepo.On("FindByEmail", "guest@example.com").Return(&model.User{ID: uuid.New()}, nil)
+		mockBookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{{ID: seatIDs[0], Price: 1500, Status: "available"}}, nil)
+		mockBookingsRepo.On("GetPerformance", performanceID).Return(performance, nil)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, "", "")
+
+		assert.ErrorIs(t, err, ErrNotOnSale)
+		mockBookingsRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
 	t.Run("user creation fails", func(t *testing.T) {
 		mockBookingsRepo := new(MockBookingsRepository)
 		mockUsersRepo := new(MockUsersRepository)
@@ -213,13 +523,33 @@ func TestCreateBooking(t *testing.T) {
 		mockUsersRepo.On("Create", mock.AnythingOfType("*model.User")).
 			Return(errors.New("database error"))
 
-		booking, err := service.CreateBooking("+1234567890", "John", uuid.New(), []uuid.UUID{uuid.New()})
+		booking, err := service.CreateBooking(testActor, "+1234567890", "John", uuid.New(), []uuid.UUID{uuid.New()}, "", "")
 
 		assert.Error(t, err)
 		assert.Nil(t, booking)
 		assert.EqualError(t, err, "failed to create user")
 		mockBookingsRepo.AssertNotCalled(t, "Create")
 	})
+
+	t.Run("expired hold", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewBookings(mockBookingsRepo, mockUsersRepo, WithBookingsClock(clock))
+
+		performanceID := uuid.New()
+		hold := &model.SeatHold{ID: uuid.New(), PerformanceID: performanceID, ExpiresAt: clock.now.Add(-time.Second)}
+
+		mockUsersRepo.On("FindByEmail", "+1234567890").Return(&model.User{ID: uuid.New()}, nil)
+		mockBookingsRepo.On("ClaimHold", hashHoldToken("token"), performanceID, mock.AnythingOfType("uuid.UUID")).
+			Return(hold, nil)
+
+		booking, err := service.CreateBooking(testActor, "+1234567890", "John", performanceID, nil, "", "token")
+
+		assert.ErrorIs(t, err, ErrHoldExpired)
+		assert.Nil(t, booking)
+		mockBookingsRepo.AssertNotCalled(t, "Create")
+	})
 }
 
 func TestGetBookingByID(t *testing.T) {
@@ -284,8 +614,8 @@ func TestGetUserBookings(t *testing.T) {
 		user := &model.User{ID: userID, Email: "+1234567890"}
 
 		expectedBookings := []model.Booking{
-			{ID: uuid.New(), UserID: userID, TotalPrice: 1500},
-			{ID: uuid.New(), UserID: userID, TotalPrice: 2000},
+			{ID: uuid.New(), UserID: &userID, TotalPrice: 1500},
+			{ID: uuid.New(), UserID: &userID, TotalPrice: 2000},
 		}
 
 		mockUsersRepo.On("FindByEmail", "+1234567890").Return(user, nil)
@@ -348,16 +678,14 @@ func TestCancelBooking(t *testing.T) {
 			},
 		}
 
-		mockBookingsRepo.On("GetByID", bookingID).Return(booking, nil)
-		mockBookingsRepo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
-			return b.ID == bookingID && b.Status == "cancelled"
-		})).Return(nil)
+		mockBookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+		mockBookingsRepo.On("UpdateStatus", bookingID, "cancelled").Return(nil)
 		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatID1, "available", (*uuid.UUID)(nil)).
 			Return(nil)
 		mockBookingsRepo.On("UpdatePerformanceSeatStatus", seatID2, "available", (*uuid.UUID)(nil)).
 			Return(nil)
 
-		err := service.CancelBooking(bookingID.String())
+		err := service.CancelBooking(testActor, bookingID.String())
 
 		assert.NoError(t, err)
 		mockBookingsRepo.AssertExpectations(t)
@@ -368,11 +696,46 @@ func TestCancelBooking(t *testing.T) {
 		mockUsersRepo := new(MockUsersRepository)
 		service := NewBookings(mockBookingsRepo, mockUsersRepo)
 
-		err := service.CancelBooking("invalid-uuid")
+		err := service.CancelBooking(testActor, "invalid-uuid")
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "invalid booking ID format")
-		mockBookingsRepo.AssertNotCalled(t, "GetByID")
+		mockBookingsRepo.AssertNotCalled(t, "LockByID")
+	})
+
+	t.Run("owner cancels own booking", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		service := NewBookings(mockBookingsRepo, mockUsersRepo)
+
+		ownerID := uuid.New()
+		bookingID := uuid.New()
+		booking := &model.Booking{ID: bookingID, UserID: &ownerID, Status: "pending"}
+
+		mockBookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+		mockBookingsRepo.On("UpdateStatus", bookingID, "cancelled").Return(nil)
+
+		err := service.CancelBooking(model.Actor{UserID: &ownerID, Role: model.RoleCustomer}, bookingID.String())
+
+		assert.NoError(t, err)
+		mockBookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("another customer cannot cancel", func(t *testing.T) {
+		mockBookingsRepo := new(MockBookingsRepository)
+		mockUsersRepo := new(MockUsersRepository)
+		service := NewBookings(mockBookingsRepo, mockUsersRepo)
+
+		ownerID, customerID := uuid.New(), uuid.New()
+		bookingID := uuid.New()
+		booking := &model.Booking{ID: bookingID, UserID: &ownerID, Status: "pending"}
+
+		mockBookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+
+		err := service.CancelBooking(model.Actor{UserID: &customerID, Role: model.RoleCustomer}, bookingID.String())
+
+		assert.ErrorIs(t, err, ErrNotBookingOwner)
+		mockBookingsRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
 	})
 
 	t.Run("booking already cancelled", func(t *testing.T) {
@@ -386,13 +749,13 @@ func TestCancelBooking(t *testing.T) {
 			Status: "cancelled",
 		}
 
-		mockBookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		mockBookingsRepo.On("LockByID", bookingID).Return(booking, nil)
 
-		err := service.CancelBooking(bookingID.String())
+		err := service.CancelBooking(testActor, bookingID.String())
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "booking already cancelled")
-		mockBookingsRepo.AssertNotCalled(t, "Update")
+		mockBookingsRepo.AssertNotCalled(t, "UpdateStatus")
 	})
 
 	t.Run("cannot cancel confirmed booking", func(t *testing.T) {
@@ -406,13 +769,14 @@ func TestCancelBooking(t *testing.T) {
 			Status: "confirmed",
 		}
 
-		mockBookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		mockBookingsRepo.On("LockByID", bookingID).Return(booking, nil)
 
-		err := service.CancelBooking(bookingID.String())
+		err := service.CancelBooking(testActor, bookingID.String())
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "cannot cancel confirmed booking")
-		mockBookingsRepo.AssertNotCalled(t, "Update")
+		mockBookingsRepo.AssertNotCalled(t, "UpdateStatus")
+		mockBookingsRepo.AssertNotCalled(t, "UpdatePerformanceSeatStatus")
 	})
 
 	t.Run("booking not found", func(t *testing.T) {
@@ -421,12 +785,12 @@ func TestCancelBooking(t *testing.T) {
 		service := NewBookings(mockBookingsRepo, mockUsersRepo)
 
 		bookingID := uuid.New()
-		mockBookingsRepo.On("GetByID", bookingID).Return(nil, errors.New("not found"))
+		mockBookingsRepo.On("LockByID", bookingID).Return(nil, errors.New("not found"))
 
-		err := service.CancelBooking(bookingID.String())
+		err := service.CancelBooking(testActor, bookingID.String())
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "booking not found")
-		mockBookingsRepo.AssertNotCalled(t, "Update")
+		mockBookingsRepo.AssertNotCalled(t, "UpdateStatus")
 	})
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Операции с бронированиями внутри транзакции. Каждый сценарий описывает только
+// то, что ему нужно; BookingsTx собирает их для репозиториев, открывающих транзакцию.
+
+// seatPricingTx - расчет цены и резервирование мест за бронированием
+type seatPricingTx interface {
+	CountOccupiedSeats(performanceID uuid.UUID) (occupied, total int64, err error)
+	UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error
+	SetChargedPrice(seatID uuid.UUID, price int) error
+}
+
+// WaitlistTx - предложение освободившихся мест листу ожидания в транзакции,
+// которая их освободила
+type WaitlistTx interface {
+	seatPricingTx
+	EmailEnqueuer
+	AuditRecorder
+	Create(booking *model.Booking) error
+	GetPerformance(id uuid.UUID) (*model.Performance, error)
+	LockWaitlist(performanceID uuid.UUID) ([]model.WaitlistEntry, error)
+	LockAvailableSeats(performanceID uuid.UUID, category string, limit int) ([]model.PerformanceSeat, error)
+	UpdateWaitlistEntry(entry *model.WaitlistEntry) error
+	CloseWaitlistOffers(bookingIDs []uuid.UUID, status string) ([]model.WaitlistEntry, error)
+}
+
+// reserveTx - создание бронирования на свободные или удержанные места
+type reserveTx interface {
+	WaitlistTx
+	AuditRecorder
+	GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error)
+	LockPromoCode(code string) (*model.PromoCode, error)
+	CountPromoRedemptions(promoCodeID, userID uuid.UUID) (int64, error)
+	RedeemPromoCode(redemption *model.PromoRedemption) error
+	ClaimHold(tokenHash string, performanceID, bookingID uuid.UUID) (*model.SeatHold, error)
+	DeleteHold(id uuid.UUID) (int64, error)
+}
+
+// settleTx - продажа мест оплаченного бронирования и выпуск билетов
+type settleTx interface {
+	EmailEnqueuer
+	AuditRecorder
+	LockByID(id uuid.UUID) (*model.Booking, error)
+	Update(booking *model.Booking) error
+	UpdatePerformanceSeatStatus(seatID uuid.UUID, status string, bookingID *uuid.UUID) error
+	CreateTickets(tickets []model.Ticket) error
+	CreatePayment(payment *model.Payment) error
+	LockPayment(id uuid.UUID) (*model.Payment, error)
+	UpdatePayment(payment *model.Payment) error
+}
+
+// refundTx - отмена мест бронирования и возврат денег за них
+type refundTx interface {
+	WaitlistTx
+	AuditRecorder
+	LockByID(id uuid.UUID) (*model.Booking, error)
+	Update(booking *model.Booking) error
+	UpdateStatus(id uuid.UUID, status string) error
+	ReleasePromoCode(bookingID uuid.UUID) error
+	VoidTickets(bookingID uuid.UUID, seatIDs []uuid.UUID) error
+	LockCapturedPayment(bookingID uuid.UUID) (*model.Payment, error)
+	UpdatePayment(payment *model.Payment) error
+	CreateRefund(refund *model.Refund) error
+}
+
+// moveTx - пересадка бронирования на места другого показа
+type moveTx interface {
+	settleTx
+	seatPricingTx
+	GetPerformanceSeatsByIDs(seatIDs []uuid.UUID, performanceID uuid.UUID) ([]model.PerformanceSeat, error)
+	VoidTickets(bookingID uuid.UUID, seatIDs []uuid.UUID) error
+}
+
+// reminderTx - отметка об отправленном напоминании вместе с письмом
+type reminderTx interface {
+	EmailEnqueuer
+	RecordReminder(bookingID uuid.UUID, offset time.Duration, at time.Time) (bool, error)
+}
+
+// reminderSettingsTx - включение и отключение напоминаний владельцем брони
+type reminderSettingsTx interface {
+	AuditRecorder
+	LockByID(id uuid.UUID) (*model.Booking, error)
+	UpdateReminders(id uuid.UUID, enabled bool) error
+}
+
+// BookingsTx - транзакция репозитория бронирований
+type BookingsTx interface {
+	reserveTx
+	settleTx
+	refundTx
+	moveTx
+	reminderTx
+	reminderSettingsTx
+}
+
+// ReleaseHook вызывается в транзакции, освободившей места бронирований released
+type ReleaseHook func(tx WaitlistTx, released []model.Booking) error
+
+// FreedHook вызывается в транзакции, вернувшей места показов performanceIDs в продажу
+type FreedHook func(tx WaitlistTx, performanceIDs []uuid.UUID) error
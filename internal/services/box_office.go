@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	"theater-ticket-system/internal/tickets"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCashierRequired      = errors.New("sale must be made by a cashier")
+	ErrInvalidPaymentMethod = errors.New("payment method must be cash or card")
+	ErrInsufficientCash     = errors.New("tendered cash is less than the total")
+	ErrPromoNeedsCustomer   = errors.New("promo code requires customer email")
+	ErrNotBoxOfficeSale     = errors.New("booking was not sold at the box office")
+	ErrInvalidShiftPeriod   = errors.New("shift must end after it starts")
+)
+
+// BoxOfficeProvider - платежная система, которой записываются оплаты в кассе
+const BoxOfficeProvider = "box_office"
+
+// paidAtBoxOffice сообщает, принят ли платеж в кассе театра
+func paidAtBoxOffice(payment *model.Payment) bool {
+	return payment.Method == model.PaymentCash || payment.Method == model.PaymentCard
+}
+
+// BoxOfficeSale - продажа мест в кассе
+type BoxOfficeSale struct {
+	PerformanceID uuid.UUID
+	SeatIDs       []uuid.UUID
+	Email         string // необязателен: без него продажа оформляется без покупателя
+	Name          string
+	PromoCode     string
+	Method        string // cash или card
+	Tendered      int    // сколько наличных передал покупатель
+}
+
+// Sell продает места в кассе: бронирование сразу подтверждается, места продаются,
+// билеты выпускаются, а оплата записывается со способом оплаты и кассиром actor.
+// Писем покупатель не получает - ему выдаются чек и распечатанные билеты. С email
+// продажа привязывается к покупателю, без него промокод применить нельзя.
+func (s *Bookings) Sell(actor model.Actor, sale BoxOfficeSale) (*model.Booking, *model.Payment, error) {
+	if actor.UserID == nil {
+		return nil, nil, ErrCashierRequired
+	}
+	if sale.Method != model.PaymentCash && sale.Method != model.PaymentCard {
+		return nil, nil, ErrInvalidPaymentMethod
+	}
+	if len(sale.SeatIDs) == 0 {
+		return nil, nil, errors.New("at least one seat must be selected")
+	}
+
+	var user *model.User
+	if sale.Email != "" {
+		var err error
+		if user, err = s.findOrCreateUser(sale.Email, sale.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := s.clock.Now()
+	booking := &model.Booking{
+		ID:            uuid.New(),
+		PerformanceID: sale.PerformanceID,
+		Status:        "confirmed",
+		ExpiresAt:     now,
+
+		RemindersEnabled: user != nil,
+	}
+	if user != nil {
+		booking.UserID = &user.ID
+	}
+
+	payment := &model.Payment{
+		ID:        uuid.New(),
+		BookingID: booking.ID,
+		Provider:  BoxOfficeProvider,
+		Currency:  s.currency,
+		Status:    "captured",
+		Method:    sale.Method,
+		CashierID: actor.UserID,
+		CreatedAt: now,
+	}
+	payment.IntentID = BoxOfficeProvider + ":" + payment.ID.String()
+
+	err := s.repo.Transaction(func(tx BookingsTx) error {
+		_, seats, err := s.reserve(tx, booking, user, sale.SeatIDs, sale.PromoCode, "", true)
+		if err != nil {
+			return err
+		}
+
+		payment.Amount = booking.TotalPrice
+		if sale.Method == model.PaymentCash {
+			if sale.Tendered < booking.TotalPrice {
+				return ErrInsufficientCash
+			}
+			tendered := sale.Tendered
+			payment.Tendered = &tendered
+		}
+
+		for _, seat := range seats {
+			if err := tx.UpdatePerformanceSeatStatus(seat.ID, "sold", &booking.ID); err != nil {
+				return err
+			}
+		}
+		booking.PerformanceSeats = seats
+		issued, err := issueTickets(booking)
+		if err != nil {
+			return err
+		}
+		if err := tx.CreateTickets(issued); err != nil {
+			return err
+		}
+		if err := tx.CreatePayment(payment); err != nil {
+			return err
+		}
+
+		return recordAudit(s.audit, tx, actor, "booking.sell", AuditBooking, booking.ID, nil, bookingAudit(booking, seats))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sold, err := s.repo.GetByID(booking.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sold, payment, nil
+}
+
+// MethodTotal - число и сумма операций одним способом оплаты
+type MethodTotal = repository.MethodTotal
+
+type BoxOfficeRepository interface {
+	GetPayment(bookingID uuid.UUID) (*model.Payment, error)
+	SalesByMethod(cashierID uuid.UUID, from, to time.Time) ([]repository.MethodTotal, error)
+	RefundsByMethod(cashierID uuid.UUID, from, to time.Time) ([]repository.MethodTotal, error)
+}
+
+// ShiftReport - сверка кассы кассира за смену [From, To)
+type ShiftReport struct {
+	CashierID uuid.UUID
+	From      time.Time
+	To        time.Time
+	Sales     []MethodTotal
+	Refunds   []MethodTotal // возвраты по продажам кассира
+
+	Revenue      int // продажи за вычетом возвратов
+	ExpectedCash int // наличные, которые должны остаться в кассе
+	CountedCash  *int
+	Discrepancy  *int // CountedCash - ExpectedCash
+}
+
+// BoxOffice - продажа билетов в кассе театра: продажа, чеки и сверка смены кассира
+type BoxOffice struct {
+	bookings *Bookings
+	repo     BoxOfficeRepository
+	clock    Clock
+	location *time.Location
+}
+
+// NewBoxOffice создает сервис кассы. Даты в чеках и границы смены по умолчанию
+// определяются в часовом поясе театра location.
+func NewBoxOffice(bookings *Bookings, repo BoxOfficeRepository, clock Clock, location *time.Location) *BoxOffice {
+	return &BoxOffice{
+		bookings: bookings,
+		repo:     repo,
+		clock:    clock,
+		location: location,
+	}
+}
+
+// Sell продает места в кассе, см. Bookings.Sell
+func (s *BoxOffice) Sell(actor model.Actor, sale BoxOfficeSale) (*model.Booking, *model.Payment, error) {
+	return s.bookings.Sell(actor, sale)
+}
+
+// ReceiptPDF формирует чек продажи в кассе для печати на ленте
+func (s *BoxOffice) ReceiptPDF(id string) ([]byte, error) {
+	bookingID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid booking ID format")
+	}
+
+	payment, err := s.repo.GetPayment(bookingID)
+	if err != nil {
+		return nil, ErrNotBoxOfficeSale
+	}
+	booking, err := s.bookings.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	receipt := tickets.Receipt{
+		Number:    receiptNumber(payment),
+		BookingID: booking.ID.String(),
+		IssuedAt:  payment.CreatedAt.In(s.location),
+		HallName:  booking.Performance.Hall.Name,
+		Date:      booking.Performance.Date.In(s.location),
+		Subtotal:  booking.Subtotal,
+		Discount:  booking.Discount,
+		Total:     payment.Amount,
+		Refunded:  payment.Refunded,
+		Method:    payment.Method,
+		Currency:  payment.Currency,
+	}
+	if payment.Cashier != nil {
+		receipt.Cashier = payment.Cashier.Name
+		if receipt.Cashier == "" {
+			receipt.Cashier = payment.Cashier.Email
+		}
+	}
+	if booking.Performance.Play != nil {
+		receipt.PlayTitle = booking.Performance.Play.Title
+	}
+	if payment.Tendered != nil {
+		receipt.Tendered = *payment.Tendered
+		receipt.Change = *payment.Tendered - payment.Amount
+	}
+	for _, seat := range booking.PerformanceSeats {
+		receipt.Lines = append(receipt.Lines, tickets.ReceiptLine{
+			Section: seat.Seat.Section,
+			Row:     seat.Seat.Row,
+			Number:  seat.Seat.Number,
+			Price:   seatPrice(seat),
+		})
+	}
+
+	return tickets.RenderReceiptPDF(receipt)
+}
+
+// receiptNumber - короткий номер чека для поиска продажи по распечатке
+func receiptNumber(payment *model.Payment) string {
+	return fmt.Sprintf("%X", payment.ID[:4])
+}
+
+// ShiftReport сверяет кассу кассира за смену [from, to). Без from смена считается
+// с начала текущих суток в часовом поясе театра, без to - до текущего момента.
+// Если передана пересчитанная сумма наличных counted, в отчете будет расхождение.
+func (s *BoxOffice) ShiftReport(cashierID uuid.UUID, from, to *time.Time, counted *int) (*ShiftReport, error) {
+	now := s.clock.Now()
+	report := &ShiftReport{CashierID: cashierID, To: now}
+	if to != nil {
+		report.To = *to
+	}
+	if from != nil {
+		report.From = *from
+	} else {
+		local := report.To.In(s.location)
+		report.From = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	}
+	if !report.To.After(report.From) {
+		return nil, ErrInvalidShiftPeriod
+	}
+
+	var err error
+	if report.Sales, err = s.repo.SalesByMethod(cashierID, report.From, report.To); err != nil {
+		return nil, err
+	}
+	if report.Refunds, err = s.repo.RefundsByMethod(cashierID, report.From, report.To); err != nil {
+		return nil, err
+	}
+
+	for _, sales := range report.Sales {
+		report.Revenue += sales.Amount
+		if sales.Method == model.PaymentCash {
+			report.ExpectedCash += sales.Amount
+		}
+	}
+	for _, refunds := range report.Refunds {
+		report.Revenue -= refunds.Amount
+		if refunds.Method == model.PaymentCash {
+			report.ExpectedCash -= refunds.Amount
+		}
+	}
+
+	if counted != nil {
+		discrepancy := *counted - report.ExpectedCash
+		report.CountedCash = counted
+		report.Discrepancy = &discrepancy
+	}
+	return report, nil
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockBoxOfficeRepository struct {
+	mock.Mock
+}
+
+func (m *MockBoxOfficeRepository) GetPayment(bookingID uuid.UUID) (*model.Payment, error) {
+	args := m.Called(bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockBoxOfficeRepository) SalesByMethod(cashierID uuid.UUID, from, to time.Time) ([]repository.MethodTotal, error) {
+	args := m.Called(cashierID, from, to)
+	return args.Get(0).([]repository.MethodTotal), args.Error(1)
+}
+
+func (m *MockBoxOfficeRepository) RefundsByMethod(cashierID uuid.UUID, from, to time.Time) ([]repository.MethodTotal, error) {
+	args := m.Called(cashierID, from, to)
+	return args.Get(0).([]repository.MethodTotal), args.Error(1)
+}
+
+// expectAvailableSeats ожидает запросов показа performance и двух его свободных мест
+// по 1500 и возвращает id этих мест
+func expectAvailableSeats(repo *MockBookingsRepository, performance *model.Performance) []uuid.UUID {
+	seats := []uuid.UUID{uuid.New(), uuid.New()}
+	repo.On("GetPerformanceSeatsByIDs", seats, performance.ID).Return([]model.PerformanceSeat{
+		{ID: seats[0], Price: 1500, Status: "available"},
+		{ID: seats[1], Price: 1500, Status: "available"},
+	}, nil)
+	repo.On("GetPerformance", performance.ID).Return(performance, nil)
+	repo.On("CountOccupiedSeats", performance.ID).Return(int64(0), int64(100), nil)
+	return seats
+}
+
+// expectSale ожидает сохранения проданного бронирования
+func expectSale(repo *MockBookingsRepository) {
+	repo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+		return b.Status == "confirmed" && b.TotalPrice == 3000
+	})).Return(nil)
+	repo.On("UpdatePerformanceSeatStatus", mock.Anything, "reserved", mock.AnythingOfType("*uuid.UUID")).Return(nil)
+	repo.On("SetChargedPrice", mock.Anything, 1500).Return(nil)
+	repo.On("UpdatePerformanceSeatStatus", mock.Anything, "sold", mock.AnythingOfType("*uuid.UUID")).Return(nil)
+	repo.On("CreateTickets", mock.MatchedBy(func(tickets []model.Ticket) bool {
+		return len(tickets) == 2
+	})).Return(nil)
+	repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&model.Booking{Status: "confirmed"}, nil)
+}
+
+func TestSell(t *testing.T) {
+	t.Run("cash sale with change", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+		customer := &model.User{ID: uuid.New(), Email: "anna@example.com"}
+
+		users.On("FindByEmail", "anna@example.com").Return(customer, nil)
+		expectSale(repo)
+		repo.On("CreatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Method == model.PaymentCash && p.Amount == 3000 && p.Tendered != nil && *p.Tendered == 5000 &&
+				p.CashierID == testActor.UserID && p.Provider == BoxOfficeProvider && p.Status == "captured" &&
+				p.Currency == "BYN"
+		})).Return(nil)
+
+		_, payment, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Email:         "anna@example.com",
+			Method:        model.PaymentCash,
+			Tendered:      5000,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3000, payment.Amount)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "EnqueueEmail", mock.Anything)
+	})
+
+	t.Run("walk-in card sale without customer", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+
+		repo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.UserID == nil && !b.RemindersEnabled
+		})).Return(nil)
+		expectSale(repo)
+		repo.On("CreatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Method == model.PaymentCard && p.Tendered == nil
+		})).Return(nil)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentCard,
+		})
+
+		require.NoError(t, err)
+		users.AssertNotCalled(t, "FindByEmail", mock.Anything)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("sells after online sales close", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+		performance.Status = "sales_closed"
+
+		expectSale(repo)
+		repo.On("CreatePayment", mock.Anything).Return(nil)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentCard,
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("performance started", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+		clock.now = performance.Date.Add(time.Minute)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentCard,
+		})
+
+		assert.ErrorIs(t, err, ErrPerformanceStarted)
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("insufficient cash", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+
+		repo.On("Create", mock.Anything).Return(nil)
+		repo.On("UpdatePerformanceSeatStatus", mock.Anything, "reserved", mock.AnythingOfType("*uuid.UUID")).Return(nil)
+		repo.On("SetChargedPrice", mock.Anything, 1500).Return(nil)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentCash,
+			Tendered:      2000,
+		})
+
+		assert.ErrorIs(t, err, ErrInsufficientCash)
+		repo.AssertNotCalled(t, "CreatePayment", mock.Anything)
+	})
+
+	t.Run("promo code without customer", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			PromoCode:     "SPRING",
+			Method:        model.PaymentCard,
+		})
+
+		assert.ErrorIs(t, err, ErrPromoNeedsCustomer)
+		repo.AssertNotCalled(t, "LockPromoCode", mock.Anything)
+	})
+
+	t.Run("invalid payment method", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+
+		_, _, err := service.Sell(testActor, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentOnline,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPaymentMethod)
+	})
+
+	t.Run("anonymous actor", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		users := new(MockUsersRepository)
+		service := NewBookings(repo, users, WithBookingsClock(clock), WithBookingsCurrency("BYN"))
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: clock.now.Add(time.Hour)}
+		seats := expectAvailableSeats(repo, performance)
+
+		_, _, err := service.Sell(model.Actor{}, BoxOfficeSale{
+			PerformanceID: performance.ID,
+			SeatIDs:       seats,
+			Method:        model.PaymentCard,
+		})
+
+		assert.ErrorIs(t, err, ErrCashierRequired)
+	})
+}
+
+func TestShiftReport(t *testing.T) {
+	location := time.FixedZone("MSK", 3*60*60)
+	clock := &fakeClock{now: time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)}
+	cashierID := uuid.New()
+
+	t.Run("defaults to the current day with discrepancy", func(t *testing.T) {
+		repo := new(MockBoxOfficeRepository)
+		service := NewBoxOffice(nil, repo, clock, location)
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, location)
+
+		repo.On("SalesByMethod", cashierID, from, clock.now).Return([]repository.MethodTotal{
+			{Method: model.PaymentCard, Count: 2, Amount: 4000},
+			{Method: model.PaymentCash, Count: 3, Amount: 6000},
+		}, nil)
+		repo.On("RefundsByMethod", cashierID, from, clock.now).Return([]repository.MethodTotal{
+			{Method: model.PaymentCash, Count: 1, Amount: 1500},
+		}, nil)
+
+		counted := 4400
+		report, err := service.ShiftReport(cashierID, nil, nil, &counted)
+
+		require.NoError(t, err)
+		assert.True(t, report.From.Equal(from))
+		assert.Equal(t, 8500, report.Revenue)
+		assert.Equal(t, 4500, report.ExpectedCash)
+		require.NotNil(t, report.Discrepancy)
+		assert.Equal(t, -100, *report.Discrepancy)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		service := NewBoxOffice(nil, new(MockBoxOfficeRepository), clock, location)
+		from := clock.now.Add(time.Hour)
+
+		_, err := service.ShiftReport(cashierID, &from, nil, nil)
+
+		assert.ErrorIs(t, err, ErrInvalidShiftPeriod)
+	})
+}
+
+func TestRefundBoxOfficePayment(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	repo := new(MockBookingsRepository)
+	booking, payment, seats := capturedBooking(t, payments.NewFake("secret"), clock.now)
+	service := NewRefunds(nil, repo, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+	payment.Method = model.PaymentCash
+	payment.Provider = BoxOfficeProvider
+	payment.IntentID = BoxOfficeProvider + ":" + payment.ID.String()
+
+	repo.On("LockByID", booking.ID).Return(booking, nil)
+	repo.On("Update", mock.Anything).Return(nil)
+	repo.On("UpdatePerformanceSeatStatus", mock.Anything, "available", (*uuid.UUID)(nil)).Return(nil)
+	repo.On("VoidTickets", booking.ID, seats).Return(nil)
+	repo.On("LockCapturedPayment", booking.ID).Return(payment, nil)
+	repo.On("UpdatePayment", mock.Anything).Return(nil)
+	repo.On("CreateRefund", mock.MatchedBy(func(r *model.Refund) bool {
+		return r.Amount == 3000 && r.ProviderRefundID == "" && r.PaymentID != nil && *r.PaymentID == payment.ID
+	})).Return(nil)
+	repo.On("GetByID", booking.ID).Return(booking, nil)
+
+	_, refund, err := service.CancelSeats(testActor, booking.ID.String(), nil,
+		&RefundOverride{Percent: 100, Reason: "возврат в кассе", StaffID: uuid.New()})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3000, refund.Amount)
+	repo.AssertExpectations(t)
+}
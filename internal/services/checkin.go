@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/tickets"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketVerifier проверяет подпись кода билета
+type TicketVerifier interface {
+	Verify(code string) (*tickets.Payload, error)
+}
+
+type CheckInRepository interface {
+	Transaction(fn func(tx CheckInTx) error) error
+	CountAttendance(performanceID uuid.UUID) (tickets, admitted int64, byGate map[string]int64, err error)
+}
+
+// CheckInTx - операции контроля входа, доступные внутри транзакции
+type CheckInTx interface {
+	LockTicket(id uuid.UUID) (*model.Ticket, error)
+	AdmitTicket(id uuid.UUID, gate string, at time.Time) error
+	CreateScan(scan *model.TicketScan) error
+}
+
+var (
+	ErrTicketInvalid          = errors.New("invalid ticket code")
+	ErrTicketNotFound         = errors.New("ticket not found")
+	ErrTicketVoided           = errors.New("ticket has been voided")
+	ErrTicketWrongPerformance = errors.New("ticket is for another performance")
+	ErrPerformanceCancelled   = errors.New("performance has been cancelled")
+	ErrAdmissionNotOpen       = errors.New("admission has not opened yet")
+	ErrAdmissionClosed        = errors.New("admission is closed")
+	ErrTicketAlreadyAdmitted  = errors.New("ticket already admitted")
+)
+
+// maxScanClockSkew - насколько часы сканера могут спешить относительно сервера
+const maxScanClockSkew = 5 * time.Minute
+
+// ScanInput - одно сканирование билета на входе
+type ScanInput struct {
+	Code          string
+	PerformanceID uuid.UUID // показ, на который пропускает вход
+	Gate          string
+	DeviceID      string
+	ScannedAt     time.Time // для офлайн-сканирований - время на устройстве
+	Offline       bool
+}
+
+// BatchScanResult - итог обработки одного сканирования из пакета. Scan пуст,
+// если сканирование не удалось записать.
+type BatchScanResult struct {
+	Scan *model.TicketScan
+	Err  error
+}
+
+// Attendance - посещаемость показа
+type Attendance struct {
+	Tickets  int64
+	Admitted int64
+	ByGate   map[string]int64
+}
+
+type CheckIn struct {
+	repo        CheckInRepository
+	verifier    TicketVerifier
+	clock       Clock
+	opensBefore time.Duration
+	closesAfter time.Duration
+}
+
+// NewCheckIn создает сервис контроля входа. Вход на показ открыт
+// с opensBefore до начала и до closesAfter после начала.
+func NewCheckIn(repo CheckInRepository, verifier TicketVerifier, clock Clock, opensBefore, closesAfter time.Duration) *CheckIn {
+	return &CheckIn{
+		repo:        repo,
+		verifier:    verifier,
+		clock:       clock,
+		opensBefore: opensBefore,
+		closesAfter: closesAfter,
+	}
+}
+
+// Scan проверяет билет и отмечает проход зрителя. Каждое сканирование записывается,
+// в том числе отклоненное. Для повторно предъявленного билета возвращается
+// ErrTicketAlreadyAdmitted, а время первого прохода доступно в scan.Ticket.AdmittedAt.
+func (s *CheckIn) Scan(input ScanInput) (*model.TicketScan, error) {
+	now := s.clock.Now()
+	if input.ScannedAt.IsZero() {
+		input.ScannedAt = now
+	}
+	if input.ScannedAt.After(now.Add(maxScanClockSkew)) {
+		return nil, errors.New("scan time is in the future")
+	}
+
+	scan := &model.TicketScan{
+		ID:            uuid.New(),
+		PerformanceID: input.PerformanceID,
+		Gate:          input.Gate,
+		DeviceID:      input.DeviceID,
+		Offline:       input.Offline,
+		ScannedAt:     input.ScannedAt,
+	}
+
+	var rejection error
+	err := s.repo.Transaction(func(tx CheckInTx) error {
+		ticket, reason, err := s.check(tx, input)
+		if err != nil {
+			return err
+		}
+		if ticket != nil {
+			scan.TicketID = &ticket.ID
+			scan.Ticket = ticket
+		}
+
+		rejection = reason
+		switch {
+		case reason == nil:
+			if err := tx.AdmitTicket(ticket.ID, input.Gate, input.ScannedAt); err != nil {
+				return err
+			}
+			ticket.AdmittedAt = &input.ScannedAt
+			ticket.AdmittedGate = input.Gate
+			scan.Result = model.ScanAdmitted
+		case errors.Is(reason, ErrTicketAlreadyAdmitted):
+			scan.Result = model.ScanDuplicate
+		case errors.Is(reason, ErrTicketInvalid), errors.Is(reason, ErrTicketNotFound):
+			scan.Result = model.ScanInvalid
+		default:
+			scan.Result = model.ScanRejected
+		}
+		if reason != nil {
+			scan.Reason = reason.Error()
+		}
+
+		return tx.CreateScan(scan)
+	})
+	if err != nil {
+		return nil, errors.New("failed to record scan")
+	}
+
+	return scan, rejection
+}
+
+// check возвращает билет и причину отказа в проходе; err - ошибка обращения к базе
+func (s *CheckIn) check(tx CheckInTx, input ScanInput) (*model.Ticket, error, error) {
+	payload, err := s.verifier.Verify(input.Code)
+	if err != nil {
+		return nil, ErrTicketInvalid, nil
+	}
+	if payload.PerformanceID != input.PerformanceID {
+		return nil, ErrTicketWrongPerformance, nil
+	}
+
+	ticket, err := tx.LockTicket(payload.TicketID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTicketNotFound, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Код перевыпущенного билета подписан, но больше не действует
+	if ticket.Nonce != payload.Nonce || ticket.PerformanceSeatID != payload.PerformanceSeatID {
+		return ticket, ErrTicketInvalid, nil
+	}
+	if ticket.Status != "valid" {
+		return ticket, ErrTicketVoided, nil
+	}
+	if ticket.Performance.Status == "cancelled" {
+		return ticket, ErrPerformanceCancelled, nil
+	}
+	if input.ScannedAt.Before(ticket.Performance.Date.Add(-s.opensBefore)) {
+		return ticket, ErrAdmissionNotOpen, nil
+	}
+	if input.ScannedAt.After(ticket.Performance.Date.Add(s.closesAfter)) {
+		return ticket, ErrAdmissionClosed, nil
+	}
+	if ticket.AdmittedAt != nil {
+		return ticket, ErrTicketAlreadyAdmitted, nil
+	}
+
+	return ticket, nil, nil
+}
+
+// ScanBatch обрабатывает сканирования, накопленные устройством без связи,
+// в порядке их времени на устройстве: проход засчитывается первому сканированию билета
+func (s *CheckIn) ScanBatch(performanceID uuid.UUID, gate, deviceID string, scans []ScanInput) ([]BatchScanResult, error) {
+	if len(scans) == 0 {
+		return nil, errors.New("at least one scan is required")
+	}
+
+	order := make([]int, len(scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scans[order[i]].ScannedAt.Before(scans[order[j]].ScannedAt)
+	})
+
+	// Результаты возвращаются в порядке запроса
+	results := make([]BatchScanResult, len(scans))
+	for _, i := range order {
+		input := scans[i]
+		input.PerformanceID = performanceID
+		input.Gate = gate
+		input.DeviceID = deviceID
+		input.Offline = true
+
+		// Ошибка одного сканирования не мешает обработать остальные
+		scan, err := s.Scan(input)
+		results[i] = BatchScanResult{Scan: scan, Err: err}
+	}
+
+	return results, nil
+}
+
+// GetAttendance возвращает число выпущенных билетов и прошедших зрителей показа
+func (s *CheckIn) GetAttendance(performanceID string) (*Attendance, error) {
+	id, err := uuid.Parse(performanceID)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+
+	issued, admitted, byGate, err := s.repo.CountAttendance(id)
+	if err != nil {
+		return nil, errors.New("failed to count attendance")
+	}
+	return &Attendance{Tickets: issued, Admitted: admitted, ByGate: byGate}, nil
+}
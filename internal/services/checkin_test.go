@@ -0,0 +1,210 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/tickets"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCheckInRepository struct {
+	mock.Mock
+	tx *MockCheckInTx
+}
+
+func (m *MockCheckInRepository) Transaction(fn func(tx CheckInTx) error) error {
+	return fn(m.tx)
+}
+
+func (m *MockCheckInRepository) CountAttendance(performanceID uuid.UUID) (int64, int64, map[string]int64, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Get(2).(map[string]int64), args.Error(3)
+}
+
+type MockCheckInTx struct {
+	mock.Mock
+}
+
+func (m *MockCheckInTx) LockTicket(id uuid.UUID) (*model.Ticket, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Ticket), args.Error(1)
+}
+
+func (m *MockCheckInTx) AdmitTicket(id uuid.UUID, gate string, at time.Time) error {
+	args := m.Called(id, gate, at)
+	return args.Error(0)
+}
+
+func (m *MockCheckInTx) CreateScan(scan *model.TicketScan) error {
+	args := m.Called(scan)
+	return args.Error(0)
+}
+
+// newSignedTicket создает действующий билет на показ 10 мая в 19:00 и его код, подписанный signer
+func newSignedTicket(t *testing.T, signer *tickets.Signer) (*model.Ticket, string) {
+	performance := model.Performance{ID: uuid.New(), Date: time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC), Status: "scheduled"}
+	ticket := &model.Ticket{
+		ID:                uuid.New(),
+		BookingID:         uuid.New(),
+		PerformanceID:     performance.ID,
+		PerformanceSeatID: uuid.New(),
+		Nonce:             "nonce",
+		Status:            "valid",
+		Performance:       performance,
+	}
+	code, err := signer.Sign(TicketPayload(*ticket))
+	require.NoError(t, err)
+	return ticket, code
+}
+
+func TestScan(t *testing.T) {
+	t.Run("admits valid ticket", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, code := newSignedTicket(t, signer)
+
+		tx.On("LockTicket", ticket.ID).Return(ticket, nil)
+		tx.On("AdmitTicket", ticket.ID, "north", clock.now).Return(nil)
+		tx.On("CreateScan", mock.MatchedBy(func(s *model.TicketScan) bool {
+			return s.Result == model.ScanAdmitted && *s.TicketID == ticket.ID && s.DeviceID == "scanner-1"
+		})).Return(nil)
+
+		scan, err := service.Scan(ScanInput{Code: code, PerformanceID: ticket.PerformanceID, Gate: "north", DeviceID: "scanner-1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, clock.now, *scan.Ticket.AdmittedAt)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("rejects duplicate with original admission time", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, code := newSignedTicket(t, signer)
+
+		admittedAt := clock.now.Add(-10 * time.Minute)
+		ticket.AdmittedAt = &admittedAt
+		ticket.AdmittedGate = "south"
+
+		tx.On("LockTicket", ticket.ID).Return(ticket, nil)
+		tx.On("CreateScan", mock.MatchedBy(func(s *model.TicketScan) bool {
+			return s.Result == model.ScanDuplicate
+		})).Return(nil)
+
+		scan, err := service.Scan(ScanInput{Code: code, PerformanceID: ticket.PerformanceID, Gate: "north", DeviceID: "scanner-1"})
+
+		assert.ErrorIs(t, err, ErrTicketAlreadyAdmitted)
+		assert.Equal(t, admittedAt, *scan.Ticket.AdmittedAt)
+		assert.Equal(t, "south", scan.Response().AdmittedGate)
+		tx.AssertNotCalled(t, "AdmitTicket", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("records forged code", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, _ := newSignedTicket(t, signer)
+
+		forged, err := tickets.NewSigner("other").Sign(TicketPayload(*ticket))
+		require.NoError(t, err)
+
+		tx.On("CreateScan", mock.MatchedBy(func(s *model.TicketScan) bool {
+			return s.Result == model.ScanInvalid && s.TicketID == nil
+		})).Return(nil)
+
+		_, err = service.Scan(ScanInput{Code: forged, PerformanceID: ticket.PerformanceID, Gate: "north", DeviceID: "scanner-1"})
+
+		assert.ErrorIs(t, err, ErrTicketInvalid)
+		tx.AssertNotCalled(t, "LockTicket", mock.Anything)
+	})
+
+	t.Run("ticket for another performance", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		_, code := newSignedTicket(t, signer)
+
+		tx.On("CreateScan", mock.MatchedBy(func(s *model.TicketScan) bool {
+			return s.Result == model.ScanRejected
+		})).Return(nil)
+
+		_, err := service.Scan(ScanInput{Code: code, PerformanceID: uuid.New(), Gate: "north", DeviceID: "scanner-1"})
+
+		assert.ErrorIs(t, err, ErrTicketWrongPerformance)
+	})
+
+	t.Run("admission not open yet", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, code := newSignedTicket(t, signer)
+		clock.now = ticket.Performance.Date.Add(-3 * time.Hour)
+
+		tx.On("LockTicket", ticket.ID).Return(ticket, nil)
+		tx.On("CreateScan", mock.AnythingOfType("*model.TicketScan")).Return(nil)
+
+		_, err := service.Scan(ScanInput{Code: code, PerformanceID: ticket.PerformanceID, Gate: "north", DeviceID: "scanner-1"})
+
+		assert.ErrorIs(t, err, ErrAdmissionNotOpen)
+	})
+}
+
+func TestScanBatch(t *testing.T) {
+	t.Run("first scan in device time wins", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, code := newSignedTicket(t, signer)
+
+		early := clock.now.Add(-20 * time.Minute)
+		late := clock.now.Add(-5 * time.Minute)
+
+		tx.On("LockTicket", ticket.ID).Return(ticket, nil)
+		tx.On("AdmitTicket", ticket.ID, "north", early).Return(nil).Once()
+		tx.On("CreateScan", mock.AnythingOfType("*model.TicketScan")).Return(nil)
+
+		results, err := service.ScanBatch(ticket.PerformanceID, "north", "scanner-1", []ScanInput{
+			{Code: code, ScannedAt: late},
+			{Code: code, ScannedAt: early},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, ErrTicketAlreadyAdmitted)
+		assert.Equal(t, model.ScanDuplicate, results[0].Scan.Result)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, model.ScanAdmitted, results[1].Scan.Result)
+		assert.True(t, results[1].Scan.Offline)
+	})
+
+	t.Run("scan from the future", func(t *testing.T) {
+		tx := new(MockCheckInTx)
+		signer := tickets.NewSigner("secret")
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 18, 30, 0, 0, time.UTC)}
+		service := NewCheckIn(&MockCheckInRepository{tx: tx}, signer, clock, 2*time.Hour, time.Hour)
+		ticket, code := newSignedTicket(t, signer)
+
+		results, err := service.ScanBatch(ticket.PerformanceID, "north", "scanner-1", []ScanInput{
+			{Code: code, ScannedAt: clock.now.Add(time.Hour)},
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, results[0].Scan)
+		assert.EqualError(t, results[0].Err, "scan time is in the future")
+	})
+}
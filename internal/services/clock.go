@@ -0,0 +1,17 @@
+package service
+
+import "time"
+
+// Clock - источник текущего времени, в тестах подменяется фиксированными часами
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock возвращает реальное время
+var SystemClock Clock = systemClock{}
@@ -0,0 +1,545 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPostponement = errors.New("invalid postponement target")
+
+// Причины, по которым бронирование не удалось перенести или отменить автоматически
+const (
+	unresolvedSeatsTaken   = "seats are taken on the new performance"
+	unresolvedSeatsMissing = "seats do not exist on the new performance"
+)
+
+type DisruptionsRepository interface {
+	GetByID(id uuid.UUID) (*model.Performance, error)
+	GetSeats(performanceID uuid.UUID) ([]model.PerformanceSeat, error)
+	Transaction(fn func(tx PerformancesTx) error) error
+}
+
+type DisruptedBookingsRepository interface {
+	ActiveBookingIDs(performanceID uuid.UUID) ([]uuid.UUID, error)
+	Transaction(fn func(tx BookingsTx) error) error
+}
+
+// PostponeTarget - куда переносится показ: на существующий показ того же спектакля
+// или на новую дату, под которую создается показ в том же зале
+type PostponeTarget struct {
+	PerformanceID *uuid.UUID
+	Date          *time.Time
+}
+
+// UnresolvedBooking - бронирование, которое не удалось обработать автоматически
+type UnresolvedBooking struct {
+	BookingID uuid.UUID
+	Booking   *model.Booking // nil, если бронирование не удалось загрузить
+	Reason    string
+}
+
+// DisruptionReport - итог отмены или переноса показа
+type DisruptionReport struct {
+	Performance *model.Performance
+	Target      *model.Performance // показ, на который перенесены бронирования
+	Moved       []uuid.UUID
+	Cancelled   []uuid.UUID
+	Refunded    int // сумма возвратов
+	Unresolved  []UnresolvedBooking
+}
+
+// Disruptions - отмена и перенос показов с бронированиями. Каждое бронирование
+// обрабатывается в своей транзакции, поэтому сбой одного не откатывает остальные:
+// оно попадает в отчет, а повторный вызов обрабатывает оставшиеся бронирования.
+type Disruptions struct {
+	repo         DisruptionsRepository
+	bookingsRepo DisruptedBookingsRepository
+	schedule     *Schedule
+	refunds      *Refunds
+	clock        Clock
+	emails       *EmailService
+	audit        *Audit
+}
+
+// NewDisruptions создает сервис отмены и переноса показов. emails и audit необязательны.
+func NewDisruptions(repo DisruptionsRepository, bookingsRepo DisruptedBookingsRepository, schedule *Schedule, refunds *Refunds, clock Clock, emails *EmailService, audit *Audit) *Disruptions {
+	return &Disruptions{
+		repo:         repo,
+		bookingsRepo: bookingsRepo,
+		schedule:     schedule,
+		refunds:      refunds,
+		clock:        clock,
+		emails:       emails,
+		audit:        audit,
+	}
+}
+
+// Cancel отменяет показ: отменяет все его бронирования, освобождает места, возвращает
+// полную стоимость оплаченных бронирований и сообщает покупателям об отмене.
+// Для уже отмененного показа обрабатываются бронирования, оставшиеся от прошлой попытки.
+func (s *Disruptions) Cancel(actor model.Actor, id string) (*DisruptionReport, error) {
+	performance, err := s.getPerformance(id)
+	if err != nil {
+		return nil, err
+	}
+	// Письма покупателям, чьи бронирования не удалось отменить, отправляются только при первой отмене
+	firstRun := performance.Status != PerformanceCancelled
+	if err := s.setStatus(actor, performance, PerformanceCancelled, "performance.cancel"); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.bookingsRepo.ActiveBookingIDs(performance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DisruptionReport{Performance: performance}
+	for _, bookingID := range ids {
+		booking, refund, err := s.cancelBooking(actor, bookingID)
+		switch {
+		case err != nil:
+			report.Unresolved = append(report.Unresolved, UnresolvedBooking{BookingID: bookingID, Booking: booking, Reason: err.Error()})
+			if booking != nil && firstRun {
+				data := bookingEmailData(booking, &booking.User, &booking.Performance, booking.PerformanceSeats)
+				if booking.Status != "confirmed" {
+					data.Total = 0
+				}
+				if err := s.notify(notifications.TemplatePerformanceCancelled, booking, data); err != nil {
+					return nil, err
+				}
+			}
+		case booking != nil:
+			report.Cancelled = append(report.Cancelled, bookingID)
+			if refund != nil {
+				report.Refunded += refund.Amount
+			}
+		}
+	}
+	return report, nil
+}
+
+// cancelBooking отменяет бронирование отмененного показа и возвращает деньги за него.
+// Возвращает nil, если бронирование уже не активно. Если вернуть деньги не удалось,
+// бронирование остается как было и возвращается вместе с ошибкой.
+func (s *Disruptions) cancelBooking(actor model.Actor, id uuid.UUID) (*model.Booking, *model.Refund, error) {
+	var loaded *model.Booking
+	var status string
+	var refund *model.Refund
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		booking, err := tx.LockByID(id)
+		if err != nil {
+			return err
+		}
+		if booking.Status != "pending" && booking.Status != "confirmed" {
+			return nil
+		}
+		loaded, status = booking, booking.Status
+
+		before := bookingAudit(booking, booking.PerformanceSeats)
+		paid := booking.Status == "confirmed"
+		booking.Status = "cancelled"
+		if err := tx.Update(booking); err != nil {
+			return err
+		}
+
+		seatIDs := make([]uuid.UUID, len(booking.PerformanceSeats))
+		for i, seat := range booking.PerformanceSeats {
+			seatIDs[i] = seat.ID
+			if err := tx.UpdatePerformanceSeatStatus(seat.ID, "available", nil); err != nil {
+				return err
+			}
+		}
+		if booking.PromoCodeID != nil {
+			if err := tx.ReleasePromoCode(booking.ID); err != nil {
+				return err
+			}
+		}
+		err = recordAudit(s.audit, tx, actor, "booking.cancel", AuditBooking, booking.ID, before, bookingAudit(booking, nil))
+		if err != nil {
+			return err
+		}
+
+		data := bookingEmailData(booking, &booking.User, &booking.Performance, booking.PerformanceSeats)
+		if !paid {
+			// Неоплаченные бронирования возвращать не нужно
+			data.Total = 0
+		}
+		if err := enqueueEmail(s.emails, tx, notifications.TemplatePerformanceCancelled, booking.User.Email, data); err != nil {
+			return err
+		}
+		if !paid {
+			return nil
+		}
+
+		if err := tx.VoidTickets(booking.ID, seatIDs); err != nil {
+			return err
+		}
+		// Деньги возвращаются последним шагом: если платежная система откажет,
+		// бронирование останется активным и попадет в отчет
+		refund = cancellationRefund(booking, s.clock.Now())
+		return s.refunds.issue(tx, refund)
+	})
+	if err != nil {
+		if loaded != nil {
+			// Транзакция откатилась: бронирование осталось активным
+			loaded.Status = status
+		}
+		return loaded, nil, err
+	}
+	return loaded, refund, nil
+}
+
+// Postpone переносит показ: переводит его в статус postponed и пересаживает все
+// бронирования на те же места показа target. Если target задан датой, показ
+// создается в том же зале с тем же тарифом. Бронирования, места которых на новом
+// показе заняты или отсутствуют, остаются на прежнем показе и попадают в отчет.
+// Покупатели получают письмо о переносе в обоих случаях.
+//
+// Повторный перенос уже перенесенного показа на тот же показ или ту же дату
+// обрабатывает оставшиеся бронирования, не создавая новый показ. Перенос на
+// другой показ или другую дату отклоняется.
+func (s *Disruptions) Postpone(actor model.Actor, id string, target PostponeTarget) (*DisruptionReport, error) {
+	performance, err := s.getPerformance(id)
+	if err != nil {
+		return nil, err
+	}
+	if (target.PerformanceID == nil) == (target.Date == nil) {
+		return nil, fmt.Errorf("%w: either performance or date is required", ErrInvalidPostponement)
+	}
+	if target.Date != nil && !target.Date.After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: new date is in the past", ErrInvalidPostponement)
+	}
+
+	var next *model.Performance
+	var seats []model.PerformanceSeat
+	if target.PerformanceID != nil {
+		next, err = s.targetPerformance(performance, *target.PerformanceID)
+	} else {
+		next, seats, err = s.schedule.newPerformance(performance.PlayID, performance.HallID, *target.Date,
+			performance.PricePlanID, performance.Premiere, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Письма о том, что бронирование не перенесено, отправляются только при первом переносе
+	var firstRun bool
+	err = s.repo.Transaction(func(tx PerformancesTx) error {
+		// Блокировка показа не дает параллельному переносу создать второй показ
+		locked, err := tx.LockByID(performance.ID)
+		if err != nil {
+			return err
+		}
+		performance = locked
+
+		if performance.Status == PerformancePostponed {
+			next, err = s.postponedTo(tx, performance, target, next)
+			return err
+		}
+		if !canTransition(performance.Status, PerformancePostponed) {
+			return fmt.Errorf("%w: %s performance cannot be %s", ErrInvalidTransition, performance.Status, PerformancePostponed)
+		}
+		firstRun = true
+
+		if seats != nil {
+			if performance.Status == PerformanceOnSale || performance.Status == PerformanceSalesClosed {
+				next.Status = PerformanceOnSale
+			}
+			if err := s.schedule.insertPerformance(tx, actor, next, seats); err != nil {
+				return err
+			}
+			next.Hall = performance.Hall
+		}
+
+		before := performanceAudit(performance)
+		performance.Status = PerformancePostponed
+		performance.PostponedToID = &next.ID
+		if err := tx.Update(performance); err != nil {
+			return err
+		}
+		err = recordAudit(s.audit, tx, actor, "performance.postpone", AuditPerformance, performance.ID, before, performanceAudit(performance))
+		if err != nil {
+			return err
+		}
+		return tx.ReleaseHolds(performance.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap, err := s.mapSeats(performance, next)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.bookingsRepo.ActiveBookingIDs(performance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DisruptionReport{Performance: performance, Target: next}
+	for _, bookingID := range ids {
+		booking, err := s.moveBooking(actor, bookingID, performance, next, seatMap)
+		if err == nil {
+			if booking != nil {
+				report.Moved = append(report.Moved, bookingID)
+			}
+			continue
+		}
+
+		report.Unresolved = append(report.Unresolved, UnresolvedBooking{BookingID: bookingID, Booking: booking, Reason: err.Error()})
+		if booking != nil && firstRun {
+			data := bookingEmailData(booking, &booking.User, next, nil)
+			data.PreviousDate = performance.Date
+			if err := s.notify(notifications.TemplatePerformancePostponed, booking, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// postponedTo возвращает показ, на который уже перенесен показ performance,
+// если target указывает на него. requested - показ target, проверенный до блокировки.
+func (s *Disruptions) postponedTo(tx PerformancesTx, performance *model.Performance, target PostponeTarget, requested *model.Performance) (*model.Performance, error) {
+	if performance.PostponedToID == nil {
+		// Показ перенесен до того, как стал сохраняться показ переноса:
+		// по дате нельзя понять, создан ли для нее показ
+		if target.Date != nil {
+			return nil, fmt.Errorf("%w: performance is already postponed", ErrInvalidPostponement)
+		}
+		return requested, nil
+	}
+
+	next, err := tx.LockByID(*performance.PostponedToID)
+	if err != nil {
+		return nil, err
+	}
+	if target.PerformanceID != nil && *target.PerformanceID != next.ID {
+		return nil, fmt.Errorf("%w: performance is already postponed to another performance", ErrInvalidPostponement)
+	}
+	if target.Date != nil && !target.Date.Equal(next.Date) {
+		return nil, fmt.Errorf("%w: performance is already postponed to another date", ErrInvalidPostponement)
+	}
+	return next, nil
+}
+
+// targetPerformance проверяет, что на показ targetID можно перенести показ performance
+func (s *Disruptions) targetPerformance(performance *model.Performance, targetID uuid.UUID) (*model.Performance, error) {
+	if targetID == performance.ID {
+		return nil, fmt.Errorf("%w: performance cannot be moved to itself", ErrInvalidPostponement)
+	}
+	target, err := s.repo.GetByID(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: performance not found", ErrInvalidPostponement)
+	}
+	if target.PlayID != performance.PlayID {
+		return nil, fmt.Errorf("%w: performance of another play", ErrInvalidPostponement)
+	}
+	if target.Status != PerformanceScheduled && target.Status != PerformanceOnSale {
+		return nil, fmt.Errorf("%w: %s performance", ErrInvalidPostponement, target.Status)
+	}
+	if !target.Date.After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: performance has already started", ErrInvalidPostponement)
+	}
+	return target, nil
+}
+
+// mapSeats сопоставляет места показа from местам показа to. В том же зале место
+// сопоставляется само себе, в другом - месту с той же секцией, рядом и номером.
+func (s *Disruptions) mapSeats(from, to *model.Performance) (map[uuid.UUID]model.PerformanceSeat, error) {
+	fromSeats, err := s.repo.GetSeats(from.ID)
+	if err != nil {
+		return nil, err
+	}
+	toSeats, err := s.repo.GetSeats(to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	type seatKey struct {
+		section     string
+		row, number int
+		hallSeatID  uuid.UUID
+	}
+	key := func(seat model.PerformanceSeat) seatKey {
+		if from.HallID == to.HallID {
+			return seatKey{hallSeatID: seat.SeatID}
+		}
+		return seatKey{section: seat.Seat.Section, row: seat.Seat.Row, number: seat.Seat.Number}
+	}
+
+	byKey := make(map[seatKey]model.PerformanceSeat, len(toSeats))
+	for _, seat := range toSeats {
+		byKey[key(seat)] = seat
+	}
+	seatMap := make(map[uuid.UUID]model.PerformanceSeat, len(fromSeats))
+	for _, seat := range fromSeats {
+		if target, ok := byKey[key(seat)]; ok {
+			seatMap[seat.ID] = target
+		}
+	}
+	return seatMap, nil
+}
+
+// moveBooking пересаживает бронирование на места показа to. Цена мест и статус
+// оплаты сохраняются, билеты оплаченного бронирования перевыпускаются.
+// Возвращает nil, если бронирование уже не активно.
+func (s *Disruptions) moveBooking(actor model.Actor, id uuid.UUID, from, to *model.Performance, seatMap map[uuid.UUID]model.PerformanceSeat) (*model.Booking, error) {
+	var loaded *model.Booking
+	var oldSeats []model.PerformanceSeat
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		booking, err := tx.LockByID(id)
+		if err != nil {
+			return err
+		}
+		if booking.Status != "pending" && booking.Status != "confirmed" {
+			return nil
+		}
+		loaded, oldSeats = booking, booking.PerformanceSeats
+
+		oldIDs := make([]uuid.UUID, len(oldSeats))
+		newIDs := make([]uuid.UUID, len(oldSeats))
+		for i, seat := range oldSeats {
+			target, ok := seatMap[seat.ID]
+			if !ok {
+				return errors.New(unresolvedSeatsMissing)
+			}
+			oldIDs[i] = seat.ID
+			newIDs[i] = target.ID
+		}
+
+		locked, err := tx.GetPerformanceSeatsByIDs(newIDs, to.ID)
+		if err != nil {
+			return err
+		}
+		if len(locked) != len(newIDs) {
+			return errors.New(unresolvedSeatsTaken)
+		}
+		newSeats := make(map[uuid.UUID]model.PerformanceSeat, len(locked))
+		for _, seat := range locked {
+			newSeats[seat.ID] = seat
+		}
+
+		before := bookingAudit(booking, oldSeats)
+		paid := booking.Status == "confirmed"
+		moved := make([]model.PerformanceSeat, len(oldSeats))
+		for i, seat := range oldSeats {
+			price := seatPrice(seat)
+			target := newSeats[newIDs[i]]
+			target.ChargedPrice = &price
+
+			err := tx.UpdatePerformanceSeatStatus(target.ID, "reserved", &booking.ID)
+			if errors.Is(err, repository.ErrSeatNotAvailable) {
+				return errors.New(unresolvedSeatsTaken)
+			}
+			if err != nil {
+				return err
+			}
+			if paid {
+				if err := tx.UpdatePerformanceSeatStatus(target.ID, "sold", &booking.ID); err != nil {
+					return err
+				}
+			}
+			if err := tx.SetChargedPrice(target.ID, price); err != nil {
+				return err
+			}
+			if err := tx.UpdatePerformanceSeatStatus(seat.ID, "available", nil); err != nil {
+				return err
+			}
+			moved[i] = target
+		}
+
+		booking.PerformanceID = to.ID
+		booking.PerformanceSeats = moved
+		if err := tx.Update(booking); err != nil {
+			return err
+		}
+		err = recordAudit(s.audit, tx, actor, "booking.move", AuditBooking, booking.ID, before, bookingAudit(booking, moved))
+		if err != nil {
+			return err
+		}
+
+		if paid {
+			if err := tx.VoidTickets(booking.ID, oldIDs); err != nil {
+				return err
+			}
+			issued, err := issueTickets(booking)
+			if err != nil {
+				return err
+			}
+			if err := tx.CreateTickets(issued); err != nil {
+				return err
+			}
+		}
+
+		data := bookingEmailData(booking, &booking.User, to, moved)
+		data.PreviousDate = from.Date
+		data.Moved = true
+		return enqueueEmail(s.emails, tx, notifications.TemplatePerformancePostponed, booking.User.Email, data)
+	})
+	if err != nil {
+		if loaded != nil {
+			// Транзакция откатилась: бронирование осталось на прежних местах
+			loaded.PerformanceID = from.ID
+			loaded.PerformanceSeats = oldSeats
+		}
+		return loaded, err
+	}
+	return loaded, nil
+}
+
+// notify отправляет покупателю письмо о бронировании, которое не удалось обработать
+// автоматически: транзакция обработки откатилась вместе с письмом
+func (s *Disruptions) notify(template string, booking *model.Booking, data notifications.BookingData) error {
+	return s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		return enqueueEmail(s.emails, tx, template, booking.User.Email, data)
+	})
+}
+
+func (s *Disruptions) getPerformance(id string) (*model.Performance, error) {
+	performanceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+	performance, err := s.repo.GetByID(performanceID)
+	if err != nil {
+		return nil, errors.New("performance not found")
+	}
+	return performance, nil
+}
+
+// setStatus переводит показ в статус status и снимает удержания его мест.
+// Если показ уже в этом статусе, ничего не меняется.
+func (s *Disruptions) setStatus(actor model.Actor, performance *model.Performance, status, action string) error {
+	if performance.Status == status {
+		return nil
+	}
+	if !canTransition(performance.Status, status) {
+		return fmt.Errorf("%w: %s performance cannot be %s", ErrInvalidTransition, performance.Status, status)
+	}
+
+	before := performanceAudit(performance)
+	previous := performance.Status
+	performance.Status = status
+	err := s.repo.Transaction(func(tx PerformancesTx) error {
+		if err := tx.Update(performance); err != nil {
+			return err
+		}
+		if err := recordAudit(s.audit, tx, actor, action, AuditPerformance, performance.ID, before, performanceAudit(performance)); err != nil {
+			return err
+		}
+		return tx.ReleaseHolds(performance.ID)
+	})
+	if err != nil {
+		performance.Status = previous
+		return err
+	}
+	return nil
+}
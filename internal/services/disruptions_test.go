@@ -0,0 +1,468 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// upcomingPerformance создает показ на продаже через три дня после now
+func upcomingPerformance(now time.Time) *model.Performance {
+	return &model.Performance{
+		ID:     uuid.New(),
+		PlayID: uuid.New(),
+		HallID: uuid.New(),
+		Date:   now.Add(72 * time.Hour),
+		Status: "on_sale",
+		Play:   &model.Play{Title: "Гамлет"},
+	}
+}
+
+// paidBooking создает оплаченное бронирование мест seats показа performance по 1500 и платеж за него
+func paidBooking(t *testing.T, provider *payments.Fake, performance *model.Performance, seats ...model.PerformanceSeat) (*model.Booking, *model.Payment) {
+	price := 1500
+	booking := &model.Booking{
+		ID:            uuid.New(),
+		PerformanceID: performance.ID,
+		Status:        "confirmed",
+		Subtotal:      price * len(seats),
+		TotalPrice:    price * len(seats),
+		User:          model.User{Email: "paid@example.com"},
+		Performance:   *performance,
+	}
+	for _, seat := range seats {
+		seat.ChargedPrice = &price
+		seat.Status = "sold"
+		booking.PerformanceSeats = append(booking.PerformanceSeats, seat)
+	}
+
+	intent, err := provider.CreateIntent(booking.ID, booking.TotalPrice, "BYN")
+	require.NoError(t, err)
+	_, err = provider.Capture(intent.ID, "tok_visa")
+	require.NoError(t, err)
+	payment := &model.Payment{ID: uuid.New(), BookingID: booking.ID, IntentID: intent.ID, Amount: booking.TotalPrice, Status: "captured"}
+	return booking, payment
+}
+
+func performanceSeat(performanceID uuid.UUID, row, number int) model.PerformanceSeat {
+	return model.PerformanceSeat{
+		ID:            uuid.New(),
+		PerformanceID: performanceID,
+		SeatID:        uuid.New(),
+		Price:         1500,
+		Status:        "available",
+		Seat:          model.Seat{Section: "Партер", Row: row, Number: number},
+	}
+}
+
+func TestDisruptionsCancel(t *testing.T) {
+	t.Run("refunds paid bookings and notifies customers", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		refunds := NewRefunds(nil, bookings, provider, testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		paid, payment := paidBooking(t, provider, performance, performanceSeat(performance.ID, 1, 1), performanceSeat(performance.ID, 1, 2))
+		unpaid := &model.Booking{
+			ID:               uuid.New(),
+			Status:           "pending",
+			TotalPrice:       1500,
+			User:             model.User{Email: "unpaid@example.com"},
+			Performance:      *performance,
+			PerformanceSeats: []model.PerformanceSeat{performanceSeat(performance.ID, 2, 1)},
+		}
+
+		tx.On("Update", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.Status == "cancelled"
+		})).Return(nil)
+		tx.On("ReleaseHolds", performance.ID).Return(nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{paid.ID, unpaid.ID}, nil)
+		bookings.On("LockByID", paid.ID).Return(paid, nil)
+		bookings.On("LockByID", unpaid.ID).Return(unpaid, nil)
+		bookings.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "cancelled"
+		})).Return(nil).Twice()
+		bookings.On("UpdatePerformanceSeatStatus", mock.Anything, "available", (*uuid.UUID)(nil)).Return(nil).Times(3)
+		bookings.On("VoidTickets", paid.ID, []uuid.UUID{paid.PerformanceSeats[0].ID, paid.PerformanceSeats[1].ID}).Return(nil)
+		bookings.On("LockCapturedPayment", paid.ID).Return(payment, nil)
+		bookings.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Refunded == 3000 && p.Status == "refunded"
+		})).Return(nil)
+		bookings.On("CreateRefund", mock.MatchedBy(func(r *model.Refund) bool {
+			return r.Amount == 3000 && r.Percent == 100 && len(r.Items) == 2 && r.ProviderRefundID != ""
+		})).Return(nil)
+		bookings.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+			return m.Recipient == "paid@example.com" && strings.Contains(m.TextBody, "3000 BYN будет возвращена")
+		})).Return(nil).Once()
+		bookings.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+			return m.Recipient == "unpaid@example.com" && !strings.Contains(m.TextBody, "будет возвращена")
+		})).Return(nil).Once()
+
+		report, err := service.Cancel(testActor, performance.ID.String())
+
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{paid.ID, unpaid.ID}, report.Cancelled)
+		assert.Equal(t, 3000, report.Refunded)
+		assert.Empty(t, report.Unresolved)
+		tx.AssertExpectations(t)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("failed refund is reported", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		refunds := NewRefunds(nil, bookings, provider, testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		paid, payment := paidBooking(t, provider, performance, performanceSeat(performance.ID, 1, 1))
+		payment.IntentID = "pi_unknown"
+
+		tx.On("Update", mock.Anything).Return(nil)
+		tx.On("ReleaseHolds", performance.ID).Return(nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{paid.ID}, nil)
+		bookings.On("LockByID", paid.ID).Return(paid, nil)
+		bookings.On("Update", mock.Anything).Return(nil)
+		bookings.On("UpdatePerformanceSeatStatus", mock.Anything, "available", (*uuid.UUID)(nil)).Return(nil)
+		bookings.On("VoidTickets", paid.ID, mock.Anything).Return(nil)
+		bookings.On("LockCapturedPayment", paid.ID).Return(payment, nil)
+		bookings.On("EnqueueEmail", mock.Anything).Return(nil)
+
+		report, err := service.Cancel(testActor, performance.ID.String())
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Cancelled)
+		require.Len(t, report.Unresolved, 1)
+		assert.Equal(t, paid.ID, report.Unresolved[0].BookingID)
+		assert.Equal(t, "confirmed", report.Unresolved[0].Booking.Status)
+		assert.Equal(t, ErrRefundFailed.Error(), report.Unresolved[0].Reason)
+		bookings.AssertNotCalled(t, "CreateRefund", mock.Anything)
+	})
+
+	t.Run("retry on cancelled performance", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		performance.Status = "cancelled"
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{}, nil)
+
+		report, err := service.Cancel(testActor, performance.ID.String())
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Unresolved)
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("completed performance", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		performance.Status = "completed"
+
+		_, err := service.Cancel(testActor, performance.ID.String())
+
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+		bookings.AssertNotCalled(t, "ActiveBookingIDs", mock.Anything)
+	})
+
+	t.Run("invalid uuid format", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+
+		_, err := service.Cancel(testActor, "invalid-uuid")
+
+		assert.EqualError(t, err, "invalid performance ID format")
+	})
+}
+
+func TestDisruptionsPostpone(t *testing.T) {
+	// newTarget создает показ того же спектакля в том же зале через неделю
+	newTarget := func(repo *MockScheduleRepository, performance *model.Performance) *model.Performance {
+		target := &model.Performance{
+			ID:     uuid.New(),
+			PlayID: performance.PlayID,
+			HallID: performance.HallID,
+			Date:   performance.Date.Add(7 * 24 * time.Hour),
+			Status: "scheduled",
+			Play:   performance.Play,
+		}
+		repo.On("GetByID", target.ID).Return(target, nil)
+		return target
+	}
+
+	// sameSeat создает место показа target на месте зала seat
+	sameSeat := func(target *model.Performance, seat model.PerformanceSeat) model.PerformanceSeat {
+		moved := performanceSeat(target.ID, seat.Seat.Row, seat.Seat.Number)
+		moved.SeatID = seat.SeatID
+		return moved
+	}
+
+	t.Run("moves bookings to the same seats", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		refunds := NewRefunds(nil, bookings, provider, testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		target := newTarget(repo, performance)
+		old := []model.PerformanceSeat{performanceSeat(performance.ID, 3, 11), performanceSeat(performance.ID, 3, 12)}
+		moved := []model.PerformanceSeat{sameSeat(target, old[0]), sameSeat(target, old[1])}
+		booking, _ := paidBooking(t, provider, performance, old...)
+
+		tx.On("Update", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.ID == performance.ID && p.Status == "postponed"
+		})).Return(nil)
+		tx.On("ReleaseHolds", performance.ID).Return(nil)
+		repo.On("GetSeats", performance.ID).Return(old, nil)
+		repo.On("GetSeats", target.ID).Return(moved, nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{booking.ID}, nil)
+		bookings.On("LockByID", booking.ID).Return(booking, nil)
+		bookings.On("GetPerformanceSeatsByIDs", []uuid.UUID{moved[0].ID, moved[1].ID}, target.ID).Return(moved, nil)
+		for i := range old {
+			bookings.On("UpdatePerformanceSeatStatus", moved[i].ID, "reserved", &booking.ID).Return(nil)
+			bookings.On("UpdatePerformanceSeatStatus", moved[i].ID, "sold", &booking.ID).Return(nil)
+			bookings.On("SetChargedPrice", moved[i].ID, 1500).Return(nil)
+			bookings.On("UpdatePerformanceSeatStatus", old[i].ID, "available", (*uuid.UUID)(nil)).Return(nil)
+		}
+		bookings.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.PerformanceID == target.ID && b.Status == "confirmed"
+		})).Return(nil)
+		bookings.On("VoidTickets", booking.ID, []uuid.UUID{old[0].ID, old[1].ID}).Return(nil)
+		bookings.On("CreateTickets", mock.MatchedBy(func(tickets []model.Ticket) bool {
+			return len(tickets) == 2 && tickets[0].PerformanceID == target.ID && tickets[0].PerformanceSeatID == moved[0].ID
+		})).Return(nil)
+		bookings.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+			return m.Recipient == "paid@example.com" && strings.Contains(m.TextBody, "места остались прежними")
+		})).Return(nil).Once()
+
+		report, err := service.Postpone(testActor, performance.ID.String(), PostponeTarget{PerformanceID: &target.ID})
+
+		require.NoError(t, err)
+		assert.Equal(t, target, report.Target)
+		assert.Equal(t, []uuid.UUID{booking.ID}, report.Moved)
+		assert.Empty(t, report.Unresolved)
+		tx.AssertExpectations(t)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("taken seats are reported", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		refunds := NewRefunds(nil, bookings, provider, testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		target := newTarget(repo, performance)
+		old := []model.PerformanceSeat{performanceSeat(performance.ID, 3, 11), performanceSeat(performance.ID, 3, 12)}
+		moved := []model.PerformanceSeat{sameSeat(target, old[0]), sameSeat(target, old[1])}
+		booking, _ := paidBooking(t, provider, performance, old...)
+
+		tx.On("Update", mock.Anything).Return(nil)
+		tx.On("ReleaseHolds", performance.ID).Return(nil)
+		repo.On("GetSeats", performance.ID).Return(old, nil)
+		repo.On("GetSeats", target.ID).Return(moved, nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{booking.ID}, nil)
+		bookings.On("LockByID", booking.ID).Return(booking, nil)
+		// Второе место на новом показе уже продано
+		bookings.On("GetPerformanceSeatsByIDs", mock.Anything, target.ID).Return(moved[:1], nil)
+		bookings.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+			return strings.Contains(m.TextBody, "перенести ваше бронирование автоматически не удалось")
+		})).Return(nil).Once()
+
+		report, err := service.Postpone(testActor, performance.ID.String(), PostponeTarget{PerformanceID: &target.ID})
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Moved)
+		require.Len(t, report.Unresolved, 1)
+		assert.Equal(t, unresolvedSeatsTaken, report.Unresolved[0].Reason)
+		assert.Equal(t, performance.ID, report.Unresolved[0].Booking.PerformanceID)
+		bookings.AssertNotCalled(t, "Update", mock.Anything)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("seats are matched by row and number in another hall", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		target := newTarget(repo, performance)
+		target.HallID = uuid.New()
+		old := performanceSeat(performance.ID, 3, 11)
+
+		repo.On("GetSeats", performance.ID).Return([]model.PerformanceSeat{old}, nil)
+		repo.On("GetSeats", target.ID).Return([]model.PerformanceSeat{
+			performanceSeat(target.ID, 3, 12),
+			performanceSeat(target.ID, 3, 11),
+		}, nil)
+
+		seatMap, err := service.mapSeats(performance, target)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, seatMap[old.ID].Seat.Row)
+		assert.Equal(t, 11, seatMap[old.ID].Seat.Number)
+	})
+
+	t.Run("new date creates performance in the same hall", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		schedule := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, clock, nil)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, schedule, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		date := performance.Date.Add(14 * 24 * time.Hour)
+		playsRepo.On("GetByID", performance.PlayID).Return(&model.Play{ID: performance.PlayID, Duration: 120}, nil)
+		seatsRepo.On("GetByHallID", performance.HallID).Return([]model.Seat{{ID: uuid.New(), Row: 1, Number: 1}}, nil)
+
+		tx.On("Update", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.Status == "postponed" && p.PostponedToID != nil
+		})).Return(nil)
+		tx.On("ReleaseHolds", performance.ID).Return(nil)
+		tx.On("LockHall", performance.HallID).Return(nil)
+		tx.On("FindOverlapping", performance.HallID, date, mock.Anything, 30*time.Minute, mock.Anything).Return([]model.Performance{}, nil)
+		tx.On("Create", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.Date.Equal(date) && p.HallID == performance.HallID && p.Status == "on_sale"
+		})).Return(nil)
+		tx.On("CreateSeats", mock.Anything).Return(nil)
+		repo.On("GetSeats", mock.Anything).Return([]model.PerformanceSeat{}, nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{}, nil)
+
+		report, err := service.Postpone(testActor, performance.ID.String(), PostponeTarget{Date: &date})
+
+		require.NoError(t, err)
+		require.NotNil(t, report.Target)
+		assert.Equal(t, date, report.Target.Date)
+		assert.Equal(t, &report.Target.ID, performance.PostponedToID)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("repeated postponement to the same date reuses the new performance", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		schedule := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, clock, nil)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, schedule, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		next := newTarget(repo, performance)
+		performance.Status = "postponed"
+		performance.PostponedToID = &next.ID
+		playsRepo.On("GetByID", performance.PlayID).Return(&model.Play{ID: performance.PlayID, Duration: 120}, nil)
+		seatsRepo.On("GetByHallID", performance.HallID).Return([]model.Seat{{ID: uuid.New(), Row: 1, Number: 1}}, nil)
+		tx.On("LockByID", next.ID).Return(next, nil)
+		repo.On("GetSeats", mock.Anything).Return([]model.PerformanceSeat{}, nil)
+		bookings.On("ActiveBookingIDs", performance.ID).Return([]uuid.UUID{}, nil)
+
+		report, err := service.Postpone(testActor, performance.ID.String(), PostponeTarget{Date: &next.Date})
+
+		require.NoError(t, err)
+		assert.Equal(t, next, report.Target)
+		tx.AssertNotCalled(t, "Create", mock.Anything)
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+
+		other := next.Date.Add(24 * time.Hour)
+		_, err = service.Postpone(testActor, performance.ID.String(), PostponeTarget{Date: &other})
+		assert.ErrorIs(t, err, ErrInvalidPostponement)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		bookings := new(MockBookingsRepository)
+		refunds := NewRefunds(nil, bookings, payments.NewFake("secret"), testRefundPolicy, clock, nil, nil, nil)
+		emails := newTestEmailService(t, clock)
+		service := NewDisruptions(repo, bookings, nil, refunds, clock, emails, nil)
+
+		performance := upcomingPerformance(clock.now)
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		other := &model.Performance{ID: uuid.New(), PlayID: uuid.New(), Status: "on_sale", Date: performance.Date}
+		repo.On("GetByID", other.ID).Return(other, nil)
+		past := clock.now.Add(-time.Hour)
+
+		for name, target := range map[string]PostponeTarget{
+			"no target":        {},
+			"both targets":     {PerformanceID: &other.ID, Date: &past},
+			"past date":        {Date: &past},
+			"another play":     {PerformanceID: &other.ID},
+			"same performance": {PerformanceID: &performance.ID},
+		} {
+			_, err := service.Postpone(testActor, performance.ID.String(), target)
+			assert.ErrorIs(t, err, ErrInvalidPostponement, name)
+		}
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
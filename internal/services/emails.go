@@ -1,19 +1,40 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"log"
 	"math/big"
-	"net/smtp"
-	"theater-ticket-system/internal/config"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+const verificationCodeTTL = 10 * time.Minute
+
+// EmailEnqueuer - транзакция, в которой письмо ставится в outbox вместе с изменением данных
+type EmailEnqueuer interface {
+	EnqueueEmail(message *model.OutboxMessage) error
+}
+
+// EmailSender доставляет письма получателям
+type EmailSender interface {
+	Send(msg notifications.Message) error
+}
+
 type EmailService struct {
-	cfg *config.Config
+	templates *notifications.Templates
+	clock     Clock
 }
 
-func NewEmailService(cfg *config.Config) *EmailService {
-	return &EmailService{cfg: cfg}
+func NewEmailService(templates *notifications.Templates, clock Clock) *EmailService {
+	return &EmailService{
+		templates: templates,
+		clock:     clock,
+	}
 }
 
 // GenerateCode создает 6-значный код
@@ -26,44 +47,189 @@ func (s *EmailService) GenerateCode() (string, error) {
 	return fmt.Sprintf("%06d", n.Int64()%1000000), nil
 }
 
-// SendVerificationCode отправляет код подтверждения на email
-func (s *EmailService) SendVerificationCode(email, code string) error {
-	from := s.cfg.Email.From
-	password := s.cfg.Email.Password
-	smtpHost := s.cfg.Email.SMTPHost
-	smtpPort := s.cfg.Email.SMTPPort
+// Compose формирует письмо по шаблону и готовит его к записи в outbox
+func (s *EmailService) Compose(template, to string, data interface{}) (*model.OutboxMessage, error) {
+	msg, err := s.templates.Render(template, to, data)
+	if err != nil {
+		return nil, err
+	}
 
-	// Формируем сообщение
-	subject := "Код подтверждения - Театральная касса"
-	body := fmt.Sprintf(`
-Здравствуйте!
+	return &model.OutboxMessage{
+		ID:            uuid.New(),
+		Template:      template,
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Status:        "pending",
+		NextAttemptAt: s.clock.Now(),
+	}, nil
+}
 
-Ваш код подтверждения: %s
+// SendVerificationCode ставит письмо с кодом подтверждения в outbox в транзакции tx,
+// сохранившей код
+func (s *EmailService) SendVerificationCode(tx EmailEnqueuer, email, code string) error {
+	return enqueueEmail(s, tx, notifications.TemplateVerificationCode, email, notifications.VerificationCodeData{
+		Code: code,
+		TTL:  verificationCodeTTL,
+	})
+}
 
-Код действителен в течение 10 минут.
+// enqueueEmail ставит письмо в outbox в транзакции tx. Если рассылка не настроена
+// или адрес получателя неизвестен, письмо пропускается.
+func enqueueEmail(emails *EmailService, tx EmailEnqueuer, template, to string, data interface{}) error {
+	if emails == nil || to == "" {
+		return nil
+	}
+	message, err := emails.Compose(template, to, data)
+	if err != nil {
+		return err
+	}
+	return tx.EnqueueEmail(message)
+}
 
-Если вы не запрашивали этот код, просто проигнорируйте это письмо.
+// bookingEmailData собирает данные письма о бронировании. Цена места - та,
+// по которой оно продано, если она зафиксирована.
+func bookingEmailData(booking *model.Booking, user *model.User, performance *model.Performance, seats []model.PerformanceSeat) notifications.BookingData {
+	data := notifications.BookingData{
+		BookingID: booking.ID,
+		Date:      performance.Date,
+		HallName:  performance.Hall.Name,
+		Total:     booking.TotalPrice,
+		ExpiresAt: booking.ExpiresAt,
+		Seats:     make([]notifications.SeatData, len(seats)),
+	}
+	if user != nil {
+		data.Name = user.Name
+	}
+	if performance.Play != nil {
+		data.PlayTitle = performance.Play.Title
+	}
+	for i, seat := range seats {
+		price := seat.Price
+		if seat.ChargedPrice != nil {
+			price = *seat.ChargedPrice
+		}
+		data.Seats[i] = notifications.SeatData{
+			Section: seat.Seat.Section,
+			Row:     seat.Seat.Row,
+			Number:  seat.Seat.Number,
+			Price:   price,
+		}
+	}
+	return data
+}
 
---
-Театральная касса
-`, code)
+const (
+	emailOutboxBatchSize = 50
+	emailOutboxLease     = 5 * time.Minute
+	emailMaxAttempts     = 8
+	emailBaseBackoff     = 30 * time.Second
+	emailMaxBackoff      = time.Hour
+)
 
-	message := []byte(fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"Content-Type: text/plain; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s", from, email, subject, body))
+type EmailDeliveryRepository interface {
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error)
+	MarkSent(id uuid.UUID, at time.Time) error
+	MarkAttemptFailed(id uuid.UUID, lastErr string, next *time.Time) error
+}
 
-	// Аутентификация
-	auth := smtp.PlainAuth("", from, password, smtpHost)
+// EmailOutbox - фоновый процесс, отправляющий письма из outbox с повторами
+type EmailOutbox struct {
+	repo     EmailDeliveryRepository
+	sender   EmailSender
+	clock    Clock
+	interval time.Duration
+}
 
-	// Отправка письма
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	err := smtp.SendMail(addr, auth, from, []string{email}, message)
-	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+func NewEmailOutbox(repo EmailDeliveryRepository, sender EmailSender, clock Clock, interval time.Duration) *EmailOutbox {
+	return &EmailOutbox{
+		repo:     repo,
+		sender:   sender,
+		clock:    clock,
+		interval: interval,
+	}
+}
+
+// emailBackoff возвращает паузу перед следующей попыткой после attempts неудачных
+func emailBackoff(attempts int) time.Duration {
+	backoff := emailBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= emailMaxBackoff {
+			return emailMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// DeliverOnce отправляет все письма, которые пора отправить. Возвращает число отправленных.
+func (w *EmailOutbox) DeliverOnce() (int, error) {
+	sent := 0
+	for {
+		messages, err := w.repo.ClaimDue(w.clock.Now(), emailOutboxLease, emailOutboxBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, message := range messages {
+			if w.deliver(message) {
+				sent++
+			}
+		}
+
+		if len(messages) < emailOutboxBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+func (w *EmailOutbox) deliver(message model.OutboxMessage) bool {
+	err := w.sender.Send(notifications.Message{
+		To:      message.Recipient,
+		Subject: message.Subject,
+		Text:    message.TextBody,
+		HTML:    message.HTMLBody,
+	})
+	if err == nil {
+		if err := w.repo.MarkSent(message.ID, w.clock.Now()); err != nil {
+			log.Println("Failed to mark email as sent:", err)
+		}
+		return true
+	}
+
+	attempts := message.Attempts + 1
+	var next *time.Time
+	if attempts < emailMaxAttempts {
+		at := w.clock.Now().Add(emailBackoff(attempts))
+		next = &at
+	} else {
+		log.Printf("Giving up on email %s to %s after %d attempts: %v", message.ID, message.Recipient, attempts, err)
+	}
+	if err := w.repo.MarkAttemptFailed(message.ID, err.Error(), next); err != nil {
+		log.Println("Failed to record email delivery attempt:", err)
 	}
+	return false
+}
 
-	return nil
+// Run запускает периодическую отправку до отмены контекста
+func (w *EmailOutbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := w.DeliverOnce()
+			if err != nil {
+				log.Println("Email delivery failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Sent %d emails", n)
+			}
+		}
+	}
 }
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockEmailDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailDeliveryRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockEmailDeliveryRepository) MarkSent(id uuid.UUID, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockEmailDeliveryRepository) MarkAttemptFailed(id uuid.UUID, lastErr string, next *time.Time) error {
+	args := m.Called(id, lastErr, next)
+	return args.Error(0)
+}
+
+type failingSender struct{}
+
+func (failingSender) Send(notifications.Message) error {
+	return errors.New("connection refused")
+}
+
+func newTestEmailService(t *testing.T, clock Clock) *EmailService {
+	templates, err := notifications.NewTemplates(time.UTC, "BYN")
+	require.NoError(t, err)
+	return NewEmailService(templates, clock)
+}
+
+func TestSendVerificationCode(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	service := newTestEmailService(t, clock)
+	tx := new(MockBookingsRepository)
+
+	tx.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+		return m.Template == notifications.TemplateVerificationCode && m.Recipient == "anna@example.com" &&
+			m.Status == "pending" && m.NextAttemptAt.Equal(clock.now)
+	})).Return(nil)
+
+	err := service.SendVerificationCode(tx, "anna@example.com", "123456")
+
+	assert.NoError(t, err)
+	tx.AssertExpectations(t)
+}
+
+func TestEmailOutboxDeliverOnce(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	message := model.OutboxMessage{ID: uuid.New(), Recipient: "anna@example.com", Subject: "Тема", TextBody: "Текст"}
+
+	t.Run("sends due messages", func(t *testing.T) {
+		repo := new(MockEmailDeliveryRepository)
+		mailbox := notifications.NewMailbox()
+		worker := NewEmailOutbox(repo, mailbox, &fakeClock{now: now}, time.Second)
+
+		repo.On("ClaimDue", now, emailOutboxLease, emailOutboxBatchSize).Return([]model.OutboxMessage{message}, nil)
+		repo.On("MarkSent", message.ID, now).Return(nil)
+
+		sent, err := worker.DeliverOnce()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, mailbox.Messages(), 1)
+		assert.Equal(t, "anna@example.com", mailbox.Messages()[0].To)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("retries with backoff", func(t *testing.T) {
+		repo := new(MockEmailDeliveryRepository)
+		worker := NewEmailOutbox(repo, failingSender{}, &fakeClock{now: now}, time.Second)
+
+		retried := message
+		retried.Attempts = 2
+		repo.On("ClaimDue", now, emailOutboxLease, emailOutboxBatchSize).Return([]model.OutboxMessage{retried}, nil)
+		repo.On("MarkAttemptFailed", message.ID, "connection refused", mock.MatchedBy(func(next *time.Time) bool {
+			return next != nil && next.Equal(now.Add(2*time.Minute))
+		})).Return(nil)
+
+		sent, err := worker.DeliverOnce()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		repo := new(MockEmailDeliveryRepository)
+		worker := NewEmailOutbox(repo, failingSender{}, &fakeClock{now: now}, time.Second)
+
+		exhausted := message
+		exhausted.Attempts = emailMaxAttempts - 1
+		repo.On("ClaimDue", now, emailOutboxLease, emailOutboxBatchSize).Return([]model.OutboxMessage{exhausted}, nil)
+		repo.On("MarkAttemptFailed", message.ID, "connection refused", (*time.Time)(nil)).Return(nil)
+
+		_, err := worker.DeliverOnce()
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestEmailBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, emailBackoff(1))
+	assert.Equal(t, time.Minute, emailBackoff(2))
+	assert.Equal(t, 4*time.Minute, emailBackoff(4))
+	assert.Equal(t, time.Hour, emailBackoff(20))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log"
+	"theater-ticket-system/internal/models/models"
+	"time"
+)
+
+const expiryBatchSize = 100
+
+type BookingExpiryRepository interface {
+	// ExpirePending переводит не более limit просроченных pending-бронирований
+	// в статус expired и освобождает их места, затем в той же транзакции вызывает onRelease.
+	// Возвращает число обработанных бронирований.
+	ExpirePending(now time.Time, limit int, onRelease ReleaseHook) (int, error)
+}
+
+// BookingExpiry - фоновый процесс, снимающий бронь с неоплаченных мест
+type BookingExpiry struct {
+	repo     BookingExpiryRepository
+	clock    Clock
+	interval time.Duration
+	waitlist *Waitlist
+	audit    *Audit
+}
+
+// NewBookingExpiry создает процесс снятия брони. Освобожденные места предлагаются
+// листу ожидания waitlist, если он задан. audit необязателен: без него снятые
+// брони не попадают в журнал аудита.
+func NewBookingExpiry(repo BookingExpiryRepository, clock Clock, interval time.Duration, waitlist *Waitlist, audit *Audit) *BookingExpiry {
+	return &BookingExpiry{
+		repo:     repo,
+		clock:    clock,
+		interval: interval,
+		waitlist: waitlist,
+		audit:    audit,
+	}
+}
+
+// ExpireOnce обрабатывает все просроченные бронирования пачками
+func (w *BookingExpiry) ExpireOnce() (int, error) {
+	total := 0
+	for {
+		n, err := w.repo.ExpirePending(w.clock.Now(), expiryBatchSize, w.released)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < expiryBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// released записывает снятые брони в журнал аудита и предлагает их места листу ожидания
+func (w *BookingExpiry) released(tx WaitlistTx, expired []model.Booking) error {
+	for _, booking := range expired {
+		err := recordAudit(w.audit, tx, systemActor, "booking.expire", AuditBooking, booking.ID,
+			auditFields{"status": "pending"}, auditFields{"status": booking.Status})
+		if err != nil {
+			return err
+		}
+	}
+	return w.waitlist.SeatsReleased(tx, expired)
+}
+
+// Run запускает периодическую проверку до отмены контекста
+func (w *BookingExpiry) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := w.ExpireOnce()
+			if err != nil {
+				log.Println("Booking expiry failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Expired %d pending bookings", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type MockBookingExpiryRepository struct {
+	mock.Mock
+}
+
+func (m *MockBookingExpiryRepository) ExpirePending(now time.Time, limit int, onRelease ReleaseHook) (int, error) {
+	args := m.Called(now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestBookingExpiry_ExpireOnce(t *testing.T) {
+	t.Run("uses clock time", func(t *testing.T) {
+		mockRepo := new(MockBookingExpiryRepository)
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		worker := NewBookingExpiry(mockRepo, clock, time.Minute, nil, nil)
+
+		mockRepo.On("ExpirePending", clock.now, expiryBatchSize).Return(3, nil)
+
+		n, err := worker.ExpireOnce()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("drains full batches", func(t *testing.T) {
+		mockRepo := new(MockBookingExpiryRepository)
+		clock := &fakeClock{now: time.Now()}
+		worker := NewBookingExpiry(mockRepo, clock, time.Minute, nil, nil)
+
+		mockRepo.On("ExpirePending", clock.now, expiryBatchSize).Return(expiryBatchSize, nil).Twice()
+		mockRepo.On("ExpirePending", clock.now, expiryBatchSize).Return(5, nil).Once()
+
+		n, err := worker.ExpireOnce()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2*expiryBatchSize+5, n)
+		mockRepo.AssertNumberOfCalls(t, "ExpirePending", 3)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockBookingExpiryRepository)
+		clock := &fakeClock{now: time.Now()}
+		worker := NewBookingExpiry(mockRepo, clock, time.Minute, nil, nil)
+
+		mockRepo.On("ExpirePending", clock.now, expiryBatchSize).Return(0, errors.New("database error"))
+
+		n, err := worker.ExpireOnce()
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+	})
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HallsRepository interface {
+	GetAll() ([]model.Hall, error)
+	GetByID(id uuid.UUID) (*model.Hall, error)
+	HasUpcomingPerformances(hallID uuid.UUID, after time.Time) (bool, error)
+	Transaction(fn func(tx HallsTx) error) error
+}
+
+// HallsTx - операции со схемой зала, доступные внутри транзакции
+type HallsTx interface {
+	AuditRecorder
+	Create(hall *model.Hall) error
+	Update(hall *model.Hall) error
+	Delete(id uuid.UUID) error
+	LockHall(hallID uuid.UUID) error
+	GetSeats(hallID uuid.UUID) ([]model.Seat, error)
+	CountCommittedSeats(seatIDs []uuid.UUID, after time.Time) (int64, error)
+	CreateSeats(seats []model.Seat) error
+	UpdateSeat(seat *model.Seat) error
+	DeleteSeats(seatIDs []uuid.UUID, after time.Time) error
+	UpcomingPerformances(hallID uuid.UUID, after time.Time) ([]model.Performance, error)
+	CreatePerformanceSeats(seats []model.PerformanceSeat) error
+	UpdateCapacity(hallID uuid.UUID, capacity int) error
+}
+
+var seatCategories = map[string]bool{
+	"parterre": true,
+	"balcony":  true,
+	"box":      true,
+}
+
+type Halls struct {
+	repo    HallsRepository
+	pricing PricingRule
+	clock   Clock
+	audit   *Audit
+}
+
+// NewHalls создает сервис залов. audit необязателен: без него изменения залов
+// не попадают в журнал аудита.
+func NewHalls(repo HallsRepository, pricing PricingRule, clock Clock, audit *Audit) *Halls {
+	return &Halls{
+		repo:    repo,
+		pricing: pricing,
+		clock:   clock,
+		audit:   audit,
+	}
+}
+
+func (s *Halls) GetAllHalls() ([]model.Hall, error) {
+	return s.repo.GetAll()
+}
+
+func (s *Halls) GetHallByID(id string) (*model.Hall, error) {
+	hallID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid hall ID format")
+	}
+
+	hall, err := s.repo.GetByID(hallID)
+	if err != nil {
+		return nil, errors.New("hall not found")
+	}
+
+	return hall, nil
+}
+
+// CreateHall создает зал вместе со схемой мест
+func (s *Halls) CreateHall(actor model.Actor, name string, seats []model.Seat) (*model.Hall, error) {
+	if name == "" {
+		return nil, errors.New("hall name is required")
+	}
+	if err := validateSeats(seats); err != nil {
+		return nil, err
+	}
+
+	hall := &model.Hall{
+		ID:       uuid.New(),
+		Name:     name,
+		Capacity: len(seats),
+	}
+
+	err := s.repo.Transaction(func(tx HallsTx) error {
+		if err := tx.Create(hall); err != nil {
+			return err
+		}
+		if _, err := s.applyLayout(tx, hall.ID, seats); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "hall.create", AuditHall, hall.ID, nil, hallAudit(hall, seats))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hall, nil
+}
+
+func (s *Halls) UpdateHall(actor model.Actor, id, name string) (*model.Hall, error) {
+	hall, err := s.GetHallByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, errors.New("hall name is required")
+	}
+
+	before := hallAudit(hall, nil)
+	hall.Name = name
+	err = s.repo.Transaction(func(tx HallsTx) error {
+		if err := tx.Update(hall); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "hall.update", AuditHall, hall.ID, before, hallAudit(hall, nil))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hall, nil
+}
+
+func (s *Halls) DeleteHall(actor model.Actor, id string) error {
+	hall, err := s.GetHallByID(id)
+	if err != nil {
+		return err
+	}
+
+	busy, err := s.repo.HasUpcomingPerformances(hall.ID, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if busy {
+		return errors.New("hall has upcoming performances")
+	}
+
+	return s.repo.Transaction(func(tx HallsTx) error {
+		if err := tx.Delete(hall.ID); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "hall.delete", AuditHall, hall.ID, hallAudit(hall, nil), nil)
+	})
+}
+
+// UpdateLayout заменяет схему мест зала. Места, совпадающие по секции, ряду и номеру,
+// сохраняют свой ID; удалить место, уже проданное или забронированное на будущий показ, нельзя.
+func (s *Halls) UpdateLayout(actor model.Actor, id string, seats []model.Seat) (*model.Hall, error) {
+	hall, err := s.GetHallByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSeats(seats); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Transaction(func(tx HallsTx) error {
+		existing, err := s.applyLayout(tx, hall.ID, seats)
+		if err != nil {
+			return err
+		}
+		before := hallAudit(hall, existing)
+		hall.Capacity = len(seats)
+		return recordAudit(s.audit, tx, actor, "hall.layout", AuditHall, hall.ID, before, hallAudit(hall, seats))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hall, nil
+}
+
+// applyLayout приводит схему зала к seats и возвращает схему, которая была до изменения
+func (s *Halls) applyLayout(tx HallsTx, hallID uuid.UUID, seats []model.Seat) ([]model.Seat, error) {
+	if err := tx.LockHall(hallID); err != nil {
+		return nil, err
+	}
+
+	existing, err := tx.GetSeats(hallID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]model.Seat, len(existing))
+	for _, seat := range existing {
+		current[seatKey(seat)] = seat
+	}
+
+	var created []model.Seat
+	for _, seat := range seats {
+		seat.HallID = hallID
+		old, ok := current[seatKey(seat)]
+		if !ok {
+			seat.ID = uuid.New()
+			created = append(created, seat)
+			continue
+		}
+		delete(current, seatKey(seat))
+
+		seat.ID = old.ID
+		if seatChanged(old, seat) {
+			if err := tx.UpdateSeat(&seat); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	removed := make([]uuid.UUID, 0, len(current))
+	for _, seat := range current {
+		removed = append(removed, seat.ID)
+	}
+
+	now := s.clock.Now()
+	committed, err := tx.CountCommittedSeats(removed, now)
+	if err != nil {
+		return nil, err
+	}
+	if committed > 0 {
+		return nil, errors.New("layout change would remove seats sold for upcoming performances")
+	}
+
+	if err := tx.DeleteSeats(removed, now); err != nil {
+		return nil, err
+	}
+	if err := tx.CreateSeats(created); err != nil {
+		return nil, err
+	}
+
+	// Новые места сразу появляются в продаже на уже назначенные показы
+	if len(created) > 0 {
+		performances, err := tx.UpcomingPerformances(hallID, now)
+		if err != nil {
+			return nil, err
+		}
+		var performanceSeats []model.PerformanceSeat
+		for _, performance := range performances {
+			var pricing PricingRule = s.pricing
+			if performance.PricePlan != nil {
+				pricing = performance.PricePlan
+			}
+			for _, seat := range created {
+				performanceSeats = append(performanceSeats, model.PerformanceSeat{
+					ID:            uuid.New(),
+					PerformanceID: performance.ID,
+					SeatID:        seat.ID,
+					Price:         pricing.Price(seat),
+					Status:        "available",
+				})
+			}
+		}
+		if err := tx.CreatePerformanceSeats(performanceSeats); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.UpdateCapacity(hallID, len(seats)); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func validateSeats(seats []model.Seat) error {
+	if len(seats) == 0 {
+		return errors.New("hall layout must contain at least one seat")
+	}
+
+	seen := make(map[string]bool, len(seats))
+	for _, seat := range seats {
+		if !seatCategories[seat.Category] {
+			return fmt.Errorf("unknown seat category %q", seat.Category)
+		}
+		if seat.Row <= 0 || seat.Number <= 0 {
+			return errors.New("row and seat numbers must be positive")
+		}
+		key := seatKey(seat)
+		if seen[key] {
+			return fmt.Errorf("duplicate seat: section %q row %d seat %d", seat.Section, seat.Row, seat.Number)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func seatChanged(old, seat model.Seat) bool {
+	return old.Position != seat.Position ||
+		old.Category != seat.Category ||
+		old.Accessible != seat.Accessible ||
+		old.RestrictedView != seat.RestrictedView
+}
+
+func seatKey(seat model.Seat) string {
+	return fmt.Sprintf("%s/%d/%d", seat.Section, seat.Row, seat.Number)
+}
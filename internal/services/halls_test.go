@@ -0,0 +1,273 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHallsRepository struct {
+	mock.Mock
+	tx *MockHallsTx
+}
+
+func (m *MockHallsRepository) GetAll() ([]model.Hall, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Hall), args.Error(1)
+}
+
+func (m *MockHallsRepository) GetByID(id uuid.UUID) (*model.Hall, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Hall), args.Error(1)
+}
+
+func (m *MockHallsRepository) HasUpcomingPerformances(hallID uuid.UUID, after time.Time) (bool, error) {
+	args := m.Called(hallID, after)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHallsRepository) Transaction(fn func(tx HallsTx) error) error {
+	return fn(m.tx)
+}
+
+type MockHallsTx struct {
+	mock.Mock
+}
+
+func (m *MockHallsTx) Create(hall *model.Hall) error {
+	args := m.Called(hall)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) Update(hall *model.Hall) error {
+	args := m.Called(hall)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) LockHall(hallID uuid.UUID) error {
+	args := m.Called(hallID)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) GetSeats(hallID uuid.UUID) ([]model.Seat, error) {
+	args := m.Called(hallID)
+	return args.Get(0).([]model.Seat), args.Error(1)
+}
+
+func (m *MockHallsTx) CountCommittedSeats(seatIDs []uuid.UUID, after time.Time) (int64, error) {
+	args := m.Called(seatIDs, after)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHallsTx) CreateSeats(seats []model.Seat) error {
+	args := m.Called(seats)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) UpdateSeat(seat *model.Seat) error {
+	args := m.Called(seat)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) DeleteSeats(seatIDs []uuid.UUID, after time.Time) error {
+	args := m.Called(seatIDs, after)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) UpcomingPerformances(hallID uuid.UUID, after time.Time) ([]model.Performance, error) {
+	args := m.Called(hallID, after)
+	return args.Get(0).([]model.Performance), args.Error(1)
+}
+
+func (m *MockHallsTx) CreatePerformanceSeats(seats []model.PerformanceSeat) error {
+	args := m.Called(seats)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) UpdateCapacity(hallID uuid.UUID, capacity int) error {
+	args := m.Called(hallID, capacity)
+	return args.Error(0)
+}
+
+func (m *MockHallsTx) RecordAudit(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func TestCreateHall(t *testing.T) {
+	t.Run("creates hall with seats", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		seats := []model.Seat{
+			{Section: "parterre", Row: 1, Number: 1, Position: 1, Category: "parterre"},
+			{Section: "parterre", Row: 1, Number: 2, Position: 3, Category: "parterre", Accessible: true},
+		}
+
+		tx.On("Create", mock.MatchedBy(func(h *model.Hall) bool {
+			return h.Name == "Малый зал" && h.Capacity == 2
+		})).Return(nil)
+		tx.On("LockHall", mock.AnythingOfType("uuid.UUID")).Return(nil)
+		tx.On("GetSeats", mock.AnythingOfType("uuid.UUID")).Return([]model.Seat{}, nil)
+		tx.On("CountCommittedSeats", []uuid.UUID{}, clock.now).Return(int64(0), nil)
+		tx.On("DeleteSeats", []uuid.UUID{}, clock.now).Return(nil)
+		tx.On("CreateSeats", mock.MatchedBy(func(created []model.Seat) bool {
+			return len(created) == 2 && created[0].ID != uuid.Nil && created[1].Accessible
+		})).Return(nil)
+		tx.On("UpcomingPerformances", mock.AnythingOfType("uuid.UUID"), clock.now).Return([]model.Performance{}, nil)
+		tx.On("CreatePerformanceSeats", mock.Anything).Return(nil)
+		tx.On("UpdateCapacity", mock.AnythingOfType("uuid.UUID"), 2).Return(nil)
+
+		hall, err := service.CreateHall(testActor, "Малый зал", seats)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, hall.Capacity)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("duplicate seat", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		seats := []model.Seat{
+			{Section: "box", Row: 1, Number: 1, Category: "box"},
+			{Section: "box", Row: 1, Number: 1, Category: "box"},
+		}
+
+		_, err := service.CreateHall(testActor, "Ложи", seats)
+
+		assert.EqualError(t, err, `duplicate seat: section "box" row 1 seat 1`)
+		tx.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUpdateLayout(t *testing.T) {
+	t.Run("keeps matching seats and prices new ones for upcoming performances", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		hall := &model.Hall{ID: uuid.New(), Name: "Большой зал", Capacity: 2}
+		kept := model.Seat{ID: uuid.New(), HallID: hall.ID, Section: "parterre", Row: 1, Number: 1, Position: 1, Category: "parterre"}
+		removed := model.Seat{ID: uuid.New(), HallID: hall.ID, Section: "parterre", Row: 1, Number: 2, Position: 2, Category: "parterre"}
+		performanceID := uuid.New()
+
+		layout := []model.Seat{
+			{Section: "parterre", Row: 1, Number: 1, Position: 1, Category: "parterre", Accessible: true},
+			{Section: "balcony", Row: 8, Number: 1, Position: 1, Category: "balcony"},
+		}
+
+		repo.On("GetByID", hall.ID).Return(hall, nil)
+		tx.On("LockHall", hall.ID).Return(nil)
+		tx.On("GetSeats", hall.ID).Return([]model.Seat{kept, removed}, nil)
+		tx.On("UpdateSeat", mock.MatchedBy(func(s *model.Seat) bool {
+			return s.ID == kept.ID && s.Accessible
+		})).Return(nil)
+		tx.On("CountCommittedSeats", []uuid.UUID{removed.ID}, clock.now).Return(int64(0), nil)
+		tx.On("DeleteSeats", []uuid.UUID{removed.ID}, clock.now).Return(nil)
+		tx.On("CreateSeats", mock.MatchedBy(func(created []model.Seat) bool {
+			return len(created) == 1 && created[0].HallID == hall.ID && created[0].Section == "balcony"
+		})).Return(nil)
+		tx.On("UpcomingPerformances", hall.ID, clock.now).Return([]model.Performance{{ID: performanceID}}, nil)
+		tx.On("CreatePerformanceSeats", mock.MatchedBy(func(seats []model.PerformanceSeat) bool {
+			return len(seats) == 1 && seats[0].PerformanceID == performanceID &&
+				seats[0].Price == 1000 && seats[0].Status == "available"
+		})).Return(nil)
+		tx.On("UpdateCapacity", hall.ID, 2).Return(nil)
+
+		result, err := service.UpdateLayout(testActor, hall.ID.String(), layout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Capacity)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("refuses to remove sold seats", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		hall := &model.Hall{ID: uuid.New(), Capacity: 2}
+		kept := model.Seat{ID: uuid.New(), HallID: hall.ID, Row: 1, Number: 1, Category: "parterre"}
+		sold := model.Seat{ID: uuid.New(), HallID: hall.ID, Row: 1, Number: 2, Category: "parterre"}
+
+		repo.On("GetByID", hall.ID).Return(hall, nil)
+		tx.On("LockHall", hall.ID).Return(nil)
+		tx.On("GetSeats", hall.ID).Return([]model.Seat{kept, sold}, nil)
+		tx.On("CountCommittedSeats", []uuid.UUID{sold.ID}, clock.now).Return(int64(1), nil)
+
+		_, err := service.UpdateLayout(testActor, hall.ID.String(), []model.Seat{{Row: 1, Number: 1, Category: "parterre"}})
+
+		assert.EqualError(t, err, "layout change would remove seats sold for upcoming performances")
+		tx.AssertNotCalled(t, "DeleteSeats", mock.Anything, mock.Anything)
+		tx.AssertNotCalled(t, "UpdateCapacity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		hall := &model.Hall{ID: uuid.New()}
+		repo.On("GetByID", hall.ID).Return(hall, nil)
+
+		_, err := service.UpdateLayout(testActor, hall.ID.String(), []model.Seat{{Row: 1, Number: 1, Category: "vip"}})
+
+		assert.EqualError(t, err, `unknown seat category "vip"`)
+	})
+}
+
+func TestDeleteHall(t *testing.T) {
+	t.Run("hall with upcoming performances", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		hall := &model.Hall{ID: uuid.New()}
+		repo.On("GetByID", hall.ID).Return(hall, nil)
+		repo.On("HasUpcomingPerformances", hall.ID, clock.now).Return(true, nil)
+
+		err := service.DeleteHall(testActor, hall.ID.String())
+
+		assert.EqualError(t, err, "hall has upcoming performances")
+		tx.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("deletes idle hall", func(t *testing.T) {
+		tx := new(MockHallsTx)
+		repo := &MockHallsRepository{tx: tx}
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewHalls(repo, DefaultPricing, clock, nil)
+
+		hall := &model.Hall{ID: uuid.New()}
+		repo.On("GetByID", hall.ID).Return(hall, nil)
+		repo.On("HasUpcomingPerformances", hall.ID, clock.now).Return(false, nil)
+		tx.On("Delete", hall.ID).Return(nil)
+
+		err := service.DeleteHall(testActor, hall.ID.String())
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		tx.AssertExpectations(t)
+	})
+}
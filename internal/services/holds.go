@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const holdsReleaseBatchSize = 100
+
+var (
+	ErrHoldNotFound = errors.New("hold not found")
+	ErrHoldExpired  = errors.New("hold has expired")
+	ErrHoldMaxed    = errors.New("hold cannot be extended any further")
+
+	ErrHoldTooLarge     = errors.New("too many seats in one hold")
+	ErrSessionHoldLimit = errors.New("too many seats are already held")
+)
+
+type HoldsRepository interface {
+	Create(hold *model.SeatHold, seatIDs []uuid.UUID, maxSessionSeats int) error
+	GetByID(id uuid.UUID) (*model.SeatHold, error)
+	Extend(id uuid.UUID, now, expiresAt time.Time) (bool, error)
+	Release(hold *model.SeatHold, onRelease FreedHook) error
+	ReleaseExpired(now time.Time, limit int, onRelease FreedHook) (int, error)
+	GetPerformance(id uuid.UUID) (*model.Performance, error)
+}
+
+// Holds удерживает места на несколько минут, пока покупатель оформляет бронирование.
+// Удержание принадлежит анонимной сессии с токеном, выданным при создании.
+type Holds struct {
+	repo        HoldsRepository
+	clock       Clock
+	ttl         time.Duration
+	maxDuration time.Duration
+	closing     time.Duration
+	interval    time.Duration
+	limits      HoldLimits
+	waitlist    *Waitlist
+}
+
+// HoldLimits - сколько мест можно удерживать. 0 - без ограничения.
+type HoldLimits struct {
+	Seats        int // мест в одном удержании
+	SessionSeats int // мест во всех действующих удержаниях одной сессии
+}
+
+// NewHolds создает сервис удержаний. Каждое продление сдвигает срок на ttl, но не
+// дальше maxDuration от создания удержания. Удерживать места можно, пока идет продажа,
+// то есть не позже closeBefore до начала показа. Истекшие удержания снимаются каждые
+// interval, освободившиеся места предлагаются листу ожидания waitlist, если он задан.
+func NewHolds(repo HoldsRepository, clock Clock, ttl, maxDuration, closeBefore, interval time.Duration, limits HoldLimits, waitlist *Waitlist) *Holds {
+	return &Holds{
+		repo:        repo,
+		clock:       clock,
+		ttl:         ttl,
+		maxDuration: maxDuration,
+		closing:     closeBefore,
+		interval:    interval,
+		limits:      limits,
+		waitlist:    waitlist,
+	}
+}
+
+// Hold удерживает места seatIDs показа для анонимной сессии session. Возвращает
+// удержание и токен, которым владелец продлевает, снимает удержание или оформляет
+// по нему бронирование.
+func (s *Holds) Hold(performanceID string, seatIDs []uuid.UUID, session string) (*model.SeatHold, string, error) {
+	id, err := uuid.Parse(performanceID)
+	if err != nil {
+		return nil, "", errors.New("invalid performance ID format")
+	}
+	seatIDs = uniqueSeatIDs(seatIDs)
+	if len(seatIDs) == 0 {
+		return nil, "", errors.New("at least one seat must be selected")
+	}
+	if s.limits.Seats > 0 && len(seatIDs) > s.limits.Seats {
+		return nil, "", fmt.Errorf("%w: at most %d", ErrHoldTooLarge, s.limits.Seats)
+	}
+
+	performance, err := s.repo.GetPerformance(id)
+	if err != nil {
+		return nil, "", errors.New("performance not found")
+	}
+	now := s.clock.Now()
+	if err := checkOnSale(performance, now, s.closing); err != nil {
+		return nil, "", err
+	}
+
+	token, err := newHoldToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	hold := &model.SeatHold{
+		ID:            uuid.New(),
+		PerformanceID: id,
+		TokenHash:     hashHoldToken(token),
+		Session:       session,
+		ExpiresAt:     s.expiresAt(now, now),
+		CreatedAt:     now,
+	}
+	if err := s.repo.Create(hold, seatIDs, s.limits.SessionSeats); err != nil {
+		if errors.Is(err, repository.ErrSeatNotAvailable) {
+			return nil, "", errors.New("some seats are not available")
+		}
+		if errors.Is(err, repository.ErrSessionHoldLimit) {
+			return nil, "", fmt.Errorf("%w: at most %d", ErrSessionHoldLimit, s.limits.SessionSeats)
+		}
+		return nil, "", err
+	}
+	return hold, token, nil
+}
+
+// Extend продлевает удержание еще на ttl
+func (s *Holds) Extend(holdID, token string) (*model.SeatHold, error) {
+	hold, err := s.owned(holdID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if !hold.ExpiresAt.After(now) {
+		return nil, ErrHoldExpired
+	}
+	expiresAt := s.expiresAt(hold.CreatedAt, now)
+	if !expiresAt.After(hold.ExpiresAt) {
+		return nil, ErrHoldMaxed
+	}
+
+	extended, err := s.repo.Extend(hold.ID, now, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !extended {
+		return nil, ErrHoldExpired
+	}
+
+	hold.ExpiresAt = expiresAt
+	return hold, nil
+}
+
+// Release снимает удержание и возвращает места в продажу
+func (s *Holds) Release(holdID, token string) error {
+	hold, err := s.owned(holdID, token)
+	if err != nil {
+		return err
+	}
+	return s.repo.Release(hold, s.waitlist.SeatsFreed)
+}
+
+// ReleaseExpired снимает все истекшие удержания пачками
+func (s *Holds) ReleaseExpired() (int, error) {
+	total := 0
+	for {
+		n, err := s.repo.ReleaseExpired(s.clock.Now(), holdsReleaseBatchSize, s.waitlist.SeatsFreed)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < holdsReleaseBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Run периодически снимает истекшие удержания до отмены контекста. Места с истекшим
+// удержанием можно занять и раньше, очистка лишь возвращает им статус available.
+func (s *Holds) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ReleaseExpired()
+			if err != nil {
+				log.Println("Seat hold cleanup failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Released %d expired seat holds", n)
+			}
+		}
+	}
+}
+
+// owned возвращает удержание holdID, если token принадлежит его владельцу
+func (s *Holds) owned(holdID, token string) (*model.SeatHold, error) {
+	id, err := uuid.Parse(holdID)
+	if err != nil {
+		return nil, errors.New("invalid hold ID format")
+	}
+
+	hold, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hold.TokenHash), []byte(hashHoldToken(token))) != 1 {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// expiresAt возвращает срок удержания, созданного в createdAt, при продлении в момент now
+func (s *Holds) expiresAt(createdAt, now time.Time) time.Time {
+	expiresAt := now.Add(s.ttl)
+	if limit := createdAt.Add(s.maxDuration); s.maxDuration > 0 && expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
+
+func newHoldToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashHoldToken возвращает хеш токена удержания, который хранится в базе
+func hashHoldToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func uniqueSeatIDs(seatIDs []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(seatIDs))
+	unique := make([]uuid.UUID, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockHoldsRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldsRepository) Create(hold *model.SeatHold, seatIDs []uuid.UUID, maxSessionSeats int) error {
+	args := m.Called(hold, seatIDs, maxSessionSeats)
+	return args.Error(0)
+}
+
+func (m *MockHoldsRepository) GetByID(id uuid.UUID) (*model.SeatHold, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SeatHold), args.Error(1)
+}
+
+func (m *MockHoldsRepository) Extend(id uuid.UUID, now, expiresAt time.Time) (bool, error) {
+	args := m.Called(id, now, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHoldsRepository) Release(hold *model.SeatHold, onRelease FreedHook) error {
+	args := m.Called(hold, onRelease)
+	return args.Error(0)
+}
+
+func (m *MockHoldsRepository) ReleaseExpired(now time.Time, limit int, onRelease FreedHook) (int, error) {
+	args := m.Called(now, limit, onRelease)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockHoldsRepository) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Performance), args.Error(1)
+}
+
+// testHoldLimits - не больше 2 мест в удержании и 4 мест на сессию
+var testHoldLimits = HoldLimits{Seats: 2, SessionSeats: 4}
+
+func TestHold(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	performanceID := uuid.New()
+	seatA, seatB := uuid.New(), uuid.New()
+
+	t.Run("holds seats for ttl", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+		repo.On("GetPerformance", performanceID).
+			Return(&model.Performance{ID: performanceID, Status: "on_sale", Date: now.Add(24 * time.Hour)}, nil)
+		repo.On("Create", mock.MatchedBy(func(h *model.SeatHold) bool {
+			return h.PerformanceID == performanceID && h.Session == "203.0.113.7" && h.ExpiresAt.Equal(now.Add(5*time.Minute))
+		}), []uuid.UUID{seatA, seatB}, 4).Return(nil)
+
+		hold, token, err := service.Hold(performanceID.String(), []uuid.UUID{seatA, seatB, seatA}, "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		// В базе хранится только хеш токена
+		assert.Equal(t, hashHoldToken(token), hold.TokenHash)
+		assert.NotEqual(t, token, hold.TokenHash)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("seats already taken", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+		repo.On("GetPerformance", performanceID).
+			Return(&model.Performance{ID: performanceID, Status: "on_sale", Date: now.Add(time.Hour)}, nil)
+		repo.On("Create", mock.Anything, []uuid.UUID{seatA}, 4).Return(repository.ErrSeatNotAvailable)
+
+		_, _, err := service.Hold(performanceID.String(), []uuid.UUID{seatA}, "203.0.113.7")
+
+		assert.EqualError(t, err, "some seats are not available")
+	})
+
+	t.Run("too many seats in one hold", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+		_, _, err := service.Hold(performanceID.String(), []uuid.UUID{seatA, seatB, uuid.New()}, "203.0.113.7")
+
+		assert.ErrorIs(t, err, ErrHoldTooLarge)
+		repo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("session already holds too many seats", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+		repo.On("GetPerformance", performanceID).
+			Return(&model.Performance{ID: performanceID, Status: "on_sale", Date: now.Add(time.Hour)}, nil)
+		repo.On("Create", mock.Anything, []uuid.UUID{seatA}, 4).Return(repository.ErrSessionHoldLimit)
+
+		_, _, err := service.Hold(performanceID.String(), []uuid.UUID{seatA}, "203.0.113.7")
+
+		assert.ErrorIs(t, err, ErrSessionHoldLimit)
+	})
+
+	t.Run("performance already started", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+		repo.On("GetPerformance", performanceID).
+			Return(&model.Performance{ID: performanceID, Status: "on_sale", Date: now.Add(-time.Minute)}, nil)
+
+		_, _, err := service.Hold(performanceID.String(), []uuid.UUID{seatA}, "203.0.113.7")
+
+		assert.ErrorIs(t, err, ErrPerformanceStarted)
+		repo.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestExtendHold(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	newHold := func(createdAt, expiresAt time.Time) *model.SeatHold {
+		return &model.SeatHold{ID: uuid.New(), TokenHash: hashHoldToken("token"), CreatedAt: createdAt, ExpiresAt: expiresAt}
+	}
+
+	t.Run("extends by ttl", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := newHold(now.Add(-4*time.Minute), now.Add(time.Minute))
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+		repo.On("Extend", hold.ID, now, now.Add(5*time.Minute)).Return(true, nil)
+
+		extended, err := service.Extend(hold.ID.String(), "token")
+
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(5*time.Minute), extended.ExpiresAt)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("capped at max duration", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := newHold(now.Add(-18*time.Minute), now.Add(time.Minute))
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+		repo.On("Extend", hold.ID, now, now.Add(2*time.Minute)).Return(true, nil)
+
+		extended, err := service.Extend(hold.ID.String(), "token")
+
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(2*time.Minute), extended.ExpiresAt)
+	})
+
+	t.Run("max duration reached", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := newHold(now.Add(-19*time.Minute), now.Add(time.Minute))
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+
+		_, err := service.Extend(hold.ID.String(), "token")
+
+		assert.ErrorIs(t, err, ErrHoldMaxed)
+		repo.AssertNotCalled(t, "Extend")
+	})
+
+	t.Run("expired hold", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := newHold(now.Add(-6*time.Minute), now.Add(-time.Minute))
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+
+		_, err := service.Extend(hold.ID.String(), "token")
+
+		assert.ErrorIs(t, err, ErrHoldExpired)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := newHold(now, now.Add(5*time.Minute))
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+
+		_, err := service.Extend(hold.ID.String(), "stolen")
+
+		assert.ErrorIs(t, err, ErrHoldNotFound)
+		repo.AssertNotCalled(t, "Extend")
+	})
+}
+
+func TestReleaseHold(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("releases owned hold", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		hold := &model.SeatHold{ID: uuid.New(), TokenHash: hashHoldToken("token"), ExpiresAt: now.Add(time.Minute)}
+
+		repo.On("GetByID", hold.ID).Return(hold, nil)
+		repo.On("Release", hold, mock.Anything).Return(nil)
+
+		err := service.Release(hold.ID.String(), "token")
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unknown hold", func(t *testing.T) {
+		repo := new(MockHoldsRepository)
+		service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+		id := uuid.New()
+
+		repo.On("GetByID", id).Return(nil, gorm.ErrRecordNotFound)
+
+		err := service.Release(id.String(), "token")
+
+		assert.ErrorIs(t, err, ErrHoldNotFound)
+	})
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockHoldsRepository)
+	service := NewHolds(repo, &fakeClock{now: now}, 5*time.Minute, 20*time.Minute, 0, time.Minute, testHoldLimits, nil)
+
+	repo.On("ReleaseExpired", now, holdsReleaseBatchSize, mock.Anything).Return(holdsReleaseBatchSize, nil).Once()
+	repo.On("ReleaseExpired", now, holdsReleaseBatchSize, mock.Anything).Return(3, nil).Once()
+
+	n, err := service.ReleaseExpired()
+
+	assert.NoError(t, err)
+	assert.Equal(t, holdsReleaseBatchSize+3, n)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"theater-ticket-system/internal/models/models"
+	"time"
+)
+
+// Статусы показа. Показ создается запланированным, затем открывается продажа,
+// которая закрывается незадолго до начала, а после окончания показ завершается.
+// Отменить или перенести можно любой еще не завершенный показ.
+const (
+	PerformanceScheduled   = "scheduled"
+	PerformanceOnSale      = "on_sale"
+	PerformanceSalesClosed = "sales_closed"
+	PerformanceCompleted   = "completed"
+	PerformanceCancelled   = "cancelled"
+	PerformancePostponed   = "postponed"
+)
+
+// performanceTransitions - допустимые переходы между статусами показа
+var performanceTransitions = map[string][]string{
+	PerformanceScheduled:   {PerformanceOnSale, PerformanceCompleted, PerformanceCancelled, PerformancePostponed},
+	PerformanceOnSale:      {PerformanceSalesClosed, PerformanceCompleted, PerformanceCancelled, PerformancePostponed},
+	PerformanceSalesClosed: {PerformanceOnSale, PerformanceCompleted, PerformanceCancelled, PerformancePostponed},
+	PerformancePostponed:   {PerformanceScheduled, PerformanceOnSale, PerformanceCancelled},
+}
+
+var (
+	ErrNotOnSale         = errors.New("performance is not on sale")
+	ErrSalesClosed       = fmt.Errorf("%w: sales are closed", ErrNotOnSale)
+	ErrInvalidTransition = errors.New("invalid performance status transition")
+)
+
+// canTransition проверяет, можно ли перевести показ из статуса from в статус to
+func canTransition(from, to string) bool {
+	for _, next := range performanceTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOnSale проверяет, что на показ можно купить билеты в момент now:
+// продажа открыта и до начала показа осталось больше closeBefore
+func checkOnSale(performance *model.Performance, now time.Time, closeBefore time.Duration) error {
+	switch performance.Status {
+	case PerformanceOnSale:
+	case PerformanceCancelled:
+		return ErrPerformanceCancelled
+	case PerformanceSalesClosed:
+		return ErrSalesClosed
+	default:
+		return ErrNotOnSale
+	}
+	if !performance.Date.After(now) {
+		return ErrPerformanceStarted
+	}
+	if !now.Before(performance.Date.Add(-closeBefore)) {
+		return ErrSalesClosed
+	}
+	return nil
+}
+
+// checkBoxOfficeSale проверяет, что касса может продать билеты на показ в момент now.
+// В отличие от онлайн-продажи касса продает билеты до самого начала показа.
+func checkBoxOfficeSale(performance *model.Performance, now time.Time) error {
+	if performance.Status == PerformanceSalesClosed && performance.Date.After(now) {
+		return nil
+	}
+	return checkOnSale(performance, now, 0)
+}
+
+// LifecycleTx - смена статусов показов со временем внутри транзакции
+type LifecycleTx interface {
+	AuditRecorder
+	// CloseSales закрывает продажу на показы, начинающиеся не позже before.
+	// Возвращает показы в том виде, в каком они были до закрытия продажи.
+	CloseSales(before time.Time) ([]model.Performance, error)
+	// CompleteFinished завершает показы, закончившиеся не позже now.
+	// Возвращает показы в том виде, в каком они были до завершения.
+	CompleteFinished(now time.Time) ([]model.Performance, error)
+}
+
+type LifecycleRepository interface {
+	Transaction(fn func(tx LifecycleTx) error) error
+}
+
+// PerformanceLifecycle - фоновый процесс, продвигающий показы по статусам со временем:
+// закрывает продажу за closeBefore до начала и завершает прошедшие показы
+type PerformanceLifecycle struct {
+	repo        LifecycleRepository
+	clock       Clock
+	closeBefore time.Duration
+	interval    time.Duration
+	audit       *Audit
+}
+
+// NewPerformanceLifecycle создает процесс смены статусов показов. audit необязателен:
+// без него смена статусов не попадает в журнал аудита.
+func NewPerformanceLifecycle(repo LifecycleRepository, clock Clock, closeBefore, interval time.Duration, audit *Audit) *PerformanceLifecycle {
+	return &PerformanceLifecycle{
+		repo:        repo,
+		clock:       clock,
+		closeBefore: closeBefore,
+		interval:    interval,
+		audit:       audit,
+	}
+}
+
+// AdvanceOnce закрывает продажу и завершает показы, для которых наступило время.
+// Возвращает число показов, на которые закрыта продажа, и число завершенных.
+func (w *PerformanceLifecycle) AdvanceOnce() (int64, int64, error) {
+	now := w.clock.Now()
+	var closed, completed []model.Performance
+	err := w.repo.Transaction(func(tx LifecycleTx) error {
+		var err error
+		closed, err = tx.CloseSales(now.Add(w.closeBefore))
+		if err != nil {
+			return err
+		}
+		return w.recordTransitions(tx, "performance.close_sales", closed, PerformanceSalesClosed)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = w.repo.Transaction(func(tx LifecycleTx) error {
+		var err error
+		completed, err = tx.CompleteFinished(now)
+		if err != nil {
+			return err
+		}
+		return w.recordTransitions(tx, "performance.complete", completed, PerformanceCompleted)
+	})
+	if err != nil {
+		return int64(len(closed)), 0, err
+	}
+	return int64(len(closed)), int64(len(completed)), nil
+}
+
+// recordTransitions записывает в журнал аудита перевод показов performances в статус status
+func (w *PerformanceLifecycle) recordTransitions(tx LifecycleTx, action string, performances []model.Performance, status string) error {
+	for i := range performances {
+		performance := &performances[i]
+		before := performanceAudit(performance)
+		performance.Status = status
+		err := recordAudit(w.audit, tx, systemActor, action, AuditPerformance, performance.ID, before, performanceAudit(performance))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run запускает периодическую проверку до отмены контекста
+func (w *PerformanceLifecycle) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			closed, completed, err := w.AdvanceOnce()
+			if err != nil {
+				log.Println("Performance lifecycle failed:", err)
+				continue
+			}
+			if closed > 0 || completed > 0 {
+				log.Printf("Closed sales for %d performances, completed %d performances", closed, completed)
+			}
+		}
+	}
+}
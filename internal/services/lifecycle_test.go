@@ -0,0 +1,114 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLifecycleRepository struct {
+	mock.Mock
+}
+
+func (m *MockLifecycleRepository) Transaction(fn func(tx LifecycleTx) error) error {
+	return fn(m)
+}
+
+func (m *MockLifecycleRepository) CloseSales(before time.Time) ([]model.Performance, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Performance), args.Error(1)
+}
+
+func (m *MockLifecycleRepository) CompleteFinished(now time.Time) ([]model.Performance, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Performance), args.Error(1)
+}
+
+func (m *MockLifecycleRepository) RecordAudit(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, canTransition(PerformanceScheduled, PerformanceOnSale))
+	assert.True(t, canTransition(PerformanceOnSale, PerformanceSalesClosed))
+	assert.True(t, canTransition(PerformanceSalesClosed, PerformanceCompleted))
+	assert.True(t, canTransition(PerformancePostponed, PerformanceOnSale))
+	assert.True(t, canTransition(PerformanceOnSale, PerformanceCancelled))
+
+	assert.False(t, canTransition(PerformanceCompleted, PerformanceOnSale))
+	assert.False(t, canTransition(PerformanceCancelled, PerformanceScheduled))
+	assert.False(t, canTransition(PerformancePostponed, PerformanceCompleted))
+	assert.False(t, canTransition(PerformanceOnSale, PerformanceOnSale))
+}
+
+func TestCheckOnSale(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	closeBefore := 30 * time.Minute
+
+	tests := []struct {
+		name        string
+		performance model.Performance
+		err         error
+	}{
+		{"on sale", model.Performance{Status: "on_sale", Date: now.Add(2 * time.Hour)}, nil},
+		{"not opened yet", model.Performance{Status: "scheduled", Date: now.Add(2 * time.Hour)}, ErrNotOnSale},
+		{"postponed", model.Performance{Status: "postponed", Date: now.Add(2 * time.Hour)}, ErrNotOnSale},
+		{"closed by status", model.Performance{Status: "sales_closed", Date: now.Add(2 * time.Hour)}, ErrSalesClosed},
+		{"closed by time", model.Performance{Status: "on_sale", Date: now.Add(closeBefore)}, ErrSalesClosed},
+		{"started", model.Performance{Status: "on_sale", Date: now}, ErrPerformanceStarted},
+		{"cancelled", model.Performance{Status: "cancelled", Date: now.Add(2 * time.Hour)}, ErrPerformanceCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOnSale(&tt.performance, now, closeBefore)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	assert.ErrorIs(t, ErrSalesClosed, ErrNotOnSale)
+}
+
+func TestPerformanceLifecycle(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	t.Run("closes sales and completes finished performances", func(t *testing.T) {
+		repo := new(MockLifecycleRepository)
+		lifecycle := NewPerformanceLifecycle(repo, clock, time.Hour, time.Minute, nil)
+
+		repo.On("CloseSales", clock.now.Add(time.Hour)).Return([]model.Performance{{Status: "on_sale"}, {Status: "on_sale"}}, nil)
+		repo.On("CompleteFinished", clock.now).Return([]model.Performance{{Status: "sales_closed"}}, nil)
+
+		closed, completed, err := lifecycle.AdvanceOnce()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), closed)
+		assert.Equal(t, int64(1), completed)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		repo := new(MockLifecycleRepository)
+		lifecycle := NewPerformanceLifecycle(repo, clock, time.Hour, time.Minute, nil)
+
+		repo.On("CloseSales", mock.Anything).Return(nil, assert.AnError)
+
+		_, _, err := lifecycle.AdvanceOnce()
+
+		assert.ErrorIs(t, err, assert.AnError)
+		repo.AssertNotCalled(t, "CompleteFinished", mock.Anything)
+	})
+}
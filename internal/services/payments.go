@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"theater-ticket-system/internal/payments"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentProvider - внешняя платежная система
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(bookingID uuid.UUID, amount int, currency string) (*payments.Intent, error)
+	Capture(intentID, paymentToken string) (*payments.Intent, error)
+	Refund(intentID string, amount int) (*payments.Refund, error)
+	VerifyWebhook(payload []byte, signature string) (*payments.Event, error)
+}
+
+type PaymentsRepository interface {
+	Create(payment *model.Payment) error
+	Update(payment *model.Payment) error
+	GetByIntentID(intentID string) (*model.Payment, error)
+	ListByStatus(status string, limit int) ([]model.Payment, error)
+}
+
+const refundRetryBatchSize = 100
+
+var (
+	errBookingNotPending = errors.New("booking is no longer pending")
+	errPaymentSettled    = errors.New("payment is already settled")
+)
+
+type Payments struct {
+	repo         PaymentsRepository
+	bookingsRepo BookingsRepository
+	provider     PaymentProvider
+	clock        Clock
+	currency     string
+	emails       *EmailService
+	audit        *Audit
+}
+
+// NewPayments создает сервис оплаты. emails и audit необязательны: без них письмо
+// о подтверждении бронирования не отправляется, а подтверждения и возвраты
+// не попадают в журнал аудита.
+func NewPayments(repo PaymentsRepository, bookingsRepo BookingsRepository, provider PaymentProvider, clock Clock, currency string, emails *EmailService, audit *Audit) *Payments {
+	return &Payments{
+		repo:         repo,
+		bookingsRepo: bookingsRepo,
+		provider:     provider,
+		clock:        clock,
+		currency:     currency,
+		emails:       emails,
+		audit:        audit,
+	}
+}
+
+// ConfirmBooking оплачивает pending-бронирование и переводит его места в sold.
+// Бронь, стоимость которой целиком покрыта промокодом, подтверждается без платежа.
+func (s *Payments) ConfirmBooking(id, paymentToken string) (*model.Booking, error) {
+	bookingID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid booking ID format")
+	}
+
+	booking, err := s.bookingsRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.Status == "confirmed" {
+		return nil, errors.New("booking already confirmed")
+	}
+	if booking.Status != "pending" {
+		return nil, errors.New("only pending bookings can be confirmed")
+	}
+	if !booking.ExpiresAt.IsZero() && booking.ExpiresAt.Before(s.clock.Now()) {
+		return nil, errors.New("booking has expired")
+	}
+
+	// Скидка покрыла всю стоимость: платить нечего, платежная система не нужна
+	if booking.TotalPrice == 0 {
+		if err := s.confirmFree(booking.ID); err != nil {
+			return nil, err
+		}
+		return s.bookingsRepo.GetByID(booking.ID)
+	}
+
+	if paymentToken == "" {
+		return nil, errors.New("payment token is required")
+	}
+
+	intent, err := s.provider.CreateIntent(booking.ID, booking.TotalPrice, s.currency)
+	if err != nil {
+		return nil, errors.New("failed to create payment")
+	}
+
+	payment := &model.Payment{
+		ID:        uuid.New(),
+		BookingID: booking.ID,
+		Provider:  s.provider.Name(),
+		IntentID:  intent.ID,
+		Amount:    intent.Amount,
+		Currency:  intent.Currency,
+		Status:    "pending",
+	}
+	if err := s.repo.Create(payment); err != nil {
+		return nil, errors.New("failed to save payment")
+	}
+
+	if _, err := s.provider.Capture(intent.ID, paymentToken); err != nil {
+		payment.Status = "failed"
+		if err := s.repo.Update(payment); err != nil {
+			log.Println("Failed to mark payment as failed:", err)
+		}
+		if errors.Is(err, payments.ErrPaymentDeclined) {
+			return nil, errors.New("payment declined")
+		}
+		return nil, errors.New("failed to capture payment")
+	}
+
+	if err := s.settle(payment); err != nil {
+		return nil, err
+	}
+
+	return s.bookingsRepo.GetByID(booking.ID)
+}
+
+// HandleWebhook обрабатывает уведомление платежной системы. Повторная доставка
+// одного и того же события безопасна.
+func (s *Payments) HandleWebhook(payload []byte, signature string) error {
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return errors.New("invalid webhook signature")
+	}
+
+	payment, err := s.repo.GetByIntentID(event.IntentID)
+	if err != nil {
+		return errors.New("payment not found")
+	}
+
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		if payment.Status != "pending" {
+			return nil
+		}
+		return s.settle(payment)
+	case payments.EventPaymentFailed:
+		if payment.Status != "pending" {
+			return nil
+		}
+		payment.Status = "failed"
+		return s.repo.Update(payment)
+	default:
+		return nil
+	}
+}
+
+// settle фиксирует успешно списанный платеж: в одной транзакции платеж становится
+// captured, бронь подтверждается, места продаются. Платеж блокируется на время
+// транзакции, поэтому ConfirmBooking и вебхук об одном платеже не подтверждают
+// бронь дважды: второй вызов видит captured и завершается успешно. Если бронь
+// подтвердить не удалось - она успела истечь, быть отмененной или транзакция
+// упала - деньги возвращаются.
+func (s *Payments) settle(payment *model.Payment) error {
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		current, err := tx.LockPayment(payment.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != "pending" {
+			payment.Status = current.Status
+			return errPaymentSettled
+		}
+
+		if err := s.confirm(tx, payment.BookingID); err != nil {
+			return err
+		}
+		current.Status = "captured"
+		return tx.UpdatePayment(current)
+	})
+
+	switch {
+	case err == nil:
+		payment.Status = "captured"
+		return nil
+	case errors.Is(err, errPaymentSettled):
+		// Платеж уже обработал параллельный вызов: бронь подтверждена или деньги возвращены
+		if payment.Status == "captured" {
+			return nil
+		}
+		return errBookingNotPending
+	}
+
+	s.refundCaptured(payment)
+	if errors.Is(err, errBookingNotPending) {
+		return err
+	}
+	log.Println("Failed to confirm booking:", err)
+	return errors.New("failed to confirm booking")
+}
+
+// confirmFree подтверждает бронь, полностью оплаченную скидкой, без платежа
+func (s *Payments) confirmFree(bookingID uuid.UUID) error {
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		return s.confirm(tx, bookingID)
+	})
+	if err != nil && !errors.Is(err, errBookingNotPending) {
+		log.Println("Failed to confirm booking:", err)
+		return errors.New("failed to confirm booking")
+	}
+	return err
+}
+
+// confirm подтверждает pending-бронь в транзакции tx: места продаются, выпускаются
+// билеты, покупателю отправляется письмо
+func (s *Payments) confirm(tx settleTx, bookingID uuid.UUID) error {
+	booking, err := tx.LockByID(bookingID)
+	if err != nil {
+		return err
+	}
+	if booking.Status != "pending" {
+		return errBookingNotPending
+	}
+
+	before := bookingAudit(booking, booking.PerformanceSeats)
+	booking.Status = "confirmed"
+	if err := tx.Update(booking); err != nil {
+		return err
+	}
+	err = recordAudit(s.audit, tx, systemActor, "booking.confirm", AuditBooking, booking.ID,
+		before, bookingAudit(booking, booking.PerformanceSeats))
+	if err != nil {
+		return err
+	}
+
+	for _, seat := range booking.PerformanceSeats {
+		if err := tx.UpdatePerformanceSeatStatus(seat.ID, "sold", &booking.ID); err != nil {
+			return err
+		}
+	}
+
+	issued, err := issueTickets(booking)
+	if err != nil {
+		return err
+	}
+	if err := tx.CreateTickets(issued); err != nil {
+		return err
+	}
+
+	return enqueueEmail(s.emails, tx, notifications.TemplateBookingConfirmed, booking.User.Email,
+		bookingEmailData(booking, &booking.User, &booking.Performance, booking.PerformanceSeats))
+}
+
+// refundCaptured возвращает списанный платеж, бронь по которому не подтвердилась.
+// Платеж, который тем временем перестал быть pending, не трогается. Если платежная
+// система недоступна, платеж помечается refund_pending и возврат повторяется в RetryRefunds.
+func (s *Payments) refundCaptured(payment *model.Payment) {
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		current, err := tx.LockPayment(payment.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != "pending" {
+			return nil
+		}
+
+		before := paymentAudit(current)
+		current.Status = "refunded"
+		if _, err := s.provider.Refund(current.IntentID, current.Amount); err != nil {
+			log.Println("Failed to refund payment for unconfirmed booking:", err)
+			current.Status = "refund_pending"
+		}
+		if err := tx.UpdatePayment(current); err != nil {
+			return err
+		}
+		payment.Status = current.Status
+		return recordAudit(s.audit, tx, systemActor, "payment.refund", AuditBooking, current.BookingID,
+			before, paymentAudit(current))
+	})
+	if err != nil {
+		log.Println("Failed to save payment refund status:", err)
+	}
+}
+
+// RetryRefunds повторяет возвраты платежей в статусе refund_pending пачками.
+// Возвращает число успешно возвращенных платежей.
+func (s *Payments) RetryRefunds() (int, error) {
+	pending, err := s.repo.ListByStatus("refund_pending", refundRetryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	for i := range pending {
+		payment := &pending[i]
+		if _, err := s.provider.Refund(payment.IntentID, payment.Amount); err != nil {
+			log.Println("Refund retry failed:", err)
+			continue
+		}
+		payment.Status = "refunded"
+		if err := s.repo.Update(payment); err != nil {
+			return refunded, err
+		}
+		refunded++
+	}
+	return refunded, nil
+}
+
+// Run запускает периодический повтор возвратов до отмены контекста
+func (s *Payments) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.RetryRefunds()
+			if err != nil {
+				log.Println("Refund retry failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Refunded %d payments of unconfirmed bookings", n)
+			}
+		}
+	}
+}
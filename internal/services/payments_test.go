@@ -0,0 +1,396 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPaymentsRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentsRepository) Create(payment *model.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockPaymentsRepository) Update(payment *model.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockPaymentsRepository) GetByIntentID(intentID string) (*model.Payment, error) {
+	args := m.Called(intentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentsRepository) ListByStatus(status string, limit int) ([]model.Payment, error) {
+	args := m.Called(status, limit)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func TestConfirmBooking(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		seatID := uuid.New()
+		booking := &model.Booking{
+			ID:               bookingID,
+			TotalPrice:       3500,
+			Status:           "pending",
+			ExpiresAt:        clock.now.Add(5 * time.Minute),
+			PerformanceSeats: []model.PerformanceSeat{{ID: seatID}},
+		}
+
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "confirmed"
+		})).Return(nil)
+		bookingsRepo.On("UpdatePerformanceSeatStatus", seatID, "sold", &bookingID).Return(nil)
+		bookingsRepo.On("CreateTickets", mock.MatchedBy(func(issued []model.Ticket) bool {
+			return len(issued) == 1 && issued[0].BookingID == bookingID &&
+				issued[0].PerformanceSeatID == seatID && issued[0].Nonce != ""
+		})).Return(nil)
+		locked := &model.Payment{}
+		paymentsRepo.On("Create", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.BookingID == bookingID && p.Amount == 3500 && p.Currency == "BYN"
+		})).Run(func(args mock.Arguments) {
+			*locked = *args.Get(0).(*model.Payment)
+		}).Return(nil)
+		bookingsRepo.On("LockPayment", mock.AnythingOfType("uuid.UUID")).Return(locked, nil)
+		bookingsRepo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "captured"
+		})).Return(nil)
+
+		result, err := service.ConfirmBooking(bookingID.String(), "tok_visa")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "confirmed", result.Status)
+		assert.Equal(t, "captured", locked.Status)
+		bookingsRepo.AssertExpectations(t)
+		paymentsRepo.AssertExpectations(t)
+	})
+
+	t.Run("fully discounted booking is confirmed without payment", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		seatID := uuid.New()
+		booking := &model.Booking{
+			ID:               bookingID,
+			TotalPrice:       0,
+			Discount:         3500,
+			Status:           "pending",
+			ExpiresAt:        clock.now.Add(5 * time.Minute),
+			PerformanceSeats: []model.PerformanceSeat{{ID: seatID}},
+		}
+
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "confirmed"
+		})).Return(nil)
+		bookingsRepo.On("UpdatePerformanceSeatStatus", seatID, "sold", &bookingID).Return(nil)
+		bookingsRepo.On("CreateTickets", mock.AnythingOfType("[]model.Ticket")).Return(nil)
+
+		result, err := service.ConfirmBooking(bookingID.String(), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "confirmed", result.Status)
+		bookingsRepo.AssertExpectations(t)
+		bookingsRepo.AssertNotCalled(t, "LockPayment", mock.Anything)
+		paymentsRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("payment declined", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		booking := &model.Booking{
+			ID:         bookingID,
+			TotalPrice: 1500,
+			Status:     "pending",
+			ExpiresAt:  clock.now.Add(time.Minute),
+		}
+
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		paymentsRepo.On("Create", mock.AnythingOfType("*model.Payment")).Return(nil)
+		paymentsRepo.On("Update", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "failed"
+		})).Return(nil)
+
+		result, err := service.ConfirmBooking(bookingID.String(), payments.DeclinedToken)
+
+		assert.EqualError(t, err, "payment declined")
+		assert.Nil(t, result)
+		bookingsRepo.AssertNotCalled(t, "LockByID", mock.Anything)
+		paymentsRepo.AssertExpectations(t)
+	})
+
+	t.Run("expired booking", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		bookingsRepo.On("GetByID", bookingID).Return(&model.Booking{
+			ID:        bookingID,
+			Status:    "pending",
+			ExpiresAt: clock.now.Add(-time.Second),
+		}, nil)
+
+		_, err := service.ConfirmBooking(bookingID.String(), "tok_visa")
+
+		assert.EqualError(t, err, "booking has expired")
+		paymentsRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("already confirmed", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		bookingsRepo.On("GetByID", bookingID).Return(&model.Booking{ID: bookingID, Status: "confirmed"}, nil)
+
+		_, err := service.ConfirmBooking(bookingID.String(), "tok_visa")
+
+		assert.EqualError(t, err, "booking already confirmed")
+	})
+
+	t.Run("booking expired during capture is refunded", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		bookingsRepo.On("GetByID", bookingID).Return(&model.Booking{
+			ID:         bookingID,
+			TotalPrice: 1500,
+			Status:     "pending",
+			ExpiresAt:  clock.now.Add(time.Minute),
+		}, nil)
+		bookingsRepo.On("LockByID", bookingID).Return(&model.Booking{ID: bookingID, Status: "expired"}, nil)
+		locked := &model.Payment{}
+		paymentsRepo.On("Create", mock.AnythingOfType("*model.Payment")).Run(func(args mock.Arguments) {
+			*locked = *args.Get(0).(*model.Payment)
+		}).Return(nil)
+		bookingsRepo.On("LockPayment", mock.AnythingOfType("uuid.UUID")).Return(locked, nil)
+		bookingsRepo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "refunded"
+		})).Return(nil)
+
+		_, err := service.ConfirmBooking(bookingID.String(), "tok_visa")
+
+		assert.EqualError(t, err, "booking is no longer pending")
+		bookingsRepo.AssertExpectations(t)
+		bookingsRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("failed confirmation is refunded", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		booking := &model.Booking{
+			ID:         bookingID,
+			TotalPrice: 1500,
+			Status:     "pending",
+			ExpiresAt:  clock.now.Add(time.Minute),
+		}
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("LockByID", bookingID).Return(booking, nil)
+		bookingsRepo.On("Update", mock.Anything).Return(errors.New("connection reset"))
+		locked := &model.Payment{}
+		paymentsRepo.On("Create", mock.AnythingOfType("*model.Payment")).Run(func(args mock.Arguments) {
+			*locked = *args.Get(0).(*model.Payment)
+		}).Return(nil)
+		bookingsRepo.On("LockPayment", mock.AnythingOfType("uuid.UUID")).Return(locked, nil)
+		bookingsRepo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "refunded"
+		})).Return(nil)
+
+		_, err := service.ConfirmBooking(bookingID.String(), "tok_visa")
+
+		assert.EqualError(t, err, "failed to confirm booking")
+		bookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid uuid format", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		_, err := service.ConfirmBooking("invalid-uuid", "tok_visa")
+
+		assert.EqualError(t, err, "invalid booking ID format")
+		bookingsRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+}
+
+func TestHandleWebhook(t *testing.T) {
+	t.Run("invalid signature", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		err := service.HandleWebhook([]byte(`{"type":"payment.succeeded","intent_id":"pi_1"}`), "deadbeef")
+
+		assert.EqualError(t, err, "invalid webhook signature")
+		paymentsRepo.AssertNotCalled(t, "GetByIntentID", mock.Anything)
+	})
+
+	t.Run("failed payment event", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		payload := []byte(`{"type":"payment.failed","intent_id":"pi_1"}`)
+		paymentsRepo.On("GetByIntentID", "pi_1").Return(&model.Payment{IntentID: "pi_1", Status: "pending"}, nil)
+		paymentsRepo.On("Update", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "failed"
+		})).Return(nil)
+
+		err := service.HandleWebhook(payload, provider.SignWebhook(payload))
+
+		assert.NoError(t, err)
+		paymentsRepo.AssertExpectations(t)
+	})
+
+	t.Run("duplicate success event is ignored", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		payload := []byte(`{"type":"payment.succeeded","intent_id":"pi_1"}`)
+		paymentsRepo.On("GetByIntentID", "pi_1").Return(&model.Payment{IntentID: "pi_1", Status: "captured"}, nil)
+
+		err := service.HandleWebhook(payload, provider.SignWebhook(payload))
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertNotCalled(t, "LockByID", mock.Anything)
+	})
+
+	t.Run("success event racing confirmation is not refunded", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		// Вебхук прочитал платеж до того, как ConfirmBooking его зафиксировал
+		paymentID := uuid.New()
+		payload := []byte(`{"type":"payment.succeeded","intent_id":"pi_1"}`)
+		paymentsRepo.On("GetByIntentID", "pi_1").Return(&model.Payment{
+			ID: paymentID, IntentID: "pi_1", Amount: 1500, Status: "pending",
+		}, nil)
+		bookingsRepo.On("LockPayment", paymentID).Return(&model.Payment{
+			ID: paymentID, IntentID: "pi_1", Amount: 1500, Status: "captured",
+		}, nil)
+
+		err := service.HandleWebhook(payload, provider.SignWebhook(payload))
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertNotCalled(t, "LockByID", mock.Anything)
+		bookingsRepo.AssertNotCalled(t, "UpdatePayment", mock.Anything)
+		paymentsRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestRetryRefunds(t *testing.T) {
+	t.Run("refund rejected by provider is kept for retry", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		bookingID := uuid.New()
+		paymentID := uuid.New()
+		payload := []byte(`{"type":"payment.succeeded","intent_id":"pi_unknown"}`)
+		paymentsRepo.On("GetByIntentID", "pi_unknown").Return(&model.Payment{
+			ID: paymentID, IntentID: "pi_unknown", BookingID: bookingID, Amount: 1500, Status: "pending",
+		}, nil)
+		bookingsRepo.On("LockPayment", paymentID).Return(&model.Payment{
+			ID: paymentID, IntentID: "pi_unknown", BookingID: bookingID, Amount: 1500, Status: "pending",
+		}, nil)
+		bookingsRepo.On("LockByID", bookingID).Return(nil, errors.New("connection reset"))
+		bookingsRepo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Status == "refund_pending"
+		})).Return(nil)
+
+		err := service.HandleWebhook(payload, provider.SignWebhook(payload))
+
+		assert.EqualError(t, err, "failed to confirm booking")
+		bookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("pending refunds are retried", func(t *testing.T) {
+		paymentsRepo := new(MockPaymentsRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		clock := &fakeClock{now: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC)}
+		service := NewPayments(paymentsRepo, bookingsRepo, provider, clock, "BYN", nil, nil)
+
+		intent, err := provider.CreateIntent(uuid.New(), 1500, "BYN")
+		require.NoError(t, err)
+		_, err = provider.Capture(intent.ID, "tok_visa")
+		require.NoError(t, err)
+
+		paymentsRepo.On("ListByStatus", "refund_pending", refundRetryBatchSize).Return([]model.Payment{
+			{IntentID: intent.ID, Amount: 1500, Status: "refund_pending"},
+			{IntentID: "pi_unknown", Amount: 1500, Status: "refund_pending"},
+		}, nil)
+		paymentsRepo.On("Update", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.IntentID == intent.ID && p.Status == "refunded"
+		})).Return(nil).Once()
+
+		n, err := service.RetryRefunds()
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		paymentsRepo.AssertExpectations(t)
+	})
+}
@@ -2,14 +2,39 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// PerformanceFilter - условия выборки показов; ID пьесы и зала передаются строками
+// из запроса и разбираются сервисом
+type PerformanceFilter struct {
+	PlayID    *string
+	HallID    *string
+	Status    string
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	Available *bool
+}
+
+var (
+	ErrInvalidPlayID = errors.New("invalid play ID format")
+	ErrInvalidHallID = errors.New("invalid hall ID format")
+)
+
+// Поля сортировки списка показов; по умолчанию ближайшие первыми
+var (
+	performanceSortFields  = []string{"date", "created_at"}
+	defaultPerformanceSort = listing.Sort{Field: "date"}
+)
+
 type PerformancesRepository interface {
-	GetAll(playID *uuid.UUID, dateFrom, dateTo *time.Time) ([]model.Performance, error)
+	GetAll(filter repository.PerformanceFilter, query listing.Query) (listing.Page[model.Performance], error)
 	GetByID(id uuid.UUID) (*model.Performance, error)
 	GetSeats(performanceID uuid.UUID) ([]model.PerformanceSeat, error)
 }
@@ -22,17 +47,49 @@ func NewPerformances(repo PerformancesRepository) *Performances {
 	return &Performances{repo: repo}
 }
 
-func (s *Performances) GetAllPerformances(playID *string, dateFrom, dateTo *time.Time) ([]model.Performance, error) {
-	var playUUID *uuid.UUID
-	if playID != nil && *playID != "" {
-		parsed, err := uuid.Parse(*playID)
-		if err != nil {
-			return nil, errors.New("invalid play ID format")
-		}
-		playUUID = &parsed
+// GetAllPerformances возвращает страницу афиши. sort - поле из performanceSortFields,
+// с "-" для сортировки по убыванию; cursor - next_cursor предыдущей страницы.
+// Неверные параметры возвращают ошибку listing.ErrInvalidQuery, неверные ID пьесы
+// и зала - ErrInvalidPlayID и ErrInvalidHallID.
+func (s *Performances) GetAllPerformances(filter PerformanceFilter, sort, cursor string, limit int) (listing.Page[model.Performance], error) {
+	if filter.DateFrom != nil && filter.DateTo != nil && filter.DateFrom.After(*filter.DateTo) {
+		return listing.Page[model.Performance]{}, fmt.Errorf("%w: date_from is after date_to", listing.ErrInvalidQuery)
 	}
 
-	return s.repo.GetAll(playUUID, dateFrom, dateTo)
+	order, err := listing.ParseSort(sort, performanceSortFields, defaultPerformanceSort)
+	if err != nil {
+		return listing.Page[model.Performance]{}, err
+	}
+	query, err := listing.NewQuery(order, cursor, limit)
+	if err != nil {
+		return listing.Page[model.Performance]{}, err
+	}
+
+	repoFilter := repository.PerformanceFilter{
+		Status:    filter.Status,
+		DateFrom:  filter.DateFrom,
+		DateTo:    filter.DateTo,
+		Available: filter.Available,
+	}
+	if repoFilter.PlayID, err = parseOptionalID(filter.PlayID, ErrInvalidPlayID); err != nil {
+		return listing.Page[model.Performance]{}, err
+	}
+	if repoFilter.HallID, err = parseOptionalID(filter.HallID, ErrInvalidHallID); err != nil {
+		return listing.Page[model.Performance]{}, err
+	}
+	return s.repo.GetAll(repoFilter, query)
+}
+
+// parseOptionalID разбирает необязательный ID фильтра, возвращая invalid при ошибке
+func parseOptionalID(value *string, invalid error) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, invalid
+	}
+	return &id, nil
 }
 
 func (s *Performances) GetPerformanceByID(id string) (*model.Performance, error) {
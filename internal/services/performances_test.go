@@ -3,7 +3,9 @@ package service
 import (
 	"errors"
 	"testing"
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,12 +17,9 @@ type MockPerformancesRepository struct {
 	mock.Mock
 }
 
-func (m *MockPerformancesRepository) GetAll(playID *uuid.UUID, dateFrom, dateTo *time.Time) ([]model.Performance, error) {
-	args := m.Called(playID, dateFrom, dateTo)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]model.Performance), args.Error(1)
+func (m *MockPerformancesRepository) GetAll(filter repository.PerformanceFilter, query listing.Query) (listing.Page[model.Performance], error) {
+	args := m.Called(filter, query)
+	return args.Get(0).(listing.Page[model.Performance]), args.Error(1)
 }
 
 func (m *MockPerformancesRepository) GetByID(id uuid.UUID) (*model.Performance, error) {
@@ -40,23 +39,28 @@ func (m *MockPerformancesRepository) GetSeats(performanceID uuid.UUID) ([]model.
 }
 
 func TestGetAllPerformances(t *testing.T) {
+	defaultQuery := listing.Query{Sort: listing.Sort{Field: "date"}, Limit: listing.DefaultLimit}
+
 	t.Run("success without filters", func(t *testing.T) {
 		mockRepo := new(MockPerformancesRepository)
 		service := NewPerformances(mockRepo)
 
-		expectedPerformances := []model.Performance{
-			{
-				ID:     uuid.New(),
-				PlayID: uuid.New(),
-				Date:   time.Now().AddDate(0, 0, 1),
-				Status: "scheduled",
+		expectedPerformances := listing.Page[model.Performance]{
+			Items: []model.Performance{
+				{
+					ID:     uuid.New(),
+					PlayID: uuid.New(),
+					Date:   time.Now().AddDate(0, 0, 1),
+					Status: "scheduled",
+				},
 			},
+			Total: 1,
 		}
 
-		mockRepo.On("GetAll", (*uuid.UUID)(nil), (*time.Time)(nil), (*time.Time)(nil)).
+		mockRepo.On("GetAll", repository.PerformanceFilter{}, defaultQuery).
 			Return(expectedPerformances, nil)
 
-		performances, err := service.GetAllPerformances(nil, nil, nil)
+		performances, err := service.GetAllPerformances(PerformanceFilter{}, "", "", 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPerformances, performances)
@@ -70,19 +74,22 @@ func TestGetAllPerformances(t *testing.T) {
 		playID := uuid.New()
 		playIDStr := playID.String()
 
-		expectedPerformances := []model.Performance{
-			{
-				ID:     uuid.New(),
-				PlayID: playID,
-				Date:   time.Now(),
-				Status: "scheduled",
+		expectedPerformances := listing.Page[model.Performance]{
+			Items: []model.Performance{
+				{
+					ID:     uuid.New(),
+					PlayID: playID,
+					Date:   time.Now(),
+					Status: "scheduled",
+				},
 			},
+			Total: 1,
 		}
 
-		mockRepo.On("GetAll", &playID, (*time.Time)(nil), (*time.Time)(nil)).
+		mockRepo.On("GetAll", repository.PerformanceFilter{PlayID: &playID}, defaultQuery).
 			Return(expectedPerformances, nil)
 
-		performances, err := service.GetAllPerformances(&playIDStr, nil, nil)
+		performances, err := service.GetAllPerformances(PerformanceFilter{PlayID: &playIDStr}, "", "", 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPerformances, performances)
@@ -96,17 +103,20 @@ func TestGetAllPerformances(t *testing.T) {
 		dateFrom := time.Now()
 		dateTo := time.Now().AddDate(0, 0, 7)
 
-		expectedPerformances := []model.Performance{
-			{
-				ID:   uuid.New(),
-				Date: time.Now().AddDate(0, 0, 3),
+		expectedPerformances := listing.Page[model.Performance]{
+			Items: []model.Performance{
+				{
+					ID:   uuid.New(),
+					Date: time.Now().AddDate(0, 0, 3),
+				},
 			},
+			Total: 1,
 		}
 
-		mockRepo.On("GetAll", (*uuid.UUID)(nil), &dateFrom, &dateTo).
+		mockRepo.On("GetAll", repository.PerformanceFilter{DateFrom: &dateFrom, DateTo: &dateTo}, defaultQuery).
 			Return(expectedPerformances, nil)
 
-		performances, err := service.GetAllPerformances(nil, &dateFrom, &dateTo)
+		performances, err := service.GetAllPerformances(PerformanceFilter{DateFrom: &dateFrom, DateTo: &dateTo}, "", "", 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPerformances, performances)
@@ -119,10 +129,10 @@ func TestGetAllPerformances(t *testing.T) {
 
 		invalidID := "invalid-uuid"
 
-		performances, err := service.GetAllPerformances(&invalidID, nil, nil)
+		performances, err := service.GetAllPerformances(PerformanceFilter{PlayID: &invalidID}, "", "", 0)
 
 		assert.Error(t, err)
-		assert.Nil(t, performances)
+		assert.Empty(t, performances.Items)
 		assert.EqualError(t, err, "invalid play ID format")
 		mockRepo.AssertNotCalled(t, "GetAll")
 	})
@@ -131,15 +141,99 @@ func TestGetAllPerformances(t *testing.T) {
 		mockRepo := new(MockPerformancesRepository)
 		service := NewPerformances(mockRepo)
 
-		mockRepo.On("GetAll", (*uuid.UUID)(nil), (*time.Time)(nil), (*time.Time)(nil)).
-			Return(nil, errors.New("database error"))
+		mockRepo.On("GetAll", repository.PerformanceFilter{}, defaultQuery).
+			Return(listing.Page[model.Performance]{}, errors.New("database error"))
 
-		performances, err := service.GetAllPerformances(nil, nil, nil)
+		performances, err := service.GetAllPerformances(PerformanceFilter{}, "", "", 0)
 
 		assert.Error(t, err)
-		assert.Nil(t, performances)
+		assert.Empty(t, performances.Items)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filters with sort and cursor", func(t *testing.T) {
+		mockRepo := new(MockPerformancesRepository)
+		service := NewPerformances(mockRepo)
+
+		playID := uuid.New()
+		hallID := uuid.New()
+		playIDStr, hallIDStr := playID.String(), hallID.String()
+		available := true
+		dateFrom := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+		order := listing.Sort{Field: "date", Desc: true}
+		last := model.Performance{ID: uuid.New(), Date: dateFrom.AddDate(0, 0, 3)}
+		cursor := listing.Cursor{Sort: order.String(), Value: last.Date.Format(time.RFC3339Nano), ID: last.ID}.Encode()
+		expected := listing.Page[model.Performance]{
+			Items: []model.Performance{{ID: uuid.New(), PlayID: playID, HallID: hallID, Date: dateFrom.AddDate(0, 0, 2), Status: "on_sale"}},
+			Total: 2,
+		}
+		mockRepo.On("GetAll", repository.PerformanceFilter{
+			PlayID:    &playID,
+			HallID:    &hallID,
+			Status:    "on_sale",
+			DateFrom:  &dateFrom,
+			Available: &available,
+		}, mock.MatchedBy(func(query listing.Query) bool {
+			return query.Sort == order && query.Limit == 10 && query.Cursor != nil && query.Cursor.ID == last.ID
+		})).Return(expected, nil)
+
+		page, err := service.GetAllPerformances(PerformanceFilter{
+			PlayID:    &playIDStr,
+			HallID:    &hallIDStr,
+			Status:    "on_sale",
+			DateFrom:  &dateFrom,
+			Available: &available,
+		}, "-date", cursor, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, page)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("invalid hall ID format", func(t *testing.T) {
+		mockRepo := new(MockPerformancesRepository)
+		service := NewPerformances(mockRepo)
+
+		invalidID := "invalid-uuid"
+
+		_, err := service.GetAllPerformances(PerformanceFilter{HallID: &invalidID}, "", "", 0)
+
+		assert.ErrorIs(t, err, ErrInvalidHallID)
+		mockRepo.AssertNotCalled(t, "GetAll")
+	})
+
+	t.Run("date range is reversed", func(t *testing.T) {
+		mockRepo := new(MockPerformancesRepository)
+		service := NewPerformances(mockRepo)
+
+		dateFrom := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+		dateTo := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := service.GetAllPerformances(PerformanceFilter{DateFrom: &dateFrom, DateTo: &dateTo}, "", "", 0)
+
+		assert.ErrorIs(t, err, listing.ErrInvalidQuery)
+		mockRepo.AssertNotCalled(t, "GetAll")
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		mockRepo := new(MockPerformancesRepository)
+		service := NewPerformances(mockRepo)
+
+		_, err := service.GetAllPerformances(PerformanceFilter{}, "-price", "", 0)
+
+		assert.ErrorIs(t, err, listing.ErrInvalidQuery)
+		mockRepo.AssertNotCalled(t, "GetAll")
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		mockRepo := new(MockPerformancesRepository)
+		service := NewPerformances(mockRepo)
+
+		_, err := service.GetAllPerformances(PerformanceFilter{}, "", "not-a-cursor", 0)
+
+		assert.ErrorIs(t, err, listing.ErrInvalidQuery)
+		mockRepo.AssertNotCalled(t, "GetAll")
+	})
 }
 
 func TestGetPerformanceByID(t *testing.T) {
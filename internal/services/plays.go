@@ -3,27 +3,90 @@ package service
 import (
 	"errors"
 	"github.com/google/uuid"
+	"strings"
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	"unicode/utf8"
 )
 
+// PlayFilter - условия выборки спектаклей
+type PlayFilter = repository.PlayFilter
+
+// Поля сортировки списка спектаклей; по умолчанию сначала новые
+var (
+	playSortFields  = []string{"title", "author", "duration", "created_at"}
+	defaultPlaySort = listing.Sort{Field: "created_at", Desc: true}
+)
+
+// Ограничения поиска по репертуару
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchLength    = 200
+)
+
+// ErrInvalidSearch - пустая или слишком длинная поисковая строка
+var ErrInvalidSearch = errors.New("search query must be 1 to 200 characters long")
+
 type PlaysRepository interface {
-	GetAll() ([]model.Play, error)
+	GetAll(filter repository.PlayFilter, query listing.Query) (listing.Page[model.Play], error)
+	Search(text string, limit int) ([]model.PlayMatch, error)
 	GetByID(id uuid.UUID) (*model.Play, error)
 	Create(play *model.Play) error
 	Update(play *model.Play) error
 	Delete(id uuid.UUID) error
+	Transaction(fn func(tx PlaysTx) error) error
+}
+
+// PlaysTx - операции со спектаклями, доступные внутри транзакции
+type PlaysTx interface {
+	AuditRecorder
+	Create(play *model.Play) error
+	Update(play *model.Play) error
+	Delete(id uuid.UUID) error
 }
 
 type Plays struct {
-	repo PlaysRepository
+	repo  PlaysRepository
+	audit *Audit
 }
 
-func NewPlays(repo PlaysRepository) *Plays {
-	return &Plays{repo: repo}
+// NewPlays создает сервис каталога спектаклей. audit необязателен: без него
+// изменения каталога не попадают в журнал аудита.
+func NewPlays(repo PlaysRepository, audit *Audit) *Plays {
+	return &Plays{repo: repo, audit: audit}
 }
 
-func (s *Plays) GetAllPlays() ([]model.Play, error) {
-	return s.repo.GetAll()
+// GetAllPlays возвращает страницу каталога. sort - поле из playSortFields, с "-"
+// для сортировки по убыванию; cursor - next_cursor предыдущей страницы.
+// Неверные параметры возвращают ошибку listing.ErrInvalidQuery.
+func (s *Plays) GetAllPlays(filter PlayFilter, sort, cursor string, limit int) (listing.Page[model.Play], error) {
+	order, err := listing.ParseSort(sort, playSortFields, defaultPlaySort)
+	if err != nil {
+		return listing.Page[model.Play]{}, err
+	}
+	query, err := listing.NewQuery(order, cursor, limit)
+	if err != nil {
+		return listing.Page[model.Play]{}, err
+	}
+	return s.repo.GetAll(filter, query)
+}
+
+// Search ищет спектакли по поисковой строке query с учетом русской морфологии и
+// возвращает не больше limit самых подходящих вместе с предстоящими показами
+func (s *Plays) Search(query string, limit int) ([]model.PlayMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxSearchLength {
+		return nil, ErrInvalidSearch
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	return s.repo.Search(query, limit)
 }
 
 func (s *Plays) GetPlayByID(id string) (*model.Play, error) {
@@ -40,7 +103,7 @@ func (s *Plays) GetPlayByID(id string) (*model.Play, error) {
 	return play, nil
 }
 
-func (s *Plays) CreatePlay(play *model.Play) error {
+func (s *Plays) CreatePlay(actor model.Actor, play *model.Play) error {
 	play.ID = uuid.New()
 	if play.Title == "" {
 		return errors.New("play title is required")
@@ -52,10 +115,15 @@ func (s *Plays) CreatePlay(play *model.Play) error {
 		return errors.New("play duration must be positive")
 	}
 
-	return s.repo.Create(play)
+	return s.repo.Transaction(func(tx PlaysTx) error {
+		if err := tx.Create(play); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "play.create", AuditPlay, play.ID, nil, playAudit(play))
+	})
 }
 
-func (s *Plays) UpdatePlay(id string, play *model.Play) error {
+func (s *Plays) UpdatePlay(actor model.Actor, id string, play *model.Play) error {
 	playID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid play ID format")
@@ -69,19 +137,29 @@ func (s *Plays) UpdatePlay(id string, play *model.Play) error {
 	play.ID = existing.ID
 	play.CreatedAt = existing.CreatedAt
 
-	return s.repo.Update(play)
+	return s.repo.Transaction(func(tx PlaysTx) error {
+		if err := tx.Update(play); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "play.update", AuditPlay, play.ID, playAudit(existing), playAudit(play))
+	})
 }
 
-func (s *Plays) DeletePlay(id string) error {
+func (s *Plays) DeletePlay(actor model.Actor, id string) error {
 	playID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid play ID format")
 	}
 
-	_, err = s.repo.GetByID(playID)
+	existing, err := s.repo.GetByID(playID)
 	if err != nil {
 		return errors.New("play not found")
 	}
 
-	return s.repo.Delete(playID)
+	return s.repo.Transaction(func(tx PlaysTx) error {
+		if err := tx.Delete(playID); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "play.delete", AuditPlay, playID, playAudit(existing), nil)
+	})
 }
@@ -2,8 +2,11 @@ package service
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"theater-ticket-system/internal/listing"
 	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,12 +19,14 @@ type MockPlaysRepository struct {
 	mock.Mock
 }
 
-func (m *MockPlaysRepository) GetAll() ([]model.Play, error) {
-	args := m.Called()
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]model.Play), args.Error(1)
+func (m *MockPlaysRepository) GetAll(filter repository.PlayFilter, query listing.Query) (listing.Page[model.Play], error) {
+	args := m.Called(filter, query)
+	return args.Get(0).(listing.Page[model.Play]), args.Error(1)
+}
+
+func (m *MockPlaysRepository) Search(text string, limit int) ([]model.PlayMatch, error) {
+	args := m.Called(text, limit)
+	return args.Get(0).([]model.PlayMatch), args.Error(1)
 }
 
 func (m *MockPlaysRepository) GetByID(id uuid.UUID) (*model.Play, error) {
@@ -47,74 +52,148 @@ func (m *MockPlaysRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
-// TestGetAllPlays тестирует получение всех спектаклей
+func (m *MockPlaysRepository) RecordAudit(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockPlaysRepository) Transaction(fn func(tx PlaysTx) error) error {
+	return fn(m)
+}
+
+// TestGetAllPlays тестирует получение страницы каталога
 func TestGetAllPlays(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+	t.Run("default sort and page size", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
-
-		expectedPlays := []model.Play{
-			{
-				ID:          uuid.New(),
-				Title:       "Гамлет",
-				Author:      "Шекспир",
-				Description: "Трагедия",
-				Duration:    180,
-				Genre:       "трагедия",
-			},
-			{
-				ID:          uuid.New(),
-				Title:       "Вишневый сад",
-				Author:      "Чехов",
-				Description: "Драма",
-				Duration:    150,
-				Genre:       "драма",
-			},
+		service := NewPlays(mockRepo, nil)
+
+		filter := PlayFilter{Genre: "трагедия"}
+		expected := listing.Page[model.Play]{
+			Items: []model.Play{{ID: uuid.New(), Title: "Гамлет", Author: "Шекспир", Duration: 180, Genre: "трагедия"}},
+			Total: 1,
 		}
+		mockRepo.On("GetAll", filter, listing.Query{
+			Sort:  listing.Sort{Field: "created_at", Desc: true},
+			Limit: listing.DefaultLimit,
+		}).Return(expected, nil)
 
-		mockRepo.On("GetAll").Return(expectedPlays, nil)
+		page, err := service.GetAllPlays(filter, "", "", 0)
 
-		plays, err := service.GetAllPlays()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, page)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sort and cursor", func(t *testing.T) {
+		mockRepo := new(MockPlaysRepository)
+		service := NewPlays(mockRepo, nil)
+
+		cursor := listing.Cursor{Sort: "-title", Value: "Чайка", ID: uuid.New()}
+		mockRepo.On("GetAll", PlayFilter{}, listing.Query{
+			Sort:   listing.Sort{Field: "title", Desc: true},
+			Cursor: &cursor,
+			Limit:  10,
+		}).Return(listing.Page[model.Play]{}, nil)
+
+		_, err := service.GetAllPlays(PlayFilter{}, "-title", cursor.Encode(), 10)
 
 		assert.NoError(t, err)
-		assert.Equal(t, expectedPlays, plays)
-		assert.Len(t, plays, 2)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockRepo := new(MockPlaysRepository)
+		service := NewPlays(mockRepo, nil)
+
+		_, err := service.GetAllPlays(PlayFilter{}, "price", "", 0)
+		assert.ErrorIs(t, err, listing.ErrInvalidQuery)
+
+		_, err = service.GetAllPlays(PlayFilter{}, "title", "garbage", 0)
+		assert.ErrorIs(t, err, listing.ErrInvalidQuery)
+
+		mockRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
-		mockRepo.On("GetAll").Return(nil, errors.New("database error"))
+		mockRepo.On("GetAll", PlayFilter{}, mock.Anything).
+			Return(listing.Page[model.Play]{}, errors.New("database error"))
 
-		plays, err := service.GetAllPlays()
+		_, err := service.GetAllPlays(PlayFilter{}, "", "", 0)
 
-		assert.Error(t, err)
-		assert.Nil(t, plays)
 		assert.EqualError(t, err, "database error")
-		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("empty list", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
-		mockRepo.On("GetAll").Return([]model.Play{}, nil)
+		mockRepo.On("GetAll", PlayFilter{}, mock.Anything).Return(listing.Page[model.Play]{Items: []model.Play{}}, nil)
 
-		plays, err := service.GetAllPlays()
+		page, err := service.GetAllPlays(PlayFilter{}, "", "", 0)
 
 		assert.NoError(t, err)
-		assert.Empty(t, plays)
+		assert.Empty(t, page.Items)
+		assert.Zero(t, page.Total)
+		assert.Empty(t, page.NextCursor)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
+// TestSearchPlays тестирует поиск по репертуару
+func TestSearchPlays(t *testing.T) {
+	t.Run("trimmed query and default limit", func(t *testing.T) {
+		mockRepo := new(MockPlaysRepository)
+		service := NewPlays(mockRepo, nil)
+
+		expected := []model.PlayMatch{{
+			Play:           model.Play{ID: uuid.New(), Title: "Вишнёвый сад", Author: "Чехов"},
+			Rank:           0.6,
+			TitleHeadline:  "<mark>Вишнёвый</mark> <mark>сад</mark>",
+			AuthorHeadline: "Чехов",
+		}}
+		mockRepo.On("Search", "вишнёвый сад", defaultSearchLimit).Return(expected, nil)
+
+		matches, err := service.Search("  вишнёвый сад ", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, matches)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockRepo := new(MockPlaysRepository)
+		service := NewPlays(mockRepo, nil)
+
+		mockRepo.On("Search", "Чехов", maxSearchLimit).Return([]model.PlayMatch{}, nil)
+
+		_, err := service.Search("Чехов", 1000)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty or too long query", func(t *testing.T) {
+		mockRepo := new(MockPlaysRepository)
+		service := NewPlays(mockRepo, nil)
+
+		_, err := service.Search("   ", 10)
+		assert.ErrorIs(t, err, ErrInvalidSearch)
+
+		_, err = service.Search(strings.Repeat("я", maxSearchLength+1), 10)
+		assert.ErrorIs(t, err, ErrInvalidSearch)
+
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
+
 // TestGetPlayByID тестирует получение спектакля по ID
 func TestGetPlayByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		expectedPlay := &model.Play{
@@ -139,7 +218,7 @@ func TestGetPlayByID(t *testing.T) {
 
 	t.Run("invalid uuid format", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		play, err := service.GetPlayByID("invalid-uuid")
 
@@ -151,7 +230,7 @@ func TestGetPlayByID(t *testing.T) {
 
 	t.Run("play not found", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		mockRepo.On("GetByID", playID).Return(nil, errors.New("not found"))
@@ -169,7 +248,7 @@ func TestGetPlayByID(t *testing.T) {
 func TestCreatePlay(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:       "Ревизор",
@@ -184,7 +263,7 @@ func TestCreatePlay(t *testing.T) {
 			return p.Title == "Ревизор" && p.Author == "Гоголь" && p.Duration == 120
 		})).Return(nil)
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, newPlay.ID)
@@ -193,7 +272,7 @@ func TestCreatePlay(t *testing.T) {
 
 	t.Run("missing title", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "",
@@ -201,7 +280,7 @@ func TestCreatePlay(t *testing.T) {
 			Duration: 120,
 		}
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play title is required")
@@ -210,7 +289,7 @@ func TestCreatePlay(t *testing.T) {
 
 	t.Run("missing author", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "Ревизор",
@@ -218,7 +297,7 @@ func TestCreatePlay(t *testing.T) {
 			Duration: 120,
 		}
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play author is required")
@@ -227,7 +306,7 @@ func TestCreatePlay(t *testing.T) {
 
 	t.Run("invalid duration zero", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "Ревизор",
@@ -235,7 +314,7 @@ func TestCreatePlay(t *testing.T) {
 			Duration: 0,
 		}
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play duration must be positive")
@@ -244,7 +323,7 @@ func TestCreatePlay(t *testing.T) {
 
 	t.Run("invalid duration negative", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "Ревизор",
@@ -252,7 +331,7 @@ func TestCreatePlay(t *testing.T) {
 			Duration: -10,
 		}
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play duration must be positive")
@@ -261,7 +340,7 @@ func TestCreatePlay(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "Ревизор",
@@ -271,7 +350,7 @@ func TestCreatePlay(t *testing.T) {
 
 		mockRepo.On("Create", mock.Anything).Return(errors.New("database error"))
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "database error")
@@ -283,7 +362,7 @@ func TestCreatePlay(t *testing.T) {
 func TestUpdatePlay(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		createdAt := time.Now().Add(-24 * time.Hour)
@@ -309,7 +388,7 @@ func TestUpdatePlay(t *testing.T) {
 				p.CreatedAt.Equal(createdAt)
 		})).Return(nil)
 
-		err := service.UpdatePlay(playID.String(), updatedPlay)
+		err := service.UpdatePlay(testActor, playID.String(), updatedPlay)
 
 		assert.NoError(t, err)
 		assert.Equal(t, playID, updatedPlay.ID)
@@ -319,13 +398,13 @@ func TestUpdatePlay(t *testing.T) {
 
 	t.Run("invalid uuid format", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		updatedPlay := &model.Play{
 			Title: "Новое название",
 		}
 
-		err := service.UpdatePlay("invalid-uuid", updatedPlay)
+		err := service.UpdatePlay(testActor, "invalid-uuid", updatedPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "invalid play ID format")
@@ -335,7 +414,7 @@ func TestUpdatePlay(t *testing.T) {
 
 	t.Run("play not found", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		updatedPlay := &model.Play{
@@ -344,7 +423,7 @@ func TestUpdatePlay(t *testing.T) {
 
 		mockRepo.On("GetByID", playID).Return(nil, errors.New("not found"))
 
-		err := service.UpdatePlay(playID.String(), updatedPlay)
+		err := service.UpdatePlay(testActor, playID.String(), updatedPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play not found")
@@ -354,7 +433,7 @@ func TestUpdatePlay(t *testing.T) {
 
 	t.Run("repository update error", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		existingPlay := &model.Play{
@@ -369,7 +448,7 @@ func TestUpdatePlay(t *testing.T) {
 		mockRepo.On("GetByID", playID).Return(existingPlay, nil)
 		mockRepo.On("Update", mock.Anything).Return(errors.New("database error"))
 
-		err := service.UpdatePlay(playID.String(), updatedPlay)
+		err := service.UpdatePlay(testActor, playID.String(), updatedPlay)
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "database error")
@@ -381,7 +460,7 @@ func TestUpdatePlay(t *testing.T) {
 func TestDeletePlay(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		existingPlay := &model.Play{
@@ -392,7 +471,7 @@ func TestDeletePlay(t *testing.T) {
 		mockRepo.On("GetByID", playID).Return(existingPlay, nil)
 		mockRepo.On("Delete", playID).Return(nil)
 
-		err := service.DeletePlay(playID.String())
+		err := service.DeletePlay(testActor, playID.String())
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -400,9 +479,9 @@ func TestDeletePlay(t *testing.T) {
 
 	t.Run("invalid uuid format", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
-		err := service.DeletePlay("invalid-uuid")
+		err := service.DeletePlay(testActor, "invalid-uuid")
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "invalid play ID format")
@@ -412,12 +491,12 @@ func TestDeletePlay(t *testing.T) {
 
 	t.Run("play not found", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		mockRepo.On("GetByID", playID).Return(nil, errors.New("not found"))
 
-		err := service.DeletePlay(playID.String())
+		err := service.DeletePlay(testActor, playID.String())
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "play not found")
@@ -427,7 +506,7 @@ func TestDeletePlay(t *testing.T) {
 
 	t.Run("repository delete error", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		existingPlay := &model.Play{
@@ -438,7 +517,7 @@ func TestDeletePlay(t *testing.T) {
 		mockRepo.On("GetByID", playID).Return(existingPlay, nil)
 		mockRepo.On("Delete", playID).Return(errors.New("database error"))
 
-		err := service.DeletePlay(playID.String())
+		err := service.DeletePlay(testActor, playID.String())
 
 		assert.Error(t, err)
 		assert.EqualError(t, err, "database error")
@@ -450,7 +529,7 @@ func TestDeletePlay(t *testing.T) {
 func TestServiceEdgeCases(t *testing.T) {
 	t.Run("create play with minimum valid values", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		newPlay := &model.Play{
 			Title:    "A",
@@ -460,7 +539,7 @@ func TestServiceEdgeCases(t *testing.T) {
 
 		mockRepo.On("Create", mock.Anything).Return(nil)
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -468,7 +547,7 @@ func TestServiceEdgeCases(t *testing.T) {
 
 	t.Run("create play with very long strings", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		longString := string(make([]byte, 10000))
 		newPlay := &model.Play{
@@ -480,7 +559,7 @@ func TestServiceEdgeCases(t *testing.T) {
 
 		mockRepo.On("Create", mock.Anything).Return(nil)
 
-		err := service.CreatePlay(newPlay)
+		err := service.CreatePlay(testActor, newPlay)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -488,7 +567,7 @@ func TestServiceEdgeCases(t *testing.T) {
 
 	t.Run("update preserves created_at timestamp", func(t *testing.T) {
 		mockRepo := new(MockPlaysRepository)
-		service := NewPlays(mockRepo)
+		service := NewPlays(mockRepo, nil)
 
 		playID := uuid.New()
 		originalCreatedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -508,7 +587,7 @@ func TestServiceEdgeCases(t *testing.T) {
 			return p.CreatedAt.Equal(originalCreatedAt)
 		})).Return(nil)
 
-		err := service.UpdatePlay(playID.String(), updatedPlay)
+		err := service.UpdatePlay(testActor, playID.String(), updatedPlay)
 
 		assert.NoError(t, err)
 		assert.Equal(t, originalCreatedAt, updatedPlay.CreatedAt)
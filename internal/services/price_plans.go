@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+)
+
+type PricePlansRepository interface {
+	GetAll() ([]model.PricePlan, error)
+	GetByID(id uuid.UUID) (*model.PricePlan, error)
+	Create(plan *model.PricePlan) error
+}
+
+type PricePlans struct {
+	repo PricePlansRepository
+}
+
+func NewPricePlans(repo PricePlansRepository) *PricePlans {
+	return &PricePlans{repo: repo}
+}
+
+func (s *PricePlans) GetAllPricePlans() ([]model.PricePlan, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PricePlans) GetPricePlanByID(id string) (*model.PricePlan, error) {
+	planID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid price plan ID format")
+	}
+
+	plan, err := s.repo.GetByID(planID)
+	if err != nil {
+		return nil, errors.New("price plan not found")
+	}
+
+	return plan, nil
+}
+
+// CreatePricePlan проверяет и сохраняет тарифный план
+func (s *PricePlans) CreatePricePlan(plan *model.PricePlan) error {
+	if plan.Name == "" {
+		return errors.New("price plan name is required")
+	}
+	if plan.DefaultPrice <= 0 {
+		return errors.New("default price must be positive")
+	}
+	if plan.WeekendSurcharge < 0 || plan.PremiereSurcharge < 0 {
+		return errors.New("surcharges cannot be negative")
+	}
+
+	plan.ID = uuid.New()
+	for i := range plan.Tiers {
+		tier := &plan.Tiers[i]
+		if tier.Category != "" && !seatCategories[tier.Category] {
+			return fmt.Errorf("unknown seat category %q", tier.Category)
+		}
+		if tier.RowFrom < 1 || (tier.RowTo != 0 && tier.RowTo < tier.RowFrom) {
+			return fmt.Errorf("tier %d: invalid row range %d-%d", i+1, tier.RowFrom, tier.RowTo)
+		}
+		if tier.Price <= 0 {
+			return fmt.Errorf("tier %d: price must be positive", i+1)
+		}
+		tier.ID = uuid.New()
+		tier.PricePlanID = plan.ID
+	}
+
+	seen := make(map[int]bool, len(plan.DemandSteps))
+	for i := range plan.DemandSteps {
+		step := &plan.DemandSteps[i]
+		if step.Occupancy < 1 || step.Occupancy > 100 {
+			return errors.New("demand step occupancy must be between 1 and 100")
+		}
+		if step.Surcharge <= 0 {
+			return errors.New("demand step surcharge must be positive")
+		}
+		if seen[step.Occupancy] {
+			return fmt.Errorf("duplicate demand step for %d%% occupancy", step.Occupancy)
+		}
+		seen[step.Occupancy] = true
+		step.ID = uuid.New()
+		step.PricePlanID = plan.ID
+	}
+
+	return s.repo.Create(plan)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPricePlansRepository struct {
+	mock.Mock
+}
+
+func (m *MockPricePlansRepository) GetAll() ([]model.PricePlan, error) {
+	args := m.Called()
+	return args.Get(0).([]model.PricePlan), args.Error(1)
+}
+
+func (m *MockPricePlansRepository) GetByID(id uuid.UUID) (*model.PricePlan, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PricePlan), args.Error(1)
+}
+
+func (m *MockPricePlansRepository) Create(plan *model.PricePlan) error {
+	args := m.Called(plan)
+	return args.Error(0)
+}
+
+func TestCreatePricePlan(t *testing.T) {
+	t.Run("assigns ids to plan and rules", func(t *testing.T) {
+		repo := new(MockPricePlansRepository)
+		service := NewPricePlans(repo)
+
+		plan := &model.PricePlan{
+			Name:         "Вечерний",
+			DefaultPrice: 1200,
+			Tiers:        []model.PriceTier{{Category: "box", RowFrom: 1, Price: 3000}},
+			DemandSteps:  []model.DemandStep{{Occupancy: 75, Surcharge: 10}},
+		}
+		repo.On("Create", plan).Return(nil)
+
+		err := service.CreatePricePlan(plan)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, plan.ID)
+		assert.Equal(t, plan.ID, plan.Tiers[0].PricePlanID)
+		assert.Equal(t, plan.ID, plan.DemandSteps[0].PricePlanID)
+	})
+
+	t.Run("invalid row range", func(t *testing.T) {
+		repo := new(MockPricePlansRepository)
+		service := NewPricePlans(repo)
+
+		err := service.CreatePricePlan(&model.PricePlan{
+			Name:         "Ошибочный",
+			DefaultPrice: 1000,
+			Tiers:        []model.PriceTier{{RowFrom: 5, RowTo: 2, Price: 1500}},
+		})
+
+		assert.EqualError(t, err, "tier 1: invalid row range 5-2")
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("duplicate demand step", func(t *testing.T) {
+		repo := new(MockPricePlansRepository)
+		service := NewPricePlans(repo)
+
+		err := service.CreatePricePlan(&model.PricePlan{
+			Name:         "Спрос",
+			DefaultPrice: 1000,
+			DemandSteps:  []model.DemandStep{{Occupancy: 50, Surcharge: 5}, {Occupancy: 50, Surcharge: 10}},
+		})
+
+		assert.EqualError(t, err, "duplicate demand step for 50% occupancy")
+	})
+}
+
+func TestPricingEngine(t *testing.T) {
+	minsk := time.FixedZone("Europe/Minsk", 3*60*60)
+	engine := NewPricingEngine(minsk)
+	plan := &model.PricePlan{
+		WeekendSurcharge: 10,
+		DemandSteps: []model.DemandStep{
+			{Occupancy: 50, Surcharge: 5},
+			{Occupancy: 90, Surcharge: 20},
+		},
+	}
+
+	t.Run("weekend is determined in theater timezone", func(t *testing.T) {
+		// Пятница 22:00 UTC - уже суббота в Минске
+		performance := &model.Performance{Date: time.Date(2025, 5, 9, 22, 0, 0, 0, time.UTC), PricePlan: plan}
+
+		assert.Equal(t, 10, engine.Surcharge(performance, 0, 100))
+	})
+
+	t.Run("highest reached demand step applies", func(t *testing.T) {
+		performance := &model.Performance{Date: time.Date(2025, 5, 7, 19, 0, 0, 0, minsk), PricePlan: plan}
+
+		assert.Equal(t, 0, engine.Surcharge(performance, 49, 100))
+		assert.Equal(t, 5, engine.Surcharge(performance, 50, 100))
+		assert.Equal(t, 20, engine.Surcharge(performance, 95, 100))
+	})
+
+	t.Run("no plan means no surcharges", func(t *testing.T) {
+		performance := &model.Performance{Date: time.Date(2025, 5, 10, 19, 0, 0, 0, minsk), Premiere: true}
+
+		assert.Equal(t, 0, engine.Surcharge(performance, 100, 100))
+	})
+
+	t.Run("charge rounds to whole units", func(t *testing.T) {
+		assert.Equal(t, 1155, engine.Charge(1050, 10))
+		assert.Equal(t, 1500, engine.Charge(1500, 0))
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"theater-ticket-system/internal/models/models"
+	"time"
+)
+
+// PricingRule определяет начальную цену места при создании показа
+type PricingRule interface {
+	Price(seat model.Seat) int
+}
+
+// CategoryPricing - цены по категориям мест с отдельной ценой для первых рядов партера
+type CategoryPricing struct {
+	Prices         map[string]int
+	FrontRows      int
+	FrontRowsPrice int
+	DefaultPrice   int
+}
+
+// DefaultPricing повторяет цены, которыми заполняется тестовая база
+var DefaultPricing = CategoryPricing{
+	Prices: map[string]int{
+		"parterre": 1500,
+		"balcony":  1000,
+		"box":      2500,
+	},
+	FrontRows:      5,
+	FrontRowsPrice: 3500,
+	DefaultPrice:   1500,
+}
+
+func (p CategoryPricing) Price(seat model.Seat) int {
+	if seat.Category == "parterre" && seat.Row <= p.FrontRows && p.FrontRowsPrice > 0 {
+		return p.FrontRowsPrice
+	}
+	if price, ok := p.Prices[seat.Category]; ok {
+		return price
+	}
+	return p.DefaultPrice
+}
+
+// PricingEngine рассчитывает цену места при бронировании: к базовой цене
+// добавляются надбавки тарифного плана за выходной день, премьеру и спрос.
+// Надбавки в процентах складываются.
+type PricingEngine struct {
+	location *time.Location
+}
+
+// NewPricingEngine создает движок; location определяет, какие дни считаются выходными
+func NewPricingEngine(location *time.Location) *PricingEngine {
+	if location == nil {
+		location = time.UTC
+	}
+	return &PricingEngine{location: location}
+}
+
+// Surcharge возвращает суммарную надбавку в процентах для показа при заполненности
+// occupied из total мест
+func (e *PricingEngine) Surcharge(performance *model.Performance, occupied, total int64) int {
+	plan := performance.PricePlan
+	if plan == nil {
+		return 0
+	}
+
+	surcharge := 0
+	switch performance.Date.In(e.location).Weekday() {
+	case time.Saturday, time.Sunday:
+		surcharge += plan.WeekendSurcharge
+	}
+	if performance.Premiere {
+		surcharge += plan.PremiereSurcharge
+	}
+
+	if total > 0 {
+		occupancy := int(occupied * 100 / total)
+		demand := 0
+		for _, step := range plan.DemandSteps {
+			if occupancy >= step.Occupancy && step.Surcharge > demand {
+				demand = step.Surcharge
+			}
+		}
+		surcharge += demand
+	}
+
+	return surcharge
+}
+
+// Charge применяет надбавку к базовой цене с округлением до целого
+func (e *PricingEngine) Charge(base, surcharge int) int {
+	return (base*(100+surcharge) + 50) / 100
+}
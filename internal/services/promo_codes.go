@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoCodesRepository interface {
+	GetAll() ([]model.PromoCode, error)
+	GetByID(id uuid.UUID) (*model.PromoCode, error)
+	Create(promo *model.PromoCode) error
+	SetActive(id uuid.UUID, active bool) error
+}
+
+type PromoCodes struct {
+	repo PromoCodesRepository
+}
+
+func NewPromoCodes(repo PromoCodesRepository) *PromoCodes {
+	return &PromoCodes{repo: repo}
+}
+
+func (s *PromoCodes) GetAllPromoCodes() ([]model.PromoCode, error) {
+	return s.repo.GetAll()
+}
+
+// CreatePromoCode проверяет и сохраняет промокод. Код хранится в верхнем регистре.
+func (s *PromoCodes) CreatePromoCode(promo *model.PromoCode) error {
+	promo.Code = normalizePromoCode(promo.Code)
+	if promo.Code == "" {
+		return errors.New("promo code is required")
+	}
+
+	switch promo.Kind {
+	case model.PromoKindPercent:
+		if promo.Value <= 0 || promo.Value > 100 {
+			return errors.New("percentage discount must be between 1 and 100")
+		}
+	case model.PromoKindFixed:
+		if promo.Value <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown promo code kind %q", promo.Kind)
+	}
+
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidUntil.After(*promo.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	if promo.MaxUses < 0 || promo.MaxUsesPerUser < 0 {
+		return errors.New("usage limits cannot be negative")
+	}
+	if promo.Category != "" && !seatCategories[promo.Category] {
+		return fmt.Errorf("unknown seat category %q", promo.Category)
+	}
+
+	promo.ID = uuid.New()
+	promo.UsedCount = 0
+	promo.Active = true
+	return s.repo.Create(promo)
+}
+
+// DeactivatePromoCode отключает промокод; уже примененные скидки сохраняются
+func (s *PromoCodes) DeactivatePromoCode(id string) error {
+	promoID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid promo code ID format")
+	}
+
+	if _, err := s.repo.GetByID(promoID); err != nil {
+		return errors.New("promo code not found")
+	}
+
+	return s.repo.SetActive(promoID, false)
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// promoDiscount рассчитывает скидку по промокоду для мест бронирования.
+// prices - цены мест seats с учетом надбавок, userUses - сколько раз пользователь уже применял код.
+func promoDiscount(promo *model.PromoCode, performance *model.Performance, seats []model.PerformanceSeat, prices []int, userUses int64, now time.Time) (int, error) {
+	if !promo.Active {
+		return 0, errors.New("promo code is not active")
+	}
+	if promo.ValidFrom != nil && now.Before(*promo.ValidFrom) {
+		return 0, errors.New("promo code is not valid yet")
+	}
+	if promo.ValidUntil != nil && !now.Before(*promo.ValidUntil) {
+		return 0, errors.New("promo code has expired")
+	}
+	if promo.MaxUses > 0 && promo.UsedCount >= promo.MaxUses {
+		return 0, errors.New("promo code usage limit reached")
+	}
+	if promo.MaxUsesPerUser > 0 && userUses >= int64(promo.MaxUsesPerUser) {
+		return 0, errors.New("promo code usage limit per customer reached")
+	}
+	if promo.PlayID != nil && *promo.PlayID != performance.PlayID {
+		return 0, errors.New("promo code is not valid for this play")
+	}
+	if promo.PerformanceID != nil && *promo.PerformanceID != performance.ID {
+		return 0, errors.New("promo code is not valid for this performance")
+	}
+
+	eligible := 0
+	for i, seat := range seats {
+		if promo.Category == "" || seat.Seat.Category == promo.Category {
+			eligible += prices[i]
+		}
+	}
+	if eligible == 0 {
+		return 0, errors.New("promo code does not apply to selected seats")
+	}
+
+	if promo.Kind == model.PromoKindPercent {
+		return (eligible*promo.Value + 50) / 100, nil
+	}
+	return min(promo.Value, eligible), nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPromoCodesRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromoCodesRepository) GetAll() ([]model.PromoCode, error) {
+	args := m.Called()
+	return args.Get(0).([]model.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodesRepository) GetByID(id uuid.UUID) (*model.PromoCode, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodesRepository) Create(promo *model.PromoCode) error {
+	args := m.Called(promo)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodesRepository) SetActive(id uuid.UUID, active bool) error {
+	args := m.Called(id, active)
+	return args.Error(0)
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	t.Run("normalizes code", func(t *testing.T) {
+		repo := new(MockPromoCodesRepository)
+		service := NewPromoCodes(repo)
+
+		promo := &model.PromoCode{Code: "  spring25 ", Kind: model.PromoKindPercent, Value: 25}
+		repo.On("Create", promo).Return(nil)
+
+		err := service.CreatePromoCode(promo)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SPRING25", promo.Code)
+		assert.True(t, promo.Active)
+	})
+
+	t.Run("percentage above 100", func(t *testing.T) {
+		repo := new(MockPromoCodesRepository)
+		service := NewPromoCodes(repo)
+
+		err := service.CreatePromoCode(&model.PromoCode{Code: "ALL", Kind: model.PromoKindPercent, Value: 120})
+
+		assert.EqualError(t, err, "percentage discount must be between 1 and 100")
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestPromoDiscount(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	performance := &model.Performance{ID: uuid.New(), PlayID: uuid.New()}
+	seats := []model.PerformanceSeat{
+		{Seat: model.Seat{Category: "parterre"}},
+		{Seat: model.Seat{Category: "balcony"}},
+	}
+	prices := []int{3000, 1000}
+
+	t.Run("percentage of all seats", func(t *testing.T) {
+		promo := &model.PromoCode{Kind: model.PromoKindPercent, Value: 15, Active: true}
+
+		discount, err := promoDiscount(promo, performance, seats, prices, 0, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 600, discount)
+	})
+
+	t.Run("fixed amount limited to eligible category", func(t *testing.T) {
+		promo := &model.PromoCode{Kind: model.PromoKindFixed, Value: 2500, Category: "balcony", Active: true}
+
+		discount, err := promoDiscount(promo, performance, seats, prices, 0, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, discount)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		until := now.Add(-time.Hour)
+		promo := &model.PromoCode{Kind: model.PromoKindFixed, Value: 100, ValidUntil: &until, Active: true}
+
+		_, err := promoDiscount(promo, performance, seats, prices, 0, now)
+
+		assert.EqualError(t, err, "promo code has expired")
+	})
+
+	t.Run("usage limits", func(t *testing.T) {
+		promo := &model.PromoCode{Kind: model.PromoKindFixed, Value: 100, MaxUses: 10, UsedCount: 10, Active: true}
+		_, err := promoDiscount(promo, performance, seats, prices, 0, now)
+		assert.EqualError(t, err, "promo code usage limit reached")
+
+		promo = &model.PromoCode{Kind: model.PromoKindFixed, Value: 100, MaxUsesPerUser: 1, Active: true}
+		_, err = promoDiscount(promo, performance, seats, prices, 1, now)
+		assert.EqualError(t, err, "promo code usage limit per customer reached")
+	})
+
+	t.Run("restricted to another play", func(t *testing.T) {
+		otherPlay := uuid.New()
+		promo := &model.PromoCode{Kind: model.PromoKindPercent, Value: 10, PlayID: &otherPlay, Active: true}
+
+		_, err := promoDiscount(promo, performance, seats, prices, 0, now)
+
+		assert.EqualError(t, err, "promo code is not valid for this play")
+	})
+}
+
+func TestCreateBookingWithPromoCode(t *testing.T) {
+	t.Run("records subtotal, discount and redemption", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		usersRepo := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		service := NewBookings(bookingsRepo, usersRepo, WithBookingsClock(clock))
+
+		user := &model.User{ID: uuid.New()}
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New()}
+		promo := &model.PromoCode{ID: uuid.New(), Code: "FRIENDS", Kind: model.PromoKindPercent, Value: 20, Active: true}
+
+		usersRepo.On("FindByEmail", "guest@example.com").Return(user, nil)
+		bookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{{ID: seatIDs[0], Price: 2000, Status: "available"}}, nil)
+		bookingsRepo.On("GetPerformance", performanceID).Return(onSalePerformance(performanceID), nil)
+		bookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(0), int64(10), nil)
+		bookingsRepo.On("LockPromoCode", "FRIENDS").Return(promo, nil)
+		bookingsRepo.On("CountPromoRedemptions", promo.ID, user.ID).Return(int64(0), nil)
+		bookingsRepo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Subtotal == 2000 && b.Discount == 400 && b.TotalPrice == 1600 &&
+				b.PromoCodeID != nil && *b.PromoCodeID == promo.ID
+		})).Return(nil)
+		bookingsRepo.On("RedeemPromoCode", mock.MatchedBy(func(r *model.PromoRedemption) bool {
+			return r.PromoCodeID == promo.ID && r.UserID == user.ID && r.Discount == 400
+		})).Return(nil)
+		bookingsRepo.On("UpdatePerformanceSeatStatus", seatIDs[0], "reserved", mock.AnythingOfType("*uuid.UUID")).Return(nil)
+		bookingsRepo.On("SetChargedPrice", seatIDs[0], 2000).Return(nil)
+		bookingsRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&model.Booking{}, nil)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, " friends ", "")
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown code rejects booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		usersRepo := new(MockUsersRepository)
+		service := NewBookings(bookingsRepo, usersRepo)
+
+		performanceID := uuid.New()
+		seatIDs := []uuid.UUID{uuid.New()}
+
+		usersRepo.On("FindByEmail", "guest@example.com").Return(&model.User{ID: uuid.New()}, nil)
+		bookingsRepo.On("GetPerformanceSeatsByIDs", seatIDs, performanceID).
+			Return([]model.PerformanceSeat{{ID: seatIDs[0], Price: 2000, Status: "available"}}, nil)
+		bookingsRepo.On("GetPerformance", performanceID).Return(onSalePerformance(performanceID), nil)
+		bookingsRepo.On("CountOccupiedSeats", performanceID).Return(int64(0), int64(10), nil)
+		bookingsRepo.On("LockPromoCode", "NOPE").Return(nil, assert.AnError)
+
+		_, err := service.CreateBooking(testActor, "guest@example.com", "Guest", performanceID, seatIDs, "nope", "")
+
+		assert.EqualError(t, err, "invalid promo code")
+		bookingsRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestCancelBookingReleasesPromoCode(t *testing.T) {
+	bookingsRepo := new(MockBookingsRepository)
+	service := NewBookings(bookingsRepo, new(MockUsersRepository))
+
+	promoID := uuid.New()
+	booking := &model.Booking{ID: uuid.New(), Status: "pending", PromoCodeID: &promoID}
+
+	bookingsRepo.On("LockByID", booking.ID).Return(booking, nil)
+	bookingsRepo.On("UpdateStatus", booking.ID, "cancelled").Return(nil)
+	bookingsRepo.On("ReleasePromoCode", booking.ID).Return(nil)
+
+	err := service.CancelBooking(testActor, booking.ID.String())
+
+	assert.NoError(t, err)
+	bookingsRepo.AssertExpectations(t)
+}
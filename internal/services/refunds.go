@@ -0,0 +1,384 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBookingNotActive = errors.New("booking is no longer active")
+	ErrSeatNotInBooking = errors.New("seat does not belong to booking")
+	ErrRefundFailed     = errors.New("failed to refund payment")
+	ErrNotBookingOwner  = errors.New("booking belongs to another user")
+)
+
+// canManageBooking сообщает, может ли actor отменять бронирование и смотреть его
+// возвраты: это владелец брони или сотрудник с правом на возвраты
+func canManageBooking(actor model.Actor, booking *model.Booking) bool {
+	if actor.Can(model.PermissionManageRefunds) {
+		return true
+	}
+	return actor.UserID != nil && booking.UserID != nil && *booking.UserID == *actor.UserID
+}
+
+// RefundTier - процент возврата, если до начала показа осталось не меньше Before
+type RefundTier struct {
+	Before  time.Duration
+	Percent int
+}
+
+// RefundPolicy определяет процент возврата по времени, оставшемуся до начала показа.
+// Если подходящей ступени нет, деньги не возвращаются.
+type RefundPolicy struct {
+	tiers []RefundTier
+}
+
+func NewRefundPolicy(tiers []RefundTier) *RefundPolicy {
+	sorted := append([]RefundTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before > sorted[j].Before
+	})
+	return &RefundPolicy{tiers: sorted}
+}
+
+// Percent возвращает процент возврата при отмене в момент now
+func (p *RefundPolicy) Percent(performanceDate, now time.Time) int {
+	left := performanceDate.Sub(now)
+	for _, tier := range p.tiers {
+		if left >= tier.Before {
+			return tier.Percent
+		}
+	}
+	return 0
+}
+
+// RefundOverride - решение сотрудника вернуть другой процент, чем по политике
+type RefundOverride struct {
+	Percent int
+	Reason  string
+	StaffID uuid.UUID
+}
+
+type RefundsRepository interface {
+	GetByBookingID(bookingID uuid.UUID) ([]model.Refund, error)
+}
+
+// Refunds отменяет места бронирований и возвращает деньги за оплаченные места
+type Refunds struct {
+	repo         RefundsRepository
+	bookingsRepo BookingsRepository
+	provider     PaymentProvider
+	policy       *RefundPolicy
+	clock        Clock
+	emails       *EmailService
+	waitlist     *Waitlist
+	audit        *Audit
+}
+
+// NewRefunds создает сервис возвратов. emails, waitlist и audit необязательны.
+func NewRefunds(repo RefundsRepository, bookingsRepo BookingsRepository, provider PaymentProvider, policy *RefundPolicy, clock Clock, emails *EmailService, waitlist *Waitlist, audit *Audit) *Refunds {
+	return &Refunds{
+		repo:         repo,
+		bookingsRepo: bookingsRepo,
+		provider:     provider,
+		policy:       policy,
+		clock:        clock,
+		emails:       emails,
+		waitlist:     waitlist,
+		audit:        audit,
+	}
+}
+
+// CancelSeats отменяет места seatIDs бронирования (все, если seatIDs пуст) и освобождает их.
+// Цена бронирования пересчитывается. За места оплаченного бронирования возвращается
+// процент по политике возврата или, если задан override, процент, выбранный сотрудником;
+// после начала показа отменить места может только сотрудник. Возвращает бронирование
+// после отмены и возврат, если бронь была оплачена.
+func (s *Refunds) CancelSeats(actor model.Actor, bookingID string, seatIDs []uuid.UUID, override *RefundOverride) (*model.Booking, *model.Refund, error) {
+	id, err := uuid.Parse(bookingID)
+	if err != nil {
+		return nil, nil, errors.New("invalid booking ID format")
+	}
+
+	var refund *model.Refund
+	err = s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		booking, err := tx.LockByID(id)
+		if err != nil {
+			return errors.New("booking not found")
+		}
+		if !canManageBooking(actor, booking) {
+			return ErrNotBookingOwner
+		}
+		if booking.Status != "pending" && booking.Status != "confirmed" {
+			return ErrBookingNotActive
+		}
+
+		cancelled, kept, err := splitSeats(booking.PerformanceSeats, seatIDs)
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+		if override == nil && !booking.Performance.Date.After(now) {
+			return ErrPerformanceStarted
+		}
+		before := bookingAudit(booking, booking.PerformanceSeats)
+
+		paid := booking.Status == "confirmed"
+		shares := seatShares(booking)
+		value := 0
+		for _, seat := range cancelled {
+			value += shares[seat.ID]
+		}
+
+		full := len(kept) == 0
+		if full {
+			booking.Status = "cancelled"
+		} else {
+			for _, seat := range cancelled {
+				booking.Subtotal -= seatPrice(seat)
+			}
+			booking.TotalPrice -= value
+			booking.Discount = max(booking.Subtotal-booking.TotalPrice, 0)
+		}
+		if err := tx.Update(booking); err != nil {
+			return err
+		}
+
+		action := "booking.cancel_seats"
+		if full {
+			action = "booking.cancel"
+		}
+		err = recordAudit(s.audit, tx, actor, action, AuditBooking, booking.ID, before, bookingAudit(booking, kept))
+		if err != nil {
+			return err
+		}
+
+		cancelledIDs := make([]uuid.UUID, len(cancelled))
+		for i, seat := range cancelled {
+			cancelledIDs[i] = seat.ID
+			if err := tx.UpdatePerformanceSeatStatus(seat.ID, "available", nil); err != nil {
+				return err
+			}
+		}
+		if full && booking.PromoCodeID != nil {
+			if err := tx.ReleasePromoCode(booking.ID); err != nil {
+				return err
+			}
+		}
+
+		if paid {
+			if err := tx.VoidTickets(booking.ID, cancelledIDs); err != nil {
+				return err
+			}
+			refund = s.newRefund(booking, cancelled, shares, value, override, now)
+
+			data := bookingEmailData(booking, &booking.User, &booking.Performance, cancelled)
+			data.Refund = refund.Amount
+			if full {
+				data.Total = 0
+			}
+			if err := enqueueEmail(s.emails, tx, notifications.TemplateRefundIssued, booking.User.Email, data); err != nil {
+				return err
+			}
+		} else if full {
+			err := enqueueEmail(s.emails, tx, notifications.TemplateBookingCancelled, booking.User.Email,
+				bookingEmailData(booking, &booking.User, &booking.Performance, cancelled))
+			if err != nil {
+				return err
+			}
+		}
+
+		// Освободившиеся места в той же транзакции предлагаются листу ожидания
+		if full {
+			err = s.waitlist.SeatsReleased(tx, []model.Booking{*booking})
+		} else {
+			err = s.waitlist.SeatsFreed(tx, []uuid.UUID{booking.PerformanceID})
+		}
+		if err != nil {
+			return err
+		}
+
+		if refund == nil {
+			return nil
+		}
+		// Деньги возвращаются последним шагом: если платежная система откажет,
+		// транзакция откатится и места останутся за покупателем
+		return s.issue(tx, refund)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	booking, err := s.bookingsRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return booking, refund, nil
+}
+
+// GetRefunds возвращает возвраты бронирования
+func (s *Refunds) GetRefunds(actor model.Actor, bookingID string) ([]model.Refund, error) {
+	id, err := uuid.Parse(bookingID)
+	if err != nil {
+		return nil, errors.New("invalid booking ID format")
+	}
+
+	booking, err := s.bookingsRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if !canManageBooking(actor, booking) {
+		return nil, ErrNotBookingOwner
+	}
+	return s.repo.GetByBookingID(id)
+}
+
+// newRefund рассчитывает возврат за места cancelled стоимостью value
+func (s *Refunds) newRefund(booking *model.Booking, cancelled []model.PerformanceSeat, shares map[uuid.UUID]int, value int, override *RefundOverride, now time.Time) *model.Refund {
+	refund := &model.Refund{
+		ID:            uuid.New(),
+		BookingID:     booking.ID,
+		SeatsValue:    value,
+		PolicyPercent: s.policy.Percent(booking.Performance.Date, now),
+		CreatedAt:     now,
+		Items:         make([]model.RefundItem, len(cancelled)),
+	}
+	refund.Percent = refund.PolicyPercent
+	if override != nil {
+		refund.Percent = override.Percent
+		refund.OverriddenBy = &override.StaffID
+		refund.Reason = override.Reason
+	}
+	refund.Amount = value * refund.Percent / 100
+
+	for i, seat := range cancelled {
+		refund.Items[i] = model.RefundItem{
+			RefundID:          refund.ID,
+			PerformanceSeatID: seat.ID,
+			Paid:              shares[seat.ID],
+		}
+	}
+	return refund
+}
+
+// cancellationRefund рассчитывает возврат полной стоимости бронирования отмененного показа
+func cancellationRefund(booking *model.Booking, now time.Time) *model.Refund {
+	shares := seatShares(booking)
+	refund := &model.Refund{
+		ID:            uuid.New(),
+		BookingID:     booking.ID,
+		Amount:        booking.TotalPrice,
+		SeatsValue:    booking.TotalPrice,
+		Percent:       100,
+		PolicyPercent: 100,
+		Reason:        "performance cancelled",
+		CreatedAt:     now,
+		Items:         make([]model.RefundItem, len(booking.PerformanceSeats)),
+	}
+	for i, seat := range booking.PerformanceSeats {
+		refund.Items[i] = model.RefundItem{
+			RefundID:          refund.ID,
+			PerformanceSeatID: seat.ID,
+			Paid:              shares[seat.ID],
+		}
+	}
+	return refund
+}
+
+// issue возвращает деньги через платежную систему и сохраняет возврат
+func (s *Refunds) issue(tx refundTx, refund *model.Refund) error {
+	if refund.Amount > 0 {
+		payment, err := tx.LockCapturedPayment(refund.BookingID)
+		if err != nil {
+			return errors.New("payment not found")
+		}
+		refund.Amount = min(refund.Amount, payment.Amount-payment.Refunded)
+		refund.PaymentID = &payment.ID
+
+		// Оплату в кассе касса и возвращает: наличными или на карту через терминал
+		if !paidAtBoxOffice(payment) {
+			result, err := s.provider.Refund(payment.IntentID, refund.Amount)
+			if err != nil {
+				log.Println("Refund failed:", err)
+				return ErrRefundFailed
+			}
+			refund.ProviderRefundID = result.ID
+		}
+
+		payment.Refunded += refund.Amount
+		if payment.Refunded >= payment.Amount {
+			payment.Status = "refunded"
+		}
+		if err := tx.UpdatePayment(payment); err != nil {
+			log.Printf("Failed to record refund %s of payment %s: %v", refund.ProviderRefundID, payment.ID, err)
+			return err
+		}
+	}
+
+	if err := tx.CreateRefund(refund); err != nil {
+		log.Printf("Failed to record refund %s: %v", refund.ProviderRefundID, err)
+		return err
+	}
+	return nil
+}
+
+// splitSeats делит места бронирования на отменяемые seatIDs (все, если seatIDs пуст) и остающиеся
+func splitSeats(seats []model.PerformanceSeat, seatIDs []uuid.UUID) (cancelled, kept []model.PerformanceSeat, err error) {
+	if len(seatIDs) == 0 {
+		return seats, nil, nil
+	}
+
+	selected := make(map[uuid.UUID]bool, len(seatIDs))
+	for _, id := range seatIDs {
+		selected[id] = true
+	}
+	for _, seat := range seats {
+		if selected[seat.ID] {
+			cancelled = append(cancelled, seat)
+			delete(selected, seat.ID)
+		} else {
+			kept = append(kept, seat)
+		}
+	}
+	if len(selected) > 0 {
+		return nil, nil, ErrSeatNotInBooking
+	}
+	return cancelled, kept, nil
+}
+
+// seatShares распределяет итоговую цену бронирования по местам пропорционально
+// их цене, чтобы скидка уменьшала сумму возврата за каждое место. Доли в сумме
+// дают ровно TotalPrice.
+func seatShares(booking *model.Booking) map[uuid.UUID]int {
+	subtotal := 0
+	for _, seat := range booking.PerformanceSeats {
+		subtotal += seatPrice(seat)
+	}
+
+	shares := make(map[uuid.UUID]int, len(booking.PerformanceSeats))
+	left := booking.TotalPrice
+	for i, seat := range booking.PerformanceSeats {
+		share := left
+		if i < len(booking.PerformanceSeats)-1 && subtotal > 0 {
+			share = booking.TotalPrice * seatPrice(seat) / subtotal
+		}
+		shares[seat.ID] = share
+		left -= share
+	}
+	return shares
+}
+
+// seatPrice возвращает цену, по которой место забронировано
+func seatPrice(seat model.PerformanceSeat) int {
+	if seat.ChargedPrice != nil {
+		return *seat.ChargedPrice
+	}
+	return seat.Price
+}
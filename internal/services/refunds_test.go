@@ -0,0 +1,275 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/payments"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testRefundPolicy = NewRefundPolicy([]RefundTier{
+	{Before: 24 * time.Hour, Percent: 50},
+	{Before: 7 * 24 * time.Hour, Percent: 100},
+})
+
+func TestRefundPolicy(t *testing.T) {
+	date := time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 100, testRefundPolicy.Percent(date, date.Add(-8*24*time.Hour)))
+	assert.Equal(t, 100, testRefundPolicy.Percent(date, date.Add(-7*24*time.Hour)))
+	assert.Equal(t, 50, testRefundPolicy.Percent(date, date.Add(-2*24*time.Hour)))
+	assert.Equal(t, 0, testRefundPolicy.Percent(date, date.Add(-time.Hour)))
+	assert.Equal(t, 0, NewRefundPolicy(nil).Percent(date, date.Add(-30*24*time.Hour)))
+}
+
+func TestSeatShares(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	price := func(p int) *int { return &p }
+	booking := &model.Booking{
+		Subtotal:   3000,
+		TotalPrice: 2000, // скидка 1000
+		PerformanceSeats: []model.PerformanceSeat{
+			{ID: a, Price: 1000, ChargedPrice: price(1000)},
+			{ID: b, Price: 1000, ChargedPrice: price(1000)},
+			{ID: c, Price: 1000, ChargedPrice: price(1000)},
+		},
+	}
+
+	shares := seatShares(booking)
+
+	assert.Equal(t, 666, shares[a])
+	assert.Equal(t, 666, shares[b])
+	assert.Equal(t, 668, shares[c])
+}
+
+type MockRefundsRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundsRepository) GetByBookingID(bookingID uuid.UUID) ([]model.Refund, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).([]model.Refund), args.Error(1)
+}
+
+// capturedBooking создает оплаченное через provider бронирование двух мест по 2000
+// со скидкой 1000 на показ через двое суток после now
+func capturedBooking(t *testing.T, provider *payments.Fake, now time.Time) (*model.Booking, *model.Payment, []uuid.UUID) {
+	intent, err := provider.CreateIntent(uuid.New(), 3000, "BYN")
+	require.NoError(t, err)
+	_, err = provider.Capture(intent.ID, "tok_visa")
+	require.NoError(t, err)
+
+	price := 2000
+	seats := []uuid.UUID{uuid.New(), uuid.New()}
+	booking := &model.Booking{
+		ID:            uuid.New(),
+		PerformanceID: uuid.New(),
+		Status:        "confirmed",
+		Subtotal:      4000,
+		Discount:      1000,
+		TotalPrice:    3000,
+		User:          model.User{Email: "anna@example.com"},
+		Performance:   model.Performance{Date: now.Add(48 * time.Hour)},
+		PerformanceSeats: []model.PerformanceSeat{
+			{ID: seats[0], Price: price, ChargedPrice: &price, Status: "sold"},
+			{ID: seats[1], Price: price, ChargedPrice: &price, Status: "sold"},
+		},
+	}
+	payment := &model.Payment{ID: uuid.New(), BookingID: booking.ID, IntentID: intent.ID, Amount: 3000, Status: "captured"}
+	return booking, payment, seats
+}
+
+func TestCancelSeats(t *testing.T) {
+	t.Run("partial cancellation refunds by policy", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, payment, seats := capturedBooking(t, provider, clock.now)
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+		repo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "confirmed" && b.Subtotal == 2000 && b.TotalPrice == 1500 && b.Discount == 500
+		})).Return(nil)
+		repo.On("UpdatePerformanceSeatStatus", seats[0], "available", (*uuid.UUID)(nil)).Return(nil)
+		repo.On("VoidTickets", booking.ID, []uuid.UUID{seats[0]}).Return(nil)
+		repo.On("LockCapturedPayment", booking.ID).Return(payment, nil)
+		repo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Refunded == 750 && p.Status == "captured"
+		})).Return(nil)
+		repo.On("CreateRefund", mock.MatchedBy(func(r *model.Refund) bool {
+			return r.SeatsValue == 1500 && r.Amount == 750 && r.Percent == 50 && r.PolicyPercent == 50 &&
+				r.OverriddenBy == nil && len(r.Items) == 1 && r.ProviderRefundID != ""
+		})).Return(nil)
+		repo.On("GetByID", booking.ID).Return(booking, nil)
+
+		_, refund, err := service.CancelSeats(testActor, booking.ID.String(), []uuid.UUID{seats[0]}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 750, refund.Amount)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("staff override after the performance started", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, payment, seats := capturedBooking(t, provider, clock.now)
+		clock.now = booking.Performance.Date.Add(time.Hour)
+		staffID := uuid.New()
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+		repo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "cancelled"
+		})).Return(nil)
+		repo.On("UpdatePerformanceSeatStatus", mock.Anything, "available", (*uuid.UUID)(nil)).Return(nil)
+		repo.On("VoidTickets", booking.ID, seats).Return(nil)
+		repo.On("LockCapturedPayment", booking.ID).Return(payment, nil)
+		repo.On("UpdatePayment", mock.MatchedBy(func(p *model.Payment) bool {
+			return p.Refunded == 3000 && p.Status == "refunded"
+		})).Return(nil)
+		repo.On("CreateRefund", mock.MatchedBy(func(r *model.Refund) bool {
+			return r.Amount == 3000 && r.Percent == 100 && r.PolicyPercent == 0 &&
+				r.OverriddenBy != nil && *r.OverriddenBy == staffID && r.Reason == "спектакль сорван"
+		})).Return(nil)
+		repo.On("GetByID", booking.ID).Return(booking, nil)
+
+		_, refund, err := service.CancelSeats(testActor, booking.ID.String(), nil,
+			&RefundOverride{Percent: 100, Reason: "спектакль сорван", StaffID: staffID})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3000, refund.Amount)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("customer cannot cancel after the performance started", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, _, _ := capturedBooking(t, provider, clock.now)
+		clock.now = booking.Performance.Date.Add(time.Minute)
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+
+		_, _, err := service.CancelSeats(testActor, booking.ID.String(), nil, nil)
+
+		assert.ErrorIs(t, err, ErrPerformanceStarted)
+		repo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("another customer cannot cancel", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, _, _ := capturedBooking(t, provider, clock.now)
+		ownerID, customerID := uuid.New(), uuid.New()
+		booking.UserID = &ownerID
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+
+		_, _, err := service.CancelSeats(model.Actor{UserID: &customerID, Role: model.RoleCustomer},
+			booking.ID.String(), nil, nil)
+
+		assert.ErrorIs(t, err, ErrNotBookingOwner)
+		repo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("seat from another booking", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, _, _ := capturedBooking(t, provider, clock.now)
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+
+		_, _, err := service.CancelSeats(testActor, booking.ID.String(), []uuid.UUID{uuid.New()}, nil)
+
+		assert.ErrorIs(t, err, ErrSeatNotInBooking)
+	})
+
+	t.Run("provider failure keeps the seats", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, payment, seats := capturedBooking(t, provider, clock.now)
+		payment.IntentID = "pi_unknown"
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+		repo.On("Update", mock.Anything).Return(nil)
+		repo.On("UpdatePerformanceSeatStatus", mock.Anything, "available", (*uuid.UUID)(nil)).Return(nil)
+		repo.On("VoidTickets", booking.ID, mock.Anything).Return(nil)
+		repo.On("LockCapturedPayment", booking.ID).Return(payment, nil)
+
+		_, _, err := service.CancelSeats(testActor, booking.ID.String(), []uuid.UUID{seats[1]}, nil)
+
+		assert.ErrorIs(t, err, ErrRefundFailed)
+		repo.AssertNotCalled(t, "CreateRefund", mock.Anything)
+	})
+
+	t.Run("pending booking is cancelled without refund", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+		repo := new(MockBookingsRepository)
+		provider := payments.NewFake("secret")
+		service := NewRefunds(nil, repo, provider, testRefundPolicy, clock, nil, nil, nil)
+		booking, _, seats := capturedBooking(t, provider, clock.now)
+		booking.Status = "pending"
+
+		repo.On("LockByID", booking.ID).Return(booking, nil)
+		repo.On("Update", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.Status == "pending" && b.TotalPrice == 1500
+		})).Return(nil)
+		repo.On("UpdatePerformanceSeatStatus", seats[1], "available", (*uuid.UUID)(nil)).Return(nil)
+		repo.On("GetByID", booking.ID).Return(booking, nil)
+
+		_, refund, err := service.CancelSeats(testActor, booking.ID.String(), []uuid.UUID{seats[1]}, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, refund)
+		repo.AssertNotCalled(t, "VoidTickets", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "LockCapturedPayment", mock.Anything)
+	})
+}
+
+func TestGetRefunds(t *testing.T) {
+	ownerID := uuid.New()
+	bookingID := uuid.New()
+	booking := &model.Booking{ID: bookingID, UserID: &ownerID}
+
+	t.Run("owner sees refunds", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		refundsRepo := new(MockRefundsRepository)
+		service := NewRefunds(refundsRepo, bookingsRepo, payments.NewFake("secret"), testRefundPolicy, SystemClock, nil, nil, nil)
+
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+		refundsRepo.On("GetByBookingID", bookingID).Return([]model.Refund{{BookingID: bookingID, Amount: 1500}}, nil)
+
+		refunds, err := service.GetRefunds(model.Actor{UserID: &ownerID, Role: model.RoleCustomer}, bookingID.String())
+
+		require.NoError(t, err)
+		assert.Len(t, refunds, 1)
+	})
+
+	t.Run("another customer is rejected", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		refundsRepo := new(MockRefundsRepository)
+		service := NewRefunds(refundsRepo, bookingsRepo, payments.NewFake("secret"), testRefundPolicy, SystemClock, nil, nil, nil)
+		customerID := uuid.New()
+
+		bookingsRepo.On("GetByID", bookingID).Return(booking, nil)
+
+		_, err := service.GetRefunds(model.Actor{UserID: &customerID, Role: model.RoleCustomer}, bookingID.String())
+
+		assert.ErrorIs(t, err, ErrNotBookingOwner)
+		refundsRepo.AssertNotCalled(t, "GetByBookingID", mock.Anything)
+	})
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderBookingsRepository interface {
+	GetForReminder(performanceID uuid.UUID, offset time.Duration) ([]model.Booking, error)
+	Transaction(fn func(tx BookingsTx) error) error
+}
+
+type ReminderPerformancesRepository interface {
+	GetStartingBetween(from, to time.Time) ([]model.Performance, error)
+}
+
+// Reminders - фоновый процесс, напоминающий покупателям о предстоящих показах
+type Reminders struct {
+	bookingsRepo     ReminderBookingsRepository
+	performancesRepo ReminderPerformancesRepository
+	emails           *EmailService
+	clock            Clock
+	offsets          []time.Duration // по возрастанию
+	interval         time.Duration
+}
+
+// NewReminders создает планировщик напоминаний. offsets - за сколько до начала
+// показа отправляется каждое напоминание.
+func NewReminders(bookingsRepo ReminderBookingsRepository, performancesRepo ReminderPerformancesRepository, emails *EmailService, clock Clock, offsets []time.Duration, interval time.Duration) *Reminders {
+	sorted := make([]time.Duration, len(offsets))
+	copy(sorted, offsets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &Reminders{
+		bookingsRepo:     bookingsRepo,
+		performancesRepo: performancesRepo,
+		emails:           emails,
+		clock:            clock,
+		offsets:          sorted,
+		interval:         interval,
+	}
+}
+
+// dueOffset возвращает ближайшее к началу показа напоминание, время которого уже
+// наступило. Более ранние пропущенные напоминания не отправляются.
+func (s *Reminders) dueOffset(performance model.Performance, now time.Time) (time.Duration, bool) {
+	for _, offset := range s.offsets {
+		if !now.Before(performance.Date.Add(-offset)) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// SendDue ставит в очередь все наступившие напоминания. Возвращает их число.
+func (s *Reminders) SendDue() (int, error) {
+	if len(s.offsets) == 0 {
+		return 0, nil
+	}
+
+	now := s.clock.Now()
+	performances, err := s.performancesRepo.GetStartingBetween(now, now.Add(s.offsets[len(s.offsets)-1]))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range performances {
+		performance := &performances[i]
+		offset, ok := s.dueOffset(*performance, now)
+		if !ok {
+			continue
+		}
+
+		bookings, err := s.bookingsRepo.GetForReminder(performance.ID, offset)
+		if err != nil {
+			return sent, err
+		}
+
+		for j := range bookings {
+			booking := &bookings[j]
+			// Купившему билеты позже времени напоминания оно не нужно
+			if booking.CreatedAt.After(performance.Date.Add(-offset)) {
+				continue
+			}
+
+			ok, err := s.remind(booking, performance, offset, now)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// remind отмечает напоминание и ставит письмо в outbox в одной транзакции, поэтому
+// напоминание не уходит дважды ни после перезапуска, ни с нескольких экземпляров
+func (s *Reminders) remind(booking *model.Booking, performance *model.Performance, offset time.Duration, now time.Time) (bool, error) {
+	recorded := false
+	err := s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		var err error
+		recorded, err = tx.RecordReminder(booking.ID, offset, now)
+		if err != nil || !recorded {
+			return err
+		}
+
+		return enqueueEmail(s.emails, tx, notifications.TemplateReminder, booking.User.Email,
+			bookingEmailData(booking, &booking.User, performance, booking.PerformanceSeats))
+	})
+	return recorded, err
+}
+
+// Run запускает периодическую проверку до отмены контекста
+func (s *Reminders) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.SendDue()
+			if err != nil {
+				log.Println("Sending reminders failed:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Queued %d performance reminders", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReminderPerformancesRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderPerformancesRepository) GetStartingBetween(from, to time.Time) ([]model.Performance, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]model.Performance), args.Error(1)
+}
+
+// testReminderOffsets - напоминания за сутки и за два часа до начала
+var testReminderOffsets = []time.Duration{2 * time.Hour, 24 * time.Hour}
+
+func TestSendDueReminders(t *testing.T) {
+	t.Run("sends the closest due reminder", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		performancesRepo := new(MockReminderPerformancesRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		emails := newTestEmailService(t, clock)
+		service := NewReminders(bookingsRepo, performancesRepo, emails, clock, testReminderOffsets, time.Minute)
+
+		// Показ через полтора часа: напоминание за 24 часа уже неактуально
+		performance := model.Performance{ID: uuid.New(), Date: clock.now.Add(90 * time.Minute), Play: &model.Play{Title: "Ревизор"}}
+		booking := model.Booking{
+			ID:        uuid.New(),
+			Status:    "confirmed",
+			CreatedAt: clock.now.AddDate(0, 0, -3),
+			User:      model.User{Email: "anna@example.com"},
+		}
+
+		performancesRepo.On("GetStartingBetween", clock.now, clock.now.Add(24*time.Hour)).Return([]model.Performance{performance}, nil)
+		bookingsRepo.On("GetForReminder", performance.ID, 2*time.Hour).Return([]model.Booking{booking}, nil)
+		bookingsRepo.On("RecordReminder", booking.ID, 2*time.Hour, clock.now).Return(true, nil)
+		bookingsRepo.On("EnqueueEmail", mock.MatchedBy(func(m *model.OutboxMessage) bool {
+			return m.Template == notifications.TemplateReminder && m.Recipient == "anna@example.com"
+		})).Return(nil)
+
+		sent, err := service.SendDue()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		bookingsRepo.AssertExpectations(t)
+	})
+
+	t.Run("skips reminder already recorded by another instance", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		performancesRepo := new(MockReminderPerformancesRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		emails := newTestEmailService(t, clock)
+		service := NewReminders(bookingsRepo, performancesRepo, emails, clock, testReminderOffsets, time.Minute)
+
+		performance := model.Performance{ID: uuid.New(), Date: clock.now.Add(20 * time.Hour)}
+		booking := model.Booking{ID: uuid.New(), Status: "confirmed", CreatedAt: clock.now.AddDate(0, 0, -3)}
+
+		performancesRepo.On("GetStartingBetween", clock.now, clock.now.Add(24*time.Hour)).Return([]model.Performance{performance}, nil)
+		bookingsRepo.On("GetForReminder", performance.ID, 24*time.Hour).Return([]model.Booking{booking}, nil)
+		bookingsRepo.On("RecordReminder", booking.ID, 24*time.Hour, clock.now).Return(false, nil)
+
+		sent, err := service.SendDue()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		bookingsRepo.AssertNotCalled(t, "EnqueueEmail", mock.Anything)
+	})
+
+	t.Run("skips bookings made after the reminder time", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		performancesRepo := new(MockReminderPerformancesRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		emails := newTestEmailService(t, clock)
+		service := NewReminders(bookingsRepo, performancesRepo, emails, clock, testReminderOffsets, time.Minute)
+
+		performance := model.Performance{ID: uuid.New(), Date: clock.now.Add(20 * time.Hour)}
+		booking := model.Booking{ID: uuid.New(), Status: "confirmed", CreatedAt: clock.now.Add(-time.Hour)}
+
+		performancesRepo.On("GetStartingBetween", clock.now, clock.now.Add(24*time.Hour)).Return([]model.Performance{performance}, nil)
+		bookingsRepo.On("GetForReminder", performance.ID, 24*time.Hour).Return([]model.Booking{booking}, nil)
+
+		sent, err := service.SendDue()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		bookingsRepo.AssertNotCalled(t, "RecordReminder", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetReminders(t *testing.T) {
+	t.Run("opts out", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewBookings(bookingsRepo, new(MockUsersRepository))
+
+		booking := &model.Booking{ID: uuid.New(), Status: "confirmed", RemindersEnabled: true}
+		bookingsRepo.On("LockByID", booking.ID).Return(booking, nil)
+		bookingsRepo.On("UpdateReminders", booking.ID, false).Return(nil)
+
+		result, err := service.SetReminders(testActor, booking.ID.String(), false)
+
+		assert.NoError(t, err)
+		assert.False(t, result.RemindersEnabled)
+		bookingsRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("another user's booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewBookings(bookingsRepo, new(MockUsersRepository))
+
+		ownerID, otherID := uuid.New(), uuid.New()
+		booking := &model.Booking{ID: uuid.New(), UserID: &ownerID, Status: "confirmed", RemindersEnabled: true}
+		bookingsRepo.On("LockByID", booking.ID).Return(booking, nil)
+
+		_, err := service.SetReminders(model.Actor{UserID: &otherID, Role: model.RoleCustomer}, booking.ID.String(), false)
+
+		assert.ErrorIs(t, err, ErrNotBookingOwner)
+		bookingsRepo.AssertNotCalled(t, "UpdateReminders", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancelled booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewBookings(bookingsRepo, new(MockUsersRepository))
+
+		booking := &model.Booking{ID: uuid.New(), Status: "cancelled"}
+		bookingsRepo.On("LockByID", booking.ID).Return(booking, nil)
+
+		_, err := service.SetReminders(testActor, booking.ID.String(), false)
+
+		assert.EqualError(t, err, "booking is no longer active")
+	})
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPerformanceDateInPast  = errors.New("performance date must be in the future")
+	ErrPerformanceHasBookings = errors.New("performance has bookings, postpone it to change the date")
+)
+
+type ScheduleRepository interface {
+	GetByID(id uuid.UUID) (*model.Performance, error)
+	Transaction(fn func(tx PerformancesTx) error) error
+}
+
+// PerformancesTx - операции с расписанием, доступные внутри транзакции
+type PerformancesTx interface {
+	EmailEnqueuer
+	AuditRecorder
+	LockHall(hallID uuid.UUID) error
+	LockByID(id uuid.UUID) (*model.Performance, error)
+	HasActiveBookings(performanceID uuid.UUID) (bool, error)
+	FindOverlapping(hallID uuid.UUID, start, end time.Time, turnover time.Duration, excludeID uuid.UUID) ([]model.Performance, error)
+	Create(performance *model.Performance) error
+	CreateSeats(seats []model.PerformanceSeat) error
+	Update(performance *model.Performance) error
+	ReleaseHolds(performanceID uuid.UUID) error
+}
+
+// Schedule - управление расписанием показов
+type Schedule struct {
+	repo      ScheduleRepository
+	playsRepo PlaysRepository
+	seatsRepo SeatsRepository
+	plansRepo PricePlansRepository
+	pricing   PricingRule
+	turnover  time.Duration
+	clock     Clock
+	audit     *Audit
+}
+
+// NewSchedule создает сервис расписания. audit необязателен: без него изменения
+// расписания не попадают в журнал аудита.
+func NewSchedule(repo ScheduleRepository, playsRepo PlaysRepository, seatsRepo SeatsRepository, plansRepo PricePlansRepository, pricing PricingRule, turnover time.Duration, clock Clock, audit *Audit) *Schedule {
+	return &Schedule{
+		repo:      repo,
+		playsRepo: playsRepo,
+		seatsRepo: seatsRepo,
+		plansRepo: plansRepo,
+		pricing:   pricing,
+		turnover:  turnover,
+		clock:     clock,
+		audit:     audit,
+	}
+}
+
+// CreatePerformance назначает показ и создает места на него по схеме зала.
+// Базовые цены мест берутся из тарифного плана, если он указан. С onSale
+// продажа билетов открывается сразу, иначе показ остается запланированным.
+func (s *Schedule) CreatePerformance(actor model.Actor, playID, hallID uuid.UUID, date time.Time, pricePlanID *uuid.UUID, premiere, onSale bool) (*model.Performance, error) {
+	performance, seats, err := s.newPerformance(playID, hallID, date, pricePlanID, premiere, onSale)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.Transaction(func(tx PerformancesTx) error {
+		return s.insertPerformance(tx, actor, performance, seats)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return performance, nil
+}
+
+// newPerformance подготавливает показ спектакля playID в зале hallID вместе с его
+// местами, цены которых считаются по тарифу pricePlanID или тарифу по умолчанию
+func (s *Schedule) newPerformance(playID, hallID uuid.UUID, date time.Time, pricePlanID *uuid.UUID, premiere, onSale bool) (*model.Performance, []model.PerformanceSeat, error) {
+	play, err := s.playsRepo.GetByID(playID)
+	if err != nil {
+		return nil, nil, errors.New("play not found")
+	}
+
+	pricing := s.pricing
+	if pricePlanID != nil {
+		plan, err := s.plansRepo.GetByID(*pricePlanID)
+		if err != nil {
+			return nil, nil, errors.New("price plan not found")
+		}
+		pricing = plan
+	}
+
+	hallSeats, err := s.seatsRepo.GetByHallID(hallID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(hallSeats) == 0 {
+		return nil, nil, errors.New("hall has no seats")
+	}
+
+	status := PerformanceScheduled
+	if onSale {
+		status = PerformanceOnSale
+	}
+	performance := &model.Performance{
+		ID:     uuid.New(),
+		PlayID: play.ID,
+		HallID: hallID,
+		Date:   date,
+		Status: status,
+
+		PricePlanID: pricePlanID,
+		Premiere:    premiere,
+	}
+
+	seats := make([]model.PerformanceSeat, len(hallSeats))
+	for i, seat := range hallSeats {
+		seats[i] = model.PerformanceSeat{
+			ID:            uuid.New(),
+			PerformanceID: performance.ID,
+			SeatID:        seat.ID,
+			Price:         pricing.Price(seat),
+			Status:        "available",
+		}
+	}
+	performance.Play = play
+	return performance, seats, nil
+}
+
+// insertPerformance сохраняет в транзакции tx показ, подготовленный newPerformance
+func (s *Schedule) insertPerformance(tx PerformancesTx, actor model.Actor, performance *model.Performance, seats []model.PerformanceSeat) error {
+	if err := s.checkOverlap(tx, performance, performance.Play); err != nil {
+		return err
+	}
+	if err := tx.Create(performance); err != nil {
+		return err
+	}
+	if err := tx.CreateSeats(seats); err != nil {
+		return err
+	}
+	return recordAudit(s.audit, tx, actor, "performance.create", AuditPerformance, performance.ID,
+		nil, performanceAudit(performance))
+}
+
+// UpdatePerformance переносит показ или меняет спектакль. Зал не меняется,
+// так как места показа привязаны к его схеме. Дату показа с бронированиями
+// менять нельзя: такой показ переносится через Disruptions.Postpone, который
+// переносит и бронирования.
+func (s *Schedule) UpdatePerformance(actor model.Actor, id string, playID uuid.UUID, date time.Time) (*model.Performance, error) {
+	performanceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+
+	var performance *model.Performance
+	var play *model.Play
+	err = s.repo.Transaction(func(tx PerformancesTx) error {
+		performance, err = tx.LockByID(performanceID)
+		if err != nil {
+			return errors.New("performance not found")
+		}
+		if performance.Status == PerformanceCancelled {
+			return errors.New("performance is cancelled")
+		}
+		if performance.Status == PerformanceCompleted {
+			return errors.New("performance is completed")
+		}
+		if !date.After(s.clock.Now()) {
+			return ErrPerformanceDateInPast
+		}
+		if !date.Equal(performance.Date) {
+			active, err := tx.HasActiveBookings(performance.ID)
+			if err != nil {
+				return err
+			}
+			if active {
+				return ErrPerformanceHasBookings
+			}
+		}
+
+		play, err = s.playsRepo.GetByID(playID)
+		if err != nil {
+			return errors.New("play not found")
+		}
+
+		before := performanceAudit(performance)
+		performance.PlayID = play.ID
+		performance.Date = date
+
+		if err := s.checkOverlap(tx, performance, play); err != nil {
+			return err
+		}
+		if err := tx.Update(performance); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "performance.update", AuditPerformance, performance.ID,
+			before, performanceAudit(performance))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	performance.Play = play
+	return performance, nil
+}
+
+// SetStatus переводит показ в статус status по правилам жизненного цикла: открывает
+// или закрывает продажу, возвращает показ в запланированные или переносит его.
+// Отмена и перенос показа с бронированиями выполняются сервисом Disruptions,
+// а завершение - фоновым процессом PerformanceLifecycle.
+func (s *Schedule) SetStatus(actor model.Actor, id, status string) (*model.Performance, error) {
+	performanceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+
+	switch status {
+	case PerformanceScheduled, PerformanceOnSale, PerformanceSalesClosed:
+	default:
+		return nil, fmt.Errorf("%w: status %q cannot be set directly", ErrInvalidTransition, status)
+	}
+
+	performance, err := s.repo.GetByID(performanceID)
+	if err != nil {
+		return nil, errors.New("performance not found")
+	}
+	if !canTransition(performance.Status, status) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, performance.Status, status)
+	}
+	if status == PerformanceOnSale && !performance.Date.After(s.clock.Now()) {
+		return nil, ErrPerformanceStarted
+	}
+
+	before := performanceAudit(performance)
+	performance.Status = status
+	err = s.repo.Transaction(func(tx PerformancesTx) error {
+		if err := tx.Update(performance); err != nil {
+			return err
+		}
+		return recordAudit(s.audit, tx, actor, "performance.status", AuditPerformance, performance.ID,
+			before, performanceAudit(performance))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return performance, nil
+}
+
+func (s *Schedule) checkOverlap(tx PerformancesTx, performance *model.Performance, play *model.Play) error {
+	if err := tx.LockHall(performance.HallID); err != nil {
+		return err
+	}
+
+	end := performance.Date.Add(time.Duration(play.Duration)*time.Minute + s.turnover)
+	overlapping, err := tx.FindOverlapping(performance.HallID, performance.Date, end, s.turnover, performance.ID)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return fmt.Errorf("hall is busy: overlaps with performance at %s", overlapping[0].Date.Format(time.RFC3339))
+	}
+	return nil
+}
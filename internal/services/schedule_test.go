@@ -0,0 +1,404 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduleRepository struct {
+	mock.Mock
+	tx *MockPerformancesTx
+}
+
+func (m *MockScheduleRepository) GetByID(id uuid.UUID) (*model.Performance, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Performance), args.Error(1)
+}
+
+func (m *MockScheduleRepository) GetSeats(performanceID uuid.UUID) ([]model.PerformanceSeat, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).([]model.PerformanceSeat), args.Error(1)
+}
+
+func (m *MockScheduleRepository) Transaction(fn func(tx PerformancesTx) error) error {
+	return fn(m.tx)
+}
+
+type MockPerformancesTx struct {
+	mock.Mock
+}
+
+func (m *MockPerformancesTx) LockHall(hallID uuid.UUID) error {
+	args := m.Called(hallID)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) LockByID(id uuid.UUID) (*model.Performance, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Performance), args.Error(1)
+}
+
+func (m *MockPerformancesTx) HasActiveBookings(performanceID uuid.UUID) (bool, error) {
+	args := m.Called(performanceID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPerformancesTx) FindOverlapping(hallID uuid.UUID, start, end time.Time, turnover time.Duration, excludeID uuid.UUID) ([]model.Performance, error) {
+	args := m.Called(hallID, start, end, turnover, excludeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Performance), args.Error(1)
+}
+
+func (m *MockPerformancesTx) Create(performance *model.Performance) error {
+	args := m.Called(performance)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) CreateSeats(seats []model.PerformanceSeat) error {
+	args := m.Called(seats)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) Update(performance *model.Performance) error {
+	args := m.Called(performance)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) ReleaseHolds(performanceID uuid.UUID) error {
+	args := m.Called(performanceID)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) EnqueueEmail(message *model.OutboxMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockPerformancesTx) RecordAudit(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+// scheduleClock - текущее время в тестах расписания
+var scheduleClock = &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+func TestCreatePerformance(t *testing.T) {
+	t.Run("generates priced seats", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		play := &model.Play{ID: uuid.New(), Duration: 120}
+		hallID := uuid.New()
+		date := time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC)
+		hallSeats := []model.Seat{
+			{ID: uuid.New(), HallID: hallID, Row: 1, Number: 1, Category: "parterre"},
+			{ID: uuid.New(), HallID: hallID, Row: 6, Number: 1, Category: "parterre"},
+			{ID: uuid.New(), HallID: hallID, Row: 8, Number: 1, Category: "balcony"},
+		}
+
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		seatsRepo.On("GetByHallID", hallID).Return(hallSeats, nil)
+		tx.On("LockHall", hallID).Return(nil)
+		tx.On("FindOverlapping", hallID, date, date.Add(150*time.Minute), 30*time.Minute, mock.AnythingOfType("uuid.UUID")).
+			Return([]model.Performance{}, nil)
+		tx.On("Create", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.PlayID == play.ID && p.HallID == hallID && p.Status == "scheduled"
+		})).Return(nil)
+		tx.On("CreateSeats", mock.MatchedBy(func(seats []model.PerformanceSeat) bool {
+			return len(seats) == 3 &&
+				seats[0].Price == 3500 && seats[1].Price == 1500 && seats[2].Price == 1000 &&
+				seats[0].Status == "available"
+		})).Return(nil)
+
+		performance, err := service.CreatePerformance(testActor, play.ID, hallID, date, nil, false, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, play, performance.Play)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("prices seats from price plan", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		plansRepo := new(MockPricePlansRepository)
+		service := NewSchedule(&MockScheduleRepository{tx: tx}, playsRepo, seatsRepo, plansRepo, DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		play := &model.Play{ID: uuid.New(), Duration: 120}
+		hallID := uuid.New()
+		date := time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC)
+		plan := &model.PricePlan{
+			ID:           uuid.New(),
+			DefaultPrice: 900,
+			Tiers: []model.PriceTier{
+				{Category: "parterre", RowFrom: 1, RowTo: 3, Price: 4000},
+				{Category: "parterre", RowFrom: 4, Price: 2000},
+			},
+		}
+		hallSeats := []model.Seat{
+			{ID: uuid.New(), Row: 2, Category: "parterre"},
+			{ID: uuid.New(), Row: 6, Category: "parterre"},
+			{ID: uuid.New(), Row: 8, Category: "balcony"},
+		}
+
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		plansRepo.On("GetByID", plan.ID).Return(plan, nil)
+		seatsRepo.On("GetByHallID", hallID).Return(hallSeats, nil)
+		tx.On("LockHall", hallID).Return(nil)
+		tx.On("FindOverlapping", hallID, date, mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Performance{}, nil)
+		tx.On("Create", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.PricePlanID != nil && *p.PricePlanID == plan.ID && p.Premiere && p.Status == "on_sale"
+		})).Return(nil)
+		tx.On("CreateSeats", mock.MatchedBy(func(seats []model.PerformanceSeat) bool {
+			return seats[0].Price == 4000 && seats[1].Price == 2000 && seats[2].Price == 900
+		})).Return(nil)
+
+		_, err := service.CreatePerformance(testActor, play.ID, hallID, date, &plan.ID, true, true)
+
+		assert.NoError(t, err)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("overlapping performance", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		play := &model.Play{ID: uuid.New(), Duration: 120}
+		hallID := uuid.New()
+		date := time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC)
+
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		seatsRepo.On("GetByHallID", hallID).Return([]model.Seat{{ID: uuid.New(), Category: "balcony"}}, nil)
+		tx.On("LockHall", hallID).Return(nil)
+		tx.On("FindOverlapping", hallID, date, mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Performance{{ID: uuid.New(), Date: date.Add(time.Hour)}}, nil)
+
+		performance, err := service.CreatePerformance(testActor, play.ID, hallID, date, nil, false, false)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "hall is busy")
+		assert.Nil(t, performance)
+		tx.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("play not found", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		playID := uuid.New()
+		playsRepo.On("GetByID", playID).Return(nil, assert.AnError)
+
+		_, err := service.CreatePerformance(testActor, playID, uuid.New(), time.Now(), nil, false, false)
+
+		assert.EqualError(t, err, "play not found")
+		tx.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("hall without seats", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		play := &model.Play{ID: uuid.New(), Duration: 90}
+		hallID := uuid.New()
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		seatsRepo.On("GetByHallID", hallID).Return([]model.Seat{}, nil)
+
+		_, err := service.CreatePerformance(testActor, play.ID, hallID, time.Now(), nil, false, false)
+
+		assert.EqualError(t, err, "hall has no seats")
+	})
+}
+
+func TestUpdatePerformance(t *testing.T) {
+	t.Run("reschedule excludes itself from overlap check", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), HallID: uuid.New(), Status: "scheduled"}
+		play := &model.Play{ID: uuid.New(), Duration: 60}
+		date := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		tx.On("HasActiveBookings", performance.ID).Return(false, nil)
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		tx.On("LockHall", performance.HallID).Return(nil)
+		tx.On("FindOverlapping", performance.HallID, date, date.Add(90*time.Minute), 30*time.Minute, performance.ID).
+			Return([]model.Performance{}, nil)
+		tx.On("Update", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.Date.Equal(date) && p.PlayID == play.ID
+		})).Return(nil)
+
+		result, err := service.UpdatePerformance(testActor, performance.ID.String(), play.ID, date)
+
+		assert.NoError(t, err)
+		assert.Equal(t, date, result.Date)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("date of performance with bookings is not changed", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "on_sale", Date: time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)}
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		tx.On("HasActiveBookings", performance.ID).Return(true, nil)
+
+		_, err := service.UpdatePerformance(testActor, performance.ID.String(), uuid.New(), performance.Date.Add(24*time.Hour))
+
+		assert.ErrorIs(t, err, ErrPerformanceHasBookings)
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("play of performance with bookings can be changed", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		date := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+		performance := &model.Performance{ID: uuid.New(), HallID: uuid.New(), Status: "on_sale", Date: date}
+		play := &model.Play{ID: uuid.New(), Duration: 60}
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+		playsRepo.On("GetByID", play.ID).Return(play, nil)
+		tx.On("LockHall", performance.HallID).Return(nil)
+		tx.On("FindOverlapping", performance.HallID, date, date.Add(90*time.Minute), 30*time.Minute, performance.ID).
+			Return([]model.Performance{}, nil)
+		tx.On("Update", mock.Anything).Return(nil)
+
+		_, err := service.UpdatePerformance(testActor, performance.ID.String(), play.ID, date)
+
+		assert.NoError(t, err)
+		tx.AssertNotCalled(t, "HasActiveBookings", mock.Anything)
+	})
+
+	t.Run("date in the past", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "scheduled"}
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+
+		_, err := service.UpdatePerformance(testActor, performance.ID.String(), uuid.New(), scheduleClock.now.Add(-time.Hour))
+
+		assert.ErrorIs(t, err, ErrPerformanceDateInPast)
+	})
+
+	t.Run("cancelled performance", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "cancelled"}
+		tx.On("LockByID", performance.ID).Return(performance, nil)
+
+		_, err := service.UpdatePerformance(testActor, performance.ID.String(), uuid.New(), time.Now())
+
+		assert.EqualError(t, err, "performance is cancelled")
+	})
+}
+
+func TestSetPerformanceStatus(t *testing.T) {
+	t.Run("opens sales", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "scheduled", Date: scheduleClock.now.Add(7 * 24 * time.Hour)}
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+		tx.On("Update", mock.MatchedBy(func(p *model.Performance) bool {
+			return p.Status == "on_sale"
+		})).Return(nil)
+
+		result, err := service.SetStatus(testActor, performance.ID.String(), "on_sale")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "on_sale", result.Status)
+		tx.AssertExpectations(t)
+	})
+
+	t.Run("transition not allowed", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "completed", Date: scheduleClock.now.Add(-24 * time.Hour)}
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+
+		_, err := service.SetStatus(testActor, performance.ID.String(), "on_sale")
+
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("cancelled and completed are not set directly", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		for _, status := range []string{"cancelled", "completed"} {
+			_, err := service.SetStatus(testActor, uuid.New().String(), status)
+			assert.ErrorIs(t, err, ErrInvalidTransition)
+		}
+		repo.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+
+	t.Run("sales cannot open after curtain", func(t *testing.T) {
+		tx := new(MockPerformancesTx)
+		repo := &MockScheduleRepository{tx: tx}
+		playsRepo := new(MockPlaysRepository)
+		seatsRepo := new(MockSeatsRepository)
+		service := NewSchedule(repo, playsRepo, seatsRepo, new(MockPricePlansRepository), DefaultPricing, 30*time.Minute, scheduleClock, nil)
+
+		performance := &model.Performance{ID: uuid.New(), Status: "sales_closed", Date: scheduleClock.now.Add(-time.Minute)}
+		repo.On("GetByID", performance.ID).Return(performance, nil)
+
+		_, err := service.SetStatus(testActor, performance.ID.String(), "on_sale")
+
+		assert.ErrorIs(t, err, ErrPerformanceStarted)
+		tx.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
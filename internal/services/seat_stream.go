@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatEventsChannel - канал NOTIFY, в который триггер публикует изменения мест
+const SeatEventsChannel = "seat_events"
+
+const (
+	// seatReplayLimit - сколько пропущенных событий досылается при переподключении;
+	// отставшему сильнее клиенту отправляется снимок схемы
+	seatReplayLimit      = 500
+	seatSubscriberBuffer = 64
+	seatListenRetryMin   = time.Second
+	seatListenRetryMax   = 30 * time.Second
+	seatEventsPruneEvery = time.Hour
+)
+
+type SeatEventsRepository interface {
+	GetSince(performanceID uuid.UUID, afterSeq int64, limit int) ([]model.SeatEvent, error)
+	GetAfter(afterSeq int64, limit int) ([]model.SeatEvent, error)
+	Snapshot(performanceID uuid.UUID) (int64, []model.PerformanceSeat, error)
+	PerformanceExists(id uuid.UUID) (bool, error)
+	DeleteBefore(before time.Time) error
+}
+
+// NotificationListener доставляет уведомления канала Postgres. Возвращенный канал
+// закрывается при потере соединения.
+type NotificationListener interface {
+	Listen(ctx context.Context, channel string) (<-chan string, error)
+}
+
+// SeatSnapshot - статусы всех мест показа на момент события LastEventID
+type SeatSnapshot struct {
+	LastEventID int64
+	Seats       []model.PerformanceSeat
+}
+
+// SeatSubscription - подписка на изменения мест одного показа. Если клиент не успевает
+// читать события, подписка закрывается, и клиент переподключается с Last-Event-ID.
+type SeatSubscription struct {
+	Snapshot *SeatSnapshot     // снимок вместо пропущенных событий, если их не восстановить
+	Replay   []model.SeatEvent // события, пропущенные с Last-Event-ID
+	Events   <-chan model.SeatEvent
+
+	events        chan model.SeatEvent
+	performanceID uuid.UUID
+	stream        *SeatStream
+}
+
+// Close отписывает клиента
+func (sub *SeatSubscription) Close() {
+	sub.stream.unsubscribe(sub)
+}
+
+// SeatStream раздает изменения мест подписчикам этого экземпляра сервера.
+// События приходят от Postgres через LISTEN, поэтому изменения, сделанные на
+// других экземплярах, тоже доставляются.
+type SeatStream struct {
+	repo      SeatEventsRepository
+	listener  NotificationListener
+	clock     Clock
+	retention time.Duration
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*SeatSubscription]struct{}
+	lastID      int64 // номер seq последнего события, полученного через LISTEN
+}
+
+// NewSeatStream создает раздачу событий. События старше retention удаляются из журнала.
+func NewSeatStream(repo SeatEventsRepository, listener NotificationListener, clock Clock, retention time.Duration) *SeatStream {
+	return &SeatStream{
+		repo:        repo,
+		listener:    listener,
+		clock:       clock,
+		retention:   retention,
+		subscribers: make(map[uuid.UUID]map[*SeatSubscription]struct{}),
+	}
+}
+
+// Subscribe подписывает клиента на изменения мест показа. Клиент без lastEventID
+// получает снимок схемы; переподключившийся - пропущенные события.
+func (s *SeatStream) Subscribe(performanceID uuid.UUID, lastEventID int64) (*SeatSubscription, error) {
+	exists, err := s.repo.PerformanceExists(performanceID)
+	if err != nil {
+		return nil, errors.New("failed to find performance")
+	}
+	if !exists {
+		return nil, errors.New("performance not found")
+	}
+
+	events := make(chan model.SeatEvent, seatSubscriberBuffer)
+	sub := &SeatSubscription{
+		Events:        events,
+		events:        events,
+		performanceID: performanceID,
+		stream:        s,
+	}
+
+	// Подписываемся до чтения журнала, чтобы не потерять события между чтением и подпиской.
+	// Повторы клиент отбрасывает по номеру события: seq растет в порядке фиксации,
+	// поэтому событие с меньшим номером не может прийти позже уже доставленного.
+	s.mu.Lock()
+	if s.subscribers[performanceID] == nil {
+		s.subscribers[performanceID] = make(map[*SeatSubscription]struct{})
+	}
+	s.subscribers[performanceID][sub] = struct{}{}
+	s.mu.Unlock()
+
+	if lastEventID > 0 {
+		sub.Replay, err = s.repo.GetSince(performanceID, lastEventID, seatReplayLimit)
+		if err != nil {
+			sub.Close()
+			return nil, errors.New("failed to load missed seat events")
+		}
+		if len(sub.Replay) < seatReplayLimit {
+			return sub, nil
+		}
+		sub.Replay = nil
+	}
+
+	lastID, seats, err := s.repo.Snapshot(performanceID)
+	if err != nil {
+		sub.Close()
+		return nil, errors.New("failed to load seats")
+	}
+	sub.Snapshot = &SeatSnapshot{LastEventID: lastID, Seats: seats}
+	return sub, nil
+}
+
+func (s *SeatStream) unsubscribe(sub *SeatSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := s.subscribers[sub.performanceID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(s.subscribers, sub.performanceID)
+	}
+	close(sub.events)
+}
+
+// Publish рассылает событие подписчикам его показа. Медленный подписчик отключается,
+// чтобы не задерживать остальных.
+func (s *SeatStream) Publish(event model.SeatEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.Seq > s.lastID {
+		s.lastID = event.Seq
+	}
+
+	subs := s.subscribers[event.PerformanceID]
+	for sub := range subs {
+		select {
+		case sub.events <- event:
+		default:
+			delete(subs, sub)
+			close(sub.events)
+		}
+	}
+	if len(subs) == 0 {
+		delete(s.subscribers, event.PerformanceID)
+	}
+}
+
+// catchUp досылает события, пропущенные, пока не было соединения для LISTEN
+func (s *SeatStream) catchUp() error {
+	s.mu.Lock()
+	lastID := s.lastID
+	s.mu.Unlock()
+	if lastID == 0 {
+		return nil
+	}
+
+	for {
+		events, err := s.repo.GetAfter(lastID, seatReplayLimit)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			s.Publish(event)
+			lastID = event.Seq
+		}
+		if len(events) < seatReplayLimit {
+			return nil
+		}
+	}
+}
+
+// listen получает события, пока соединение не оборвется или не отменен ctx
+func (s *SeatStream) listen(ctx context.Context) error {
+	payloads, err := s.listener.Listen(ctx, SeatEventsChannel)
+	if err != nil {
+		return err
+	}
+	if err := s.catchUp(); err != nil {
+		log.Println("Failed to catch up seat events:", err)
+	}
+
+	for payload := range payloads {
+		var event model.SeatEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			log.Println("Invalid seat event:", err)
+			continue
+		}
+		s.Publish(event)
+	}
+	return errors.New("seat events connection lost")
+}
+
+// Run слушает события мест и периодически чистит журнал до отмены контекста
+func (s *SeatStream) Run(ctx context.Context) {
+	go s.prune(ctx)
+
+	retry := seatListenRetryMin
+	for {
+		started := s.clock.Now()
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Println("Seat events listener stopped:", err)
+
+		// Долго проработавшее соединение переподключается сразу
+		if s.clock.Now().Sub(started) > seatListenRetryMax {
+			retry = seatListenRetryMin
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry *= 2
+		if retry > seatListenRetryMax {
+			retry = seatListenRetryMax
+		}
+	}
+}
+
+func (s *SeatStream) prune(ctx context.Context) {
+	ticker := time.NewTicker(seatEventsPruneEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.repo.DeleteBefore(s.clock.Now().Add(-s.retention)); err != nil {
+				log.Println("Pruning seat events failed:", err)
+			}
+		}
+	}
+}
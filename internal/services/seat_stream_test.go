@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSeatEventsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSeatEventsRepository) GetSince(performanceID uuid.UUID, afterSeq int64, limit int) ([]model.SeatEvent, error) {
+	args := m.Called(performanceID, afterSeq, limit)
+	return args.Get(0).([]model.SeatEvent), args.Error(1)
+}
+
+func (m *MockSeatEventsRepository) GetAfter(afterSeq int64, limit int) ([]model.SeatEvent, error) {
+	args := m.Called(afterSeq, limit)
+	return args.Get(0).([]model.SeatEvent), args.Error(1)
+}
+
+func (m *MockSeatEventsRepository) Snapshot(performanceID uuid.UUID) (int64, []model.PerformanceSeat, error) {
+	args := m.Called(performanceID)
+	return args.Get(0).(int64), args.Get(1).([]model.PerformanceSeat), args.Error(2)
+}
+
+func (m *MockSeatEventsRepository) PerformanceExists(id uuid.UUID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSeatEventsRepository) DeleteBefore(before time.Time) error {
+	args := m.Called(before)
+	return args.Error(0)
+}
+
+// fakeListener отдает заранее заданные уведомления и закрывает канал, как при обрыве соединения
+type fakeListener struct {
+	payloads []string
+}
+
+func (l *fakeListener) Listen(ctx context.Context, channel string) (<-chan string, error) {
+	ch := make(chan string, len(l.payloads))
+	for _, p := range l.payloads {
+		ch <- p
+	}
+	close(ch)
+	l.payloads = nil
+	return ch, nil
+}
+
+func TestSeatStreamSubscribe(t *testing.T) {
+	performanceID := uuid.New()
+
+	t.Run("new client gets snapshot", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		seats := []model.PerformanceSeat{{ID: uuid.New(), Status: "available"}}
+		repo.On("PerformanceExists", performanceID).Return(true, nil)
+		repo.On("Snapshot", performanceID).Return(int64(42), seats, nil)
+
+		sub, err := stream.Subscribe(performanceID, 0)
+
+		require.NoError(t, err)
+		defer sub.Close()
+		assert.Equal(t, int64(42), sub.Snapshot.LastEventID)
+		assert.Equal(t, seats, sub.Snapshot.Seats)
+		assert.Empty(t, sub.Replay)
+	})
+
+	t.Run("reconnecting client gets missed events", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		missed := []model.SeatEvent{{Seq: 11, PerformanceID: performanceID, Status: "reserved"}}
+		repo.On("PerformanceExists", performanceID).Return(true, nil)
+		repo.On("GetSince", performanceID, int64(10), seatReplayLimit).Return(missed, nil)
+
+		sub, err := stream.Subscribe(performanceID, 10)
+
+		require.NoError(t, err)
+		defer sub.Close()
+		assert.Nil(t, sub.Snapshot)
+		assert.Equal(t, missed, sub.Replay)
+	})
+
+	t.Run("too many missed events fall back to snapshot", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		repo.On("PerformanceExists", performanceID).Return(true, nil)
+		repo.On("GetSince", performanceID, int64(10), seatReplayLimit).
+			Return(make([]model.SeatEvent, seatReplayLimit), nil)
+		repo.On("Snapshot", performanceID).Return(int64(900), []model.PerformanceSeat{}, nil)
+
+		sub, err := stream.Subscribe(performanceID, 10)
+
+		require.NoError(t, err)
+		defer sub.Close()
+		assert.Empty(t, sub.Replay)
+		assert.Equal(t, int64(900), sub.Snapshot.LastEventID)
+	})
+
+	t.Run("unknown performance", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		repo.On("PerformanceExists", performanceID).Return(false, nil)
+
+		_, err := stream.Subscribe(performanceID, 0)
+
+		assert.EqualError(t, err, "performance not found")
+	})
+}
+
+func TestSeatStreamPublish(t *testing.T) {
+	performanceID := uuid.New()
+
+	subscribe := func(t *testing.T, stream *SeatStream, repo *MockSeatEventsRepository, id uuid.UUID) *SeatSubscription {
+		repo.On("PerformanceExists", id).Return(true, nil)
+		repo.On("Snapshot", id).Return(int64(0), []model.PerformanceSeat{}, nil)
+		sub, err := stream.Subscribe(id, 0)
+		require.NoError(t, err)
+		return sub
+	}
+
+	t.Run("delivers events of subscribed performance only", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		sub := subscribe(t, stream, repo, performanceID)
+		defer sub.Close()
+
+		stream.Publish(model.SeatEvent{Seq: 1, PerformanceID: uuid.New(), Status: "reserved"})
+		stream.Publish(model.SeatEvent{Seq: 2, PerformanceID: performanceID, Status: "sold"})
+
+		event := <-sub.Events
+		assert.Equal(t, int64(2), event.Seq)
+		assert.Equal(t, "sold", event.Kind())
+		assert.Empty(t, sub.Events)
+	})
+
+	t.Run("disconnects slow subscriber", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		sub := subscribe(t, stream, repo, performanceID)
+
+		for i := 0; i <= seatSubscriberBuffer; i++ {
+			stream.Publish(model.SeatEvent{Seq: int64(i + 1), PerformanceID: performanceID, Status: "reserved"})
+		}
+
+		received := 0
+		for range sub.Events {
+			received++
+		}
+		assert.Equal(t, seatSubscriberBuffer, received)
+		assert.Empty(t, stream.subscribers)
+
+		// Повторное закрытие после отключения безопасно
+		sub.Close()
+	})
+
+	t.Run("notifications reach subscribers and gaps are caught up after reconnect", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		sub := subscribe(t, stream, repo, performanceID)
+		defer sub.Close()
+
+		listener.payloads = []string{
+			`{"id":5,"seq":5,"performance_id":"` + performanceID.String() + `","status":"available","created_at":"2025-05-10T15:00:00.123456+03:00"}`,
+		}
+		err := stream.listen(context.Background())
+		assert.Error(t, err)
+
+		event := <-sub.Events
+		assert.Equal(t, int64(5), event.Seq)
+		assert.Equal(t, "released", event.Kind())
+
+		// Пока соединения не было, произошло еще одно изменение
+		repo.On("GetAfter", int64(5), seatReplayLimit).
+			Return([]model.SeatEvent{{Seq: 6, PerformanceID: performanceID, Status: "reserved"}}, nil)
+		stream.listen(context.Background())
+
+		event = <-sub.Events
+		assert.Equal(t, int64(6), event.Seq)
+	})
+	t.Run("event committed after a later numbered one is not lost", func(t *testing.T) {
+		repo := new(MockSeatEventsRepository)
+		listener := &fakeListener{}
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		stream := NewSeatStream(repo, listener, clock, 24*time.Hour)
+		sub := subscribe(t, stream, repo, performanceID)
+		defer sub.Close()
+
+		// Транзакция с id 10 начала менять места раньше, а зафиксировалась позже
+		// транзакции с id 11: номер seq отражает порядок фиксации
+		listener.payloads = []string{
+			`{"id":11,"seq":7,"performance_id":"` + performanceID.String() + `","status":"reserved"}`,
+			`{"id":10,"seq":8,"performance_id":"` + performanceID.String() + `","status":"sold"}`,
+		}
+		stream.listen(context.Background())
+
+		first, second := <-sub.Events, <-sub.Events
+		assert.Equal(t, []int64{11, 10}, []int64{first.ID, second.ID})
+		assert.Less(t, first.Seq, second.Seq)
+
+		// После переподключения журнал дочитывается с последнего номера seq, а не id
+		repo.On("GetAfter", int64(8), seatReplayLimit).Return([]model.SeatEvent{}, nil)
+		stream.listen(context.Background())
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/tickets"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TicketSigner подписывает содержимое QR-кода билета
+type TicketSigner interface {
+	Sign(payload tickets.Payload) (string, error)
+}
+
+type Tickets struct {
+	bookingsRepo BookingsRepository
+	signer       TicketSigner
+	location     *time.Location
+}
+
+func NewTickets(bookingsRepo BookingsRepository, signer TicketSigner, location *time.Location) *Tickets {
+	return &Tickets{
+		bookingsRepo: bookingsRepo,
+		signer:       signer,
+		location:     location,
+	}
+}
+
+// issueTickets выпускает по билету на каждое место бронирования
+func issueTickets(booking *model.Booking) ([]model.Ticket, error) {
+	issued := make([]model.Ticket, 0, len(booking.PerformanceSeats))
+	for _, seat := range booking.PerformanceSeats {
+		nonce, err := tickets.NewNonce()
+		if err != nil {
+			return nil, err
+		}
+		issued = append(issued, model.Ticket{
+			ID:                uuid.New(),
+			BookingID:         booking.ID,
+			PerformanceID:     booking.PerformanceID,
+			PerformanceSeatID: seat.ID,
+			Nonce:             nonce,
+			Status:            "valid",
+		})
+	}
+	return issued, nil
+}
+
+// TicketPayload возвращает содержимое QR-кода билета
+func TicketPayload(ticket model.Ticket) tickets.Payload {
+	return tickets.Payload{
+		TicketID:          ticket.ID,
+		BookingID:         ticket.BookingID,
+		PerformanceID:     ticket.PerformanceID,
+		PerformanceSeatID: ticket.PerformanceSeatID,
+		Nonce:             ticket.Nonce,
+	}
+}
+
+// BookingTicketsPDF формирует PDF с билетами подтвержденного бронирования. Скачать
+// билеты может только владелец брони или сотрудник с правом на возвраты.
+func (s *Tickets) BookingTicketsPDF(actor model.Actor, id string) ([]byte, error) {
+	bookingID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid booking ID format")
+	}
+
+	booking, err := s.bookingsRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if !canManageBooking(actor, booking) {
+		return nil, ErrNotBookingOwner
+	}
+
+	if booking.Status != "confirmed" {
+		return nil, errors.New("tickets are available only for confirmed bookings")
+	}
+	if len(booking.Tickets) == 0 {
+		return nil, errors.New("booking has no tickets")
+	}
+
+	seats := make(map[uuid.UUID]model.PerformanceSeat, len(booking.PerformanceSeats))
+	for _, seat := range booking.PerformanceSeats {
+		seats[seat.ID] = seat
+	}
+
+	var playTitle string
+	if booking.Performance.Play != nil {
+		playTitle = booking.Performance.Play.Title
+	}
+
+	entries := make([]tickets.Entry, 0, len(booking.Tickets))
+	for _, ticket := range booking.Tickets {
+		seat, ok := seats[ticket.PerformanceSeatID]
+		if !ok {
+			continue
+		}
+
+		code, err := s.signer.Sign(TicketPayload(ticket))
+		if err != nil {
+			return nil, errors.New("failed to sign ticket")
+		}
+
+		price := seat.Price
+		if seat.ChargedPrice != nil {
+			price = *seat.ChargedPrice
+		}
+
+		entries = append(entries, tickets.Entry{
+			Code:      code,
+			PlayTitle: playTitle,
+			HallName:  booking.Performance.Hall.Name,
+			Date:      booking.Performance.Date.In(s.location),
+			Section:   seat.Seat.Section,
+			Row:       seat.Seat.Row,
+			Number:    seat.Seat.Number,
+			Price:     price,
+		})
+	}
+
+	pdf, err := tickets.RenderPDF(entries)
+	if err != nil {
+		return nil, errors.New("failed to render tickets")
+	}
+	return pdf, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/tickets"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingTicketsPDF(t *testing.T) {
+	t.Run("renders tickets of confirmed booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewTickets(bookingsRepo, tickets.NewSigner("secret"), time.UTC)
+
+		charged := 3850
+		seat := model.PerformanceSeat{
+			ID:           uuid.New(),
+			Price:        3500,
+			ChargedPrice: &charged,
+			Seat:         model.Seat{Section: "parterre", Row: 3, Number: 12},
+		}
+		ownerID := uuid.New()
+		booking := &model.Booking{
+			ID:     uuid.New(),
+			UserID: &ownerID,
+			Status: "confirmed",
+			Performance: model.Performance{
+				Date: time.Date(2025, 5, 10, 19, 0, 0, 0, time.UTC),
+				Play: &model.Play{Title: "Вишневый сад"},
+				Hall: model.Hall{Name: "Большой зал"},
+			},
+			PerformanceSeats: []model.PerformanceSeat{seat},
+			Tickets:          []model.Ticket{{ID: uuid.New(), PerformanceSeatID: seat.ID, Nonce: "n1"}},
+		}
+		bookingsRepo.On("GetByID", booking.ID).Return(booking, nil)
+
+		pdf, err := service.BookingTicketsPDF(model.Actor{UserID: &ownerID, Role: model.RoleCustomer}, booking.ID.String())
+
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF")))
+	})
+
+	t.Run("pending booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewTickets(bookingsRepo, tickets.NewSigner("secret"), time.UTC)
+
+		booking := &model.Booking{ID: uuid.New(), Status: "pending"}
+		bookingsRepo.On("GetByID", booking.ID).Return(booking, nil)
+
+		_, err := service.BookingTicketsPDF(testActor, booking.ID.String())
+
+		assert.EqualError(t, err, "tickets are available only for confirmed bookings")
+	})
+
+	t.Run("another user's booking", func(t *testing.T) {
+		bookingsRepo := new(MockBookingsRepository)
+		service := NewTickets(bookingsRepo, tickets.NewSigner("secret"), time.UTC)
+
+		ownerID, otherID := uuid.New(), uuid.New()
+		booking := &model.Booking{ID: uuid.New(), UserID: &ownerID, Status: "confirmed"}
+		bookingsRepo.On("GetByID", booking.ID).Return(booking, nil)
+
+		_, err := service.BookingTicketsPDF(model.Actor{UserID: &otherID, Role: model.RoleCustomer}, booking.ID.String())
+
+		assert.ErrorIs(t, err, ErrNotBookingOwner)
+	})
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"theater-ticket-system/internal/models/models"
+	response "theater-ticket-system/internal/models/responses"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+type SessionsRepository interface {
+	Create(session *model.Session) error
+	GetByID(id uuid.UUID) (*model.Session, error)
+	Revoke(id uuid.UUID, at time.Time) (bool, error)
+}
+
+// TokenPair - выданные пользователю access- и refresh-токены
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+}
+
+func (p *TokenPair) Response() response.Tokens {
+	return response.Tokens{
+		AccessToken:      p.AccessToken,
+		RefreshToken:     p.RefreshToken,
+		TokenType:        "Bearer",
+		AccessExpiresAt:  p.AccessExpiresAt,
+		RefreshExpiresAt: p.RefreshExpiresAt,
+	}
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type      string    `json:"typ"`
+	SessionID uuid.UUID `json:"sid"`
+}
+
+// Tokens выпускает и проверяет подписанные JWT, привязанные к сессии пользователя
+type Tokens struct {
+	repo       SessionsRepository
+	usersRepo  UsersRepository
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	clock      Clock
+}
+
+func NewTokens(repo SessionsRepository, usersRepo UsersRepository, secret string, accessTTL, refreshTTL time.Duration, clock Clock) *Tokens {
+	return &Tokens{
+		repo:       repo,
+		usersRepo:  usersRepo,
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		clock:      clock,
+	}
+}
+
+// Issue открывает новую сессию для пользователя и выдает пару токенов
+func (s *Tokens) Issue(user *model.User) (*TokenPair, error) {
+	now := s.clock.Now()
+	session := &model.Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.repo.Create(session); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	return s.issuePair(user.ID, session, now)
+}
+
+// Refresh отзывает сессию refresh-токена и выдает новую пару (ротация токенов)
+func (s *Tokens) Refresh(refreshToken string) (*TokenPair, error) {
+	claims, err := s.parse(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.activeSession(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	revoked, err := s.repo.Revoke(session.ID, now)
+	if err != nil {
+		return nil, errors.New("failed to revoke session")
+	}
+	if !revoked {
+		// Токен уже был использован параллельным запросом
+		return nil, errors.New("session has been revoked")
+	}
+
+	next := &model.Session{
+		ID:        uuid.New(),
+		UserID:    session.UserID,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.repo.Create(next); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	return s.issuePair(session.UserID, next, now)
+}
+
+// Revoke завершает сессию, к которой относится refresh-токен
+func (s *Tokens) Revoke(refreshToken string) error {
+	claims, err := s.parse(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.Revoke(claims.SessionID, s.clock.Now()); err != nil {
+		return errors.New("failed to revoke session")
+	}
+	return nil
+}
+
+// Authenticate проверяет access-токен и возвращает его владельца
+func (s *Tokens) Authenticate(accessToken string) (*model.User, error) {
+	claims, err := s.parse(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.activeSession(claims.SessionID); err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	user, err := s.usersRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user, nil
+}
+
+func (s *Tokens) activeSession(id uuid.UUID) (*model.Session, error) {
+	session, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("session not found")
+	}
+	if session.RevokedAt != nil {
+		return nil, errors.New("session has been revoked")
+	}
+	if !session.ExpiresAt.After(s.clock.Now()) {
+		return nil, errors.New("session has expired")
+	}
+	return session, nil
+}
+
+func (s *Tokens) issuePair(userID uuid.UUID, session *model.Session, now time.Time) (*TokenPair, error) {
+	accessExpiresAt := now.Add(s.accessTTL)
+
+	access, err := s.sign(userID, session.ID, tokenTypeAccess, now, accessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := s.sign(userID, session.ID, tokenTypeRefresh, now, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *Tokens) sign(userID, sessionID uuid.UUID, tokenType string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type:      tokenType,
+		SessionID: sessionID,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", errors.New("failed to sign token")
+	}
+	return token, nil
+}
+
+func (s *Tokens) parse(tokenString, tokenType string) (*tokenClaims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims,
+		func(*jwt.Token) (interface{}, error) { return s.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithTimeFunc(s.clock.Now),
+	)
+	if err != nil {
+		return nil, errors.New("invalid or expired token")
+	}
+	if claims.Type != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+	return &claims, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memSessionsRepo struct {
+	sessions map[uuid.UUID]*model.Session
+}
+
+func (r *memSessionsRepo) Create(session *model.Session) error {
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *memSessionsRepo) GetByID(id uuid.UUID) (*model.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memSessionsRepo) Revoke(id uuid.UUID, at time.Time) (bool, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return false, nil
+	}
+	session.RevokedAt = &at
+	return true, nil
+}
+
+func TestTokens(t *testing.T) {
+	t.Run("issued access token authenticates user", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		user := &model.User{ID: uuid.New(), Email: "user@example.com"}
+		users.On("GetByID", user.ID).Return(user, nil)
+
+		pair, err := tokens.Issue(user)
+		require.NoError(t, err)
+
+		authenticated, err := tokens.Authenticate(pair.AccessToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, authenticated.ID)
+	})
+
+	t.Run("expired access token is rejected", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		user := &model.User{ID: uuid.New()}
+
+		pair, err := tokens.Issue(user)
+		require.NoError(t, err)
+
+		clock.Advance(16 * time.Minute)
+		_, err = tokens.Authenticate(pair.AccessToken)
+
+		assert.EqualError(t, err, "invalid or expired token")
+	})
+
+	t.Run("refresh token cannot be used as access token", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+
+		pair, err := tokens.Issue(&model.User{ID: uuid.New()})
+		require.NoError(t, err)
+
+		_, err = tokens.Authenticate(pair.RefreshToken)
+
+		assert.EqualError(t, err, "invalid token type")
+	})
+
+	t.Run("token signed with another secret is rejected", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		other := NewTokens(sessions, users, "other-secret", time.Minute, time.Hour, clock)
+
+		pair, err := other.Issue(&model.User{ID: uuid.New()})
+		require.NoError(t, err)
+
+		_, err = tokens.Authenticate(pair.AccessToken)
+
+		assert.EqualError(t, err, "invalid or expired token")
+	})
+
+	t.Run("refresh rotates session", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+		user := &model.User{ID: uuid.New()}
+		users.On("GetByID", user.ID).Return(user, nil)
+
+		pair, err := tokens.Issue(user)
+		require.NoError(t, err)
+
+		clock.Advance(time.Minute)
+		next, err := tokens.Refresh(pair.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = tokens.Authenticate(next.AccessToken)
+		assert.NoError(t, err)
+
+		_, err = tokens.Authenticate(pair.AccessToken)
+		assert.EqualError(t, err, "session has been revoked")
+
+		_, err = tokens.Refresh(pair.RefreshToken)
+		assert.EqualError(t, err, "session has been revoked")
+	})
+
+	t.Run("logout revokes session", func(t *testing.T) {
+		sessions := &memSessionsRepo{sessions: map[uuid.UUID]*model.Session{}}
+		users := new(MockUsersRepository)
+		clock := &fakeClock{now: time.Now()}
+		tokens := NewTokens(sessions, users, "test-secret", 15*time.Minute, 24*time.Hour, clock)
+
+		pair, err := tokens.Issue(&model.User{ID: uuid.New()})
+		require.NoError(t, err)
+
+		require.NoError(t, tokens.Revoke(pair.RefreshToken))
+
+		_, err = tokens.Authenticate(pair.AccessToken)
+		assert.EqualError(t, err, "session has been revoked")
+	})
+}
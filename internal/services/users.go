@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownRole - роли нет среди ролей системы
+var ErrUnknownRole = errors.New("unknown role")
+
+type UserRolesRepository interface {
+	FindByEmail(email string) (*model.User, error)
+	Create(user *model.User) error
+	UpdateRole(id uuid.UUID, role string) error
+}
+
+type Users struct {
+	repo UserRolesRepository
+}
+
+func NewUsers(repo UserRolesRepository) *Users {
+	return &Users{repo: repo}
+}
+
+// SetRole назначает роль пользователю с указанным email. Если пользователя еще нет,
+// он создается, чтобы первого администратора можно было завести до его входа.
+func (s *Users) SetRole(email, role string) (*model.User, error) {
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	if !model.IsValidRole(role) {
+		return nil, ErrUnknownRole
+	}
+
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.New("failed to find user")
+		}
+		user = &model.User{Email: email, Role: role}
+		if err := s.repo.Create(user); err != nil {
+			return nil, errors.New("failed to create user")
+		}
+		return user, nil
+	}
+
+	if err := s.repo.UpdateRole(user.ID, role); err != nil {
+		return nil, errors.New("failed to update role")
+	}
+	user.Role = role
+
+	return user, nil
+}
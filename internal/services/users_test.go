@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"theater-ticket-system/internal/models/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockUserRolesRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRolesRepository) FindByEmail(email string) (*model.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRolesRepository) Create(user *model.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRolesRepository) UpdateRole(id uuid.UUID, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func TestSetRole(t *testing.T) {
+	t.Run("promotes existing user", func(t *testing.T) {
+		mockRepo := new(MockUserRolesRepository)
+		service := NewUsers(mockRepo)
+
+		user := &model.User{ID: uuid.New(), Email: "boss@example.com", Role: model.RoleCustomer}
+		mockRepo.On("FindByEmail", "boss@example.com").Return(user, nil)
+		mockRepo.On("UpdateRole", user.ID, model.RoleAdmin).Return(nil)
+
+		result, err := service.SetRole("boss@example.com", model.RoleAdmin)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, result.Role)
+		assert.True(t, result.Can(model.PermissionManageCatalog))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("creates missing user", func(t *testing.T) {
+		mockRepo := new(MockUserRolesRepository)
+		service := NewUsers(mockRepo)
+
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("Create", mock.MatchedBy(func(u *model.User) bool {
+			return u.Email == "new@example.com" && u.Role == model.RoleAdmin
+		})).Return(nil)
+
+		_, err := service.SetRole("new@example.com", model.RoleAdmin)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		mockRepo := new(MockUserRolesRepository)
+		service := NewUsers(mockRepo)
+
+		_, err := service.SetRole("boss@example.com", "superuser")
+
+		assert.EqualError(t, err, "unknown role")
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockUserRolesRepository)
+		service := NewUsers(mockRepo)
+
+		user := &model.User{ID: uuid.New(), Email: "boss@example.com"}
+		mockRepo.On("FindByEmail", "boss@example.com").Return(user, nil)
+		mockRepo.On("UpdateRole", user.ID, model.RoleManager).Return(errors.New("database error"))
+
+		_, err := service.SetRole("boss@example.com", model.RoleManager)
+
+		assert.EqualError(t, err, "failed to update role")
+	})
+}
+
+func TestUserPermissions(t *testing.T) {
+	customer := &model.User{Role: model.RoleCustomer}
+	unset := &model.User{}
+	manager := &model.User{Role: model.RoleManager}
+	admin := &model.User{Role: model.RoleAdmin}
+
+	assert.False(t, customer.Can(model.PermissionManageCatalog))
+	assert.False(t, unset.Can(model.PermissionManageCatalog))
+	assert.True(t, manager.Can(model.PermissionManageCatalog))
+	assert.False(t, manager.Can(model.PermissionManageUsers))
+	assert.True(t, admin.Can(model.PermissionManageUsers))
+}
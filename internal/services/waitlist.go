@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/notifications"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaitlistRepository interface {
+	Create(entry *model.WaitlistEntry) error
+	GetActive(performanceID, userID uuid.UUID) (*model.WaitlistEntry, error)
+	Position(entry *model.WaitlistEntry) (int, error)
+	CancelWaiting(performanceID, userID uuid.UUID) (bool, error)
+	CountAvailableSeats(performanceID uuid.UUID, category string) (int64, error)
+	GetPerformance(id uuid.UUID) (*model.Performance, error)
+}
+
+type WaitlistBookingsRepository interface {
+	Transaction(fn func(tx BookingsTx) error) error
+}
+
+var (
+	ErrSeatsAvailable     = errors.New("enough seats are available, book them directly")
+	ErrAlreadyWaitlisted  = errors.New("already on the waitlist for this performance")
+	ErrNotOnWaitlist      = errors.New("not on the waitlist for this performance")
+	ErrPerformanceStarted = errors.New("performance has already started")
+)
+
+// Waitlist - лист ожидания распроданных показов. Освободившиеся места предлагаются
+// заявкам в порядке подачи: заявке сразу создается pending-бронирование на offerTTL,
+// поэтому места не попадают в общую продажу, пока покупатель решает.
+type Waitlist struct {
+	repo         WaitlistRepository
+	bookingsRepo WaitlistBookingsRepository
+	pricing      *PricingEngine
+	clock        Clock
+	offerTTL     time.Duration
+	closing      time.Duration
+	emails       *EmailService
+	audit        *Audit
+}
+
+// NewWaitlist создает лист ожидания. Места предлагаются, пока на показ идет
+// продажа, то есть не позже closeBefore до его начала. audit необязателен:
+// без него предложения мест не попадают в журнал аудита.
+func NewWaitlist(repo WaitlistRepository, bookingsRepo WaitlistBookingsRepository, pricing *PricingEngine, clock Clock, offerTTL, closeBefore time.Duration, emails *EmailService, audit *Audit) *Waitlist {
+	return &Waitlist{
+		repo:         repo,
+		bookingsRepo: bookingsRepo,
+		pricing:      pricing,
+		clock:        clock,
+		offerTTL:     offerTTL,
+		closing:      closeBefore,
+		emails:       emails,
+		audit:        audit,
+	}
+}
+
+// Join ставит покупателя в лист ожидания показа. Встать в очередь можно, только
+// если свободных мест нужной категории не хватает.
+func (s *Waitlist) Join(performanceID string, user *model.User, seats int, category string) (*model.WaitlistEntry, error) {
+	id, err := uuid.Parse(performanceID)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+	if seats <= 0 {
+		return nil, errors.New("at least one seat is required")
+	}
+
+	performance, err := s.repo.GetPerformance(id)
+	if err != nil {
+		return nil, errors.New("performance not found")
+	}
+	if err := checkOnSale(performance, s.clock.Now(), s.closing); err != nil {
+		return nil, err
+	}
+
+	available, err := s.repo.CountAvailableSeats(id, category)
+	if err != nil {
+		return nil, errors.New("failed to count available seats")
+	}
+	if available >= int64(seats) {
+		return nil, ErrSeatsAvailable
+	}
+
+	if _, err := s.repo.GetActive(id, user.ID); err == nil {
+		return nil, ErrAlreadyWaitlisted
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to check waitlist")
+	}
+
+	entry := &model.WaitlistEntry{
+		ID:            uuid.New(),
+		PerformanceID: id,
+		UserID:        user.ID,
+		Seats:         seats,
+		Category:      category,
+		Status:        "waiting",
+	}
+	if err := s.repo.Create(entry); err != nil {
+		if errors.Is(err, repository.ErrActiveWaitlistEntry) {
+			return nil, ErrAlreadyWaitlisted
+		}
+		return nil, errors.New("failed to join waitlist")
+	}
+
+	// Места могли освободиться между проверкой и записью заявки
+	err = s.bookingsRepo.Transaction(func(tx BookingsTx) error {
+		return s.offerSeats(tx, id)
+	})
+	if err != nil {
+		return nil, errors.New("failed to process waitlist")
+	}
+
+	return s.GetEntry(performanceID, user.ID)
+}
+
+// GetEntry возвращает действующую заявку покупателя вместе с местом в очереди
+func (s *Waitlist) GetEntry(performanceID string, userID uuid.UUID) (*model.WaitlistEntry, error) {
+	id, err := uuid.Parse(performanceID)
+	if err != nil {
+		return nil, errors.New("invalid performance ID format")
+	}
+
+	entry, err := s.repo.GetActive(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotOnWaitlist
+	}
+	if err != nil {
+		return nil, errors.New("failed to get waitlist entry")
+	}
+
+	if entry.Status == "waiting" {
+		entry.Position, err = s.repo.Position(entry)
+		if err != nil {
+			return nil, errors.New("failed to get waitlist position")
+		}
+	}
+	return entry, nil
+}
+
+// Leave снимает ожидающую заявку. От полученного предложения отказываются отменой бронирования.
+func (s *Waitlist) Leave(performanceID string, userID uuid.UUID) error {
+	id, err := uuid.Parse(performanceID)
+	if err != nil {
+		return errors.New("invalid performance ID format")
+	}
+
+	cancelled, err := s.repo.CancelWaiting(id, userID)
+	if err != nil {
+		return errors.New("failed to leave waitlist")
+	}
+	if !cancelled {
+		return ErrNotOnWaitlist
+	}
+	return nil
+}
+
+// SeatsReleased вызывается в транзакции, освободившей места бронирований released:
+// закрывает предложения, по которым эти бронирования были созданы, и предлагает
+// освободившиеся места следующим в очереди
+func (s *Waitlist) SeatsReleased(tx WaitlistTx, released []model.Booking) error {
+	if s == nil || len(released) == 0 {
+		return nil
+	}
+
+	byStatus := make(map[string][]uuid.UUID)
+	var performances []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, booking := range released {
+		byStatus[booking.Status] = append(byStatus[booking.Status], booking.ID)
+		if !seen[booking.PerformanceID] {
+			seen[booking.PerformanceID] = true
+			performances = append(performances, booking.PerformanceID)
+		}
+	}
+
+	for status, ids := range byStatus {
+		closed, err := tx.CloseWaitlistOffers(ids, status)
+		if err != nil {
+			return err
+		}
+		for i := range closed {
+			entry := &closed[i]
+			err := recordAudit(s.audit, tx, systemActor, "waitlist.close_offer", AuditWaitlist, entry.ID,
+				auditFields{"status": "offered"}, auditFields{"status": entry.Status})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return s.SeatsFreed(tx, performances)
+}
+
+// SeatsFreed вызывается в транзакции, вернувшей места показов в продажу без отмены
+// бронирований (например, при снятии удержания), и предлагает их листу ожидания
+func (s *Waitlist) SeatsFreed(tx WaitlistTx, performances []uuid.UUID) error {
+	if s == nil {
+		return nil
+	}
+
+	for _, performanceID := range performances {
+		if err := s.offerSeats(tx, performanceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offerSeats предлагает свободные места показа ожидающим заявкам в порядке подачи.
+// Заявка, для которой мест пока не хватает, сохраняет свое место в очереди,
+// а места достаются следующей подходящей заявке.
+func (s *Waitlist) offerSeats(tx WaitlistTx, performanceID uuid.UUID) error {
+	entries, err := tx.LockWaitlist(performanceID)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	performance, err := tx.GetPerformance(performanceID)
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now()
+	if checkOnSale(performance, now, s.closing) != nil {
+		return nil
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		seats, err := tx.LockAvailableSeats(performanceID, entry.Category, entry.Seats)
+		if err != nil {
+			return err
+		}
+		if len(seats) < entry.Seats {
+			continue
+		}
+
+		if err := s.offer(tx, entry, performance, seats, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offer бронирует места за заявкой и сообщает покупателю, до какого времени их нужно оплатить
+func (s *Waitlist) offer(tx WaitlistTx, entry *model.WaitlistEntry, performance *model.Performance, seats []model.PerformanceSeat, now time.Time) error {
+	prices, err := priceSeats(tx, s.pricing, performance, seats)
+	if err != nil {
+		return err
+	}
+
+	booking := &model.Booking{
+		ID:            uuid.New(),
+		UserID:        &entry.UserID,
+		PerformanceID: performance.ID,
+		Status:        "pending",
+		ExpiresAt:     now.Add(s.offerTTL),
+
+		RemindersEnabled: true,
+	}
+	for _, price := range prices {
+		booking.Subtotal += price
+	}
+	booking.TotalPrice = booking.Subtotal
+
+	if err := tx.Create(booking); err != nil {
+		return err
+	}
+	if err := reserveSeats(tx, booking.ID, seats, prices); err != nil {
+		return err
+	}
+
+	before := waitlistAudit(entry)
+	entry.Status = "offered"
+	entry.BookingID = &booking.ID
+	entry.OfferedAt = &now
+	if err := tx.UpdateWaitlistEntry(entry); err != nil {
+		return err
+	}
+	err = recordAudit(s.audit, tx, systemActor, "waitlist.offer", AuditWaitlist, entry.ID, before, waitlistAudit(entry))
+	if err != nil {
+		return err
+	}
+
+	return enqueueEmail(s.emails, tx, notifications.TemplateWaitlistOffer, entry.User.Email,
+		bookingEmailData(booking, &entry.User, performance, seats))
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"testing"
+	"theater-ticket-system/internal/models/models"
+	"theater-ticket-system/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockWaitlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWaitlistRepository) Create(entry *model.WaitlistEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockWaitlistRepository) GetActive(performanceID, userID uuid.UUID) (*model.WaitlistEntry, error) {
+	args := m.Called(performanceID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) Position(entry *model.WaitlistEntry) (int, error) {
+	args := m.Called(entry)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) CancelWaiting(performanceID, userID uuid.UUID) (bool, error) {
+	args := m.Called(performanceID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) CountAvailableSeats(performanceID uuid.UUID, category string) (int64, error) {
+	args := m.Called(performanceID, category)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) GetPerformance(id uuid.UUID) (*model.Performance, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Performance), args.Error(1)
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	t.Run("joins sold-out performance", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(48 * time.Hour), Status: "on_sale"}
+		user := &model.User{ID: uuid.New()}
+
+		repo.On("GetPerformance", performance.ID).Return(performance, nil)
+		repo.On("CountAvailableSeats", performance.ID, "parterre").Return(int64(1), nil)
+		repo.On("GetActive", performance.ID, user.ID).Return(nil, gorm.ErrRecordNotFound).Once()
+		repo.On("Create", mock.MatchedBy(func(e *model.WaitlistEntry) bool {
+			return e.Seats == 2 && e.Category == "parterre" && e.Status == "waiting"
+		})).Return(nil)
+		bookingsRepo.On("LockWaitlist", performance.ID).Return([]model.WaitlistEntry{}, nil)
+
+		entry := &model.WaitlistEntry{ID: uuid.New(), PerformanceID: performance.ID, UserID: user.ID, Seats: 2, Status: "waiting"}
+		repo.On("GetActive", performance.ID, user.ID).Return(entry, nil)
+		repo.On("Position", entry).Return(3, nil)
+
+		result, err := waitlist.Join(performance.ID.String(), user, 2, "parterre")
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Position)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("seats still available", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(48 * time.Hour), Status: "on_sale"}
+
+		repo.On("GetPerformance", performance.ID).Return(performance, nil)
+		repo.On("CountAvailableSeats", performance.ID, "").Return(int64(2), nil)
+
+		_, err := waitlist.Join(performance.ID.String(), &model.User{ID: uuid.New()}, 2, "")
+
+		assert.Equal(t, ErrSeatsAvailable, err)
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("already waiting", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(48 * time.Hour), Status: "on_sale"}
+		user := &model.User{ID: uuid.New()}
+
+		repo.On("GetPerformance", performance.ID).Return(performance, nil)
+		repo.On("CountAvailableSeats", performance.ID, "").Return(int64(0), nil)
+		repo.On("GetActive", performance.ID, user.ID).Return(&model.WaitlistEntry{Status: "waiting"}, nil)
+
+		_, err := waitlist.Join(performance.ID.String(), user, 1, "")
+
+		assert.Equal(t, ErrAlreadyWaitlisted, err)
+	})
+
+	t.Run("joined concurrently", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(48 * time.Hour), Status: "on_sale"}
+		user := &model.User{ID: uuid.New()}
+
+		repo.On("GetPerformance", performance.ID).Return(performance, nil)
+		repo.On("CountAvailableSeats", performance.ID, "").Return(int64(0), nil)
+		repo.On("GetActive", performance.ID, user.ID).Return(nil, gorm.ErrRecordNotFound)
+		// Параллельный запрос успел создать заявку после проверки
+		repo.On("Create", mock.Anything).Return(repository.ErrActiveWaitlistEntry)
+
+		_, err := waitlist.Join(performance.ID.String(), user, 1, "")
+
+		assert.Equal(t, ErrAlreadyWaitlisted, err)
+	})
+
+	t.Run("performance already started", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(-time.Minute), Status: "on_sale"}
+		repo.On("GetPerformance", performance.ID).Return(performance, nil)
+
+		_, err := waitlist.Join(performance.ID.String(), &model.User{ID: uuid.New()}, 1, "")
+
+		assert.Equal(t, ErrPerformanceStarted, err)
+	})
+}
+
+func TestWaitlistSeatsReleased(t *testing.T) {
+	t.Run("offers freed seats to first entry that fits", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(48 * time.Hour), Status: "on_sale"}
+		expired := model.Booking{ID: uuid.New(), PerformanceID: performance.ID, Status: "expired"}
+
+		large := model.WaitlistEntry{ID: uuid.New(), PerformanceID: performance.ID, UserID: uuid.New(), Seats: 4, Status: "waiting"}
+		small := model.WaitlistEntry{ID: uuid.New(), PerformanceID: performance.ID, UserID: uuid.New(), Seats: 2, Status: "waiting"}
+		seats := []model.PerformanceSeat{
+			{ID: uuid.New(), PerformanceID: performance.ID, Price: 2000, Status: "available"},
+			{ID: uuid.New(), PerformanceID: performance.ID, Price: 2000, Status: "available"},
+		}
+
+		bookingsRepo.On("CloseWaitlistOffers", []uuid.UUID{expired.ID}, "expired").Return(nil, nil)
+		bookingsRepo.On("LockWaitlist", performance.ID).Return([]model.WaitlistEntry{large, small}, nil)
+		bookingsRepo.On("GetPerformance", performance.ID).Return(performance, nil)
+		bookingsRepo.On("LockAvailableSeats", performance.ID, "", 4).Return(seats, nil)
+		bookingsRepo.On("LockAvailableSeats", performance.ID, "", 2).Return(seats, nil)
+		bookingsRepo.On("CountOccupiedSeats", performance.ID).Return(int64(10), int64(100), nil)
+
+		var offered *model.Booking
+		bookingsRepo.On("Create", mock.MatchedBy(func(b *model.Booking) bool {
+			return b.UserID != nil && *b.UserID == small.UserID && b.Status == "pending" && b.TotalPrice == 4000 &&
+				b.ExpiresAt.Equal(clock.now.Add(30*time.Minute))
+		})).Run(func(args mock.Arguments) {
+			offered = args.Get(0).(*model.Booking)
+		}).Return(nil)
+		bookingsRepo.On("UpdatePerformanceSeatStatus", mock.Anything, "reserved", mock.Anything).Return(nil)
+		bookingsRepo.On("SetChargedPrice", mock.Anything, 2000).Return(nil)
+		bookingsRepo.On("UpdateWaitlistEntry", mock.MatchedBy(func(e *model.WaitlistEntry) bool {
+			return e.ID == small.ID && e.Status == "offered" && e.BookingID != nil && *e.BookingID == offered.ID
+		})).Return(nil)
+
+		err := waitlist.SeatsReleased(bookingsRepo, []model.Booking{expired})
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertExpectations(t)
+		bookingsRepo.AssertNumberOfCalls(t, "Create", 1)
+		bookingsRepo.AssertNumberOfCalls(t, "UpdatePerformanceSeatStatus", 2)
+	})
+
+	t.Run("no offers for started performance", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		performance := &model.Performance{ID: uuid.New(), Date: clock.now.Add(-time.Minute), Status: "on_sale"}
+		cancelled := model.Booking{ID: uuid.New(), PerformanceID: performance.ID, Status: "cancelled"}
+		entry := model.WaitlistEntry{ID: uuid.New(), PerformanceID: performance.ID, Seats: 1, Status: "waiting"}
+
+		bookingsRepo.On("CloseWaitlistOffers", []uuid.UUID{cancelled.ID}, "cancelled").Return(nil, nil)
+		bookingsRepo.On("LockWaitlist", performance.ID).Return([]model.WaitlistEntry{entry}, nil)
+		bookingsRepo.On("GetPerformance", performance.ID).Return(performance, nil)
+
+		err := waitlist.SeatsReleased(bookingsRepo, []model.Booking{cancelled})
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertNotCalled(t, "LockAvailableSeats", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancelled booking hands seats to waitlist", func(t *testing.T) {
+		repo := new(MockWaitlistRepository)
+		bookingsRepo := new(MockBookingsRepository)
+		clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+		waitlist := NewWaitlist(repo, bookingsRepo, NewPricingEngine(time.UTC), clock, 30*time.Minute, 0, nil, nil)
+		service := NewBookings(bookingsRepo, new(MockUsersRepository), WithBookingsWaitlist(waitlist))
+
+		booking := &model.Booking{
+			ID:               uuid.New(),
+			PerformanceID:    uuid.New(),
+			Status:           "pending",
+			PerformanceSeats: []model.PerformanceSeat{{ID: uuid.New()}},
+		}
+		bookingsRepo.On("LockByID", booking.ID).Return(booking, nil)
+		bookingsRepo.On("UpdateStatus", booking.ID, "cancelled").Return(nil)
+		bookingsRepo.On("UpdatePerformanceSeatStatus", booking.PerformanceSeats[0].ID, "available", (*uuid.UUID)(nil)).Return(nil)
+		bookingsRepo.On("CloseWaitlistOffers", []uuid.UUID{booking.ID}, "cancelled").Return(nil, nil)
+		bookingsRepo.On("LockWaitlist", booking.PerformanceID).Return([]model.WaitlistEntry{}, nil)
+
+		err := service.CancelBooking(testActor, booking.ID.String())
+
+		assert.NoError(t, err)
+		bookingsRepo.AssertExpectations(t)
+	})
+}
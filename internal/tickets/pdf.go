@@ -0,0 +1,84 @@
+package tickets
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+//go:embed fonts/DejaVuSansCondensed.ttf
+var regularFont []byte
+
+//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+var boldFont []byte
+
+const fontFamily = "DejaVu"
+
+// Entry - один билет в PDF
+type Entry struct {
+	Code      string // подписанный код для QR
+	PlayTitle string
+	HallName  string
+	Date      time.Time
+	Section   string
+	Row       int
+	Number    int
+	Price     int
+}
+
+// RenderPDF формирует PDF, в котором каждый билет занимает отдельную страницу
+func RenderPDF(entries []Entry) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A5", "")
+	pdf.SetTitle("Электронные билеты", true)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", regularFont)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", boldFont)
+	pdf.SetAutoPageBreak(false, 0)
+
+	for i, entry := range entries {
+		png, err := QRCode(entry.Code, 512)
+		if err != nil {
+			return nil, err
+		}
+		image := fmt.Sprintf("qr-%d", i)
+		pdf.RegisterImageOptionsReader(image, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		pdf.AddPage()
+
+		pdf.SetFont(fontFamily, "B", 22)
+		pdf.SetXY(15, 15)
+		pdf.MultiCell(115, 10, entry.PlayTitle, "", "L", false)
+
+		pdf.SetFont(fontFamily, "", 13)
+		pdf.SetX(15)
+		pdf.CellFormat(115, 8, entry.Date.Format("02.01.2006 15:04"), "", 1, "L", false, 0, "")
+		pdf.SetX(15)
+		pdf.CellFormat(115, 8, entry.HallName, "", 1, "L", false, 0, "")
+
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "B", 15)
+		if entry.Section != "" {
+			pdf.SetX(15)
+			pdf.CellFormat(115, 9, "Сектор: "+entry.Section, "", 1, "L", false, 0, "")
+		}
+		pdf.SetX(15)
+		pdf.CellFormat(115, 9, fmt.Sprintf("Ряд %d, место %d", entry.Row, entry.Number), "", 1, "L", false, 0, "")
+
+		pdf.SetFont(fontFamily, "", 13)
+		pdf.SetX(15)
+		pdf.CellFormat(115, 8, fmt.Sprintf("Цена: %d", entry.Price), "", 1, "L", false, 0, "")
+
+		pdf.ImageOptions(image, 135, 20, 60, 60, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetXY(135, 82)
+		pdf.CellFormat(60, 5, "Предъявите код на входе", "", 1, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+package tickets
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// receiptWidth - ширина кассовой ленты в мм
+const receiptWidth = 80
+
+// ReceiptLine - одно проданное место в чеке
+type ReceiptLine struct {
+	Section string
+	Row     int
+	Number  int
+	Price   int
+}
+
+// Receipt - чек продажи в кассе
+type Receipt struct {
+	Number    string
+	BookingID string
+	IssuedAt  time.Time
+	Cashier   string
+	PlayTitle string
+	HallName  string
+	Date      time.Time
+	Lines     []ReceiptLine
+	Subtotal  int
+	Discount  int
+	Total     int
+	Refunded  int
+	Method    string // cash или card
+	Tendered  int    // только для оплаты наличными
+	Change    int
+	Currency  string
+}
+
+var receiptMethods = map[string]string{
+	"cash": "Наличные",
+	"card": "Банковская карта",
+}
+
+// RenderReceiptPDF формирует чек для печати на ленте шириной 80 мм.
+// Высота страницы подбирается по числу строк чека.
+func RenderReceiptPDF(receipt Receipt) ([]byte, error) {
+	height := 120 + 5*float64(len(receipt.Lines))
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: receiptWidth, Ht: height},
+	})
+	pdf.SetTitle("Кассовый чек", true)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", regularFont)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", boldFont)
+	pdf.SetMargins(5, 5, 5)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	width := float64(receiptWidth - 10)
+	line := func(left, right string) {
+		pdf.CellFormat(width/2, 5, left, "", 0, "L", false, 0, "")
+		pdf.CellFormat(width/2, 5, right, "", 1, "R", false, 0, "")
+	}
+	amount := func(value int) string {
+		return fmt.Sprintf("%d %s", value, receipt.Currency)
+	}
+	separator := func() {
+		y := pdf.GetY() + 1
+		pdf.Line(5, y, receiptWidth-5, y)
+		pdf.SetY(y + 2)
+	}
+
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(width, 6, "Кассовый чек № "+receipt.Number, "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 8)
+	pdf.CellFormat(width, 4, receipt.IssuedAt.Format("02.01.2006 15:04"), "", 1, "C", false, 0, "")
+	if receipt.Cashier != "" {
+		pdf.CellFormat(width, 4, "Кассир: "+receipt.Cashier, "", 1, "C", false, 0, "")
+	}
+	separator()
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.MultiCell(width, 5, receipt.PlayTitle, "", "L", false)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(width, 5, receipt.Date.Format("02.01.2006 15:04"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(width, 5, receipt.HallName, "", 1, "L", false, 0, "")
+	separator()
+
+	for _, l := range receipt.Lines {
+		seat := fmt.Sprintf("Ряд %d, место %d", l.Row, l.Number)
+		if l.Section != "" {
+			seat = l.Section + ", " + seat
+		}
+		line(seat, amount(l.Price))
+	}
+	separator()
+
+	if receipt.Discount > 0 {
+		line("Сумма", amount(receipt.Subtotal))
+		line("Скидка", "-"+amount(receipt.Discount))
+	}
+	pdf.SetFont(fontFamily, "B", 10)
+	line("Итого", amount(receipt.Total))
+	pdf.SetFont(fontFamily, "", 9)
+
+	method := receiptMethods[receipt.Method]
+	if method == "" {
+		method = receipt.Method
+	}
+	line("Оплата", method)
+	if receipt.Method == "cash" {
+		line("Получено", amount(receipt.Tendered))
+		line("Сдача", amount(receipt.Change))
+	}
+	if receipt.Refunded > 0 {
+		line("Возвращено", amount(receipt.Refunded))
+	}
+	separator()
+
+	pdf.SetFont(fontFamily, "", 7)
+	pdf.CellFormat(width, 4, "Бронирование "+receipt.BookingID, "", 1, "C", false, 0, "")
+	pdf.CellFormat(width, 4, "Спасибо за покупку!", "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,96 @@
+package tickets
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed ticket code")
+	ErrInvalidSignature = errors.New("invalid ticket signature")
+)
+
+// Payload - данные, зашитые в QR-код билета
+type Payload struct {
+	TicketID          uuid.UUID `json:"tid"`
+	BookingID         uuid.UUID `json:"bid"`
+	PerformanceID     uuid.UUID `json:"pid"`
+	PerformanceSeatID uuid.UUID `json:"sid"`
+	Nonce             string    `json:"n"`
+}
+
+// Signer подписывает и проверяет коды билетов. Подпись HMAC позволяет контролеру
+// проверить билет без обращения к базе.
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign возвращает код билета вида base64(payload).base64(hmac)
+func (s *Signer) Sign(payload Payload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Verify проверяет подпись кода и возвращает его содержимое
+func (s *Signer) Verify(token string) (*Payload, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrMalformedToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, ErrInvalidSignature
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, ErrMalformedToken
+	}
+	return &payload, nil
+}
+
+func (s *Signer) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// NewNonce возвращает случайное значение, делающее код каждого билета уникальным
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// QRCode рисует код билета в PNG
+func QRCode(token string, size int) ([]byte, error) {
+	return qrcode.Encode(token, qrcode.Medium, size)
+}
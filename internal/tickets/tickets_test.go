@@ -0,0 +1,93 @@
+package tickets
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret")
+	payload := Payload{
+		TicketID:          uuid.New(),
+		BookingID:         uuid.New(),
+		PerformanceID:     uuid.New(),
+		PerformanceSeatID: uuid.New(),
+		Nonce:             "abc",
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		code, err := signer.Sign(payload)
+		require.NoError(t, err)
+
+		verified, err := signer.Verify(code)
+
+		assert.NoError(t, err)
+		assert.Equal(t, payload, *verified)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		code, err := signer.Sign(payload)
+		require.NoError(t, err)
+
+		other := payload
+		other.PerformanceSeatID = uuid.New()
+		forged, err := signer.Sign(other)
+		require.NoError(t, err)
+
+		body, _, _ := strings.Cut(forged, ".")
+		_, signature, _ := strings.Cut(code, ".")
+
+		_, err = signer.Verify(body + "." + signature)
+
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		code, err := NewSigner("other").Sign(payload)
+		require.NoError(t, err)
+
+		_, err = signer.Verify(code)
+
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := signer.Verify("not-a-ticket")
+
+		assert.ErrorIs(t, err, ErrMalformedToken)
+	})
+}
+
+func TestRenderReceiptPDF(t *testing.T) {
+	receipt := Receipt{
+		Number:    "1A2B3C4D",
+		BookingID: uuid.NewString(),
+		IssuedAt:  time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC),
+		Cashier:   "Кассир",
+		PlayTitle: "Чайка",
+		HallName:  "Большой зал",
+		Date:      time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC),
+		Lines: []ReceiptLine{
+			{Section: "Партер", Row: 3, Number: 7, Price: 1500},
+			{Section: "Партер", Row: 3, Number: 8, Price: 1500},
+		},
+		Subtotal: 3000,
+		Discount: 300,
+		Total:    2700,
+		Method:   "cash",
+		Tendered: 3000,
+		Change:   300,
+		Currency: "RUB",
+	}
+
+	pdf, err := RenderReceiptPDF(receipt)
+
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF")))
+}